@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
@@ -42,6 +43,7 @@ func main() {
 		Timeout:      timeout,
 		MaxBodyBytes: maxBodyBytes,
 		Logger:       logger,
+		ScanRoots:    envList("ENGINE_WORKER_SCAN_ROOTS"),
 	})
 
 	mux := http.NewServeMux()
@@ -94,3 +96,11 @@ func envInt(name string, fallback int) int {
 	}
 	return parsed
 }
+
+func envList(name string) []string {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return nil
+	}
+	return filepath.SplitList(value)
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -87,3 +88,86 @@ func TestAuditCLI(t *testing.T) {
 		t.Fatalf("expected trailing newline in output")
 	}
 }
+
+func TestAuditCustomRulePacks(t *testing.T) {
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(repoRoot(t), "data", "ai-config-patterns.json"))
+	t.Setenv("HOME", t.TempDir())
+	silenceStderr(t)
+
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeFile(t, filepath.Join(repo, "AGENTS.md"), "# Agents\n\nBe nice.\n")
+	writeFile(t, filepath.Join(repo, ".markdowntown", "rules", "org.yaml"), "rules:\n  - id: ORG-TESTING\n    match:\n      paths: [\"AGENTS.md\"]\n    assert:\n      headings:\n        required: [\"## Testing\"]\n")
+
+	var runErr error
+	stdout := captureStdout(t, func() {
+		runErr = runAudit([]string{"--repo", repo, "--repo-only", "--compact", "--only", "ORG-TESTING"})
+	})
+	if runErr != nil {
+		t.Fatalf("runAudit: %v", runErr)
+	}
+	var output audit.Output
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		t.Fatalf("unmarshal: %v (%s)", err, stdout)
+	}
+	if len(output.Issues) != 1 || output.Issues[0].RuleID != "ORG-TESTING" || output.Issues[0].Paths[0].Path != "./AGENTS.md" {
+		t.Fatalf("unexpected issues: %#v", output.Issues)
+	}
+
+	if err := runAudit([]string{"--repo", repo, "--rules-dir", filepath.Join(repo, "missing")}); err == nil {
+		t.Fatalf("expected missing --rules-dir to fail")
+	}
+}
+
+func TestAuditRulePlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts require a POSIX shell")
+	}
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(repoRoot(t), "data", "ai-config-patterns.json"))
+	t.Setenv("HOME", t.TempDir())
+	silenceStderr(t)
+
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeFile(t, filepath.Join(repo, "AGENTS.md"), "# Agents\n")
+	rulesDir := filepath.Join(repo, ".markdowntown", "rules")
+	writeFile(t, filepath.Join(rulesDir, "plugins.yaml"), "plugins:\n  - id: ORG-PLUGIN\n    command: [\"./plugin.sh\"]\n    timeout: 10s\n")
+	writeFile(t, filepath.Join(rulesDir, "plugin.sh"), "#!/bin/sh\ncat >/dev/null\necho '{\"issues\":[{\"message\":\"from plugin\",\"paths\":[{\"path\":\"./AGENTS.md\",\"scope\":\"repo\"}]}]}'\n")
+	if err := os.Chmod(filepath.Join(rulesDir, "plugin.sh"), 0o700); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+
+	audited := func(args ...string) audit.Output {
+		t.Helper()
+		var runErr error
+		stdout := captureStdout(t, func() {
+			runErr = runAudit(append([]string{"--repo", repo, "--repo-only", "--compact"}, args...))
+		})
+		if runErr != nil {
+			t.Fatalf("runAudit: %v", runErr)
+		}
+		var output audit.Output
+		if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+			t.Fatalf("unmarshal: %v (%s)", err, stdout)
+		}
+		return output
+	}
+
+	if output := audited(); hasIssue(output.Issues, "ORG-PLUGIN") {
+		t.Fatalf("expected plugin to be skipped without --allow-plugins")
+	}
+
+	output := audited("--allow-plugins")
+	if !hasIssue(output.Issues, "ORG-PLUGIN") {
+		t.Fatalf("expected plugin issue, got %#v", output.Issues)
+	}
+	var timed bool
+	for _, timing := range output.Audit.RuleTimings {
+		if timing.RuleID == "ORG-PLUGIN" && timing.Issues == 1 {
+			timed = true
+		}
+	}
+	if !timed || len(output.Audit.RuleTimings) != len(audit.DefaultRules())+1 {
+		t.Fatalf("expected timings for every rule, got %#v", output.Audit.RuleTimings)
+	}
+}
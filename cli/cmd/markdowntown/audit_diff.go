@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"markdowntown-cli/internal/audit"
+	"markdowntown-cli/internal/git"
+	"markdowntown-cli/internal/scan"
+	"markdowntown-cli/internal/version"
+)
+
+const auditDiffUsage = `markdowntown audit diff
+
+Usage:
+  markdowntown audit diff --base <scan.json|git-ref> [--head <scan.json|git-ref>] [flags]
+
+Flags:
+  --base <scan.json|ref>    Base scan JSON file or git ref (required)
+  --head <scan.json|ref>    Head scan JSON file or git ref (default: working tree)
+  --repo <path>             Repo path used to resolve refs (defaults to git root)
+  --format <json|md>        Output format (default: json)
+  --compact                 Emit compact JSON (ignored for md)
+  --fail-severity <level>   Exit 1 when added issues at/above severity (error|warning|info)
+  --redact <mode>           Path redaction mode (auto|always|never)
+  --only <ruleId>           Run only these rule IDs (repeatable)
+  --ignore-rule <ruleId>    Suppress rule IDs (repeatable)
+  --exclude <glob>          Exclude paths from audit matching (repeatable)
+  --no-content              Exclude file contents from internal scans
+  --no-cache                Ignore and do not update the persistent scan cache
+  --rules-dir <path>        Custom YAML rule packs for both sides (default: .markdowntown/rules in --repo)
+  --allow-plugins           Run out-of-process plugin rules declared in rule packs
+  -h, --help                Show help
+`
+
+const (
+	diffSourceScan     = "scan"
+	diffSourceRef      = "ref"
+	diffSourceWorktree = "worktree"
+)
+
+type auditDiffOptions struct {
+	audit *auditOptions
+	base  string
+	head  string
+}
+
+func runAuditDiff(args []string) error {
+	opts, err := parseAuditDiffFlags(args)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	if opts.audit.help {
+		printAuditDiffUsage(os.Stdout)
+		return nil
+	}
+
+	registry, _, err := scan.LoadRegistry()
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+
+	if opts.audit.rulesDir == "" {
+		// Both sides use the working tree's rule packs so the rule set is identical.
+		if repoRoot, err := resolveRepoRoot(opts.audit.repoPath); err == nil {
+			dir := filepath.Join(repoRoot, filepath.FromSlash(audit.DefaultRulesDir))
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				opts.audit.rulesDir = dir
+			}
+		}
+	}
+
+	startedAt := time.Now()
+	baseOutput, baseSide, err := auditDiffSide(opts.base, opts.audit, registry)
+	if err != nil {
+		return newCLIError(fmt.Errorf("base: %w", err), 2)
+	}
+	headOutput, headSide, err := auditDiffSide(opts.head, opts.audit, registry)
+	if err != nil {
+		return newCLIError(fmt.Errorf("head: %w", err), 2)
+	}
+	threshold, err := audit.ParseSeverity(opts.audit.failSeverity)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+
+	added, resolved, unchanged := audit.DiffIssues(baseOutput.Issues, headOutput.Issues)
+	baseSide.Summary = baseOutput.Summary
+	headSide.Summary = headOutput.Summary
+	output := audit.DiffOutput{
+		SchemaVersion: version.AuditSchemaVersion,
+		Audit:         audit.Meta{ToolVersion: version.ToolVersion, AuditStartedAt: startedAt.UnixMilli(), GeneratedAt: time.Now().UnixMilli()},
+		Base:          baseSide,
+		Head:          headSide,
+		Summary:       audit.BuildDiffSummary(added, resolved, unchanged),
+		Added:         added,
+		Resolved:      resolved,
+		Unchanged:     unchanged,
+	}
+
+	if err := renderAuditDiffOutput(os.Stdout, output, opts.audit); err != nil {
+		return newCLIError(err, 2)
+	}
+	if audit.ShouldFail(added, threshold) {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func parseAuditDiffFlags(args []string) (*auditDiffOptions, error) {
+	opts := &auditDiffOptions{audit: &auditOptions{repoOnly: true}}
+	flags := flag.NewFlagSet("audit diff", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	flags.StringVar(&opts.base, "base", "", "base scan JSON or git ref")
+	flags.StringVar(&opts.head, "head", "", "head scan JSON or git ref")
+	flags.StringVar(&opts.audit.repoPath, "repo", "", "repo path (defaults to git root)")
+	flags.StringVar(&opts.audit.format, "format", "json", "output format (json or md)")
+	flags.BoolVar(&opts.audit.compact, "compact", false, "emit compact JSON")
+	flags.StringVar(&opts.audit.failSeverity, "fail-severity", string(audit.SeverityError), "exit 1 when added issues meet severity (error|warning|info)")
+	flags.StringVar(&opts.audit.redactMode, "redact", string(audit.RedactAuto), "path redaction mode (auto|always|never)")
+	flags.Var(&opts.audit.onlyRules, "only", "rule IDs to include (repeatable)")
+	flags.Var(&opts.audit.ignoreRules, "ignore-rule", "rule IDs to suppress (repeatable)")
+	flags.Var(&opts.audit.excludePaths, "exclude", "exclude path globs from audit matching (repeatable)")
+	flags.BoolVar(&opts.audit.noContent, "no-content", false, "exclude file contents from internal scans")
+	flags.BoolVar(&opts.audit.noCache, "no-cache", false, "disable the persistent scan cache")
+	flags.StringVar(&opts.audit.rulesDir, "rules-dir", "", "directory of custom YAML rule packs")
+	flags.BoolVar(&opts.audit.allowPlugins, "allow-plugins", false, "run out-of-process plugin rules from rule packs")
+	flags.BoolVar(&opts.audit.help, "help", false, "show help")
+	flags.BoolVar(&opts.audit.help, "h", false, "show help")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.audit.help {
+		return opts, nil
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	if strings.TrimSpace(opts.base) == "" {
+		return nil, fmt.Errorf("--base is required")
+	}
+	opts.audit.format = strings.ToLower(opts.audit.format)
+	if opts.audit.format != "json" && opts.audit.format != "md" {
+		return nil, fmt.Errorf("invalid format: %q (valid: json, md)", opts.audit.format)
+	}
+	return opts, nil
+}
+
+// auditDiffSide audits one side of a diff. Values naming an existing file are
+// read as scan JSON; anything else is resolved as a git ref and scanned from
+// the commit in memory. An empty value audits the working tree.
+func auditDiffSide(value string, opts *auditOptions, registry scan.Registry) (audit.Output, audit.DiffSide, error) {
+	startedAt := time.Now()
+	side := audit.DiffSide{Input: value}
+
+	var scanOutput scan.Output
+	switch {
+	case value == "":
+		side.Source = diffSourceWorktree
+		repoRoot, err := resolveRepoRoot(opts.repoPath)
+		if err != nil {
+			return audit.Output{}, side, err
+		}
+		scanOutput, err = scanAuditRoot(opts, registry, repoRoot, nil, nil)
+		if err != nil {
+			return audit.Output{}, side, err
+		}
+	case isRegularFile(value):
+		side.Source = diffSourceScan
+		var err error
+		scanOutput, err = readScanInput(value)
+		if err != nil {
+			return audit.Output{}, side, err
+		}
+	default:
+		side.Source = diffSourceRef
+		repoRoot, err := resolveRepoRoot(opts.repoPath)
+		if err != nil {
+			return audit.Output{}, side, err
+		}
+		commit, err := git.ResolveCommit(repoRoot, value)
+		if err != nil {
+			return audit.Output{}, side, err
+		}
+		side.Commit = commit
+		scanOutput, err = scanGitCommit(opts, registry, repoRoot, commit)
+		if err != nil {
+			return audit.Output{}, side, err
+		}
+	}
+
+	scanOutput, err := audit.FilterOutput(scanOutput, []string(opts.excludePaths))
+	if err != nil {
+		return audit.Output{}, side, err
+	}
+	output, _, err := executeAudit(scanOutput, registry, opts, startedAt)
+	if err != nil {
+		return audit.Output{}, side, err
+	}
+	return output, side, nil
+}
+
+// scanGitCommit scans a commit the way `audit --ref` does: from an in-memory
+// tree rooted at repoRoot, with the commit's own .gitignore rules applied.
+func scanGitCommit(opts *auditOptions, registry scan.Registry, repoRoot string, commit string) (scan.Output, error) {
+	tree, err := scan.LoadGitTree(repoRoot, commit, registry)
+	if err != nil {
+		return scan.Output{}, err
+	}
+	return scanAuditRoot(opts, registry, repoRoot, nil, tree)
+}
+
+func renderAuditDiffOutput(w io.Writer, output audit.DiffOutput, opts *auditOptions) error {
+	if opts.format == "md" {
+		_, err := fmt.Fprint(w, audit.RenderDiffMarkdown(output))
+		return err
+	}
+	enc := json.NewEncoder(w)
+	if !opts.compact {
+		enc.SetIndent("", "  ")
+	}
+	enc.SetEscapeHTML(false)
+	return enc.Encode(output)
+}
+
+func isRegularFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+func printAuditDiffUsage(w io.Writer) {
+	_, _ = fmt.Fprint(w, auditDiffUsage)
+}
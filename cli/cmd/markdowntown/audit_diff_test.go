@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/audit"
+)
+
+func TestAuditDiffRefAgainstWorktree(t *testing.T) {
+	repo := setupAuditDiffRepo(t)
+	writeFile(t, filepath.Join(repo, "AGENTS.md"), "# Instructions\n")
+
+	var runErr error
+	stdout := captureStdout(t, func() {
+		runErr = runAudit([]string{"diff", "--repo", repo, "--base", "HEAD", "--compact"})
+	})
+	if runErr != nil {
+		t.Fatalf("audit diff: %v", runErr)
+	}
+
+	var output audit.DiffOutput
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		t.Fatalf("unmarshal: %v (%s)", err, stdout)
+	}
+	if output.Base.Source != diffSourceRef || output.Base.Commit == "" {
+		t.Fatalf("unexpected base side: %#v", output.Base)
+	}
+	if output.Head.Source != diffSourceWorktree {
+		t.Fatalf("unexpected head side: %#v", output.Head)
+	}
+	if len(output.Added) != 0 {
+		t.Fatalf("expected no added issues, got %#v", output.Added)
+	}
+	if !hasIssue(output.Resolved, "MD004") {
+		t.Fatalf("expected MD004 to be resolved, got %#v", output.Resolved)
+	}
+}
+
+func TestAuditDiffRefAppliesCommitGitignore(t *testing.T) {
+	repo := setupAuditDiffRepo(t)
+	writeFile(t, filepath.Join(repo, ".gitignore"), "vendor/\n")
+	writeFile(t, filepath.Join(repo, "vendor", "AGENTS.md"), "# Vendored\n")
+	for _, args := range [][]string{
+		{"add", "-f", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", "vendor"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	var runErr error
+	stdout := captureStdout(t, func() {
+		runErr = runAudit([]string{"diff", "--repo", repo, "--base", "HEAD", "--compact"})
+	})
+	if runErr != nil {
+		t.Fatalf("audit diff: %v", runErr)
+	}
+	var output audit.DiffOutput
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		t.Fatalf("unmarshal: %v (%s)", err, stdout)
+	}
+	// Both sides see vendor/AGENTS.md as gitignored, so nothing changes.
+	if len(output.Added) != 0 || len(output.Resolved) != 0 {
+		t.Fatalf("expected identical sides, got added %#v resolved %#v", output.Added, output.Resolved)
+	}
+}
+
+func TestAuditDiffScanFileMarkdown(t *testing.T) {
+	repo := setupAuditDiffRepo(t)
+
+	var runErr error
+	scanJSON := captureStdout(t, func() {
+		runErr = runScan([]string{"--repo", repo, "--repo-only", "--quiet", "--compact"})
+	})
+	if runErr != nil {
+		t.Fatalf("scan: %v", runErr)
+	}
+	scanPath := filepath.Join(t.TempDir(), "scan.json")
+	writeFile(t, scanPath, scanJSON)
+
+	stdout := captureStdout(t, func() {
+		runErr = runAudit([]string{"diff", "--repo", repo, "--base", scanPath, "--head", "HEAD", "--format", "md"})
+	})
+	if runErr != nil {
+		t.Fatalf("audit diff: %v", runErr)
+	}
+	if !strings.Contains(stdout, "# markdowntown audit diff") || !strings.Contains(stdout, "Base: scan `"+scanPath+"`") {
+		t.Fatalf("unexpected markdown:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "No AI config issues were added or resolved.") {
+		t.Fatalf("expected identical sides, got:\n%s", stdout)
+	}
+}
+
+func TestAuditDiffRequiresBase(t *testing.T) {
+	if _, err := parseAuditDiffFlags([]string{"--head", "HEAD"}); err == nil {
+		t.Fatalf("expected missing --base error")
+	}
+	if _, err := parseAuditDiffFlags([]string{"--base", "HEAD", "--format", "csv"}); err == nil {
+		t.Fatalf("expected invalid format error")
+	}
+}
+
+func setupAuditDiffRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(repoRoot(t), "data", "ai-config-patterns.json"))
+	t.Setenv("HOME", t.TempDir())
+
+	silenceStderr(t)
+
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeFile(t, filepath.Join(repo, "AGENTS.md"), "")
+	for _, args := range [][]string{
+		{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+		}
+	}
+	return repo
+}
+
+func hasIssue(issues []audit.Issue, ruleID string) bool {
+	for _, issue := range issues {
+		if issue.RuleID == ruleID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"markdowntown-cli/internal/audit"
+	"markdowntown-cli/internal/fix"
+	"markdowntown-cli/internal/scan"
+
+	"github.com/spf13/afero"
+)
+
+// runAuditFix applies quick fixes for the audited issues and re-runs the audit.
+// With --fix-dry-run the diff is written to stdout and no files change.
+func runAuditFix(scanOutput scan.Output, registry scan.Registry, opts *auditOptions, startedAt time.Time) error {
+	auditOutput, _, err := executeAudit(scanOutput, registry, opts, startedAt)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+
+	fs := afero.NewOsFs()
+	fixes := collectAuditFixes(fs, scanOutput.RepoRoot, auditOutput.Issues, opts.fixUnsafe)
+	changes, skipped := fix.Plan(fs, fixes)
+	diff := fix.Diff(scanOutput.RepoRoot, changes)
+
+	if opts.fixDryRun {
+		_, _ = fmt.Fprint(os.Stdout, diff)
+		writeFixSummary(os.Stderr, changes, skipped, true)
+		return nil
+	}
+
+	if err := fix.Write(fs, changes); err != nil {
+		return newCLIError(err, 2)
+	}
+	_, _ = fmt.Fprint(os.Stderr, diff)
+	writeFixSummary(os.Stderr, changes, skipped, false)
+
+	rerunStartedAt := time.Now()
+	rerunScan, err := loadAuditInput(opts, registry)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	remaining, threshold, err := executeAudit(rerunScan, registry, opts, rerunStartedAt)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	if err := renderAuditOutput(remaining, opts); err != nil {
+		return newCLIError(err, 2)
+	}
+	if audit.ShouldFail(remaining.Issues, threshold) {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func collectAuditFixes(fs afero.Fs, repoRoot string, issues []audit.Issue, includeUnsafe bool) []fix.Fix {
+	var fixes []fix.Fix
+	for _, issue := range issues {
+		for _, candidate := range fix.ForIssue(fs, repoRoot, issue) {
+			if !candidate.Safe && !includeUnsafe {
+				continue
+			}
+			fixes = append(fixes, candidate)
+		}
+	}
+	return fixes
+}
+
+func writeFixSummary(w io.Writer, changes []fix.Change, skipped []fix.Fix, dryRun bool) {
+	applied := 0
+	for _, change := range changes {
+		applied += len(change.Fixes)
+	}
+	verb := "Applied"
+	if dryRun {
+		verb = "Would apply"
+	}
+	_, _ = fmt.Fprintf(w, "%s %d fix(es) to %d file(s)", verb, applied, len(changes))
+	if len(skipped) > 0 {
+		_, _ = fmt.Fprintf(w, "; skipped %d conflicting fix(es)", len(skipped))
+	}
+	_, _ = fmt.Fprintln(w, ".")
+}
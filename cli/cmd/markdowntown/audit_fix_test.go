@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/audit"
+)
+
+func TestAuditFixDryRunLeavesFiles(t *testing.T) {
+	repo := setupAuditFixRepo(t)
+
+	var runErr error
+	stdout := captureStdout(t, func() {
+		runErr = runAudit([]string{"--repo", repo, "--repo-only", "--fix-dry-run"})
+	})
+	if runErr != nil {
+		t.Fatalf("runAudit: %v", runErr)
+	}
+	if !strings.Contains(stdout, "+++ b/AGENTS.md") || !strings.Contains(stdout, "+# Instructions") {
+		t.Fatalf("expected placeholder diff, got %q", stdout)
+	}
+	if !strings.Contains(stdout, "+!AGENTS.md") {
+		t.Fatalf("expected gitignore allow entry in diff, got %q", stdout)
+	}
+
+	data, err := os.ReadFile(filepath.Join(repo, "AGENTS.md"))
+	if err != nil {
+		t.Fatalf("read AGENTS.md: %v", err)
+	}
+	if len(data) != 0 {
+		t.Fatalf("expected dry run to leave AGENTS.md empty, got %q", data)
+	}
+}
+
+func TestAuditFixAppliesAndReaudits(t *testing.T) {
+	repo := setupAuditFixRepo(t)
+
+	var runErr error
+	stdout := captureStdout(t, func() {
+		runErr = runAudit([]string{"--repo", repo, "--repo-only", "--fix", "--compact"})
+	})
+	if runErr != nil {
+		t.Fatalf("runAudit: %v", runErr)
+	}
+
+	data, err := os.ReadFile(filepath.Join(repo, "AGENTS.md"))
+	if err != nil {
+		t.Fatalf("read AGENTS.md: %v", err)
+	}
+	if string(data) != "# Instructions\n" {
+		t.Fatalf("expected placeholder content, got %q", data)
+	}
+	gitignore, err := os.ReadFile(filepath.Join(repo, ".gitignore"))
+	if err != nil {
+		t.Fatalf("read .gitignore: %v", err)
+	}
+	if string(gitignore) != "AGENTS.md\n!AGENTS.md\n" {
+		t.Fatalf("unexpected .gitignore: %q", gitignore)
+	}
+
+	var output audit.Output
+	if err := json.Unmarshal([]byte(stdout), &output); err != nil {
+		t.Fatalf("unmarshal output: %v (%s)", err, stdout)
+	}
+	for _, issue := range output.Issues {
+		if issue.RuleID == "MD002" || issue.RuleID == "MD004" {
+			t.Fatalf("expected %s to be fixed, got %#v", issue.RuleID, issue)
+		}
+	}
+}
+
+func TestAuditFixReplacesUnknownToolID(t *testing.T) {
+	root := repoRoot(t)
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+	t.Setenv("HOME", t.TempDir())
+	silenceStderr(t)
+
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	rule := filepath.Join(repo, ".github", "instructions", "style.instructions.md")
+	writeFile(t, rule, "---\ntoolId: claude-cod\n---\n# Style\n")
+
+	var runErr error
+	captureStdout(t, func() {
+		runErr = runAudit([]string{"--repo", repo, "--repo-only", "--fix", "--compact"})
+	})
+	if runErr != nil {
+		t.Fatalf("runAudit: %v", runErr)
+	}
+	data, err := os.ReadFile(rule)
+	if err != nil {
+		t.Fatalf("read rule: %v", err)
+	}
+	if string(data) != "---\ntoolId: claude-code\n---\n# Style\n" {
+		t.Fatalf("expected toolId replacement, got %q", data)
+	}
+}
+
+func TestAuditFixRejectsInput(t *testing.T) {
+	if _, err := parseAuditFlags([]string{"--input", "scan.json", "--fix"}); err == nil {
+		t.Fatalf("expected --fix with --input to fail")
+	}
+	if _, err := parseAuditFlags([]string{"--fix-unsafe"}); err == nil {
+		t.Fatalf("expected --fix-unsafe without --fix to fail")
+	}
+	if _, err := parseAuditFlags([]string{"--ref", "HEAD", "--fix"}); err == nil {
+		t.Fatalf("expected --fix with --ref to fail")
+	}
+}
+
+func setupAuditFixRepo(t *testing.T) string {
+	t.Helper()
+	root := repoRoot(t)
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+	t.Setenv("HOME", t.TempDir())
+
+	silenceStderr(t)
+
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeFile(t, filepath.Join(repo, "AGENTS.md"), "")
+	writeFile(t, filepath.Join(repo, ".gitignore"), "AGENTS.md\n")
+	return repo
+}
+
+func silenceStderr(t *testing.T) {
+	t.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open devnull: %v", err)
+	}
+	original := os.Stderr
+	os.Stderr = devNull
+	t.Cleanup(func() {
+		os.Stderr = original
+		_ = devNull.Close()
+	})
+}
@@ -37,6 +37,7 @@ func TestAuditGoldenJSON(t *testing.T) {
 	}
 	output.Audit.AuditStartedAt = 0
 	output.Audit.GeneratedAt = 0
+	output.Audit.RuleTimings = nil
 	output.SourceScan.RepoRoot = "<repoRoot>"
 	output.SourceScan.ScanStartedAt = 0
 	output.SourceScan.GeneratedAt = 0
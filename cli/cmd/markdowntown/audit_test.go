@@ -184,6 +184,7 @@ func normalizeOutput(t *testing.T, data []byte) audit.Output {
 	}
 	output.Audit.AuditStartedAt = 0
 	output.Audit.GeneratedAt = 0
+	output.Audit.RuleTimings = nil
 	return output
 }
 
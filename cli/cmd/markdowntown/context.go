@@ -13,6 +13,8 @@ import (
 	"markdowntown-cli/internal/instructions"
 	"markdowntown-cli/internal/scan"
 	"markdowntown-cli/internal/tui"
+
+	"github.com/spf13/afero"
 )
 
 const contextUsage = `markdowntown context
@@ -25,6 +27,7 @@ Flags:
   --json                Output context resolution as JSON
   --compare <c1,c2>     Compare two clients (comma-separated)
   --search <query>      Search across instruction files
+  --ref <rev>           Resolve against a commit, branch, or tag (requires --json)
   -h, --help            Show help
 `
 
@@ -40,12 +43,14 @@ func runContextWithIO(w io.Writer, args []string) error {
 	var jsonMode bool
 	var compareClients string
 	var searchQuery string
+	var ref string
 	var help bool
 
 	flags.StringVar(&repoPath, "repo", "", "repo path (defaults to git root)")
 	flags.BoolVar(&jsonMode, "json", false, "output context resolution as JSON")
 	flags.StringVar(&compareClients, "compare", "", "compare two clients (comma-separated)")
 	flags.StringVar(&searchQuery, "search", "", "search across instruction files")
+	flags.StringVar(&ref, "ref", "", "git revision to resolve against")
 	flags.BoolVar(&help, "help", false, "show help")
 	flags.BoolVar(&help, "h", false, "show help")
 
@@ -77,8 +82,12 @@ func runContextWithIO(w io.Writer, args []string) error {
 		jsonMode = true
 	}
 
+	if ref != "" && !jsonMode {
+		return fmt.Errorf("--ref requires --json")
+	}
+
 	if jsonMode {
-		return runContextJSON(w, repoRoot, targetPath, compareClients, searchQuery)
+		return runContextJSON(w, repoRoot, targetPath, compareClients, searchQuery, ref)
 	}
 
 	return tui.Start(repoRoot)
@@ -97,7 +106,9 @@ func printContextUsage(w io.Writer) {
 	_, _ = fmt.Fprint(w, contextUsage)
 }
 
-func runContextJSON(w io.Writer, repoRoot, targetPath, compare, search string) error {
+// runContextJSON resolves and optionally searches instruction files. When ref
+// is set, files are read from that commit instead of the working copy.
+func runContextJSON(w io.Writer, repoRoot, targetPath, compare, search, ref string) error {
 	absTarget, err := filepath.Abs(targetPath)
 	if err != nil {
 		return err
@@ -117,23 +128,38 @@ func runContextJSON(w io.Writer, repoRoot, targetPath, compare, search string) e
 	if err != nil {
 		return err
 	}
+	var tree *scan.GitTree
+	if ref != "" {
+		tree, err = scan.LoadGitTree(repoRoot, ref, registry)
+		if err != nil {
+			return err
+		}
+	}
 
 	engine := context_pkg.NewEngine()
 	clients := instructions.AllClients()
 
-	res, err := engine.ResolveContext(context.Background(), context_pkg.ResolveOptions{
+	resolveOpts := context_pkg.ResolveOptions{
 		RepoRoot: repoRoot,
 		FilePath: relPath,
 		Clients:  clients,
 		Registry: &registry,
-	})
+	}
+	var fs afero.Fs
+	if tree != nil {
+		fs = tree.Fs
+		resolveOpts.Fs = tree.Fs
+		resolveOpts.CheckIgnore = tree.CheckIgnore
+	}
+
+	res, err := engine.ResolveContext(context.Background(), resolveOpts)
 	if err != nil {
 		return err
 	}
 
 	var searchResults []context_pkg.SearchResult
 	if search != "" {
-		searchResults, err = context_pkg.SearchInstructions(context.Background(), repoRoot, registry, search)
+		searchResults, err = context_pkg.SearchInstructions(context.Background(), fs, repoRoot, registry, search)
 		if err != nil {
 			return err
 		}
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -74,3 +75,37 @@ func setupContextRepo(t *testing.T) string {
 
 	return repo
 }
+
+func TestContextJSONRefReadsCommit(t *testing.T) {
+	repo := setupContextRepo(t)
+	runGit(t, repo, "add", "-A")
+	runGit(t, repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init")
+	if err := os.WriteFile(filepath.Join(repo, "AGENTS.md"), []byte("uncommitted marker"), 0o600); err != nil {
+		t.Fatalf("rewrite AGENTS.md: %v", err)
+	}
+	target := filepath.Join(repo, "AGENTS.md")
+
+	var out bytes.Buffer
+	if err := runContextWithIO(&out, []string{"--json", "--repo", repo, "--ref", "HEAD", "--search", "deterministic", target}); err != nil {
+		t.Fatalf("runContextWithIO: %v", err)
+	}
+	var payload context_pkg.JSONOutput
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal context JSON: %v", err)
+	}
+	if len(payload.Search) == 0 {
+		t.Fatalf("expected search results from committed files")
+	}
+
+	out.Reset()
+	if err := runContextWithIO(&out, []string{"--json", "--repo", repo, "--ref", "HEAD", "--search", "uncommitted marker", target}); err != nil {
+		t.Fatalf("runContextWithIO: %v", err)
+	}
+	payload = context_pkg.JSONOutput{}
+	if err := json.Unmarshal(out.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal context JSON: %v", err)
+	}
+	if len(payload.Search) != 0 {
+		t.Fatalf("expected working-copy edits to be ignored, got %#v", payload.Search)
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"markdowntown-cli/internal/audit"
+	"markdowntown-cli/internal/fleet"
+	"markdowntown-cli/internal/scan"
+	"markdowntown-cli/internal/version"
+
+	"github.com/spf13/afero"
+)
+
+const scanFleetUsage = `markdowntown scan-fleet
+
+Usage:
+  markdowntown scan-fleet --repos-file <path> [flags]
+
+Flags:
+  --repos-file <path>   File listing local repo paths or git URLs, one per line (- for stdin)
+  --concurrency <n>     Repos scanned in parallel (default: 4)
+  --format <json|md|csv> Output format for stdout (default: json)
+  --summary <path>      Also write a summary file (.csv for CSV, otherwise Markdown)
+  --cache-dir <path>    Reuse clones of git URLs stored under path
+  --rules-dir <path>    Apply custom rule packs from path to every repo
+  --compact             Emit compact JSON (ignored for md/csv)
+  --quiet               Disable per-repo progress output
+  -h, --help            Show help
+`
+
+const defaultFleetConcurrency = 4
+
+type fleetOptions struct {
+	reposFile   string
+	concurrency int
+	format      string
+	summaryPath string
+	cacheDir    string
+	rulesDir    string
+	compact     bool
+	quiet       bool
+	help        bool
+}
+
+func runScanFleet(args []string) error {
+	return runScanFleetWithIO(os.Stdout, os.Stdin, args)
+}
+
+func runScanFleetWithIO(stdout io.Writer, stdin io.Reader, args []string) error {
+	opts, err := parseFleetFlags(args)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	if opts.help {
+		_, _ = fmt.Fprint(stdout, scanFleetUsage)
+		return nil
+	}
+
+	sources, baseDir, err := readFleetSources(opts.reposFile, stdin)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	if len(sources) == 0 {
+		return newCLIError(fmt.Errorf("no repos listed in %s", opts.reposFile), 2)
+	}
+
+	registry, _, err := scan.LoadRegistry()
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+
+	// Repo-local .markdowntown/rules packs are ignored: sparse clones of git
+	// URLs never check them out, and local paths must audit the same way.
+	rules, err := auditRules(&auditOptions{rulesDir: opts.rulesDir}, "")
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+
+	progress := newFleetProgress(len(sources), opts.quiet)
+	repos := fleet.Run(sources, opts.concurrency, func(source string) fleet.Repo {
+		repo := scanFleetRepo(source, baseDir, registry, rules, opts)
+		progress(repo)
+		return repo
+	})
+
+	report := fleet.Report{
+		SchemaVersion: version.FleetSchemaVersion,
+		ToolVersion:   version.ToolVersion,
+		GeneratedAt:   time.Now().UnixMilli(),
+		Rules:         fleet.Rules{RulesDir: opts.rulesDir},
+		Summary:       fleet.BuildSummary(repos),
+		Repos:         repos,
+	}
+
+	if opts.summaryPath != "" {
+		if err := writeFleetSummary(opts.summaryPath, report); err != nil {
+			return newCLIError(err, 2)
+		}
+	}
+	if err := renderFleetReport(stdout, report, opts.format, opts.compact); err != nil {
+		return newCLIError(err, 2)
+	}
+	if report.Summary.Failed > 0 {
+		return newCLIError(fmt.Errorf("%d of %d repos failed", report.Summary.Failed, report.Summary.Repos), 1)
+	}
+	return nil
+}
+
+func parseFleetFlags(args []string) (*fleetOptions, error) {
+	opts := &fleetOptions{}
+	flags := flag.NewFlagSet("scan-fleet", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	flags.StringVar(&opts.reposFile, "repos-file", "", "file listing repo paths or git URLs")
+	flags.IntVar(&opts.concurrency, "concurrency", defaultFleetConcurrency, "repos scanned in parallel")
+	flags.StringVar(&opts.format, "format", "json", "output format (json, md, or csv)")
+	flags.StringVar(&opts.summaryPath, "summary", "", "also write a Markdown or CSV summary")
+	flags.StringVar(&opts.cacheDir, "cache-dir", "", "directory for reusable clones")
+	flags.StringVar(&opts.rulesDir, "rules-dir", "", "custom rule packs applied to every repo")
+	flags.BoolVar(&opts.compact, "compact", false, "emit compact JSON")
+	flags.BoolVar(&opts.quiet, "quiet", false, "disable progress output")
+	flags.BoolVar(&opts.help, "help", false, "show help")
+	flags.BoolVar(&opts.help, "h", false, "show help")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.help {
+		return opts, nil
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	if opts.reposFile == "" {
+		return nil, fmt.Errorf("--repos-file is required")
+	}
+	if opts.concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be >= 1")
+	}
+	opts.format = strings.ToLower(opts.format)
+	if opts.format != "json" && opts.format != "md" && opts.format != "csv" {
+		return nil, fmt.Errorf("invalid format: %q (valid: json, md, csv)", opts.format)
+	}
+	return opts, nil
+}
+
+// readFleetSources returns the listed repos and the directory relative paths
+// are resolved against (the list file's directory, or cwd for stdin).
+func readFleetSources(path string, stdin io.Reader) ([]string, string, error) {
+	if path == "-" {
+		sources, err := fleet.ParseReposFile(stdin)
+		if err != nil {
+			return nil, "", err
+		}
+		cwd, err := os.Getwd()
+		return sources, cwd, err
+	}
+	file, err := os.Open(path) //nolint:gosec // user-provided repo list
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	sources, err := fleet.ParseReposFile(file)
+	if err != nil {
+		return nil, "", err
+	}
+	baseDir, err := filepath.Abs(filepath.Dir(path))
+	return sources, baseDir, err
+}
+
+// scanFleetRepo scans and audits one repo with the fleet-wide rules. Failures
+// are recorded on the returned repo so one bad entry does not stop the fleet.
+func scanFleetRepo(source string, baseDir string, registry scan.Registry, rules []audit.Rule, opts *fleetOptions) fleet.Repo {
+	var repoRoot string
+	if fleet.IsRemote(source) {
+		clone, err := scan.CloneRemote(source, scan.RemoteOptions{
+			SparsePatterns: scan.SparsePatterns(registry),
+			CacheDir:       opts.cacheDir,
+		})
+		if err != nil {
+			return fleet.FailedRepo(source, err)
+		}
+		defer clone.Cleanup()
+		repoRoot = clone.Dir
+	} else {
+		path := source
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return fleet.FailedRepo(source, err)
+		}
+		if !info.IsDir() {
+			return fleet.FailedRepo(source, fmt.Errorf("not a directory: %s", path))
+		}
+		repoRoot = filepath.Clean(path)
+	}
+
+	startedAt := time.Now()
+	result, err := scan.Scan(scan.Options{
+		RepoRoot:       repoRoot,
+		RepoOnly:       true,
+		IncludeContent: true,
+		Registry:       registry,
+		Fs:             afero.NewOsFs(),
+	})
+	if err != nil {
+		return fleet.FailedRepo(source, err)
+	}
+	result, err = scan.ApplyGitignore(result, repoRoot)
+	if err != nil {
+		return fleet.FailedRepo(source, err)
+	}
+	finishedAt := time.Now()
+	output := scan.BuildOutput(result, scan.OutputOptions{
+		SchemaVersion:   version.SchemaVersion,
+		RegistryVersion: registry.Version,
+		ToolVersion:     version.ToolVersion,
+		RepoRoot:        repoRoot,
+		ScanStartedAt:   startedAt.UnixMilli(),
+		GeneratedAt:     finishedAt.UnixMilli(),
+		Timing:          scan.Timing{DiscoveryMs: elapsedMs(startedAt, finishedAt), TotalMs: elapsedMs(startedAt, finishedAt)},
+	})
+
+	auditOutput, _, err := executeAudit(output, registry, &auditOptions{
+		failSeverity: string(audit.SeverityError),
+		redactMode:   string(audit.RedactAuto),
+		rules:        rules,
+	}, finishedAt)
+	if err != nil {
+		return fleet.FailedRepo(source, err)
+	}
+	return fleet.NewRepo(source, output, auditOutput)
+}
+
+// newFleetProgress returns a callback that reports each finished repo on
+// stderr. It is safe to call from pool workers.
+func newFleetProgress(total int, quiet bool) func(fleet.Repo) {
+	if quiet {
+		return func(fleet.Repo) {}
+	}
+	var mu sync.Mutex
+	count := 0
+	return func(repo fleet.Repo) {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+		status := "ok"
+		if repo.Error != "" {
+			status = "failed"
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "[%d/%d] %s %s\n", count, total, status, repo.Source)
+	}
+}
+
+func renderFleetReport(w io.Writer, report fleet.Report, format string, compact bool) error {
+	switch format {
+	case "md":
+		_, err := io.WriteString(w, fleet.RenderMarkdown(report))
+		return err
+	case "csv":
+		return fleet.WriteCSV(w, report)
+	default:
+		enc := json.NewEncoder(w)
+		if !compact {
+			enc.SetIndent("", "  ")
+		}
+		enc.SetEscapeHTML(false)
+		return enc.Encode(report)
+	}
+}
+
+func writeFleetSummary(path string, report fleet.Report) error {
+	file, err := os.Create(path) //nolint:gosec // user-provided output path
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = fleet.WriteCSV(file, report)
+	} else {
+		_, err = io.WriteString(file, fleet.RenderMarkdown(report))
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/fleet"
+)
+
+func TestScanFleetCLI(t *testing.T) {
+	root := repoRoot(t)
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+
+	dir := t.TempDir()
+	withAgents := filepath.Join(dir, "with-agents")
+	bare := filepath.Join(dir, "bare")
+	for _, repo := range []string{withAgents, bare} {
+		if err := os.MkdirAll(repo, 0o750); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		runGit(t, repo, "init")
+	}
+	if err := os.WriteFile(filepath.Join(withAgents, "AGENTS.md"), []byte("# Agents\n\nRun tests.\n"), 0o600); err != nil {
+		t.Fatalf("write AGENTS.md: %v", err)
+	}
+
+	reposFile := filepath.Join(dir, "repos.txt")
+	list := "# fleet\nwith-agents\nbare\nmissing\n"
+	if err := os.WriteFile(reposFile, []byte(list), 0o600); err != nil {
+		t.Fatalf("write repos file: %v", err)
+	}
+	summaryPath := filepath.Join(dir, "summary.csv")
+
+	var stdout bytes.Buffer
+	err := runScanFleetWithIO(&stdout, strings.NewReader(""), []string{"--repos-file", reposFile, "--summary", summaryPath, "--concurrency", "2", "--quiet"})
+	var cliErr *cliError
+	if !errors.As(err, &cliErr) || cliErr.code != 1 {
+		t.Fatalf("expected exit code 1 for failed repo, got %v", err)
+	}
+
+	var report fleet.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("unmarshal report: %v\n%s", err, stdout.String())
+	}
+	if len(report.Repos) != 3 || report.Summary.Scanned != 2 || report.Summary.Failed != 1 {
+		t.Fatalf("unexpected summary: %+v", report.Summary)
+	}
+	if report.Repos[0].Source != "with-agents" || report.Repos[0].InstructionFiles == 0 {
+		t.Fatalf("expected AGENTS.md to be counted: %+v", report.Repos[0])
+	}
+	if report.Repos[2].Error == "" {
+		t.Fatalf("expected missing repo to fail: %+v", report.Repos[2])
+	}
+	if len(report.Summary.MissingInstructions) != 1 || report.Summary.MissingInstructions[0] != "bare" {
+		t.Fatalf("unexpected missing instructions: %v", report.Summary.MissingInstructions)
+	}
+
+	data, err := os.ReadFile(summaryPath) //nolint:gosec // test path
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "source,status,") || !strings.Contains(string(data), "\nbare,ok,") {
+		t.Fatalf("unexpected csv summary:\n%s", data)
+	}
+}
+
+func TestScanFleetCLIStdinMarkdown(t *testing.T) {
+	root := repoRoot(t)
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+
+	repo := t.TempDir()
+	runGit(t, repo, "init")
+
+	var stdout bytes.Buffer
+	err := runScanFleetWithIO(&stdout, strings.NewReader(repo+"\n"), []string{"--repos-file", "-", "--format", "md", "--quiet"})
+	if err != nil {
+		t.Fatalf("scan-fleet: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "# markdowntown fleet\n") || !strings.Contains(stdout.String(), "## Missing instructions") {
+		t.Fatalf("unexpected markdown:\n%s", stdout.String())
+	}
+}
+
+func TestScanFleetCLIRequiresReposFile(t *testing.T) {
+	err := runScanFleetWithIO(&bytes.Buffer{}, strings.NewReader(""), nil)
+	var cliErr *cliError
+	if !errors.As(err, &cliErr) || cliErr.code != 2 {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+}
+
+func TestScanFleetCLIIgnoresRepoRulePacks(t *testing.T) {
+	root := repoRoot(t)
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+
+	repo := t.TempDir()
+	runGit(t, repo, "init")
+	writeFile(t, filepath.Join(repo, "AGENTS.md"), "# Agents\n\nRun tests.\n")
+	pack := "rules:\n  - id: ORG-TESTING\n    match:\n      paths: [\"AGENTS.md\"]\n    assert:\n      headings:\n        required: [\"## Testing\"]\n"
+	writeFile(t, filepath.Join(repo, ".markdowntown", "rules", "org.yaml"), pack)
+
+	orgRules := filepath.Join(t.TempDir(), "rules")
+	writeFile(t, filepath.Join(orgRules, "org.yaml"), pack)
+
+	for _, tt := range []struct {
+		name     string
+		rulesDir string
+		want     int
+	}{
+		{name: "repo packs ignored", want: 0},
+		{name: "rules dir", rulesDir: orgRules, want: 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			args := []string{"--repos-file", "-", "--quiet"}
+			if tt.rulesDir != "" {
+				args = append(args, "--rules-dir", tt.rulesDir)
+			}
+			if err := runScanFleetWithIO(&stdout, strings.NewReader(repo+"\n"), args); err != nil {
+				t.Fatalf("scan-fleet: %v", err)
+			}
+			var report fleet.Report
+			if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+				t.Fatalf("unmarshal report: %v", err)
+			}
+			if report.Rules.RepoRulePacks || report.Rules.RulesDir != tt.rulesDir {
+				t.Fatalf("unexpected rules record: %+v", report.Rules)
+			}
+			if got := report.Summary.IssuesByRule["ORG-TESTING"]; got != tt.want {
+				t.Fatalf("expected %d ORG-TESTING issues, got %d", tt.want, got)
+			}
+		})
+	}
+}
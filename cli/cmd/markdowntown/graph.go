@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"markdowntown-cli/internal/scan"
+
+	"github.com/spf13/afero"
+)
+
+const graphUsage = `markdowntown graph
+
+Usage:
+  markdowntown graph [flags]
+
+Flags:
+  --repo <path>                 Repo path (defaults to git root from cwd)
+  --repo-only                   Exclude user scope; scan repo only
+  --input <path|->              Render the graph from scan JSON produced with scan --graph
+  --format <dot|mermaid|json>   Output format (default: dot)
+  --compact                     Emit compact JSON (ignored for dot/mermaid)
+  --quiet                       Do not print dangling-edge and cycle warnings
+  -h, --help                    Show help
+`
+
+type graphOptions struct {
+	repoPath  string
+	repoOnly  bool
+	inputPath string
+	format    string
+	compact   bool
+	quiet     bool
+	help      bool
+}
+
+func runGraph(args []string) error {
+	opts, err := parseGraphFlags(args)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	if opts.help {
+		_, _ = fmt.Fprint(os.Stdout, graphUsage)
+		return nil
+	}
+
+	var output scan.Output
+	if opts.inputPath != "" {
+		output, err = readScanInput(opts.inputPath)
+		if err != nil {
+			return newCLIError(err, 2)
+		}
+		if output.Graph == nil {
+			return newCLIError(fmt.Errorf("scan input has no graph; rerun scan with --graph"), 2)
+		}
+	} else {
+		output, err = scanGraph(opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !opts.quiet {
+		for _, warning := range output.Warnings {
+			if warning.Code == scan.WarningGraphDangling || warning.Code == scan.WarningGraphCycle {
+				_, _ = fmt.Fprintf(os.Stderr, "warning: %s: %s\n", warning.Path, warning.Message)
+			}
+		}
+	}
+	return writeGraph(os.Stdout, output, opts.format, opts.compact)
+}
+
+func parseGraphFlags(args []string) (*graphOptions, error) {
+	opts := &graphOptions{}
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	flags.StringVar(&opts.repoPath, "repo", "", "repo path (defaults to git root)")
+	flags.BoolVar(&opts.repoOnly, "repo-only", false, "exclude user scope")
+	flags.StringVar(&opts.inputPath, "input", "", "read scan JSON from file or stdin (-)")
+	flags.StringVar(&opts.format, "format", "dot", "output format (dot, mermaid, or json)")
+	flags.BoolVar(&opts.compact, "compact", false, "emit compact JSON")
+	flags.BoolVar(&opts.quiet, "quiet", false, "suppress graph warnings")
+	flags.BoolVar(&opts.help, "help", false, "show help")
+	flags.BoolVar(&opts.help, "h", false, "show help")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.help {
+		return opts, nil
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	if opts.inputPath != "" && (opts.repoPath != "" || opts.repoOnly) {
+		return nil, fmt.Errorf("--input cannot be combined with --repo or --repo-only")
+	}
+	opts.format = strings.ToLower(opts.format)
+	if opts.format != "dot" && opts.format != "mermaid" && opts.format != "json" {
+		return nil, fmt.Errorf("invalid format: %q (valid: dot, mermaid, json)", opts.format)
+	}
+	return opts, nil
+}
+
+// scanGraph scans the repo with reference resolution enabled.
+func scanGraph(opts *graphOptions) (scan.Output, error) {
+	repoRoot, err := resolveRepoRoot(opts.repoPath)
+	if err != nil {
+		return scan.Output{}, err
+	}
+	registry, _, err := scan.LoadRegistry()
+	if err != nil {
+		return scan.Output{}, err
+	}
+	result, err := scan.Scan(scan.Options{
+		RepoRoot: repoRoot,
+		RepoOnly: opts.repoOnly,
+		Registry: registry,
+		Fs:       afero.NewOsFs(),
+		Graph:    true,
+	})
+	if err != nil {
+		return scan.Output{}, err
+	}
+	result, err = scan.ApplyGitignore(result, repoRoot)
+	if err != nil {
+		return scan.Output{}, err
+	}
+	return scan.BuildOutput(result, scan.OutputOptions{RepoRoot: repoRoot, RegistryVersion: registry.Version}), nil
+}
+
+func writeGraph(w io.Writer, output scan.Output, format string, compact bool) error {
+	switch format {
+	case "mermaid":
+		_, err := io.WriteString(w, scan.RenderGraphMermaid(output.Graph, output.RepoRoot))
+		return err
+	case "json":
+		enc := json.NewEncoder(w)
+		if !compact {
+			enc.SetIndent("", "  ")
+		}
+		enc.SetEscapeHTML(false)
+		return enc.Encode(output.Graph)
+	default:
+		_, err := io.WriteString(w, scan.RenderGraphDOT(output.Graph, output.RepoRoot))
+		return err
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/scan"
+)
+
+func TestGraphCLI(t *testing.T) {
+	root := repoRoot(t)
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+	silenceStderr(t)
+
+	repo := t.TempDir()
+	runGit(t, repo, "init")
+	if err := os.WriteFile(filepath.Join(repo, "CLAUDE.md"), []byte("@AGENTS.md\nSee [missing](docs/missing.md).\n"), 0o600); err != nil {
+		t.Fatalf("write CLAUDE.md: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "AGENTS.md"), []byte("# Agents\n"), 0o600); err != nil {
+		t.Fatalf("write AGENTS.md: %v", err)
+	}
+
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = runGraph([]string{"--repo", repo, "--repo-only", "--format", "mermaid"})
+	})
+	if runErr != nil {
+		t.Fatalf("runGraph: %v", runErr)
+	}
+	if !strings.Contains(out, `["AGENTS.md"]`) || !strings.Contains(out, "-->|claude-import|") || !strings.Contains(out, "-.->|markdown-link|") {
+		t.Fatalf("unexpected mermaid output:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		runErr = runScan([]string{"--repo", repo, "--repo-only", "--graph", "--quiet", "--compact"})
+	})
+	if runErr != nil {
+		t.Fatalf("runScan --graph: %v", runErr)
+	}
+	var output scan.Output
+	if err := json.Unmarshal([]byte(out), &output); err != nil {
+		t.Fatalf("unmarshal scan output: %v", err)
+	}
+	if output.Graph == nil || len(output.Graph.Edges) != 2 {
+		t.Fatalf("expected graph with 2 edges, got %+v", output.Graph)
+	}
+	inputPath := filepath.Join(t.TempDir(), "scan.json")
+	if err := os.WriteFile(inputPath, []byte(out), 0o600); err != nil {
+		t.Fatalf("write scan json: %v", err)
+	}
+	out = captureStdout(t, func() {
+		runErr = runGraph([]string{"--input", inputPath, "--format", "dot"})
+	})
+	if runErr != nil {
+		t.Fatalf("runGraph --input: %v", runErr)
+	}
+	if !strings.HasPrefix(out, "digraph markdowntown {") || !strings.Contains(out, `[label="claude-import"]`) {
+		t.Fatalf("unexpected dot output:\n%s", out)
+	}
+
+	if err := runGraph([]string{"--format", "svg"}); err == nil {
+		t.Fatalf("expected invalid format error")
+	}
+}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -36,12 +37,19 @@ Usage:
   markdowntown pull [flags]        # Pull and apply patches from the web app
   markdowntown scan [flags]        # Scan for AI config files
   markdowntown scan-remote [flags] # Scan a remote git repository
+  markdowntown scan-fleet [flags]  # Scan and audit many repositories
+  markdowntown graph [flags]       # Render imports and links between config files
   markdowntown suggest [flags]     # Generate evidence-backed suggestions
   markdowntown resolve [flags]     # Resolve effective instruction chain
+  markdowntown sources discover    # Propose doc sources from robots.txt and sitemaps
   markdowntown context [flags]     # Explore context for files (TUI or JSON)
   markdowntown audit [flags]       # Audit scan results
+  markdowntown audit diff [flags]  # Compare audit issues between scans or refs
   markdowntown serve               # Start LSP server
   markdowntown registry validate   # Validate pattern registry
+  markdowntown registry test       # Check pattern examples against the registry
+  markdowntown rules list          # List audit rules and their enabled state
+  markdowntown rules explain <id>  # Show documentation for a rule
   markdowntown tools list          # List recognized tools
 
 Flags:
@@ -57,6 +65,9 @@ Usage:
 Flags:
   --repo <path>         Repo path (defaults to git root from cwd)
   --repo-only           Exclude user scope; scan repo only
+  --ref <rev>           Scan a commit, branch, or tag without checking it out (implies --repo-only)
+  --archive <file>      Scan a tar, tar.gz, or zip archive without extracting it (implies --repo-only)
+  --strip-components <n> Drop n leading path elements from archive entries
   --global-scope        Include global/system scope roots (e.g., /etc)
   --global-max-files <n> Max files to scan in global scope (0 = unlimited)
   --global-max-bytes <n> Max bytes to scan in global scope (0 = unlimited)
@@ -65,11 +76,15 @@ Flags:
   --stdin               Read additional paths from stdin (one per line)
   --include-content     Include file contents in output (default)
   --no-content          Exclude file contents from output
+  --no-cache            Ignore and do not update the persistent scan cache
+  --graph               Include the import/link graph between config files
   --format <json|jsonl> Output format (default: json)
   --jsonl               Emit JSONL output (alias for --format jsonl)
   --compact             Emit compact JSON (ignored for jsonl)
   --quiet               Disable progress output
   --for-file <path>     Filter output to configs applicable to path
+  --watch               Keep running and stream JSONL added/changed/removed events
+  --watch-debounce <d>  Quiet period before rescanning changed paths (default: 200ms)
   -h, --help            Show help
 `
 
@@ -80,6 +95,8 @@ Usage:
 
 Flags:
   --ref <ref>           Git reference (branch, tag, commit) to checkout
+  --keep                Keep the clone after scanning and print its path
+  --cache-dir <path>    Reuse clones stored under path across runs (implies --keep)
   --repo-only           Exclude user scope; scan repo only
   --global-scope        Include global/system scope roots (e.g., /etc)
   --global-max-files <n> Max files to scan in global scope (0 = unlimited)
@@ -112,6 +129,7 @@ const auditUsage = `markdowntown audit
 
 Usage:
   markdowntown audit [flags]
+  markdowntown audit diff --base <scan.json|ref> [--head <scan.json|ref>]
 
 Flags:
   --input <path>            Read scan JSON from file or - for stdin
@@ -125,6 +143,7 @@ Flags:
   --include-scan-warnings   Include raw scan warnings in output
   --repo <path>             Repo path (defaults to git root)
   --repo-only               Exclude user scope when running internal scan
+  --ref <rev>               Audit a commit, branch, or tag without checking it out (implies --repo-only)
   --global-scope            Include global/system scope roots (e.g., /etc)
   --global-max-files <n>    Max files to scan in global scope (0 = unlimited)
   --global-max-bytes <n>    Max bytes to scan in global scope (0 = unlimited)
@@ -132,6 +151,12 @@ Flags:
   --scan-workers <n>        Parallel scan workers (0 = auto)
   --stdin                   Read additional scan roots from stdin
   --no-content              Exclude file contents from internal scan
+  --no-cache                Ignore and do not update the persistent scan cache
+  --rules-dir <path>        Load custom YAML rule packs (default: .markdowntown/rules)
+  --allow-plugins           Run out-of-process plugin rules declared in rule packs
+  --fix                     Apply safe quick fixes, print a diff, and re-run the audit
+  --fix-dry-run             Print the diff for safe quick fixes without writing files
+  --fix-unsafe              Also apply fixes that delete content (with --fix/--fix-dry-run)
   -h, --help                Show help
 `
 
@@ -193,12 +218,21 @@ var commands = map[string]func([]string) error{
 		}
 		return runScanRemote(args)
 	},
+	"scan-fleet": func(args []string) error {
+		if err := git.ValidateGitVersion(); err != nil {
+			return err
+		}
+		return runScanFleet(args)
+	},
+	"graph":    runGraph,
 	"suggest":  runSuggest,
 	"resolve":  runResolve,
+	"sources":  runSources,
 	"context":  runContext,
 	"audit":    runAudit,
 	"serve":    runServe,
 	"registry": runRegistry,
+	"rules":    runRules,
 	"tools":    runTools,
 }
 
@@ -230,9 +264,19 @@ func runScan(args []string) error {
 	var quiet bool
 	var help bool
 	var forFile string
+	var watch bool
+	var watchDebounce time.Duration
+	var noCache bool
+	var ref string
+	var archivePath string
+	var stripComponents int
+	var graph bool
 
 	flags.StringVar(&repoPath, "repo", "", "repo path (defaults to git root)")
 	flags.BoolVar(&repoOnly, "repo-only", false, "exclude user scope")
+	flags.StringVar(&ref, "ref", "", "git revision to scan without checking it out")
+	flags.StringVar(&archivePath, "archive", "", "tar, tar.gz, or zip archive to scan")
+	flags.IntVar(&stripComponents, "strip-components", 0, "leading path elements to drop from archive entries")
 	flags.BoolVar(&globalScope, "global-scope", false, "include global/system scope roots")
 	flags.IntVar(&globalMaxFiles, "global-max-files", 0, "max files to scan in global scope (0 = unlimited)")
 	flags.Int64Var(&globalMaxBytes, "global-max-bytes", 0, "max bytes to scan in global scope (0 = unlimited)")
@@ -246,6 +290,10 @@ func runScan(args []string) error {
 	flags.BoolVar(&compact, "compact", false, "emit compact JSON")
 	flags.BoolVar(&quiet, "quiet", false, "disable progress output")
 	flags.StringVar(&forFile, "for-file", "", "filter output to configs applicable to path")
+	flags.BoolVar(&noCache, "no-cache", false, "disable the persistent scan cache")
+	flags.BoolVar(&graph, "graph", false, "include the config reference graph")
+	flags.BoolVar(&watch, "watch", false, "stream change events")
+	flags.DurationVar(&watchDebounce, "watch-debounce", scan.DefaultWatchDebounce, "quiet period before rescanning")
 	flags.BoolVar(&help, "help", false, "show help")
 	flags.BoolVar(&help, "h", false, "show help")
 
@@ -278,6 +326,30 @@ func runScan(args []string) error {
 	if format != "json" && format != "jsonl" {
 		return fmt.Errorf("invalid format: %q (valid: json, jsonl)", format)
 	}
+	if watch && (readStdin || forFile != "") {
+		return fmt.Errorf("--watch cannot be combined with --stdin or --for-file")
+	}
+	if watch && watchDebounce <= 0 {
+		return fmt.Errorf("watch-debounce must be > 0")
+	}
+	if ref != "" && (watch || globalScope) {
+		return fmt.Errorf("--ref cannot be combined with --watch or --global-scope")
+	}
+	if archivePath != "" && (repoPath != "" || ref != "" || watch || readStdin || globalScope) {
+		return fmt.Errorf("--archive cannot be combined with --repo, --ref, --watch, --stdin, or --global-scope")
+	}
+	if stripComponents != 0 && archivePath == "" {
+		return fmt.Errorf("--strip-components requires --archive")
+	}
+	if stripComponents < 0 {
+		return fmt.Errorf("strip-components must be >= 0")
+	}
+	if archivePath != "" {
+		if noContent {
+			includeContent = false
+		}
+		return runScanArchive(archivePath, stripComponents, includeContent, scanWorkers, forFile, format, compact, graph)
+	}
 
 	repoRoot, err := resolveRepoRoot(repoPath)
 	if err != nil {
@@ -292,6 +364,25 @@ func runScan(args []string) error {
 		return err
 	}
 
+	if watch {
+		return runScanWatch(scan.WatchOptions{
+			Scan: scan.Options{
+				RepoRoot:       repoRoot,
+				RepoOnly:       repoOnly,
+				IncludeGlobal:  globalScope,
+				IncludeContent: includeContent,
+				ScanWorkers:    scanWorkers,
+				GlobalMaxFiles: globalMaxFiles,
+				GlobalMaxBytes: globalMaxBytes,
+				GlobalXDev:     globalXDev,
+				Registry:       registry,
+				Fs:             afero.NewOsFs(),
+			},
+			Debounce:  watchDebounce,
+			Gitignore: true,
+		}, os.Stdout)
+	}
+
 	stdinPaths, err := readStdinPaths(readStdin)
 	if err != nil {
 		return err
@@ -299,9 +390,18 @@ func runScan(args []string) error {
 
 	progress, finish := progressReporter(!quiet)
 	startedAt := time.Now()
+	var tree *scan.GitTree
+	if ref != "" {
+		tree, err = scan.LoadGitTree(repoRoot, ref, registry)
+		if err != nil {
+			finish()
+			return err
+		}
+	}
+	cache := openScanCache(repoRoot, registry, noCache || tree != nil)
 	result, err := scan.Scan(scan.Options{
 		RepoRoot:       repoRoot,
-		RepoOnly:       repoOnly,
+		RepoOnly:       repoOnly || tree != nil,
 		IncludeGlobal:  globalScope,
 		IncludeContent: includeContent,
 		ScanWorkers:    scanWorkers,
@@ -311,15 +411,21 @@ func runScan(args []string) error {
 		Progress:       progress,
 		StdinPaths:     stdinPaths,
 		Registry:       registry,
-		Fs:             afero.NewOsFs(),
+		Fs:             scanFs(tree),
+		Cache:          cache,
+		Graph:          graph,
 	})
 	finish()
 	if err != nil {
 		return err
 	}
-	result, err = scan.ApplyGitignore(result, repoRoot)
-	if err != nil {
-		return err
+	if tree != nil {
+		result = tree.ApplyGitignore(result)
+	} else {
+		result, err = scan.ApplyGitignore(result, repoRoot)
+		if err != nil {
+			return err
+		}
 	}
 
 	if forFile != "" {
@@ -333,6 +439,7 @@ func runScan(args []string) error {
 		HashingMs:   0,
 		GitignoreMs: 0,
 		TotalMs:     elapsedMs(startedAt, finishedAt),
+		Cache:       saveScanCache(cache),
 	}
 
 	output := scan.BuildOutput(result, scan.OutputOptions{
@@ -348,11 +455,60 @@ func runScan(args []string) error {
 	return scan.WriteOutput(os.Stdout, output, format, compact)
 }
 
+// runScanArchive scans a tar, tar.gz, or zip archive mounted in memory.
+// Output paths are reported under the archive's absolute path.
+func runScanArchive(archivePath string, stripComponents int, includeContent bool, scanWorkers int, forFile string, format string, compact bool, graph bool) error {
+	registry, _, err := scan.LoadRegistry()
+	if err != nil {
+		return err
+	}
+
+	startedAt := time.Now()
+	archive, err := scan.LoadArchiveFile(archivePath, scan.ArchiveOptions{StripComponents: stripComponents})
+	if err != nil {
+		return err
+	}
+	result, err := scan.Scan(scan.Options{
+		RepoRoot:       archive.Root,
+		RepoOnly:       true,
+		IncludeContent: includeContent,
+		ScanWorkers:    scanWorkers,
+		Registry:       registry,
+		Fs:             archive.Fs,
+		Graph:          graph,
+	})
+	if err != nil {
+		return err
+	}
+	result = archive.ApplyGitignore(result)
+	if forFile != "" {
+		// Relative --for-file paths name a file inside the archive.
+		if !filepath.IsAbs(forFile) {
+			forFile = filepath.Join(archive.Root, forFile)
+		}
+		result = scan.FilterForFile(result, forFile)
+	}
+
+	finishedAt := time.Now()
+	output := scan.BuildOutput(result, scan.OutputOptions{
+		SchemaVersion:   version.SchemaVersion,
+		RegistryVersion: registry.Version,
+		ToolVersion:     version.ToolVersion,
+		RepoRoot:        archive.Root,
+		ScanStartedAt:   startedAt.UnixMilli(),
+		GeneratedAt:     finishedAt.UnixMilli(),
+		Timing:          scan.Timing{DiscoveryMs: elapsedMs(startedAt, finishedAt), TotalMs: elapsedMs(startedAt, finishedAt)},
+	})
+	return scan.WriteOutput(os.Stdout, output, format, compact)
+}
+
 func runScanRemote(args []string) error {
 	flags := flag.NewFlagSet("scan-remote", flag.ContinueOnError)
 	flags.SetOutput(io.Discard)
 
 	var ref string
+	var keep bool
+	var cacheDir string
 	var repoOnly bool
 	var globalScope bool
 	var globalMaxFiles int
@@ -368,6 +524,8 @@ func runScanRemote(args []string) error {
 	var help bool
 
 	flags.StringVar(&ref, "ref", "", "git reference to checkout")
+	flags.BoolVar(&keep, "keep", false, "keep the clone after scanning")
+	flags.StringVar(&cacheDir, "cache-dir", "", "directory for reusable clones")
 	flags.BoolVar(&repoOnly, "repo-only", false, "exclude user scope")
 	flags.BoolVar(&globalScope, "global-scope", false, "include global/system scope roots")
 	flags.IntVar(&globalMaxFiles, "global-max-files", 0, "max files to scan in global scope (0 = unlimited)")
@@ -414,12 +572,6 @@ func runScanRemote(args []string) error {
 		return fmt.Errorf("invalid format: %q (valid: json, jsonl)", format)
 	}
 
-	repoRoot, cleanup, err := scan.CloneToTemp(url, ref)
-	if err != nil {
-		return err
-	}
-	defer cleanup()
-
 	if noContent {
 		includeContent = false
 	}
@@ -429,6 +581,24 @@ func runScanRemote(args []string) error {
 		return err
 	}
 
+	clone, err := scan.CloneRemote(url, scan.RemoteOptions{
+		Ref:            ref,
+		SparsePatterns: scan.SparsePatterns(registry),
+		CacheDir:       cacheDir,
+		Keep:           keep,
+	})
+	if err != nil {
+		return err
+	}
+	defer clone.Cleanup()
+	repoRoot := clone.Dir
+	if !quiet && !clone.Filtered {
+		_, _ = fmt.Fprintln(os.Stderr, "warning: remote does not support partial clone; fetched all blobs")
+	}
+	if keep || cacheDir != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "clone kept at %s\n", repoRoot)
+	}
+
 	progress, finish := progressReporter(!quiet)
 	startedAt := time.Now()
 	result, err := scan.Scan(scan.Options{
@@ -486,6 +656,9 @@ func runServe(args []string) error {
 }
 
 func runAudit(args []string) error {
+	if len(args) > 0 && args[0] == "diff" {
+		return runAuditDiff(args[1:])
+	}
 	opts, err := parseAuditFlags(args)
 	if err != nil {
 		return newCLIError(err, 2)
@@ -506,6 +679,10 @@ func runAudit(args []string) error {
 		return newCLIError(err, 2)
 	}
 
+	if opts.fix || opts.fixDryRun {
+		return runAuditFix(scanOutput, registry, opts, auditStartedAt)
+	}
+
 	auditOutput, threshold, err := executeAudit(scanOutput, registry, opts, auditStartedAt)
 	if err != nil {
 		return newCLIError(err, 2)
@@ -537,10 +714,18 @@ type auditOptions struct {
 	scanWorkers         int
 	readStdin           bool
 	noContent           bool
+	noCache             bool
+	ref                 string
+	rulesDir            string
+	allowPlugins        bool
+	fix                 bool
+	fixDryRun           bool
+	fixUnsafe           bool
 	help                bool
 	onlyRules           stringList
 	ignoreRules         stringList
 	excludePaths        stringList
+	rules               []audit.Rule // replaces auditRules when set
 }
 
 func parseAuditFlags(args []string) (*auditOptions, error) {
@@ -566,6 +751,13 @@ func parseAuditFlags(args []string) (*auditOptions, error) {
 	flags.IntVar(&opts.scanWorkers, "scan-workers", 0, "parallel scan workers (0 = auto)")
 	flags.BoolVar(&opts.readStdin, "stdin", false, "read additional paths from stdin")
 	flags.BoolVar(&opts.noContent, "no-content", false, "exclude file contents from internal scan")
+	flags.BoolVar(&opts.noCache, "no-cache", false, "disable the persistent scan cache")
+	flags.StringVar(&opts.ref, "ref", "", "git revision to audit without checking it out")
+	flags.StringVar(&opts.rulesDir, "rules-dir", "", "directory of custom YAML rule packs")
+	flags.BoolVar(&opts.allowPlugins, "allow-plugins", false, "run out-of-process plugin rules from rule packs")
+	flags.BoolVar(&opts.fix, "fix", false, "apply safe quick fixes")
+	flags.BoolVar(&opts.fixDryRun, "fix-dry-run", false, "print quick fix diff without writing files")
+	flags.BoolVar(&opts.fixUnsafe, "fix-unsafe", false, "include fixes that delete content")
 	flags.BoolVar(&opts.help, "help", false, "show help")
 	flags.BoolVar(&opts.help, "h", false, "show help")
 
@@ -587,9 +779,24 @@ func parseAuditFlags(args []string) (*auditOptions, error) {
 	if opts.globalScope && runtime.GOOS == "windows" {
 		_, _ = fmt.Fprintln(os.Stderr, "warning: --global-scope is not supported on Windows")
 	}
-	if opts.inputPath != "" && (opts.repoPath != "" || opts.repoOnly || opts.readStdin) {
+	if opts.inputPath != "" && (opts.repoPath != "" || opts.repoOnly || opts.readStdin || opts.ref != "") {
 		return nil, fmt.Errorf("--input cannot be combined with scan flags")
 	}
+	if opts.fix && opts.fixDryRun {
+		return nil, fmt.Errorf("--fix cannot be combined with --fix-dry-run")
+	}
+	if opts.fixUnsafe && !opts.fix && !opts.fixDryRun {
+		return nil, fmt.Errorf("--fix-unsafe requires --fix or --fix-dry-run")
+	}
+	if opts.inputPath != "" && (opts.fix || opts.fixDryRun) {
+		return nil, fmt.Errorf("--fix requires an internal scan; cannot combine with --input")
+	}
+	if opts.ref != "" && (opts.fix || opts.fixDryRun) {
+		return nil, fmt.Errorf("--fix edits the working tree; cannot combine with --ref")
+	}
+	if opts.ref != "" && opts.globalScope {
+		return nil, fmt.Errorf("--ref cannot be combined with --global-scope")
+	}
 	opts.format = strings.ToLower(opts.format)
 	if opts.format != "json" && opts.format != "md" {
 		return nil, fmt.Errorf("invalid format: %q (valid: json, md)", opts.format)
@@ -616,53 +823,115 @@ func loadAuditInput(opts *auditOptions, registry scan.Registry) (scan.Output, er
 		if err != nil {
 			return scanOutput, err
 		}
-
-		progress, finish := progressReporter(true)
-		scanStartedAt := time.Now()
-		result, err := scan.Scan(scan.Options{
-			RepoRoot:       repoRoot,
-			RepoOnly:       opts.repoOnly,
-			IncludeGlobal:  opts.globalScope,
-			IncludeContent: !opts.noContent,
-			ScanWorkers:    opts.scanWorkers,
-			GlobalMaxFiles: opts.globalMaxFiles,
-			GlobalMaxBytes: opts.globalMaxBytes,
-			GlobalXDev:     opts.globalXDev,
-			Progress:       progress,
-			StdinPaths:     stdinPaths,
-			Registry:       registry,
-			Fs:             afero.NewOsFs(),
-		})
-		finish()
+		var tree *scan.GitTree
+		if opts.ref != "" {
+			tree, err = scan.LoadGitTree(repoRoot, opts.ref, registry)
+			if err != nil {
+				return scanOutput, err
+			}
+		}
+		scanOutput, err = scanAuditRoot(opts, registry, repoRoot, stdinPaths, tree)
 		if err != nil {
 			return scanOutput, err
 		}
+	}
 
+	return audit.FilterOutput(scanOutput, []string(opts.excludePaths))
+}
+
+// scanAuditRoot runs the internal scan used by audit. A non-nil tree is
+// scanned in place of the working copy, repo scope only and without the scan
+// cache.
+func scanAuditRoot(opts *auditOptions, registry scan.Registry, repoRoot string, stdinPaths []string, tree *scan.GitTree) (scan.Output, error) {
+	progress, finish := progressReporter(true)
+	scanStartedAt := time.Now()
+	cache := openScanCache(repoRoot, registry, opts.noCache || tree != nil)
+	result, err := scan.Scan(scan.Options{
+		RepoRoot:       repoRoot,
+		RepoOnly:       opts.repoOnly || tree != nil,
+		IncludeGlobal:  opts.globalScope,
+		IncludeContent: !opts.noContent,
+		ScanWorkers:    opts.scanWorkers,
+		GlobalMaxFiles: opts.globalMaxFiles,
+		GlobalMaxBytes: opts.globalMaxBytes,
+		GlobalXDev:     opts.globalXDev,
+		Progress:       progress,
+		StdinPaths:     stdinPaths,
+		Registry:       registry,
+		Fs:             scanFs(tree),
+		Cache:          cache,
+	})
+	finish()
+	if err != nil {
+		return scan.Output{}, err
+	}
+
+	if tree != nil {
+		result = tree.ApplyGitignore(result)
+	} else {
 		result, err = scan.ApplyGitignore(result, repoRoot)
 		if err != nil {
-			return scanOutput, err
+			return scan.Output{}, err
 		}
-		scanFinishedAt := time.Now()
+	}
+	scanFinishedAt := time.Now()
 
-		timing := scan.Timing{
-			DiscoveryMs: elapsedMs(scanStartedAt, scanFinishedAt),
-			HashingMs:   0,
-			GitignoreMs: 0,
-			TotalMs:     elapsedMs(scanStartedAt, scanFinishedAt),
-		}
+	timing := scan.Timing{
+		DiscoveryMs: elapsedMs(scanStartedAt, scanFinishedAt),
+		HashingMs:   0,
+		GitignoreMs: 0,
+		TotalMs:     elapsedMs(scanStartedAt, scanFinishedAt),
+		Cache:       saveScanCache(cache),
+	}
 
-		scanOutput = scan.BuildOutput(result, scan.OutputOptions{
-			SchemaVersion:   version.SchemaVersion,
-			RegistryVersion: registry.Version,
-			ToolVersion:     version.ToolVersion,
-			RepoRoot:        repoRoot,
-			ScanStartedAt:   scanStartedAt.UnixMilli(),
-			GeneratedAt:     scanFinishedAt.UnixMilli(),
-			Timing:          timing,
-		})
+	return scan.BuildOutput(result, scan.OutputOptions{
+		SchemaVersion:   version.SchemaVersion,
+		RegistryVersion: registry.Version,
+		ToolVersion:     version.ToolVersion,
+		RepoRoot:        repoRoot,
+		ScanStartedAt:   scanStartedAt.UnixMilli(),
+		GeneratedAt:     scanFinishedAt.UnixMilli(),
+		Timing:          timing,
+	}), nil
+}
+
+// scanFs returns the filesystem a scan reads from: the loaded tree for --ref,
+// otherwise the OS filesystem.
+func scanFs(tree *scan.GitTree) afero.Fs {
+	if tree != nil {
+		return tree.Fs
 	}
+	return afero.NewOsFs()
+}
 
-	return audit.FilterOutput(scanOutput, []string(opts.excludePaths))
+// openScanCache loads the persistent scan cache for repoRoot. It returns nil
+// when disabled or when no cache location is available.
+func openScanCache(repoRoot string, registry scan.Registry, disabled bool) *scan.Cache {
+	if disabled {
+		return nil
+	}
+	path, err := scan.DefaultCachePath(repoRoot)
+	if err != nil {
+		return nil
+	}
+	return scan.LoadCache(path, scan.CacheKey{
+		RepoRoot:        repoRoot,
+		RegistryVersion: registry.Version,
+		ToolVersion:     version.ToolVersion,
+	})
+}
+
+// saveScanCache persists the cache and returns its stats for timing output.
+// Save failures only warn; the scan result is unaffected.
+func saveScanCache(cache *scan.Cache) *scan.CacheStats {
+	if cache == nil {
+		return nil
+	}
+	if err := cache.Save(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: scan cache not saved: %v\n", err)
+	}
+	stats := cache.Stats()
+	return &stats
 }
 
 func executeAudit(scanOutput scan.Output, registry scan.Registry, opts *auditOptions, startedAt time.Time) (audit.Output, audit.Severity, error) {
@@ -675,7 +944,14 @@ func executeAudit(scanOutput scan.Output, registry scan.Registry, opts *auditOpt
 		return audit.Output{}, threshold, err
 	}
 
-	rules, err := audit.FilterRules(audit.DefaultRules(), []string(opts.onlyRules), []string(opts.ignoreRules))
+	rules := opts.rules
+	if rules == nil {
+		rules, err = auditRules(opts, scanOutput.RepoRoot)
+		if err != nil {
+			return audit.Output{}, threshold, err
+		}
+	}
+	rules, err = audit.FilterRules(rules, []string(opts.onlyRules), []string(opts.ignoreRules))
 	if err != nil {
 		return audit.Output{}, threshold, err
 	}
@@ -687,11 +963,14 @@ func executeAudit(scanOutput scan.Output, registry scan.Registry, opts *auditOpt
 	}
 
 	redactor := audit.NewRedactor(scanOutput.RepoRoot, homeDir, xdgConfigHome, redact)
-	issues := engine.Run(audit.Context{
+	issues, timings, err := engine.RunTimed[audit.Context, audit.Issue](context.Background(), audit.Context{
 		Scan:     scanOutput,
 		Registry: registry,
 		Redactor: redactor,
 	}, rules)
+	if err != nil {
+		return audit.Output{}, threshold, err
+	}
 
 	normalizer := audit.NewEngine(redactor)
 	issues = normalizer.NormalizeIssues(issues)
@@ -701,7 +980,7 @@ func executeAudit(scanOutput scan.Output, registry scan.Registry, opts *auditOpt
 
 	output := audit.Output{
 		SchemaVersion:       version.AuditSchemaVersion,
-		Audit:               audit.Meta{ToolVersion: version.ToolVersion, AuditStartedAt: startedAt.UnixMilli(), GeneratedAt: generatedAt.UnixMilli()},
+		Audit:               audit.Meta{ToolVersion: version.ToolVersion, AuditStartedAt: startedAt.UnixMilli(), GeneratedAt: generatedAt.UnixMilli(), RuleTimings: auditRuleTimings(timings)},
 		SourceScan:          audit.SourceScan{SchemaVersion: scanOutput.SchemaVersion, ToolVersion: scanOutput.ToolVersion, RegistryVersion: scanOutput.RegistryVersion, RepoRoot: scanOutput.RepoRoot, ScanStartedAt: scanOutput.ScanStartedAt, GeneratedAt: scanOutput.GeneratedAt, Scans: scanOutput.Scans},
 		RegistryVersionUsed: registry.Version,
 		PathRedaction:       audit.RedactionInfo{Mode: redact, Enabled: redact != audit.RedactNever},
@@ -715,6 +994,40 @@ func executeAudit(scanOutput scan.Output, registry scan.Registry, opts *auditOpt
 	return output, threshold, nil
 }
 
+// auditRules returns the built-in rules plus custom rule packs from --rules-dir
+// or the repo's .markdowntown/rules directory.
+func auditRules(opts *auditOptions, repoRoot string) ([]audit.Rule, error) {
+	rules := audit.DefaultRules()
+	dir := opts.rulesDir
+	required := dir != ""
+	if dir == "" && repoRoot != "" {
+		dir = filepath.Join(repoRoot, filepath.FromSlash(audit.DefaultRulesDir))
+	}
+	if dir == "" {
+		return rules, nil
+	}
+	custom, warnings, err := audit.LoadRulePacks(afero.NewOsFs(), dir, audit.RulePackOptions{Required: required, AllowPlugins: opts.allowPlugins})
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s (use --allow-plugins to run)\n", warning)
+	}
+	return append(rules, custom...), nil
+}
+
+func auditRuleTimings(timings []engine.RuleTiming) []audit.RuleTiming {
+	out := make([]audit.RuleTiming, 0, len(timings))
+	for _, timing := range timings {
+		out = append(out, audit.RuleTiming{
+			RuleID:     timing.RuleID,
+			DurationMs: float64(timing.Duration.Microseconds()) / 1000,
+			Issues:     timing.Issues,
+		})
+	}
+	return out
+}
+
 func renderAuditOutput(output audit.Output, opts *auditOptions) error {
 	switch opts.format {
 	case "md":
@@ -736,10 +1049,14 @@ func runRegistry(args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("registry subcommand required")
 	}
-	if args[0] != "validate" {
+	switch args[0] {
+	case "validate":
+		return runRegistryValidate()
+	case "test":
+		return runRegistryTest(args[1:])
+	default:
 		return fmt.Errorf("unknown registry subcommand: %s", args[0])
 	}
-	return runRegistryValidate()
 }
 
 func runTools(args []string) error {
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"markdowntown-cli/internal/scan"
+)
+
+const registryTestUsage = `markdowntown registry test
+
+Usage:
+  markdowntown registry test [flags]
+
+Checks every pattern's examples.shouldMatch and examples.shouldNotMatch
+paths against the compiled registry (including custom-patterns.json).
+
+Flags:
+  --registry <path>      Test a single registry file instead of the resolved registry
+  --format <text|json>   Output format (default: text)
+  --strict               Fail on ambiguous overlaps between patterns
+  --compact              Emit compact JSON
+  -h, --help             Show help
+`
+
+type registryTestOptions struct {
+	registryPath string
+	format       string
+	strict       bool
+	compact      bool
+	help         bool
+}
+
+func runRegistryTest(args []string) error {
+	opts, err := parseRegistryTestFlags(args)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	if opts.help {
+		_, _ = fmt.Fprint(os.Stdout, registryTestUsage)
+		return nil
+	}
+
+	reg, err := loadRegistryForTest(opts.registryPath)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	result, err := scan.CheckRegistryExamples(reg)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+
+	if opts.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		if !opts.compact {
+			enc.SetIndent("", "  ")
+		}
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	} else {
+		writeRegistryTestText(os.Stdout, result)
+	}
+
+	if !result.Passed {
+		return newCLIError(fmt.Errorf("registry test: %d example(s) failed", len(result.Failures)), 1)
+	}
+	if opts.strict && len(result.Overlaps) > 0 {
+		return newCLIError(fmt.Errorf("registry test: %d ambiguous overlap(s)", len(result.Overlaps)), 1)
+	}
+	return nil
+}
+
+func parseRegistryTestFlags(args []string) (*registryTestOptions, error) {
+	opts := &registryTestOptions{}
+	flags := flag.NewFlagSet("registry test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&opts.registryPath, "registry", "", "registry file to test")
+	flags.StringVar(&opts.format, "format", "text", "output format (text or json)")
+	flags.BoolVar(&opts.strict, "strict", false, "fail on ambiguous overlaps")
+	flags.BoolVar(&opts.compact, "compact", false, "emit compact JSON")
+	flags.BoolVar(&opts.help, "help", false, "show help")
+	flags.BoolVar(&opts.help, "h", false, "show help")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.help {
+		return opts, nil
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	opts.format = strings.ToLower(opts.format)
+	if opts.format != "text" && opts.format != "json" {
+		return nil, fmt.Errorf("invalid format: %q (valid: text, json)", opts.format)
+	}
+	return opts, nil
+}
+
+// loadRegistryForTest reads path when set, otherwise the resolved registry
+// with custom patterns merged.
+func loadRegistryForTest(path string) (scan.Registry, error) {
+	if path == "" {
+		reg, _, err := scan.LoadRegistry()
+		return reg, err
+	}
+	data, err := scan.ReadRegistryFile(path)
+	if err != nil {
+		return scan.Registry{}, err
+	}
+	var reg scan.Registry
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return scan.Registry{}, fmt.Errorf("parse registry: %w", err)
+	}
+	if len(reg.Patterns) == 0 {
+		return scan.Registry{}, errors.New("registry has no patterns")
+	}
+	return reg, nil
+}
+
+func writeRegistryTestText(w io.Writer, result scan.ExampleCheckResult) {
+	for _, failure := range result.Failures {
+		line := fmt.Sprintf("FAIL %s: %s expected %s", failure.PatternID, failure.Path, failure.Expected)
+		if failure.Error != "" {
+			line += " (" + failure.Error + ")"
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+	for _, overlap := range result.Overlaps {
+		_, _ = fmt.Fprintf(w, "OVERLAP %s (%s, %s): %s\n", overlap.Path, overlap.ToolID, overlap.Scope, strings.Join(overlap.PatternIDs, ", "))
+	}
+	tested := result.PatternCount - len(result.Untested)
+	_, _ = fmt.Fprintf(w, "%d examples across %d/%d patterns: %d failed, %d overlaps\n",
+		result.ExampleCount, tested, result.PatternCount, len(result.Failures), len(result.Overlaps))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/scan"
+)
+
+func TestRegistryTestCLI(t *testing.T) {
+	root := repoRoot(t)
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = runRegistryTest(nil)
+	})
+	if runErr != nil {
+		t.Fatalf("runRegistryTest: %v\n%s", runErr, out)
+	}
+	if !strings.Contains(out, "0 failed, 0 overlaps") {
+		t.Fatalf("unexpected summary:\n%s", out)
+	}
+
+	custom := `{"version":"1","patterns":[
+  {"id":"team-rules","toolId":"team","toolName":"Team","kind":"rules","scope":"repo","paths":["team/*.md"],"type":"glob",
+   "loadBehavior":"directory-glob","application":"automatic","docs":["https://example.com"],
+   "examples":{"shouldMatch":["team/style.md","team/nested/style.md"],"shouldNotMatch":["team/notes.txt"]}},
+  {"id":"team-style","toolId":"team","toolName":"Team","kind":"rules","scope":"repo","paths":["team/style.md"],"type":"glob",
+   "loadBehavior":"single","application":"automatic","docs":["https://example.com"]}
+]}`
+	path := filepath.Join(t.TempDir(), "custom-patterns.json")
+	if err := os.WriteFile(path, []byte(custom), 0o600); err != nil {
+		t.Fatalf("write custom patterns: %v", err)
+	}
+	out = captureStdout(t, func() {
+		runErr = runRegistryTest([]string{"--registry", path, "--format", "json"})
+	})
+	var cliErr *cliError
+	if !errors.As(runErr, &cliErr) || cliErr.code != 1 {
+		t.Fatalf("expected exit code 1, got %v", runErr)
+	}
+	var result scan.ExampleCheckResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Path != "team/nested/style.md" {
+		t.Fatalf("unexpected failures: %+v", result.Failures)
+	}
+	if len(result.Overlaps) != 1 || strings.Join(result.Overlaps[0].PatternIDs, ",") != "team-rules,team-style" {
+		t.Fatalf("unexpected overlaps: %+v", result.Overlaps)
+	}
+	if strings.Join(result.Untested, ",") != "team-style" {
+		t.Fatalf("unexpected untested: %v", result.Untested)
+	}
+
+	if _, err := parseRegistryTestFlags([]string{"--format", "yaml"}); err == nil {
+		t.Fatalf("expected invalid format error")
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"markdowntown-cli/internal/audit"
+
+	"github.com/spf13/afero"
+)
+
+const rulesUsage = `markdowntown rules
+
+Usage:
+  markdowntown rules list [flags]
+  markdowntown rules explain <ruleId> [flags]
+
+Flags:
+  --repo <path>             Repo path used to find .markdowntown/rules (defaults to git root)
+  --rules-dir <path>        Custom YAML rule packs (default: .markdowntown/rules)
+  --allow-plugins           Treat plugin rules as enabled (list)
+  --only <ruleId>           Mark only these rule IDs as enabled (repeatable, list)
+  --ignore-rule <ruleId>    Mark rule IDs as disabled (repeatable, list)
+  --format <text|json>      Output format (default: text)
+  -h, --help                Show help
+`
+
+type rulesOptions struct {
+	audit  auditOptions
+	format string
+	help   bool
+}
+
+func runRules(args []string) error {
+	if len(args) == 0 {
+		return newCLIError(fmt.Errorf("rules subcommand required"), 2)
+	}
+	switch args[0] {
+	case "-h", "--help":
+		printRulesUsage(os.Stdout)
+		return nil
+	case "list":
+		return runRulesList(args[1:])
+	case "explain":
+		return runRulesExplain(args[1:])
+	default:
+		return newCLIError(fmt.Errorf("unknown rules subcommand: %s", args[0]), 2)
+	}
+}
+
+func runRulesList(args []string) error {
+	opts, positional, err := parseRulesFlags("rules list", args)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	if opts.help {
+		printRulesUsage(os.Stdout)
+		return nil
+	}
+	if len(positional) > 0 {
+		return newCLIError(fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " ")), 2)
+	}
+
+	rules, err := catalogRules(opts)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	enabled := make([]audit.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Source == audit.RuleSourcePlugin && !opts.audit.allowPlugins {
+			continue
+		}
+		enabled = append(enabled, rule)
+	}
+	enabled, err = audit.FilterRules(enabled, []string(opts.audit.onlyRules), []string(opts.audit.ignoreRules))
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+
+	infos := audit.DescribeRules(rules, enabled)
+	if opts.format == "json" {
+		return writeRulesJSON(os.Stdout, infos)
+	}
+	return writeRulesTable(os.Stdout, infos)
+}
+
+func runRulesExplain(args []string) error {
+	opts, positional, err := parseRulesFlags("rules explain", args)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	if opts.help {
+		printRulesUsage(os.Stdout)
+		return nil
+	}
+	if len(positional) != 1 {
+		return newCLIError(fmt.Errorf("rules explain requires exactly one rule ID"), 2)
+	}
+	ruleID := strings.ToUpper(strings.TrimSpace(positional[0]))
+
+	if doc, ok := audit.LookupRuleDoc(ruleID); ok {
+		if opts.format == "json" {
+			return writeRulesJSON(os.Stdout, doc)
+		}
+		_, err := fmt.Fprint(os.Stdout, doc.Markdown)
+		return err
+	}
+
+	rules, err := catalogRules(opts)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	for _, rule := range rules {
+		if !strings.EqualFold(rule.ID, ruleID) {
+			continue
+		}
+		doc := customRuleDoc(rule)
+		if opts.format == "json" {
+			return writeRulesJSON(os.Stdout, doc)
+		}
+		_, err := fmt.Fprint(os.Stdout, doc.Markdown)
+		return err
+	}
+	return newCLIError(fmt.Errorf("unknown rule: %s", positional[0]), 2)
+}
+
+// parseRulesFlags parses flags before and after positional arguments so
+// "rules explain MD001 --format json" works.
+func parseRulesFlags(name string, args []string) (*rulesOptions, []string, error) {
+	opts := &rulesOptions{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&opts.audit.repoPath, "repo", "", "repo path (defaults to git root)")
+	flags.StringVar(&opts.audit.rulesDir, "rules-dir", "", "directory of custom YAML rule packs")
+	flags.BoolVar(&opts.audit.allowPlugins, "allow-plugins", false, "treat plugin rules as enabled")
+	flags.Var(&opts.audit.onlyRules, "only", "rule IDs to include (repeatable)")
+	flags.Var(&opts.audit.ignoreRules, "ignore-rule", "rule IDs to suppress (repeatable)")
+	flags.StringVar(&opts.format, "format", "text", "output format (text or json)")
+	flags.BoolVar(&opts.help, "help", false, "show help")
+	flags.BoolVar(&opts.help, "h", false, "show help")
+
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, nil, err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	opts.format = strings.ToLower(opts.format)
+	if opts.format != "text" && opts.format != "json" {
+		return nil, nil, fmt.Errorf("invalid format: %q (valid: text, json)", opts.format)
+	}
+	return opts, positional, nil
+}
+
+// catalogRules loads built-in rules plus rule packs. Plugins are loaded for
+// display only and never executed here.
+func catalogRules(opts *rulesOptions) ([]audit.Rule, error) {
+	rules := audit.DefaultRules()
+	dir := opts.audit.rulesDir
+	required := dir != ""
+	if dir == "" {
+		repoRoot, err := resolveRepoRoot(opts.audit.repoPath)
+		if err != nil {
+			if opts.audit.repoPath != "" {
+				return nil, err
+			}
+			return rules, nil
+		}
+		dir = filepath.Join(repoRoot, filepath.FromSlash(audit.DefaultRulesDir))
+	}
+	custom, _, err := audit.LoadRulePacks(afero.NewOsFs(), dir, audit.RulePackOptions{Required: required, AllowPlugins: true})
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, custom...), nil
+}
+
+// customRuleDoc renders a short document for a rule-pack rule from its metadata.
+func customRuleDoc(rule audit.Rule) audit.RuleDoc {
+	title := rule.Title
+	if title == "" {
+		title = rule.ID
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: %s\n\n", rule.ID, title)
+	fmt.Fprintf(&b, "- Source: %s\n", rule.Source)
+	fmt.Fprintf(&b, "- Default severity: %s\n", rule.Severity)
+	if rule.Data.Category != "" {
+		fmt.Fprintf(&b, "- Category: %s\n", rule.Data.Category)
+	}
+	if rule.Data.DocURL != "" {
+		fmt.Fprintf(&b, "- Docs: %s\n", rule.Data.DocURL)
+	}
+	return audit.RuleDoc{ID: rule.ID, Title: title, Markdown: b.String()}
+}
+
+func writeRulesTable(w io.Writer, infos []audit.RuleInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tSEVERITY\tCATEGORY\tSOURCE\tENABLED\tQUICK FIXES\tTITLE")
+	for _, info := range infos {
+		enabled := "no"
+		if info.Enabled {
+			enabled = "yes"
+		}
+		fixes := strings.Join(info.QuickFixes, ",")
+		if fixes == "" {
+			fixes = "-"
+		}
+		category := info.Category
+		if category == "" {
+			category = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", info.ID, info.Severity, category, info.Source, enabled, fixes, info.Title)
+	}
+	return tw.Flush()
+}
+
+func writeRulesJSON(w io.Writer, value any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(value)
+}
+
+func printRulesUsage(w io.Writer) {
+	_, _ = fmt.Fprint(w, rulesUsage)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/audit"
+)
+
+func TestRulesList(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeFile(t, filepath.Join(repo, ".markdowntown", "rules", "org.yaml"), "rules:\n  - id: ORG-TESTING\n    title: Needs testing\n    assert:\n      maxBytes: 10\nplugins:\n  - id: ORG-PLUGIN\n    command: [\"./plugin.sh\"]\n")
+
+	var runErr error
+	stdout := captureStdout(t, func() {
+		runErr = runRules([]string{"list", "--repo", repo, "--ignore-rule", "MD002", "--format", "json"})
+	})
+	if runErr != nil {
+		t.Fatalf("rules list: %v", runErr)
+	}
+	var infos []audit.RuleInfo
+	if err := json.Unmarshal([]byte(stdout), &infos); err != nil {
+		t.Fatalf("unmarshal: %v (%s)", err, stdout)
+	}
+	byID := make(map[string]audit.RuleInfo, len(infos))
+	for _, info := range infos {
+		byID[info.ID] = info
+	}
+	if len(infos) != len(audit.DefaultRules())+2 {
+		t.Fatalf("unexpected rules: %#v", infos)
+	}
+	if !byID["MD001"].Enabled || byID["MD002"].Enabled {
+		t.Fatalf("expected --ignore-rule to disable MD002: %#v", infos)
+	}
+	if info := byID["ORG-TESTING"]; !info.Enabled || info.Source != audit.RuleSourceCustom || info.Title != "Needs testing" {
+		t.Fatalf("unexpected custom rule: %#v", info)
+	}
+	if info := byID["ORG-PLUGIN"]; info.Enabled || info.Source != audit.RuleSourcePlugin {
+		t.Fatalf("expected plugin to be listed as disabled: %#v", info)
+	}
+
+	stdout = captureStdout(t, func() {
+		runErr = runRules([]string{"list", "--repo", repo})
+	})
+	if runErr != nil || !strings.Contains(stdout, "ENABLED") || !strings.Contains(stdout, "ORG-TESTING") {
+		t.Fatalf("unexpected table output: %v\n%s", runErr, stdout)
+	}
+}
+
+func TestRulesExplain(t *testing.T) {
+	repo := t.TempDir()
+	initGitRepo(t, repo)
+	writeFile(t, filepath.Join(repo, ".markdowntown", "rules", "org.yaml"), "rules:\n  - id: ORG-TESTING\n    title: Needs testing\n    docUrl: https://example.com/org-testing\n    assert:\n      maxBytes: 10\n")
+
+	var runErr error
+	stdout := captureStdout(t, func() {
+		runErr = runRules([]string{"explain", "md013"})
+	})
+	if runErr != nil || !strings.HasPrefix(stdout, "# MD013: Shadowed config") || !strings.Contains(stdout, "## Bad") {
+		t.Fatalf("unexpected explain output: %v\n%s", runErr, stdout)
+	}
+
+	stdout = captureStdout(t, func() {
+		runErr = runRules([]string{"explain", "ORG-TESTING", "--repo", repo, "--format", "json"})
+	})
+	if runErr != nil {
+		t.Fatalf("explain custom: %v", runErr)
+	}
+	var doc audit.RuleDoc
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("unmarshal: %v (%s)", err, stdout)
+	}
+	if doc.Title != "Needs testing" || !strings.Contains(doc.Markdown, "https://example.com/org-testing") {
+		t.Fatalf("unexpected custom doc: %#v", doc)
+	}
+
+	if err := runRules([]string{"explain", "NOPE", "--repo", repo}); err == nil {
+		t.Fatalf("expected unknown rule to fail")
+	}
+	if err := runRules([]string{"explain"}); err == nil {
+		t.Fatalf("expected missing rule ID to fail")
+	}
+}
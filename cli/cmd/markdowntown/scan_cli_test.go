@@ -1,13 +1,18 @@
 package main
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"markdowntown-cli/internal/scan"
 	"markdowntown-cli/internal/version"
@@ -103,3 +108,210 @@ func TestScanCLIJSONLOutput(t *testing.T) {
 		t.Fatalf("expected schemaVersion %s, got %s", version.SchemaVersion, output.SchemaVersion)
 	}
 }
+
+func TestScanCLIWatchFlags(t *testing.T) {
+	cases := [][]string{
+		{"--watch", "--stdin"},
+		{"--watch", "--for-file", "AGENTS.md"},
+		{"--watch", "--watch-debounce", "0s"},
+	}
+	for _, args := range cases {
+		if err := runScan(args); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestScanWatchJSONL(t *testing.T) {
+	root := repoRoot(t)
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "AGENTS.md"), []byte("# Agents"), 0o600); err != nil {
+		t.Fatalf("write repo file: %v", err)
+	}
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+	registry, _, err := scan.LoadRegistry()
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+
+	// A canceled context stops after the initial events.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var out bytes.Buffer
+	err = watchScan(ctx, scan.WatchOptions{Scan: scan.Options{RepoRoot: repo, RepoOnly: true, Registry: registry}}, &out)
+	if errors.Is(err, scan.ErrWatchUnsupported) {
+		t.Skip("watch not supported on this platform")
+	}
+	if err != nil {
+		t.Fatalf("watchScan: %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if len(lines) != 1 {
+		t.Fatalf("expected one event, got %q", out.String())
+	}
+	var event scan.WatchEvent
+	if err := json.Unmarshal(lines[0], &event); err != nil {
+		t.Fatalf("unmarshal event: %v", err)
+	}
+	if event.Type != scan.WatchAdded || !event.Initial || event.Sha256 == nil || event.Entry.Path != filepath.Join(repo, "AGENTS.md") {
+		t.Fatalf("unexpected event: %#v", event)
+	}
+}
+
+func TestScanCLICacheTiming(t *testing.T) {
+	root := repoRoot(t)
+	repo := t.TempDir()
+	cmd := exec.Command("git", "init")
+	cmd.Dir = repo
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+	config := filepath.Join(repo, "AGENTS.md")
+	if err := os.WriteFile(config, []byte("# Agents"), 0o600); err != nil {
+		t.Fatalf("write repo file: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	for _, path := range []string{config, repo} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	run := func(args ...string) scan.Output {
+		t.Helper()
+		stdoutReader, stdoutWriter, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("pipe stdout: %v", err)
+		}
+		oldStdout := os.Stdout
+		os.Stdout = stdoutWriter
+		err = runScan(append([]string{"--repo", repo, "--repo-only", "--compact", "--quiet"}, args...))
+		_ = stdoutWriter.Close()
+		os.Stdout = oldStdout
+		if err != nil {
+			t.Fatalf("runScan error: %v", err)
+		}
+		outBytes, err := io.ReadAll(stdoutReader)
+		if err != nil {
+			t.Fatalf("read stdout: %v", err)
+		}
+		var output scan.Output
+		if err := json.Unmarshal(outBytes, &output); err != nil {
+			t.Fatalf("unmarshal output: %v", err)
+		}
+		return output
+	}
+
+	if cold := run(); cold.Timing.Cache == nil || cold.Timing.Cache.FileHits != 0 {
+		t.Fatalf("expected cold cache stats, got %#v", cold.Timing.Cache)
+	}
+	warm := run()
+	if warm.Timing.Cache == nil || warm.Timing.Cache.FileHits != 1 || warm.Timing.Cache.DirHits < 1 {
+		t.Fatalf("expected warm cache hits, got %#v", warm.Timing.Cache)
+	}
+	if len(warm.Configs) != 1 || warm.Configs[0].Sha256 == nil {
+		t.Fatalf("unexpected configs: %#v", warm.Configs)
+	}
+	if disabled := run("--no-cache"); disabled.Timing.Cache != nil {
+		t.Fatalf("expected no cache stats with --no-cache, got %#v", disabled.Timing.Cache)
+	}
+}
+
+func TestScanCLIRef(t *testing.T) {
+	root := repoRoot(t)
+	repo := t.TempDir()
+	runGit(t, repo, "init")
+	if err := os.WriteFile(filepath.Join(repo, "AGENTS.md"), []byte("committed"), 0o600); err != nil {
+		t.Fatalf("write AGENTS.md: %v", err)
+	}
+	runGit(t, repo, "add", "AGENTS.md")
+	runGit(t, repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "init")
+	if err := os.WriteFile(filepath.Join(repo, "AGENTS.md"), []byte("working copy edit"), 0o600); err != nil {
+		t.Fatalf("rewrite AGENTS.md: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "CLAUDE.md"), []byte("untracked"), 0o600); err != nil {
+		t.Fatalf("write CLAUDE.md: %v", err)
+	}
+
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = runScan([]string{"--repo", repo, "--ref", "HEAD", "--quiet", "--compact"})
+	})
+	if runErr != nil {
+		t.Fatalf("runScan error: %v", runErr)
+	}
+	var output scan.Output
+	if err := json.Unmarshal(bytes.TrimSpace([]byte(out)), &output); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if len(output.Configs) != 1 {
+		t.Fatalf("expected only the committed config, got %#v", output.Configs)
+	}
+	entry := output.Configs[0]
+	if filepath.Base(entry.Path) != "AGENTS.md" || entry.Content == nil || *entry.Content != "committed" {
+		t.Fatalf("expected committed AGENTS.md content, got %#v", entry)
+	}
+	if entry.SizeBytes == nil || *entry.SizeBytes != int64(len("committed")) {
+		t.Fatalf("expected size from blob, got %v", entry.SizeBytes)
+	}
+
+	if err := runScan([]string{"--repo", repo, "--ref", "HEAD", "--watch"}); err == nil {
+		t.Fatalf("expected --ref with --watch to fail")
+	}
+}
+
+func TestScanCLIArchive(t *testing.T) {
+	root := repoRoot(t)
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	writer := tar.NewWriter(gz)
+	body := []byte("# Agents\n")
+	if err := writer.WriteHeader(&tar.Header{Name: "repo-main/AGENTS.md", Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("tar header: %v", err)
+	}
+	if _, err := writer.Write(body); err != nil {
+		t.Fatalf("tar write: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("tar close: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	archivePath := filepath.Join(t.TempDir(), "repo.tar.gz")
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("write archive: %v", err)
+	}
+
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = runScan([]string{"--archive", archivePath, "--strip-components", "1", "--compact"})
+	})
+	if runErr != nil {
+		t.Fatalf("runScan error: %v", runErr)
+	}
+	var output scan.Output
+	if err := json.Unmarshal(bytes.TrimSpace([]byte(out)), &output); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if output.RepoRoot != archivePath {
+		t.Fatalf("expected repo root %s, got %s", archivePath, output.RepoRoot)
+	}
+	if len(output.Configs) != 1 || output.Configs[0].Path != filepath.Join(archivePath, "AGENTS.md") {
+		t.Fatalf("expected AGENTS.md inside the archive, got %#v", output.Configs)
+	}
+
+	if err := runScan([]string{"--archive", archivePath, "--ref", "HEAD"}); err == nil {
+		t.Fatalf("expected --archive with --ref to fail")
+	}
+	if err := runScan([]string{"--strip-components", "1"}); err == nil {
+		t.Fatalf("expected --strip-components without --archive to fail")
+	}
+}
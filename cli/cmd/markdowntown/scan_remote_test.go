@@ -90,3 +90,48 @@ func runGit(t *testing.T, dir string, args ...string) {
 		t.Fatalf("git %v failed: %v\nOutput: %s", args, err, out)
 	}
 }
+
+func TestScanRemoteCLICacheDir(t *testing.T) {
+	originDir := t.TempDir()
+	runGit(t, originDir, "init")
+	runGit(t, originDir, "config", "user.email", "you@example.com")
+	runGit(t, originDir, "config", "user.name", "Your Name")
+	if err := os.WriteFile(filepath.Join(originDir, "AGENTS.md"), []byte("# Agents"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, originDir, "add", ".")
+	runGit(t, originDir, "commit", "-m", "Initial commit")
+	bareDir := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, originDir, "clone", "--bare", originDir, bareDir)
+	runGit(t, bareDir, "config", "uploadpack.allowFilter", "true")
+
+	root := repoRoot(t)
+	t.Setenv("MARKDOWNTOWN_REGISTRY", filepath.Join(root, "data", "ai-config-patterns.json"))
+	silenceStderr(t)
+
+	cacheDir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		var runErr error
+		out := captureStdout(t, func() {
+			runErr = runScanRemote([]string{"--repo-only", "--quiet", "--compact", "--cache-dir", cacheDir, "file://" + filepath.ToSlash(bareDir)})
+		})
+		if runErr != nil {
+			t.Fatalf("runScanRemote run %d: %v", i, runErr)
+		}
+		var output scan.Output
+		if err := json.Unmarshal([]byte(out), &output); err != nil {
+			t.Fatalf("unmarshal output: %v", err)
+		}
+		if len(output.Configs) != 1 || filepath.Base(output.Configs[0].Path) != "AGENTS.md" {
+			t.Fatalf("run %d: expected AGENTS.md config, got %#v", i, output.Configs)
+		}
+	}
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatalf("read cache dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one cached clone, got %d", len(entries))
+	}
+}
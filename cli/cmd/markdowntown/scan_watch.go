@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"markdowntown-cli/internal/scan"
+)
+
+// runScanWatch streams scan.Watch events as compact JSONL until interrupted.
+func runScanWatch(opts scan.WatchOptions, w io.Writer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return watchScan(ctx, opts, w)
+}
+
+func watchScan(ctx context.Context, opts scan.WatchOptions, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	err := scan.Watch(ctx, opts, func(event scan.WatchEvent) error {
+		return enc.Encode(event)
+	})
+	if errors.Is(err, scan.ErrWatchUnsupported) {
+		return newCLIError(err, 2)
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"markdowntown-cli/internal/instructions"
+	"markdowntown-cli/internal/suggest"
+)
+
+const sourcesDiscoverUsage = `markdowntown sources discover
+
+Usage:
+  markdowntown sources discover --host <host> [flags]
+
+Reads robots.txt and the sitemaps it lists (or /sitemap.xml) on an
+allowlisted host and proposes doc-sources.json entries for pages under the
+registry's discovery path prefixes. Nothing is written; review the proposals
+and add them to the registry by hand.
+
+Flags:
+  --host <host>                                  Allowlisted host to crawl (required)
+  --client <codex|copilot|vscode|claude|gemini>  Only use this client's discovery rules
+  --max-sitemaps <n>                             Stop after n sitemaps (default 20)
+  --format <json|md>                             Output format (default json)
+  --compact                                      Emit compact JSON
+  -h, --help                                     Show help
+`
+
+// sourcesHTTPClient is replaced in tests to reach TLS test servers.
+var sourcesHTTPClient *http.Client
+
+func runSources(args []string) error {
+	return runSourcesWithIO(os.Stdout, args)
+}
+
+func runSourcesWithIO(stdout io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("sources subcommand required")
+	}
+	if args[0] != "discover" {
+		return fmt.Errorf("unknown sources subcommand: %s", args[0])
+	}
+	return runSourcesDiscover(stdout, args[1:])
+}
+
+func runSourcesDiscover(stdout io.Writer, args []string) error {
+	flags := flag.NewFlagSet("sources discover", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	var host string
+	var client string
+	var maxSitemaps int
+	var format string
+	var compact bool
+	var help bool
+
+	flags.StringVar(&host, "host", "", "host to crawl")
+	flags.StringVar(&client, "client", "", "client filter")
+	flags.IntVar(&maxSitemaps, "max-sitemaps", 20, "sitemap limit")
+	flags.StringVar(&format, "format", "json", "output format")
+	flags.BoolVar(&compact, "compact", false, "compact json")
+	flags.BoolVar(&help, "help", false, "show help")
+	flags.BoolVar(&help, "h", false, "show help")
+
+	if err := flags.Parse(args); err != nil {
+		return newCLIError(err, 2)
+	}
+	if help {
+		_, _ = fmt.Fprint(stdout, sourcesDiscoverUsage)
+		return nil
+	}
+	if flags.NArg() > 0 {
+		return newCLIError(fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " ")), 2)
+	}
+	if strings.TrimSpace(host) == "" {
+		return newCLIError(fmt.Errorf("--host is required"), 2)
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "markdown" {
+		format = "md"
+	}
+	if format != "json" && format != "md" {
+		return newCLIError(fmt.Errorf("unsupported format: %s", format), 2)
+	}
+	if client != "" {
+		parsed, err := instructions.ParseClient(client)
+		if err != nil {
+			return newCLIError(err, 2)
+		}
+		client = string(parsed)
+	}
+
+	reg, _, err := suggest.LoadSources()
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	result, err := suggest.Discover(context.Background(), reg, suggest.DiscoverOptions{
+		Host:        host,
+		Client:      client,
+		HTTPClient:  sourcesHTTPClient,
+		MaxSitemaps: maxSitemaps,
+	})
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+
+	if format == "md" {
+		writeDiscoverMarkdown(stdout, result)
+		return nil
+	}
+	enc := json.NewEncoder(stdout)
+	if !compact {
+		enc.SetIndent("", "  ")
+	}
+	enc.SetEscapeHTML(false)
+	return enc.Encode(result)
+}
+
+func writeDiscoverMarkdown(w io.Writer, result suggest.DiscoverResult) {
+	_, _ = fmt.Fprintf(w, "# Source discovery (%s)\n\n", result.Host)
+	_, _ = fmt.Fprintf(w, "Sitemaps read: %d\n", len(result.Sitemaps))
+
+	_, _ = fmt.Fprint(w, "\n## Proposed\n\n")
+	if len(result.Proposed) == 0 {
+		_, _ = fmt.Fprint(w, "_No new sources._\n")
+	}
+	for _, src := range result.Proposed {
+		_, _ = fmt.Fprintf(w, "- `%s` (%s, %s, every %dh): <%s>\n", src.ID, src.Client, src.Tier, src.RefreshHours, src.URL)
+	}
+
+	if len(result.Rejected) > 0 {
+		_, _ = fmt.Fprint(w, "\n## Rejected\n\n")
+		for _, rejected := range result.Rejected {
+			_, _ = fmt.Fprintf(w, "- <%s>: %s\n", rejected.URL, rejected.Reason)
+		}
+	}
+	if len(result.Warnings) > 0 {
+		_, _ = fmt.Fprint(w, "\n## Warnings\n\n")
+		for _, warning := range result.Warnings {
+			_, _ = fmt.Fprintf(w, "- %s\n", warning)
+		}
+	}
+}
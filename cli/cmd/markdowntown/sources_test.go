@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/suggest"
+)
+
+func TestSourcesDiscoverCLI(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, "User-agent: *\nAllow: /\n")
+	})
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `<urlset><url><loc>%[1]s/docs/guide</loc></url><url><loc>%[1]s/docs/existing</loc></url><url><loc>%[1]s/about</loc></url></urlset>`, server.URL)
+	})
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse server url: %v", err)
+	}
+	registry := fmt.Sprintf(`{
+  "version": "1.0",
+  "allowlistHosts": [%q],
+  "sources": [
+    {"id": "codex-existing", "tier": "tier-0", "client": "codex", "url": "%s/docs/existing", "refreshHours": 24}
+  ],
+  "discovery": [
+    {"client": "codex", "host": %q, "pathPrefixes": ["/docs/"]}
+  ]
+}`, parsed.Hostname(), server.URL, parsed.Hostname())
+	sourcesPath := filepath.Join(t.TempDir(), "doc-sources.json")
+	if err := os.WriteFile(sourcesPath, []byte(registry), 0o600); err != nil {
+		t.Fatalf("write sources: %v", err)
+	}
+	t.Setenv("MARKDOWNTOWN_SOURCES", sourcesPath)
+
+	previous := sourcesHTTPClient
+	sourcesHTTPClient = server.Client()
+	t.Cleanup(func() { sourcesHTTPClient = previous })
+
+	var out bytes.Buffer
+	if err := runSourcesWithIO(&out, []string{"discover", "--host", parsed.Host}); err != nil {
+		t.Fatalf("sources discover failed: %v", err)
+	}
+	var result suggest.DiscoverResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("parse output: %v\n%s", err, out.String())
+	}
+	if len(result.Proposed) != 1 || result.Proposed[0].ID != "codex-docs-guide" || result.Proposed[0].Tier != "tier-1" {
+		t.Fatalf("unexpected proposals: %+v", result.Proposed)
+	}
+	if len(result.Sitemaps) != 1 || !strings.HasSuffix(result.Sitemaps[0], "/sitemap.xml") {
+		t.Fatalf("expected /sitemap.xml fallback, got %v", result.Sitemaps)
+	}
+
+	out.Reset()
+	if err := runSourcesWithIO(&out, []string{"discover", "--host", parsed.Host, "--format", "md"}); err != nil {
+		t.Fatalf("sources discover md failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "- `codex-docs-guide` (codex, tier-1, every 24h)") {
+		t.Fatalf("unexpected markdown:\n%s", out.String())
+	}
+
+	if err := runSourcesWithIO(&out, []string{"discover"}); err == nil {
+		t.Fatalf("expected --host to be required")
+	}
+	if err := runSourcesWithIO(&out, []string{"discover", "--host", "unlisted.example"}); err == nil {
+		t.Fatalf("expected non-allowlisted host error")
+	}
+	if err := runSourcesWithIO(&out, []string{"list"}); err == nil {
+		t.Fatalf("expected unknown subcommand error")
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -14,20 +16,35 @@ import (
 	scanhash "markdowntown-cli/internal/hash"
 	"markdowntown-cli/internal/instructions"
 	"markdowntown-cli/internal/suggest"
+	syncer "markdowntown-cli/internal/sync"
+
+	"golang.org/x/sync/errgroup"
 )
 
 const suggestUsage = `markdowntown suggest
 
 Usage:
   markdowntown suggest [flags]
+  markdowntown suggest snapshot export [--client <id|all>] [--out <file>]
+  markdowntown suggest snapshot import <file>
+  markdowntown suggest changes --since <snapshot|date> [flags]
 
 Flags:
-  --client <codex|copilot|vscode|claude|gemini>  Client target (default codex)
+  --client <codex|copilot|vscode|claude|gemini>  Client target (default codex); a comma-separated list or "all"
+                                                 builds one combined report from a single fetch pass
   --format <json|md>                             Output format (default json)
   --json                                         Alias for --format json
+  --template <path>                              Render Markdown with a custom text/template (implies --format md)
   --refresh                                      Force refresh of sources
   --offline                                      Do not fetch; use cached data only
+  --from-warc <file>                             Do not fetch; replay source snapshots from a WARC archive
   --explain                                      Include proof objects in output
+  --repo <path>                                  Repo whose instructions are compared (defaults to git root)
+  --no-gaps                                      Skip comparing suggestions against repo instructions
+  --hide-covered                                 Drop suggestions the repo already covers
+  --emit-patch                                   Print a unified diff adding accepted suggestions to the primary instruction file
+  --apply                                        Apply that diff to the repo
+  --force                                        Apply even if the working tree has uncommitted changes
   -h, --help                                     Show help
 `
 
@@ -54,24 +71,48 @@ func runResolve(args []string) error {
 	return runResolveWithIO(os.Stdout, os.Stderr, args)
 }
 
-func runSuggestWithIO(stdout, _ io.Writer, args []string) error {
+func runSuggestWithIO(stdout, stderr io.Writer, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "snapshot":
+			return runSuggestSnapshot(stdout, stderr, args[1:])
+		case "changes":
+			return runSuggestChanges(stdout, stderr, args[1:])
+		}
+	}
 	flags := flag.NewFlagSet("suggest", flag.ContinueOnError)
 	flags.SetOutput(io.Discard)
 
 	var client string
 	var format string
+	var templatePath string
 	var refresh bool
 	var offline bool
+	var fromWARC string
 	var explain bool
+	var repoPath string
+	var noGaps bool
+	var hideCovered bool
+	var emitPatch bool
+	var apply bool
+	var force bool
 	var jsonOut bool
 	var help bool
 
 	flags.StringVar(&client, "client", "codex", "client target")
 	flags.StringVar(&format, "format", "json", "output format")
 	flags.BoolVar(&jsonOut, "json", false, "output json")
+	flags.StringVar(&templatePath, "template", "", "markdown template path")
 	flags.BoolVar(&refresh, "refresh", false, "refresh sources")
 	flags.BoolVar(&offline, "offline", false, "offline mode")
+	flags.StringVar(&fromWARC, "from-warc", "", "replay snapshots from a WARC archive")
 	flags.BoolVar(&explain, "explain", false, "include proof objects")
+	flags.StringVar(&repoPath, "repo", "", "repo root")
+	flags.BoolVar(&noGaps, "no-gaps", false, "skip gap analysis")
+	flags.BoolVar(&hideCovered, "hide-covered", false, "drop covered suggestions")
+	flags.BoolVar(&emitPatch, "emit-patch", false, "print suggestion patch")
+	flags.BoolVar(&apply, "apply", false, "apply suggestion patch")
+	flags.BoolVar(&force, "force", false, "apply with uncommitted changes")
 	flags.BoolVar(&help, "help", false, "show help")
 	flags.BoolVar(&help, "h", false, "show help")
 
@@ -86,27 +127,138 @@ func runSuggestWithIO(stdout, _ io.Writer, args []string) error {
 		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
 	}
 
+	formatSet := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "format" || f.Name == "json" {
+			formatSet = true
+		}
+	})
+
 	if jsonOut {
 		format = "json"
 	}
+	if templatePath != "" {
+		if formatSet && !isMarkdownFormat(format) {
+			return fmt.Errorf("--template requires --format md")
+		}
+		if emitPatch || apply {
+			return fmt.Errorf("--template cannot be combined with --emit-patch or --apply")
+		}
+		format = "md"
+	}
+	if fromWARC != "" && (offline || refresh) {
+		return fmt.Errorf("--from-warc cannot be combined with --offline or --refresh")
+	}
+	if noGaps && hideCovered {
+		return fmt.Errorf("--no-gaps cannot be combined with --hide-covered")
+	}
+	if emitPatch && apply {
+		return fmt.Errorf("--emit-patch cannot be combined with --apply")
+	}
+	if force && !apply {
+		return fmt.Errorf("--force requires --apply")
+	}
 
-	clientID, err := instructions.ParseClient(client)
+	clientIDs, err := parseSuggestClients(client)
 	if err != nil {
 		return err
 	}
+	if len(clientIDs) > 1 {
+		if templatePath != "" {
+			return fmt.Errorf("--template requires a single --client")
+		}
+		if emitPatch || apply {
+			return fmt.Errorf("--emit-patch and --apply require a single --client")
+		}
+	}
 
-	report, err := buildSuggestReport(context.Background(), clientID, suggestRunOptions{
-		Refresh: refresh,
-		Offline: offline,
-		Explain: explain,
-	})
+	var tmpl *suggest.SuggestionTemplate
+	if templatePath != "" {
+		// #nosec G304 -- template path is provided by the user.
+		data, err := os.ReadFile(templatePath)
+		if err != nil {
+			return fmt.Errorf("read template: %w", err)
+		}
+		tmpl, err = suggest.ParseSuggestionTemplate(filepath.Base(templatePath), string(data))
+		if err != nil {
+			return fmt.Errorf("parse template: %w", err)
+		}
+	}
+
+	runOpts := suggestRunOptions{
+		Refresh:  refresh,
+		Offline:  offline,
+		FromWARC: fromWARC,
+		Explain:  explain,
+	}
+	if len(clientIDs) > 1 {
+		multi, err := buildSuggestReports(context.Background(), clientIDs, runOpts)
+		if err != nil {
+			return err
+		}
+		if !noGaps {
+			for _, clientID := range clientIDs {
+				report := multi.Reports[clientID]
+				if err := applySuggestGaps(&report, clientID, repoPath, hideCovered); err != nil {
+					return err
+				}
+				multi.Reports[clientID] = report
+			}
+		}
+		return suggest.WriteMultiClientReport(stdout, format, multi)
+	}
+
+	clientID := clientIDs[0]
+	report, err := buildSuggestReport(context.Background(), clientID, runOpts)
 	if err != nil {
 		return err
 	}
+	if !noGaps {
+		if err := applySuggestGaps(&report, clientID, repoPath, hideCovered); err != nil {
+			return err
+		}
+	}
+	if emitPatch || apply {
+		return writeSuggestPatch(stdout, stderr, report, repoPath, apply, force)
+	}
+	if tmpl != nil {
+		return suggest.WriteSuggestMarkdown(stdout, report, tmpl)
+	}
 
 	return suggest.WriteSuggestReport(stdout, format, report)
 }
 
+// parseSuggestClients accepts one client, a comma-separated list, or "all".
+// Duplicates are dropped and list order is kept.
+func parseSuggestClients(value string) ([]instructions.Client, error) {
+	if strings.EqualFold(strings.TrimSpace(value), "all") {
+		return instructions.AllClients(), nil
+	}
+	var clients []instructions.Client
+	seen := make(map[instructions.Client]struct{})
+	for _, part := range strings.Split(value, ",") {
+		client, err := instructions.ParseClient(part)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[client]; ok {
+			continue
+		}
+		seen[client] = struct{}{}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+func isMarkdownFormat(format string) bool {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "md", "markdown":
+		return true
+	default:
+		return false
+	}
+}
+
 func runResolveWithIO(stdout, _ io.Writer, args []string) error {
 	flags := flag.NewFlagSet("resolve", flag.ContinueOnError)
 	flags.SetOutput(io.Discard)
@@ -188,31 +340,119 @@ func runResolveWithIO(stdout, _ io.Writer, args []string) error {
 }
 
 type suggestRunOptions struct {
-	Refresh bool
-	Offline bool
-	Explain bool
+	Refresh  bool
+	Offline  bool
+	FromWARC string
+	Explain  bool
 }
 
+// suggestFetchConcurrency bounds the source fetches in flight in one run.
+const suggestFetchConcurrency = 4
+
+// buildSuggestReport builds the report for one client. Run-wide warnings
+// come first, as in the multi-client report.
 func buildSuggestReport(ctx context.Context, client instructions.Client, opts suggestRunOptions) (suggest.Report, error) {
-	report := suggest.Report{
-		Client:      client,
+	multi, err := buildSuggestReports(ctx, []instructions.Client{client}, opts)
+	report := multi.Reports[client]
+	report.Warnings = append(multi.Warnings, report.Warnings...)
+	return report, err
+}
+
+// buildSuggestReports builds one report per client from a single pass over
+// the registry: each web source is fetched once, and each snapshot is
+// normalized once, before claims are grouped by the client that owns them.
+func buildSuggestReports(ctx context.Context, clients []instructions.Client, opts suggestRunOptions) (suggest.MultiClientReport, error) {
+	multi := suggest.MultiClientReport{
 		GeneratedAt: time.Now().UnixMilli(),
+		Reports:     make(map[instructions.Client]suggest.Report, len(clients)),
+	}
+	for _, client := range clients {
+		multi.Reports[client] = suggest.Report{Client: client, GeneratedAt: multi.GeneratedAt}
 	}
 
-	sources, err := loadSourcesForClient(client)
+	sources, err := loadSourcesForClients(clients)
 	if err != nil {
-		return report, err
+		return multi, err
+	}
+	var active []instructions.Client
+	for _, client := range clients {
+		if len(sources.forClient(client).sources) == 0 {
+			report := multi.Reports[client]
+			report.Warnings = append(report.Warnings, "no sources available for client")
+			multi.Reports[client] = report
+			continue
+		}
+		active = append(active, client)
+	}
+	if len(active) == 0 {
+		return multi, nil
+	}
+
+	// Run-wide warnings go through a scratch report so the cache, metadata
+	// and history helpers keep their single-report signatures.
+	shared := suggest.Report{}
+	reports := make(map[instructions.Client]*suggest.Report, len(active))
+	for _, client := range active {
+		report := multi.Reports[client]
+		reports[client] = &report
 	}
-	if len(sources.sources) == 0 {
-		report.Warnings = append(report.Warnings, "no sources available for client")
-		return report, nil
+	claims := make(map[instructions.Client][]suggest.Claim, len(active))
+
+	switch {
+	case opts.FromWARC != "":
+		snapshots, err := loadSuggestWARC(opts.FromWARC)
+		if err != nil {
+			return multi, err
+		}
+		shared.Warnings = append(shared.Warnings, snapshots.Warnings()...)
+		multi.GeneratedAt = 0
+		for _, client := range active {
+			subset := sources.forClient(client)
+			report := reports[client]
+			report.GeneratedAt = warcGeneratedAt(subset, snapshots)
+			if report.GeneratedAt > multi.GeneratedAt {
+				multi.GeneratedAt = report.GeneratedAt
+			}
+			claims[client] = loadClaimsFromCache(report, subset, snapshots)
+			claims[client] = append(claims[client], loadLocalClaims(report, subset)...)
+		}
+	case opts.Offline:
+		cache := newFileCache(&shared)
+		shared.Warnings = append(shared.Warnings, "offline mode enabled; using cached data only")
+		for _, client := range active {
+			subset := sources.forClient(client)
+			claims[client] = loadClaimsFromCache(reports[client], subset, cache)
+			claims[client] = append(claims[client], loadLocalClaims(reports[client], subset)...)
+		}
+	default:
+		cache := newFileCache(&shared)
+		store := newMetadataStore(&shared, opts.Refresh)
+		history := newSnapshotHistory(&shared)
+		fetcher, err := suggest.NewFetcher(suggest.FetcherOptions{Allowlist: sources.allowlist, Store: store, Cache: cache})
+		if err != nil {
+			return multi, err
+		}
+		for _, client := range active {
+			claims[client] = loadLocalClaims(reports[client], sources.forClient(client))
+		}
+
+		var web []suggest.Source
+		for _, src := range sources.sources {
+			if !src.Local() {
+				web = append(web, src)
+			}
+		}
+		docs := make(map[string]suggest.NormalizedDocument)
+		for i, outcome := range fetchSuggestSources(ctx, fetcher, web, suggestFetchConcurrency) {
+			src := web[i]
+			client := sourceClient(src)
+			claims[client] = append(claims[client], claimsFromFetch(reports[client], src, outcome, cache, history, docs)...)
+		}
 	}
 
-	cache := newFileCache(&report)
-	if opts.Offline {
-		report.Warnings = append(report.Warnings, "offline mode enabled; using cached data only")
-		claims := loadClaimsFromCache(&report, sources, cache)
-		summary := suggest.GenerateSuggestions(claims, sources.byID)
+	for _, client := range active {
+		report := reports[client]
+		summary := suggest.GenerateSuggestions(claims[client], sources.byID)
 		report.Suggestions = summary.Suggestions
 		report.Conflicts = summary.Conflicts
 		report.Omissions = summary.Omissions
@@ -221,67 +461,238 @@ func buildSuggestReport(ctx context.Context, client instructions.Client, opts su
 				report.Suggestions[i].Proof = suggest.Proof{}
 			}
 		}
-		return report, nil
+		multi.Reports[client] = *report
+	}
+	if len(clients) > 1 {
+		var all []suggest.Claim
+		for _, client := range active {
+			all = append(all, claims[client]...)
+		}
+		multi.CrossClientConflicts = suggest.DetectCrossClientConflicts(all)
 	}
+	multi.Warnings = shared.Warnings
+	return multi, nil
+}
 
-	store := newMetadataStore(&report, opts.Refresh)
-	fetcher, err := suggest.NewFetcher(suggest.FetcherOptions{Allowlist: sources.allowlist, Store: store, Cache: cache})
-	if err != nil {
-		return report, err
+type fetchOutcome struct {
+	result suggest.FetchResult
+	err    error
+}
+
+// fetchSuggestSources fetches sources with at most limit requests in flight.
+// The first source of each host goes out before the rest, so robots.txt is
+// read once per host and its warnings attach to the same source as in a
+// sequential run. Outcomes follow the order of sources.
+func fetchSuggestSources(ctx context.Context, fetcher *suggest.Fetcher, sources []suggest.Source, limit int) []fetchOutcome {
+	outcomes := make([]fetchOutcome, len(sources))
+	fetch := func(indexes []int) {
+		var group errgroup.Group
+		group.SetLimit(limit)
+		for _, i := range indexes {
+			group.Go(func() error {
+				res, err := fetcher.Fetch(ctx, suggest.FetchSource{ID: sources[i].ID, URL: sources[i].URL})
+				outcomes[i] = fetchOutcome{result: res, err: err}
+				return nil
+			})
+		}
+		_ = group.Wait()
 	}
 
-	var claims []suggest.Claim
-	for _, src := range sources.sources {
-		res, err := fetcher.Fetch(ctx, suggest.FetchSource{ID: src.ID, URL: src.URL})
-		if err != nil {
-			report.Warnings = append(report.Warnings, fmt.Sprintf("fetch %s failed: %v", src.URL, err))
-			continue
+	seenHosts := make(map[string]struct{})
+	var leaders, rest []int
+	for i, src := range sources {
+		host := src.URL
+		if parsed, err := url.Parse(src.URL); err == nil {
+			host = strings.ToLower(parsed.Hostname())
 		}
-		if res.Skipped {
-			report.Warnings = append(report.Warnings, fmt.Sprintf("fetch %s skipped: %s", src.URL, res.SkipReason))
+		if _, ok := seenHosts[host]; ok {
+			rest = append(rest, i)
 			continue
 		}
-		if len(res.Warnings) > 0 {
-			for _, warn := range res.Warnings {
-				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %s", src.URL, warn))
-			}
+		seenHosts[host] = struct{}{}
+		leaders = append(leaders, i)
+	}
+	fetch(leaders)
+	fetch(rest)
+	return outcomes
+}
+
+// claimsFromFetch caches and records one fetched source and extracts its
+// claims. docs memoizes normalized documents by snapshot so identical bodies
+// are normalized once per run.
+func claimsFromFetch(report *suggest.Report, src suggest.Source, outcome fetchOutcome, cache cacheWriter, history *suggest.SnapshotHistory, docs map[string]suggest.NormalizedDocument) []suggest.Claim {
+	res := outcome.result
+	if outcome.err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("fetch %s failed: %v", src.URL, outcome.err))
+		return nil
+	}
+	if res.Skipped {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("fetch %s skipped: %s", src.URL, res.SkipReason))
+		return nil
+	}
+	for _, warn := range res.Warnings {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %s", src.URL, warn))
+	}
+	if len(res.Body) == 0 {
+		if res.NotModified {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("fetch %s not modified but cache missing", src.URL))
+		} else {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("fetch %s returned empty body", src.URL))
 		}
-		if len(res.Body) == 0 {
-			if res.NotModified {
-				report.Warnings = append(report.Warnings, fmt.Sprintf("fetch %s not modified but cache missing", src.URL))
-			} else {
-				report.Warnings = append(report.Warnings, fmt.Sprintf("fetch %s returned empty body", src.URL))
-			}
-			continue
+		return nil
+	}
+	if cache != nil {
+		if err := cache.Put(src.URL, res.Body); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("cache %s write failed: %v", src.URL, err))
 		}
-		if cache != nil {
-			if err := cache.Put(src.URL, res.Body); err != nil {
-				report.Warnings = append(report.Warnings, fmt.Sprintf("cache %s write failed: %v", src.URL, err))
-			}
+	}
+	if history != nil {
+		if _, err := history.Record(src.URL, time.Now(), res.Body); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("history %s write failed: %v", src.URL, err))
 		}
+	}
 
-		format := formatFromURL(src.URL)
-		doc, err := suggest.NormalizeDocument(string(res.Body), format)
+	format := formatFromURL(src.URL)
+	snapshotID := "sha256:" + scanhash.SumHex(res.Body)
+	key := format + "|" + snapshotID
+	doc, ok := docs[key]
+	if !ok {
+		var err error
+		doc, err = suggest.NormalizeDocument(string(res.Body), format)
 		if err != nil {
 			report.Warnings = append(report.Warnings, fmt.Sprintf("normalize %s failed: %v", src.URL, err))
-			continue
+			return nil
 		}
-		snapshotID := "sha256:" + scanhash.SumHex(res.Body)
-		claims = append(claims, suggest.ExtractClaims(doc, src, snapshotID)...)
+		docs[key] = doc
 	}
+	return suggest.ExtractClaims(doc, src, snapshotID)
+}
 
-	summary := suggest.GenerateSuggestions(claims, sources.byID)
-	report.Suggestions = summary.Suggestions
-	report.Conflicts = summary.Conflicts
-	report.Omissions = summary.Omissions
+// loadSuggestWARC reads the snapshots recorded in a WARC archive.
+func loadSuggestWARC(path string) (*suggest.WARCSnapshots, error) {
+	// #nosec G304 -- archive path is provided by the user.
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open warc: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	snapshots, err := suggest.LoadWARCSnapshots(file)
+	if err != nil {
+		return nil, fmt.Errorf("read warc: %w", err)
+	}
+	return snapshots, nil
+}
 
-	if !opts.Explain {
-		for i := range report.Suggestions {
-			report.Suggestions[i].Proof = suggest.Proof{}
+// warcGeneratedAt is the newest record time among the sources replayed from
+// the archive, so the same archive always yields the same report.
+func warcGeneratedAt(sources sourcesByClient, snapshots *suggest.WARCSnapshots) int64 {
+	var generatedAt int64
+	for _, src := range sources.sources {
+		if recordedAt, ok := snapshots.RecordedAt(src.URL); ok && recordedAt.UnixMilli() > generatedAt {
+			generatedAt = recordedAt.UnixMilli()
 		}
 	}
+	return generatedAt
+}
+
+// applySuggestGaps classifies suggestions against the client's resolved
+// instruction chain. Without --repo, a missing git root only skips the
+// analysis with a warning.
+func applySuggestGaps(report *suggest.Report, client instructions.Client, repoPath string, hideCovered bool) error {
+	if len(report.Suggestions) == 0 {
+		return nil
+	}
+	docs, warnings, ok, err := loadGapInstructions(client, repoPath)
+	report.Warnings = append(report.Warnings, warnings...)
+	if err != nil || !ok {
+		return err
+	}
 
-	return report, nil
+	suggestions, summary := suggest.AnalyzeGaps(report.Suggestions, docs)
+	if hideCovered {
+		kept := suggestions[:0]
+		for _, suggestion := range suggestions {
+			if suggestion.Coverage != suggest.CoverageCovered {
+				kept = append(kept, suggestion)
+			}
+		}
+		suggestions = kept
+	}
+	report.Suggestions = suggestions
+	report.Gaps = &summary
+	return nil
+}
+
+// loadGapInstructions reads the client's resolved instruction files in the
+// repo. ok is false when the analysis was skipped with a warning.
+func loadGapInstructions(client instructions.Client, repoPath string) ([]suggest.InstructionText, []string, bool, error) {
+	repoRoot, err := resolveRepoRoot(repoPath)
+	if err != nil {
+		if repoPath != "" {
+			return nil, nil, false, err
+		}
+		return nil, []string{fmt.Sprintf("gap analysis skipped: %v", err)}, false, nil
+	}
+	cwd := repoRoot
+	if repoPath == "" {
+		if wd, err := os.Getwd(); err == nil {
+			cwd = wd
+		}
+	}
+
+	adapter, err := resolveAdapter(client)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	resolution, err := adapter.Resolve(instructions.ResolveOptions{RepoRoot: repoRoot, Cwd: cwd})
+	if err != nil {
+		return nil, []string{fmt.Sprintf("gap analysis skipped: resolve %s: %v", client, err)}, false, nil
+	}
+	docs, warnings := suggest.LoadInstructionTexts(nil, resolution)
+	return docs, warnings, true, nil
+}
+
+// writeSuggestPatch renders accepted suggestions into the client's primary
+// instruction file as a unified diff, printing it or applying it through the
+// same path checks and batch apply as pull.
+func writeSuggestPatch(stdout, stderr io.Writer, report suggest.Report, repoPath string, apply bool, force bool) error {
+	repoRoot, err := resolveRepoRoot(repoPath)
+	if err != nil {
+		return err
+	}
+	for _, warning := range report.Warnings {
+		_, _ = fmt.Fprintf(stderr, "warning: %s\n", warning)
+	}
+	patch, err := suggest.BuildSuggestionPatch(repoRoot, report.Client, suggest.AcceptedSuggestions(report.Suggestions))
+	if err != nil {
+		return err
+	}
+	if patch.Diff == "" {
+		_, _ = fmt.Fprintf(stderr, "No new suggestions for %s.\n", patch.Path)
+		return nil
+	}
+	if !apply {
+		_, err := io.WriteString(stdout, patch.Diff)
+		return err
+	}
+
+	results, err := syncer.ApplyPatches(repoRoot, []syncer.Patch{{
+		ID:          "suggest:sha256:" + scanhash.SumHex([]byte(patch.Diff)),
+		Path:        patch.Path,
+		PatchFormat: "unified",
+		PatchBody:   patch.Diff,
+	}}, syncer.ApplyOptions{Force: force})
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Status == syncer.PatchApplied {
+			_, _ = fmt.Fprintf(stdout, "Applied %d suggestion(s) to %s.\n", len(patch.Added), patch.Path)
+		}
+	}
+	return nil
 }
 
 type cacheWriter interface {
@@ -364,6 +775,15 @@ func newFileCache(report *suggest.Report) cacheWriter {
 	return cache
 }
 
+func newSnapshotHistory(report *suggest.Report) *suggest.SnapshotHistory {
+	history, err := suggest.NewSnapshotHistory()
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("history init failed: %v", err))
+		return nil
+	}
+	return history
+}
+
 func loadClaimsFromCache(report *suggest.Report, sources sourcesByClient, cache suggest.Cache) []suggest.Claim {
 	if cache == nil {
 		report.Warnings = append(report.Warnings, "offline cache unavailable; no cached bodies found")
@@ -372,6 +792,9 @@ func loadClaimsFromCache(report *suggest.Report, sources sourcesByClient, cache
 
 	var claims []suggest.Claim
 	for _, src := range sources.sources {
+		if src.Local() {
+			continue
+		}
 		body, ok := cache.Get(src.URL)
 		if !ok {
 			report.Warnings = append(report.Warnings, fmt.Sprintf("cache miss for %s", src.URL))
@@ -389,6 +812,40 @@ func loadClaimsFromCache(report *suggest.Report, sources sourcesByClient, cache
 	return claims
 }
 
+// loadLocalClaims reads file and directory sources straight from disk. They
+// bypass the fetcher, so robots.txt and the allowlist do not apply, and they
+// are neither cached nor recorded in the snapshot history. Each file's
+// claims cite its own file:// URL.
+func loadLocalClaims(report *suggest.Report, sources sourcesByClient) []suggest.Claim {
+	var claims []suggest.Claim
+	for _, src := range sources.sources {
+		if !src.Local() {
+			continue
+		}
+		docs, err := suggest.ReadLocalSource(src)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("read %s failed: %v", src.LocalPath(), err))
+			continue
+		}
+		if len(docs) == 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("no markdown or html files in %s", src.LocalPath()))
+			continue
+		}
+		for _, local := range docs {
+			doc, err := suggest.NormalizeDocument(string(local.Body), local.Format)
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("normalize %s failed: %v", local.Path, err))
+				continue
+			}
+			fileSource := src
+			fileSource.URL = local.URL
+			snapshotID := "sha256:" + scanhash.SumHex(local.Body)
+			claims = append(claims, suggest.ExtractClaims(doc, fileSource, snapshotID)...)
+		}
+	}
+	return claims
+}
+
 type sourcesByClient struct {
 	sources   []suggest.Source
 	byID      map[string]suggest.Source
@@ -396,15 +853,25 @@ type sourcesByClient struct {
 }
 
 func loadSourcesForClient(client instructions.Client) (sourcesByClient, error) {
+	return loadSourcesForClients([]instructions.Client{client})
+}
+
+// loadSourcesForClients keeps the registry sources of any of clients, in
+// registry order.
+func loadSourcesForClients(clients []instructions.Client) (sourcesByClient, error) {
 	reg, _, err := suggest.LoadSources()
 	if err != nil {
 		return sourcesByClient{}, err
 	}
 
+	wanted := make(map[instructions.Client]struct{}, len(clients))
+	for _, client := range clients {
+		wanted[client] = struct{}{}
+	}
 	var filtered []suggest.Source
 	byID := make(map[string]suggest.Source)
 	for _, src := range reg.Sources {
-		if strings.EqualFold(src.Client, string(client)) {
+		if _, ok := wanted[sourceClient(src)]; ok {
 			filtered = append(filtered, src)
 			byID[src.ID] = src
 		}
@@ -413,6 +880,22 @@ func loadSourcesForClient(client instructions.Client) (sourcesByClient, error) {
 	return sourcesByClient{sources: filtered, byID: byID, allowlist: reg.AllowlistHosts}, nil
 }
 
+// forClient narrows the set to one client's sources.
+func (s sourcesByClient) forClient(client instructions.Client) sourcesByClient {
+	subset := sourcesByClient{byID: make(map[string]suggest.Source), allowlist: s.allowlist}
+	for _, src := range s.sources {
+		if sourceClient(src) == client {
+			subset.sources = append(subset.sources, src)
+			subset.byID[src.ID] = src
+		}
+	}
+	return subset
+}
+
+func sourceClient(src suggest.Source) instructions.Client {
+	return instructions.Client(strings.ToLower(strings.TrimSpace(src.Client)))
+}
+
 func formatFromURL(rawURL string) string {
 	lower := strings.ToLower(rawURL)
 	switch {
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"markdowntown-cli/internal/instructions"
+	"markdowntown-cli/internal/suggest"
+)
+
+const suggestChangesUsage = `markdowntown suggest changes
+
+Usage:
+  markdowntown suggest changes --since <snapshot|date> [flags]
+
+Compares the normative claims of each source's stored snapshot at --since
+with its latest stored snapshot. Snapshots are recorded whenever suggest
+fetches a changed body or snapshot import loads an archive.
+
+Flags:
+  --since <snapshot|date>                        Snapshot ID (sha256:<hex> or a unique hex prefix) or date (YYYY-MM-DD or RFC3339)
+  --client <codex|copilot|vscode|claude|gemini>  Client target (default codex)
+  --format <json|md>                             Output format (default json)
+  --json                                         Alias for --format json
+  --repo <path>                                  Repo whose instructions are checked for new MUST claims (defaults to git root)
+  --fail-on-new-must                             Exit 1 when tier-0 sources gain MUST claims the repo does not cover
+  -h, --help                                     Show help
+`
+
+func runSuggestChanges(stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("suggest changes", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	var since string
+	var client string
+	var format string
+	var jsonOut bool
+	var repoPath string
+	var failOnNewMust bool
+	var help bool
+
+	flags.StringVar(&since, "since", "", "baseline snapshot or date")
+	flags.StringVar(&client, "client", "codex", "client target")
+	flags.StringVar(&format, "format", "json", "output format")
+	flags.BoolVar(&jsonOut, "json", false, "output json")
+	flags.StringVar(&repoPath, "repo", "", "repo root")
+	flags.BoolVar(&failOnNewMust, "fail-on-new-must", false, "fail on unreflected MUST claims")
+	flags.BoolVar(&help, "help", false, "show help")
+	flags.BoolVar(&help, "h", false, "show help")
+
+	if err := flags.Parse(args); err != nil {
+		return newCLIError(err, 2)
+	}
+	if help {
+		_, _ = fmt.Fprint(stdout, suggestChangesUsage)
+		return nil
+	}
+	if flags.NArg() > 0 {
+		return newCLIError(fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " ")), 2)
+	}
+	if strings.TrimSpace(since) == "" {
+		return newCLIError(fmt.Errorf("--since is required"), 2)
+	}
+	if jsonOut {
+		format = "json"
+	}
+
+	clientID, err := instructions.ParseClient(client)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	sources, err := loadSourcesForClient(clientID)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	history, err := suggest.NewSnapshotHistory()
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	snapshots, err := history.Snapshots()
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+	baseline, err := parseChangesSince(since, snapshots)
+	if err != nil {
+		return newCLIError(err, 2)
+	}
+
+	report := suggest.ChangeReport{
+		Client:      clientID,
+		GeneratedAt: time.Now().UnixMilli(),
+		Since:       since,
+		Sources:     []suggest.SourceChanges{},
+	}
+	var candidates []suggest.ClaimChange
+	for _, src := range sources.sources {
+		if src.Local() {
+			// Local sources are read from disk and have no snapshot history.
+			continue
+		}
+		base, head, ok := baseline.pick(src.URL, snapshots)
+		if !ok {
+			if baseline.snapshotID == "" {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("no snapshot of %s at or before %s", src.URL, since))
+			}
+			continue
+		}
+		changes, err := diffSnapshots(src, base, head)
+		if err != nil {
+			report.Warnings = append(report.Warnings, err.Error())
+			continue
+		}
+		report.Sources = append(report.Sources, suggest.SourceChanges{
+			SourceID:       src.ID,
+			URL:            src.URL,
+			Tier:           src.Tier,
+			BaseSnapshot:   base.ID,
+			BaseRecordedAt: base.RecordedAt.UnixMilli(),
+			HeadSnapshot:   head.ID,
+			HeadRecordedAt: head.RecordedAt.UnixMilli(),
+			Changes:        changes,
+		})
+		if !strings.EqualFold(strings.TrimSpace(src.Tier), "tier-0") {
+			continue
+		}
+		for _, change := range changes {
+			if change.Strength == suggest.StrengthMust && change.Kind != suggest.ChangeRemoved {
+				candidates = append(candidates, change)
+			}
+		}
+	}
+	if baseline.snapshotID != "" && len(report.Sources) == 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("snapshot %s belongs to no %s source", baseline.snapshotID, clientID))
+	}
+
+	if len(candidates) > 0 {
+		unreflected, warnings, err := unreflectedMustClaims(clientID, repoPath, candidates)
+		report.Warnings = append(report.Warnings, warnings...)
+		if err != nil {
+			return newCLIError(err, 2)
+		}
+		report.Unreflected = unreflected
+	}
+
+	if err := suggest.WriteChangeReport(stdout, format, report); err != nil {
+		return newCLIError(err, 2)
+	}
+	if failOnNewMust && len(report.Unreflected) > 0 {
+		return newCLIError(fmt.Errorf("suggest changes: %d new tier-0 MUST claim(s) not reflected in instructions", len(report.Unreflected)), 1)
+	}
+	return nil
+}
+
+// changesBaseline selects the base snapshot per source: an explicit snapshot
+// ID, or the newest snapshot recorded at or before a date.
+type changesBaseline struct {
+	snapshotID string
+	at         time.Time
+}
+
+func parseChangesSince(value string, snapshots []suggest.HistorySnapshot) (changesBaseline, error) {
+	value = strings.TrimSpace(value)
+	if at, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return changesBaseline{at: at}, nil
+	}
+	// A bare date includes every snapshot recorded that day (UTC).
+	if day, err := time.Parse("2006-01-02", value); err == nil {
+		return changesBaseline{at: day.AddDate(0, 0, 1).Add(-time.Nanosecond)}, nil
+	}
+
+	prefix := strings.TrimPrefix(strings.ToLower(value), "sha256:")
+	if len(prefix) < 7 || !isHex(prefix) {
+		return changesBaseline{}, fmt.Errorf("invalid --since %q: expected a snapshot ID or a date", value)
+	}
+	var match string
+	for _, snapshot := range snapshots {
+		if !strings.HasPrefix(strings.TrimPrefix(snapshot.ID, "sha256:"), prefix) {
+			continue
+		}
+		if match != "" && match != snapshot.ID {
+			return changesBaseline{}, fmt.Errorf("snapshot prefix %q is ambiguous", value)
+		}
+		match = snapshot.ID
+	}
+	if match == "" {
+		return changesBaseline{}, fmt.Errorf("snapshot %q not found in history", value)
+	}
+	return changesBaseline{snapshotID: match}, nil
+}
+
+// pick returns the base and latest snapshots of url. snapshots must be
+// ordered by record time.
+func (b changesBaseline) pick(url string, snapshots []suggest.HistorySnapshot) (suggest.HistorySnapshot, suggest.HistorySnapshot, bool) {
+	var base, head suggest.HistorySnapshot
+	found := false
+	for _, snapshot := range snapshots {
+		if snapshot.URL != url {
+			continue
+		}
+		head = snapshot
+		switch {
+		case b.snapshotID != "":
+			if snapshot.ID == b.snapshotID {
+				base = snapshot
+				found = true
+			}
+		case !snapshot.RecordedAt.After(b.at):
+			base = snapshot
+			found = true
+		}
+	}
+	return base, head, found
+}
+
+func diffSnapshots(src suggest.Source, base, head suggest.HistorySnapshot) ([]suggest.ClaimChange, error) {
+	if base.ID == head.ID {
+		return []suggest.ClaimChange{}, nil
+	}
+	baseClaims, err := snapshotClaims(src, base)
+	if err != nil {
+		return nil, err
+	}
+	headClaims, err := snapshotClaims(src, head)
+	if err != nil {
+		return nil, err
+	}
+	changes := suggest.DiffClaims(baseClaims, headClaims)
+	if changes == nil {
+		changes = []suggest.ClaimChange{}
+	}
+	return changes, nil
+}
+
+func snapshotClaims(src suggest.Source, snapshot suggest.HistorySnapshot) ([]suggest.Claim, error) {
+	doc, err := suggest.NormalizeDocument(string(snapshot.Body), formatFromURL(src.URL))
+	if err != nil {
+		return nil, fmt.Errorf("normalize %s (%s) failed: %v", src.URL, snapshot.ID, err)
+	}
+	return suggest.ExtractClaims(doc, src, snapshot.ID), nil
+}
+
+// unreflectedMustClaims keeps the changes the repo's instructions do not
+// cover. When the instructions cannot be resolved every change is kept.
+func unreflectedMustClaims(client instructions.Client, repoPath string, changes []suggest.ClaimChange) ([]suggest.ClaimChange, []string, error) {
+	docs, warnings, ok, err := loadGapInstructions(client, repoPath)
+	if err != nil {
+		return nil, warnings, err
+	}
+	if !ok {
+		return changes, warnings, nil
+	}
+
+	suggestions := make([]suggest.Suggestion, len(changes))
+	for i, change := range changes {
+		suggestions[i] = suggest.Suggestion{ClaimID: change.ClaimID, Text: change.Text}
+	}
+	classified, _ := suggest.AnalyzeGaps(suggestions, docs)
+	var unreflected []suggest.ClaimChange
+	for i, suggestion := range classified {
+		if suggestion.Coverage != suggest.CoverageCovered {
+			unreflected = append(unreflected, changes[i])
+		}
+	}
+	return unreflected, warnings, nil
+}
+
+func isHex(value string) bool {
+	if len(value)%2 == 1 {
+		value += "0"
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
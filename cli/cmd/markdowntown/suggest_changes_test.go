@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"markdowntown-cli/internal/suggest"
+)
+
+func TestSuggestChanges(t *testing.T) {
+	tmp := t.TempDir()
+	sourcesPath := filepath.Join(tmp, "doc-sources.json")
+	sourceURL := "https://example.com/docs"
+	if err := os.WriteFile(sourcesPath, []byte(testSourcesJSON(sourceURL)), 0o600); err != nil {
+		t.Fatalf("write sources: %v", err)
+	}
+	t.Setenv("MARKDOWNTOWN_SOURCES", sourcesPath)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("CODEX_HOME", filepath.Join(tmp, "codex-home"))
+
+	history, err := suggest.NewSnapshotHistory()
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	t0 := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := history.Record(sourceURL, t0, []byte("You MUST keep instructions short.\n")); err != nil {
+		t.Fatalf("record v1: %v", err)
+	}
+	if _, err := history.Record(sourceURL, t0.Add(48*time.Hour), []byte("You SHOULD keep instructions short.\nYou MUST run the linter before pushing.\n")); err != nil {
+		t.Fatalf("record v2: %v", err)
+	}
+
+	repo := filepath.Join(tmp, "repo")
+	if err := os.MkdirAll(repo, 0o700); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	runGit(t, repo, "init")
+	agentsPath := filepath.Join(repo, "AGENTS.md")
+	if err := os.WriteFile(agentsPath, []byte("# Rules\nKeep instructions short.\n"), 0o600); err != nil {
+		t.Fatalf("write AGENTS.md: %v", err)
+	}
+
+	var out bytes.Buffer
+	err = runSuggestWithIO(&out, io.Discard, []string{"changes", "--since", "2024-05-02", "--repo", repo, "--fail-on-new-must"})
+	var cliErr *cliError
+	if !errors.As(err, &cliErr) || cliErr.code != 1 {
+		t.Fatalf("expected exit 1 for unreflected MUST claim, got %v", err)
+	}
+	var report suggest.ChangeReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("parse report: %v\n%s", err, out.String())
+	}
+	if len(report.Sources) != 1 || len(report.Sources[0].Changes) != 2 {
+		t.Fatalf("unexpected sources: %+v", report.Sources)
+	}
+	changes := report.Sources[0].Changes
+	if changes[0].Kind != suggest.ChangeStrength || changes[0].PreviousStrength != suggest.StrengthMust || changes[0].Strength != suggest.StrengthShould {
+		t.Fatalf("unexpected strength change: %+v", changes[0])
+	}
+	if changes[1].Kind != suggest.ChangeAdded || changes[1].Span.SectionID == "" {
+		t.Fatalf("unexpected added change: %+v", changes[1])
+	}
+	if len(report.Unreflected) != 1 || !strings.Contains(report.Unreflected[0].Text, "linter") {
+		t.Fatalf("unexpected unreflected claims: %+v", report.Unreflected)
+	}
+
+	if err := os.WriteFile(agentsPath, []byte("# Rules\nKeep instructions short.\nRun the linter before pushing.\n"), 0o600); err != nil {
+		t.Fatalf("update AGENTS.md: %v", err)
+	}
+	out.Reset()
+	if err := runSuggestWithIO(&out, io.Discard, []string{"changes", "--since", report.Sources[0].BaseSnapshot[:19], "--repo", repo, "--fail-on-new-must", "--format", "md"}); err != nil {
+		t.Fatalf("expected covered claim to pass, got %v", err)
+	}
+	if !strings.Contains(out.String(), "- MUST → SHOULD: You SHOULD keep instructions short.") || strings.Contains(out.String(), "Not reflected") {
+		t.Fatalf("unexpected markdown:\n%s", out.String())
+	}
+
+	// A date includes snapshots recorded later that day.
+	out.Reset()
+	if err := runSuggestWithIO(&out, io.Discard, []string{"changes", "--since", "2024-05-01"}); err != nil {
+		t.Fatalf("changes since recording day failed: %v", err)
+	}
+	report = suggest.ChangeReport{}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if len(report.Sources) != 1 || len(report.Warnings) != 0 {
+		t.Fatalf("expected the same-day snapshot as baseline, got %+v", report)
+	}
+
+	out.Reset()
+	if err := runSuggestWithIO(&out, io.Discard, []string{"changes", "--since", "2024-01-01"}); err != nil {
+		t.Fatalf("changes before history failed: %v", err)
+	}
+	report = suggest.ChangeReport{}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if len(report.Sources) != 0 || len(report.Warnings) != 1 {
+		t.Fatalf("expected a missing-baseline warning, got %+v", report)
+	}
+
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"changes"}); err == nil {
+		t.Fatalf("expected --since to be required")
+	}
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"changes", "--since", "last week"}); err == nil {
+		t.Fatalf("expected invalid --since error")
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"markdowntown-cli/internal/instructions"
+	"markdowntown-cli/internal/suggest"
+)
+
+const suggestSnapshotUsage = `markdowntown suggest snapshot
+
+Usage:
+  markdowntown suggest snapshot export [--client <id|all>] [--out <file>]
+  markdowntown suggest snapshot import <file>
+
+Export writes cached source bodies as WARC response records (stdout unless
+--out is set). Import loads the response records of a WARC archive into the
+cache used by --offline and the snapshot history used by suggest changes.
+
+Flags:
+  --client <codex|copilot|vscode|claude|gemini|all>  Sources to export (default all)
+  --out <file>                                       Write the archive to a file
+  -h, --help                                         Show help
+`
+
+func runSuggestSnapshot(stdout, stderr io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("snapshot subcommand required")
+	}
+	switch args[0] {
+	case "export":
+		return runSuggestSnapshotExport(stdout, stderr, args[1:])
+	case "import":
+		return runSuggestSnapshotImport(stdout, stderr, args[1:])
+	case "-h", "--help":
+		_, _ = fmt.Fprint(stdout, suggestSnapshotUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown snapshot subcommand: %s", args[0])
+	}
+}
+
+func runSuggestSnapshotExport(stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("suggest snapshot export", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	var client string
+	var outPath string
+	var help bool
+	flags.StringVar(&client, "client", "all", "client target")
+	flags.StringVar(&outPath, "out", "", "output path")
+	flags.BoolVar(&help, "help", false, "show help")
+	flags.BoolVar(&help, "h", false, "show help")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if help {
+		_, _ = fmt.Fprint(stdout, suggestSnapshotUsage)
+		return nil
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	urls, err := snapshotSourceURLs(client)
+	if err != nil {
+		return err
+	}
+	cache, err := suggest.NewFileCache()
+	if err != nil {
+		return err
+	}
+	verifiedAt := snapshotVerifiedTimes()
+
+	type exportEntry struct {
+		url       string
+		fetchedAt time.Time
+		body      []byte
+	}
+	var entries []exportEntry
+	for _, url := range urls {
+		body, ok := cache.Get(url)
+		if !ok {
+			_, _ = fmt.Fprintf(stderr, "warning: no cached body for %s\n", url)
+			continue
+		}
+		fetchedAt, ok := verifiedAt[url]
+		if !ok {
+			fetchedAt, _ = cache.ModTime(url)
+		}
+		entries = append(entries, exportEntry{url: url, fetchedAt: fetchedAt, body: body})
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no cached snapshots to export; run suggest without --offline first")
+	}
+
+	out := stdout
+	if outPath != "" {
+		// #nosec G304 -- output path is provided by the user.
+		file, err := os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		out = file
+	}
+	writer := suggest.NewWARCWriter(out)
+	for _, entry := range entries {
+		if _, err := writer.WriteSnapshotResponse(entry.url, entry.fetchedAt, entry.body); err != nil {
+			return fmt.Errorf("write %s: %w", entry.url, err)
+		}
+	}
+	_, _ = fmt.Fprintf(stderr, "Exported %d snapshot(s).\n", len(entries))
+	return nil
+}
+
+func runSuggestSnapshotImport(stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("suggest snapshot import", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	var help bool
+	flags.BoolVar(&help, "help", false, "show help")
+	flags.BoolVar(&help, "h", false, "show help")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if help {
+		_, _ = fmt.Fprint(stdout, suggestSnapshotUsage)
+		return nil
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("snapshot import requires exactly one WARC file")
+	}
+
+	// #nosec G304 -- archive path is provided by the user.
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("open warc: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	snapshots, err := suggest.LoadWARCSnapshots(file)
+	if err != nil {
+		return fmt.Errorf("read warc: %w", err)
+	}
+	for _, warning := range snapshots.Warnings() {
+		_, _ = fmt.Fprintf(stderr, "warning: %s\n", warning)
+	}
+
+	cache, err := suggest.NewFileCache()
+	if err != nil {
+		return err
+	}
+	history, err := suggest.NewSnapshotHistory()
+	if err != nil {
+		return err
+	}
+	urls := snapshots.URLs()
+	for _, url := range urls {
+		body, _ := snapshots.Get(url)
+		if err := cache.Put(url, body); err != nil {
+			return fmt.Errorf("cache %s: %w", url, err)
+		}
+		recordedAt, _ := snapshots.RecordedAt(url)
+		if _, err := history.Record(url, recordedAt, body); err != nil {
+			return fmt.Errorf("history %s: %w", url, err)
+		}
+	}
+	_, _ = fmt.Fprintf(stdout, "Imported %d snapshot(s).\n", len(urls))
+	return nil
+}
+
+// snapshotSourceURLs lists the distinct web source URLs for client, or for
+// every client when client is "all". Local sources are never cached.
+func snapshotSourceURLs(client string) ([]string, error) {
+	all := strings.EqualFold(strings.TrimSpace(client), "all")
+	var clientID instructions.Client
+	if !all {
+		parsed, err := instructions.ParseClient(client)
+		if err != nil {
+			return nil, err
+		}
+		clientID = parsed
+	}
+
+	reg, _, err := suggest.LoadSources()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	var urls []string
+	for _, src := range reg.Sources {
+		if src.Local() || (!all && !strings.EqualFold(src.Client, string(clientID))) {
+			continue
+		}
+		if _, ok := seen[src.URL]; ok {
+			continue
+		}
+		seen[src.URL] = struct{}{}
+		urls = append(urls, src.URL)
+	}
+	sort.Strings(urls)
+	return urls, nil
+}
+
+// snapshotVerifiedTimes maps source URLs to their last verified fetch time.
+// Missing or unreadable metadata yields an empty map.
+func snapshotVerifiedTimes() map[string]time.Time {
+	times := make(map[string]time.Time)
+	path, err := suggest.MetadataPath()
+	if err != nil {
+		return times
+	}
+	store, err := suggest.LoadMetadata(path)
+	if err != nil {
+		return times
+	}
+	for _, record := range store.Sources {
+		if record.LastVerifiedAt == 0 {
+			continue
+		}
+		at := time.UnixMilli(record.LastVerifiedAt)
+		if existing, ok := times[record.URL]; !ok || at.After(existing) {
+			times[record.URL] = at
+		}
+	}
+	return times
+}
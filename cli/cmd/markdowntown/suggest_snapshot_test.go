@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/suggest"
+)
+
+func TestSuggestSnapshotExportImportReplay(t *testing.T) {
+	tmp := t.TempDir()
+	sourcesPath := filepath.Join(tmp, "doc-sources.json")
+	sourceURL := "https://example.com/docs"
+	if err := os.WriteFile(sourcesPath, []byte(testSourcesJSON(sourceURL)), 0o600); err != nil {
+		t.Fatalf("write sources: %v", err)
+	}
+	t.Setenv("MARKDOWNTOWN_SOURCES", sourcesPath)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("CODEX_HOME", filepath.Join(tmp, "codex-home"))
+
+	cache, err := suggest.NewFileCache()
+	if err != nil {
+		t.Fatalf("init cache: %v", err)
+	}
+	if err := cache.Put(sourceURL, []byte("You MUST keep instructions short.\n")); err != nil {
+		t.Fatalf("cache put: %v", err)
+	}
+
+	archive := filepath.Join(tmp, "snapshots.warc")
+	var stderr bytes.Buffer
+	if err := runSuggestWithIO(io.Discard, &stderr, []string{"snapshot", "export", "--client", "codex", "--out", archive}); err != nil {
+		t.Fatalf("snapshot export failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Exported 1 snapshot(s).") {
+		t.Fatalf("unexpected export output: %q", stderr.String())
+	}
+
+	// Replay from the archive with an empty cache: the run must not touch the
+	// network and must be reproducible byte-for-byte.
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "replay"))
+	replay := func() []byte {
+		var out bytes.Buffer
+		if err := runSuggestWithIO(&out, io.Discard, []string{"--from-warc", archive, "--no-gaps"}); err != nil {
+			t.Fatalf("runSuggest --from-warc failed: %v", err)
+		}
+		return out.Bytes()
+	}
+	first := replay()
+	if !bytes.Equal(first, replay()) {
+		t.Fatalf("expected deterministic replay output")
+	}
+	var report suggest.Report
+	if err := json.Unmarshal(first, &report); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if len(report.Suggestions) != 1 || report.Suggestions[0].Text != "You MUST keep instructions short." {
+		t.Fatalf("unexpected replayed suggestions: %+v", report.Suggestions)
+	}
+	if report.GeneratedAt == 0 {
+		t.Fatalf("expected generatedAt from the archive")
+	}
+
+	var out bytes.Buffer
+	if err := runSuggestWithIO(&out, io.Discard, []string{"snapshot", "import", archive}); err != nil {
+		t.Fatalf("snapshot import failed: %v", err)
+	}
+	if out.String() != "Imported 1 snapshot(s).\n" {
+		t.Fatalf("unexpected import output: %q", out.String())
+	}
+	out.Reset()
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--offline", "--no-gaps"}); err != nil {
+		t.Fatalf("runSuggest --offline failed: %v", err)
+	}
+	report = suggest.Report{}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if len(report.Suggestions) != 1 {
+		t.Fatalf("expected imported snapshot to serve offline runs, got %+v", report)
+	}
+
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"--from-warc", archive, "--offline"}); err == nil {
+		t.Fatalf("expected --from-warc/--offline conflict error")
+	}
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"snapshot", "import"}); err == nil {
+		t.Fatalf("expected missing file error")
+	}
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "empty"))
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"snapshot", "export"}); err == nil {
+		t.Fatalf("expected error when nothing is cached")
+	}
+}
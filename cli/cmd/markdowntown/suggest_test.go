@@ -6,6 +6,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"markdowntown-cli/internal/instructions"
@@ -86,3 +88,344 @@ func testSourcesJSON(url string) string {
   ]
 }`
 }
+
+func TestSuggestGapAnalysis(t *testing.T) {
+	tmp := t.TempDir()
+	sourcesPath := filepath.Join(tmp, "doc-sources.json")
+	sourceURL := "https://example.com/docs"
+	if err := os.WriteFile(sourcesPath, []byte(testSourcesJSON(sourceURL)), 0o600); err != nil {
+		t.Fatalf("write sources: %v", err)
+	}
+	t.Setenv("MARKDOWNTOWN_SOURCES", sourcesPath)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("CODEX_HOME", filepath.Join(tmp, "codex-home"))
+
+	cache, err := suggest.NewFileCache()
+	if err != nil {
+		t.Fatalf("init cache: %v", err)
+	}
+	body := []byte("You MUST keep instructions short.\nYou SHOULD run the linter before pushing.\n")
+	if err := cache.Put(sourceURL, body); err != nil {
+		t.Fatalf("cache put: %v", err)
+	}
+
+	repo := filepath.Join(tmp, "repo")
+	if err := os.MkdirAll(repo, 0o700); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	runGit(t, repo, "init")
+	agentsPath := filepath.Join(repo, "AGENTS.md")
+	if err := os.WriteFile(agentsPath, []byte("# Rules\nKeep instructions short.\n"), 0o600); err != nil {
+		t.Fatalf("write AGENTS.md: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--offline", "--repo", repo}); err != nil {
+		t.Fatalf("runSuggest failed: %v", err)
+	}
+	var report suggest.Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if report.Gaps == nil || report.Gaps.Covered != 1 || report.Gaps.Missing != 1 {
+		t.Fatalf("unexpected gap summary: %+v", report.Gaps)
+	}
+	for _, suggestion := range report.Suggestions {
+		if suggestion.Coverage == suggest.CoverageCovered {
+			if suggestion.Evidence == nil || suggestion.Evidence.Line != 2 || filepath.Base(suggestion.Evidence.Path) != "AGENTS.md" {
+				t.Fatalf("unexpected evidence: %+v", suggestion.Evidence)
+			}
+		}
+	}
+
+	out.Reset()
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--offline", "--repo", repo, "--hide-covered"}); err != nil {
+		t.Fatalf("runSuggest --hide-covered failed: %v", err)
+	}
+	report = suggest.Report{}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if len(report.Suggestions) != 1 || report.Suggestions[0].Coverage != suggest.CoverageMissing {
+		t.Fatalf("expected only the missing suggestion, got %+v", report.Suggestions)
+	}
+
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"--no-gaps", "--hide-covered"}); err == nil {
+		t.Fatalf("expected --no-gaps conflict error")
+	}
+}
+
+func TestSuggestEmitPatchAndApply(t *testing.T) {
+	tmp := t.TempDir()
+	sourcesPath := filepath.Join(tmp, "doc-sources.json")
+	sourceURL := "https://example.com/docs"
+	if err := os.WriteFile(sourcesPath, []byte(testSourcesJSON(sourceURL)), 0o600); err != nil {
+		t.Fatalf("write sources: %v", err)
+	}
+	t.Setenv("MARKDOWNTOWN_SOURCES", sourcesPath)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("CODEX_HOME", filepath.Join(tmp, "codex-home"))
+
+	cache, err := suggest.NewFileCache()
+	if err != nil {
+		t.Fatalf("init cache: %v", err)
+	}
+	body := []byte("You MUST keep instructions short.\nYou SHOULD run the linter before pushing.\n")
+	if err := cache.Put(sourceURL, body); err != nil {
+		t.Fatalf("cache put: %v", err)
+	}
+
+	repo := filepath.Join(tmp, "repo")
+	if err := os.MkdirAll(repo, 0o700); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	runGit(t, repo, "init")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "Test")
+	agentsPath := filepath.Join(repo, "AGENTS.md")
+	if err := os.WriteFile(agentsPath, []byte("# Rules\nKeep instructions short.\n"), 0o600); err != nil {
+		t.Fatalf("write AGENTS.md: %v", err)
+	}
+	runGit(t, repo, "add", "AGENTS.md")
+	runGit(t, repo, "commit", "-m", "init")
+
+	var out bytes.Buffer
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--offline", "--repo", repo, "--emit-patch"}); err != nil {
+		t.Fatalf("runSuggest --emit-patch failed: %v", err)
+	}
+	diff := out.String()
+	if !strings.HasPrefix(diff, "--- a/AGENTS.md\n+++ b/AGENTS.md\n") || !strings.Contains(diff, "+- You SHOULD run the linter before pushing.\n") {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+	if strings.Contains(diff, "+- You MUST keep instructions short.") {
+		t.Fatalf("covered suggestion should not be patched:\n%s", diff)
+	}
+
+	out.Reset()
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--offline", "--repo", repo, "--apply"}); err != nil {
+		t.Fatalf("runSuggest --apply failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Applied 1 suggestion(s) to AGENTS.md.") {
+		t.Fatalf("unexpected apply output: %q", out.String())
+	}
+	data, err := os.ReadFile(agentsPath)
+	if err != nil {
+		t.Fatalf("read AGENTS.md: %v", err)
+	}
+	if !strings.Contains(string(data), "## markdowntown suggestions\n\n<!-- markdowntown:suggestion id=suggest:sha256:") {
+		t.Fatalf("managed section missing:\n%s", data)
+	}
+
+	// The tree is now dirty and the suggestion is recorded, so a rerun has
+	// nothing to add.
+	out.Reset()
+	var errOut bytes.Buffer
+	if err := runSuggestWithIO(&out, &errOut, []string{"--offline", "--repo", repo, "--apply"}); err != nil {
+		t.Fatalf("rerun --apply failed: %v", err)
+	}
+	if out.Len() != 0 || !strings.Contains(errOut.String(), "No new suggestions for AGENTS.md.") {
+		t.Fatalf("unexpected rerun output: %q / %q", out.String(), errOut.String())
+	}
+
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"--emit-patch", "--apply"}); err == nil {
+		t.Fatalf("expected --emit-patch/--apply conflict")
+	}
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"--force"}); err == nil {
+		t.Fatalf("expected --force without --apply error")
+	}
+}
+
+func TestSuggestTemplate(t *testing.T) {
+	tmp := t.TempDir()
+	sourcesPath := filepath.Join(tmp, "doc-sources.json")
+	sourceURL := "https://example.com/docs"
+	if err := os.WriteFile(sourcesPath, []byte(testSourcesJSON(sourceURL)), 0o600); err != nil {
+		t.Fatalf("write sources: %v", err)
+	}
+	t.Setenv("MARKDOWNTOWN_SOURCES", sourcesPath)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("CODEX_HOME", filepath.Join(tmp, "codex-home"))
+
+	cache, err := suggest.NewFileCache()
+	if err != nil {
+		t.Fatalf("init cache: %v", err)
+	}
+	if err := cache.Put(sourceURL, []byte("You MUST keep instructions short.\n")); err != nil {
+		t.Fatalf("cache put: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--offline", "--no-gaps", "--client", "copilot", "--format", "md"}); err != nil {
+		t.Fatalf("runSuggest copilot failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "---\napplyTo: \"**\"\n---\n") {
+		t.Fatalf("expected applyTo frontmatter, got:\n%s", out.String())
+	}
+
+	templatePath := filepath.Join(tmp, "team.tmpl")
+	tmpl := "# Team ({{ .Client }})\n{{ range .Suggestions }}* {{ oneLine .Text }} [{{ join (sourceURLs .) \" \" }}] {{ join (spans .) \" \" }}\n{{ end }}"
+	if err := os.WriteFile(templatePath, []byte(tmpl), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	out.Reset()
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--offline", "--no-gaps", "--template", templatePath}); err != nil {
+		t.Fatalf("runSuggest --template failed: %v", err)
+	}
+	output := out.String()
+	if !strings.HasPrefix(output, "# Team (codex)\n* ") || !strings.Contains(output, "["+sourceURL+"]") {
+		t.Fatalf("unexpected template output:\n%s", output)
+	}
+
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"--template", templatePath, "--json"}); err == nil {
+		t.Fatalf("expected --template/--json conflict error")
+	}
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"--template", templatePath, "--emit-patch"}); err == nil {
+		t.Fatalf("expected --template/--emit-patch conflict error")
+	}
+	if err := os.WriteFile(templatePath, []byte("{{ .Missing"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"--offline", "--template", templatePath}); err == nil {
+		t.Fatalf("expected template parse error")
+	}
+}
+
+func TestSuggestLocalSources(t *testing.T) {
+	tmp := t.TempDir()
+	handbook := filepath.Join(tmp, "handbook")
+	if err := os.MkdirAll(handbook, 0o700); err != nil {
+		t.Fatalf("mkdir handbook: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(handbook, "testing.md"), []byte("# Testing\nYou MUST run make lint before pushing."), 0o600); err != nil {
+		t.Fatalf("write handbook: %v", err)
+	}
+	sourceURL := "https://example.com/docs"
+	registry := `{
+  "version": "1.0",
+  "allowlistHosts": ["example.com"],
+  "sources": [
+    {"id": "codex-doc", "tier": "tier-0", "client": "codex", "url": "` + sourceURL + `", "refreshHours": 24},
+    {"id": "team-handbook", "tier": "tier-1", "client": "codex", "path": "handbook"}
+  ]
+}`
+	sourcesPath := filepath.Join(tmp, "doc-sources.json")
+	if err := os.WriteFile(sourcesPath, []byte(registry), 0o600); err != nil {
+		t.Fatalf("write sources: %v", err)
+	}
+	t.Setenv("MARKDOWNTOWN_SOURCES", sourcesPath)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+
+	cache, err := suggest.NewFileCache()
+	if err != nil {
+		t.Fatalf("init cache: %v", err)
+	}
+	if err := cache.Put(sourceURL, []byte("You MUST keep instructions short.")); err != nil {
+		t.Fatalf("cache put: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--offline", "--no-gaps", "--format", "json"}); err != nil {
+		t.Fatalf("runSuggest failed: %v", err)
+	}
+	var report suggest.Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	for _, warning := range report.Warnings {
+		if strings.Contains(warning, "handbook") {
+			t.Fatalf("unexpected warning for local source: %s", warning)
+		}
+	}
+
+	byText := map[string]suggest.Suggestion{}
+	for _, suggestion := range report.Suggestions {
+		byText[suggestion.Text] = suggestion
+	}
+	local, ok := byText["You MUST run make lint before pushing."]
+	if !ok || local.Provenance != suggest.ProvenanceLocal {
+		t.Fatalf("expected local handbook suggestion, got %+v", report.Suggestions)
+	}
+	if len(local.Sources) != 1 || !strings.HasPrefix(local.Sources[0], "file:///") || !strings.HasSuffix(local.Sources[0], "/handbook/testing.md") {
+		t.Fatalf("unexpected local sources: %v", local.Sources)
+	}
+	if web := byText["You MUST keep instructions short."]; web.Provenance != suggest.ProvenanceWeb {
+		t.Fatalf("expected web provenance, got %+v", web)
+	}
+
+	out.Reset()
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--offline", "--no-gaps", "--format", "md"}); err != nil {
+		t.Fatalf("runSuggest md failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "  - Provenance: local") {
+		t.Fatalf("expected local provenance in markdown, got:\n%s", out.String())
+	}
+}
+
+func TestSuggestAllClients(t *testing.T) {
+	tmp := t.TempDir()
+	registry := `{
+  "version": "1.0",
+  "allowlistHosts": ["example.com"],
+  "sources": [
+    {"id": "codex-doc", "tier": "tier-0", "client": "codex", "url": "https://example.com/codex", "refreshHours": 24},
+    {"id": "claude-doc", "tier": "tier-0", "client": "claude", "url": "https://example.com/claude", "refreshHours": 24}
+  ]
+}`
+	sourcesPath := filepath.Join(tmp, "doc-sources.json")
+	if err := os.WriteFile(sourcesPath, []byte(registry), 0o600); err != nil {
+		t.Fatalf("write sources: %v", err)
+	}
+	t.Setenv("MARKDOWNTOWN_SOURCES", sourcesPath)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+
+	cache, err := suggest.NewFileCache()
+	if err != nil {
+		t.Fatalf("init cache: %v", err)
+	}
+	if err := cache.Put("https://example.com/codex", []byte("You MUST commit lockfiles.")); err != nil {
+		t.Fatalf("cache put: %v", err)
+	}
+	if err := cache.Put("https://example.com/claude", []byte("You MUST NOT commit lockfiles.")); err != nil {
+		t.Fatalf("cache put: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--client", "all", "--offline", "--no-gaps"}); err != nil {
+		t.Fatalf("runSuggest failed: %v", err)
+	}
+	var report suggest.MultiClientReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	if len(report.Reports) != len(instructions.AllClients()) {
+		t.Fatalf("expected a report per client, got %d", len(report.Reports))
+	}
+	for _, client := range []instructions.Client{instructions.ClientCodex, instructions.ClientClaude} {
+		section := report.Reports[client]
+		if section.Client != client || len(section.Suggestions) != 1 || len(section.Warnings) != 0 {
+			t.Fatalf("unexpected %s report: %+v", client, section)
+		}
+	}
+	if warnings := report.Reports[instructions.ClientGemini].Warnings; len(warnings) != 1 || warnings[0] != "no sources available for client" {
+		t.Fatalf("unexpected gemini warnings: %v", warnings)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "offline mode") {
+		t.Fatalf("unexpected run warnings: %v", report.Warnings)
+	}
+	if len(report.CrossClientConflicts) != 1 || !reflect.DeepEqual(report.CrossClientConflicts[0].Clients, []string{"claude", "codex"}) {
+		t.Fatalf("unexpected cross-client conflicts: %+v", report.CrossClientConflicts)
+	}
+
+	out.Reset()
+	if err := runSuggestWithIO(&out, io.Discard, []string{"--client", "codex,codex", "--offline", "--no-gaps"}); err != nil {
+		t.Fatalf("runSuggest failed: %v", err)
+	}
+	var single suggest.Report
+	if err := json.Unmarshal(out.Bytes(), &single); err != nil || single.Client != instructions.ClientCodex {
+		t.Fatalf("expected a single codex report, got %s (%v)", out.String(), err)
+	}
+
+	if err := runSuggestWithIO(io.Discard, io.Discard, []string{"--client", "codex,claude", "--emit-patch"}); err == nil {
+		t.Fatalf("expected --emit-patch to require a single client")
+	}
+}
@@ -10,6 +10,7 @@ require (
 	github.com/tliron/commonlog v0.2.21
 	github.com/tliron/glsp v0.2.2
 	go.uber.org/goleak v1.3.0
+	golang.org/x/net v0.43.0
 	golang.org/x/sync v0.19.0
 	golang.org/x/sys v0.36.0
 	golang.org/x/text v0.29.0
@@ -49,6 +50,5 @@ require (
 	github.com/tliron/go-kutil v0.4.0 // indirect
 	github.com/xo/terminfo v0.0.0-20220910002029-abceb7e1c41e // indirect
 	golang.org/x/crypto v0.42.0 // indirect
-	golang.org/x/net v0.43.0 // indirect
 	golang.org/x/term v0.35.0 // indirect
 )
@@ -0,0 +1,590 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"markdowntown-cli/internal/scan"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRulesDir is the repo-relative directory scanned for custom rule packs.
+const DefaultRulesDir = ".markdowntown/rules"
+
+const customRuleCategory = "custom"
+
+var (
+	customRuleIDPattern   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+	reservedRuleIDPattern = regexp.MustCompile(`^MD[0-9]+$`)
+)
+
+// RulePack is a YAML file containing declarative rules.
+type RulePack struct {
+	Rules   []CustomRuleSpec `yaml:"rules"`
+	Plugins []PluginSpec     `yaml:"plugins"`
+}
+
+// RulePackOptions controls how rule packs are loaded.
+type RulePackOptions struct {
+	// Required reports an error when the rules directory is missing.
+	Required bool
+	// AllowPlugins enables out-of-process plugin rules; otherwise they are
+	// skipped with a warning.
+	AllowPlugins bool
+}
+
+// CustomRuleSpec declares a rule that matches configs and asserts on their content.
+type CustomRuleSpec struct {
+	ID         string          `yaml:"id"`
+	Title      string          `yaml:"title"`
+	Severity   string          `yaml:"severity"`
+	Message    string          `yaml:"message"`
+	Suggestion string          `yaml:"suggestion"`
+	Category   string          `yaml:"category"`
+	DocURL     string          `yaml:"docUrl"`
+	Tags       []string        `yaml:"tags"`
+	Match      CustomRuleMatch `yaml:"match"`
+	Assert     CustomAssert    `yaml:"assert"`
+}
+
+// CustomRuleMatch selects the configs a custom rule applies to.
+// Empty fields match everything; values within a field are ORed.
+type CustomRuleMatch struct {
+	ToolIDs []string `yaml:"toolIds"`
+	Kinds   []string `yaml:"kinds"`
+	Scopes  []string `yaml:"scopes"`
+	Paths   []string `yaml:"paths"`
+}
+
+// CustomAssert lists the checks applied to each matched config.
+type CustomAssert struct {
+	Frontmatter       FrontmatterAssert `yaml:"frontmatter"`
+	RequiredPatterns  []string          `yaml:"requiredPatterns"`
+	ForbiddenPatterns []string          `yaml:"forbiddenPatterns"`
+	Headings          HeadingAssert     `yaml:"headings"`
+	MaxBytes          int64             `yaml:"maxBytes"`
+}
+
+// FrontmatterAssert checks frontmatter keys.
+type FrontmatterAssert struct {
+	Required  []string `yaml:"required"`
+	Forbidden []string `yaml:"forbidden"`
+}
+
+// HeadingAssert checks Markdown heading structure.
+type HeadingAssert struct {
+	// Required lists headings such as "## Testing"; the level and text must match.
+	Required []string `yaml:"required"`
+	// MaxDepth rejects headings deeper than this level (0 = unlimited).
+	MaxDepth int `yaml:"maxDepth"`
+}
+
+type compiledRule struct {
+	spec      CustomRuleSpec
+	severity  Severity
+	data      RuleData
+	required  []*regexp.Regexp
+	forbidden []*regexp.Regexp
+	headings  []heading
+}
+
+type heading struct {
+	Level int
+	Text  string
+	Line  int
+}
+
+// LoadRulePacks reads every *.yaml and *.yml file in dir and compiles the rules.
+// A missing dir yields no rules unless opts.Required is set. Warnings report
+// plugins skipped because opts.AllowPlugins is unset.
+func LoadRulePacks(fs afero.Fs, dir string, opts RulePackOptions) ([]Rule, []string, error) {
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !opts.Required {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("read rules dir: %w", err)
+	}
+
+	var specs []CustomRuleSpec
+	var plugins []*pluginRule
+	var warnings []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read rule pack %s: %w", path, err)
+		}
+		pack, err := ParseRulePack(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rule pack %s: %w", path, err)
+		}
+		specs = append(specs, pack.Rules...)
+		for _, spec := range pack.Plugins {
+			plugin, err := compilePlugin(spec, dir)
+			if err != nil {
+				return nil, nil, fmt.Errorf("rule pack %s: %w", path, err)
+			}
+			if !opts.AllowPlugins {
+				warnings = append(warnings, fmt.Sprintf("plugin %s skipped: plugins are disabled", plugin.id))
+				continue
+			}
+			plugins = append(plugins, plugin)
+		}
+	}
+
+	rules, err := CompileRules(specs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(plugins) == 0 {
+		return rules, warnings, nil
+	}
+
+	seen := make(map[string]struct{}, len(rules)+len(plugins))
+	for _, rule := range rules {
+		seen[strings.ToUpper(rule.ID)] = struct{}{}
+	}
+	builtin := builtinRuleIDs()
+	for _, plugin := range plugins {
+		key := strings.ToUpper(plugin.id)
+		if _, ok := builtin[key]; ok || reservedRuleIDPattern.MatchString(key) {
+			return nil, nil, fmt.Errorf("plugin %s: id is reserved for built-in rules", plugin.id)
+		}
+		if _, ok := seen[key]; ok {
+			return nil, nil, fmt.Errorf("plugin %s: duplicate id", plugin.id)
+		}
+		seen[key] = struct{}{}
+		rules = append(rules, plugin.rule())
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, warnings, nil
+}
+
+// ParseRulePack decodes a YAML rule pack, rejecting unknown fields.
+func ParseRulePack(data []byte) (RulePack, error) {
+	var pack RulePack
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&pack); err != nil && !errors.Is(err, io.EOF) {
+		return RulePack{}, err
+	}
+	return pack, nil
+}
+
+// CompileRules validates custom rule specs and converts them into rules.
+func CompileRules(specs []CustomRuleSpec) ([]Rule, error) {
+	builtin := builtinRuleIDs()
+	seen := make(map[string]struct{}, len(specs))
+	rules := make([]Rule, 0, len(specs))
+	for _, spec := range specs {
+		compiled, err := compileRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToUpper(compiled.spec.ID)
+		if _, ok := builtin[key]; ok || reservedRuleIDPattern.MatchString(key) {
+			return nil, fmt.Errorf("rule %s: id is reserved for built-in rules", compiled.spec.ID)
+		}
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("rule %s: duplicate id", compiled.spec.ID)
+		}
+		seen[key] = struct{}{}
+		rules = append(rules, Rule{ID: compiled.spec.ID, Severity: compiled.severity, Run: compiled.run, Source: RuleSourceCustom, Title: compiled.spec.Title, Data: compiled.data})
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
+
+func builtinRuleIDs() map[string]struct{} {
+	builtin := make(map[string]struct{})
+	for _, rule := range DefaultRules() {
+		builtin[strings.ToUpper(rule.ID)] = struct{}{}
+	}
+	return builtin
+}
+
+func compileRule(spec CustomRuleSpec) (*compiledRule, error) {
+	spec.ID = strings.TrimSpace(spec.ID)
+	if spec.ID == "" {
+		return nil, fmt.Errorf("rule id is required")
+	}
+	if !customRuleIDPattern.MatchString(spec.ID) {
+		return nil, fmt.Errorf("rule %s: id must start with a letter and contain only letters, digits, '.', '_' or '-'", spec.ID)
+	}
+
+	severity := SeverityWarning
+	if strings.TrimSpace(spec.Severity) != "" {
+		parsed, err := ParseSeverity(spec.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", spec.ID, err)
+		}
+		severity = parsed
+	}
+
+	for _, pattern := range spec.Match.Paths {
+		if !doublestar.ValidatePattern(filepath.ToSlash(pattern)) {
+			return nil, fmt.Errorf("rule %s: invalid path glob %q", spec.ID, pattern)
+		}
+	}
+	for _, scope := range spec.Match.Scopes {
+		switch scope {
+		case scan.ScopeRepo, scan.ScopeUser, scan.ScopeGlobal:
+		default:
+			return nil, fmt.Errorf("rule %s: invalid scope %q", spec.ID, scope)
+		}
+	}
+
+	compiled := &compiledRule{spec: spec, severity: severity}
+	var err error
+	if compiled.required, err = compilePatterns(spec.ID, spec.Assert.RequiredPatterns); err != nil {
+		return nil, err
+	}
+	if compiled.forbidden, err = compilePatterns(spec.ID, spec.Assert.ForbiddenPatterns); err != nil {
+		return nil, err
+	}
+	for _, value := range spec.Assert.Headings.Required {
+		parsed, ok := parseHeadingLine(value)
+		if !ok {
+			return nil, fmt.Errorf("rule %s: required heading %q must look like \"## Title\"", spec.ID, value)
+		}
+		compiled.headings = append(compiled.headings, parsed)
+	}
+	if spec.Assert.Headings.MaxDepth < 0 || spec.Assert.Headings.MaxDepth > 6 {
+		return nil, fmt.Errorf("rule %s: headings.maxDepth must be between 0 and 6", spec.ID)
+	}
+	if spec.Assert.MaxBytes < 0 {
+		return nil, fmt.Errorf("rule %s: maxBytes must be >= 0", spec.ID)
+	}
+	if !compiled.hasAssertions() {
+		return nil, fmt.Errorf("rule %s: at least one assertion is required", spec.ID)
+	}
+
+	category := spec.Category
+	if category == "" {
+		category = customRuleCategory
+	}
+	compiled.data = RuleData{Category: category, DocURL: spec.DocURL, Tags: spec.Tags}
+	return compiled, nil
+}
+
+func compilePatterns(ruleID string, patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid pattern %q: %w", ruleID, pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func (c *compiledRule) hasAssertions() bool {
+	a := c.spec.Assert
+	return len(a.Frontmatter.Required) > 0 ||
+		len(a.Frontmatter.Forbidden) > 0 ||
+		len(c.required) > 0 ||
+		len(c.forbidden) > 0 ||
+		len(c.headings) > 0 ||
+		a.Headings.MaxDepth > 0 ||
+		a.MaxBytes > 0
+}
+
+func (c *compiledRule) run(ctx Context) []Issue {
+	var issues []Issue
+	for _, entry := range ctx.Scan.Configs {
+		if !c.matches(entry, ctx.Scan.RepoRoot) {
+			continue
+		}
+		for _, failure := range c.check(entry) {
+			issues = append(issues, c.issue(ctx, entry, failure))
+		}
+	}
+	return issues
+}
+
+func (c *compiledRule) matches(entry scan.ConfigEntry, repoRoot string) bool {
+	match := c.spec.Match
+	if len(match.Scopes) > 0 && !containsFold(match.Scopes, entry.Scope) {
+		return false
+	}
+	if len(match.ToolIDs) > 0 || len(match.Kinds) > 0 {
+		found := false
+		for _, tool := range entry.Tools {
+			if len(match.ToolIDs) > 0 && !containsFold(match.ToolIDs, tool.ToolID) {
+				continue
+			}
+			if len(match.Kinds) > 0 && !containsFold(match.Kinds, tool.Kind) {
+				continue
+			}
+			found = true
+			break
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(match.Paths) > 0 {
+		candidates := pathCandidates(entry.Path, repoRoot)
+		candidates = append(candidates, filepath.Base(entry.Path))
+		for _, pattern := range match.Paths {
+			for _, candidate := range candidates {
+				if ok, _ := doublestar.Match(filepath.ToSlash(pattern), candidate); ok {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+type assertionFailure struct {
+	kind    string
+	value   string
+	message string
+	rng     *scan.Range
+}
+
+func (c *compiledRule) check(entry scan.ConfigEntry) []assertionFailure {
+	var failures []assertionFailure
+	assert := c.spec.Assert
+
+	if assert.MaxBytes > 0 && entry.SizeBytes != nil && *entry.SizeBytes > assert.MaxBytes {
+		failures = append(failures, assertionFailure{
+			kind:    "maxBytes",
+			value:   fmt.Sprintf("%d", assert.MaxBytes),
+			message: fmt.Sprintf("Config is %d bytes; the limit is %d.", *entry.SizeBytes, assert.MaxBytes),
+		})
+	}
+
+	if entry.FrontmatterError == nil {
+		for _, key := range assert.Frontmatter.Required {
+			if _, ok := entry.Frontmatter[key]; !ok {
+				failures = append(failures, assertionFailure{
+					kind:    "frontmatterRequired",
+					value:   key,
+					message: fmt.Sprintf("Frontmatter is missing required key %q.", key),
+				})
+			}
+		}
+		for _, key := range assert.Frontmatter.Forbidden {
+			if _, ok := entry.Frontmatter[key]; ok {
+				failures = append(failures, assertionFailure{
+					kind:    "frontmatterForbidden",
+					value:   key,
+					message: fmt.Sprintf("Frontmatter must not set %q.", key),
+					rng:     frontmatterLocation(entry, key),
+				})
+			}
+		}
+	}
+
+	// Content assertions need the file body; they are skipped with --no-content.
+	if entry.Content == nil {
+		return failures
+	}
+	content := *entry.Content
+
+	for _, re := range c.required {
+		if !re.MatchString(content) {
+			failures = append(failures, assertionFailure{
+				kind:    "requiredPattern",
+				value:   re.String(),
+				message: fmt.Sprintf("Config does not match required pattern %q.", re.String()),
+			})
+		}
+	}
+	for _, re := range c.forbidden {
+		loc := re.FindStringIndex(content)
+		if loc == nil {
+			continue
+		}
+		failures = append(failures, assertionFailure{
+			kind:    "forbiddenPattern",
+			value:   re.String(),
+			message: fmt.Sprintf("Config matches forbidden pattern %q.", re.String()),
+			rng:     rangeForOffsets(content, loc[0], loc[1]),
+		})
+	}
+
+	headings := markdownHeadings(content)
+	for _, want := range c.headings {
+		if !hasHeading(headings, want) {
+			failures = append(failures, assertionFailure{
+				kind:    "requiredHeading",
+				value:   formatHeading(want),
+				message: fmt.Sprintf("Missing required heading %q.", formatHeading(want)),
+			})
+		}
+	}
+	if maxDepth := assert.Headings.MaxDepth; maxDepth > 0 {
+		for _, h := range headings {
+			if h.Level <= maxDepth {
+				continue
+			}
+			failures = append(failures, assertionFailure{
+				kind:    "headingDepth",
+				value:   fmt.Sprintf("%d", maxDepth),
+				message: fmt.Sprintf("Heading %q is deeper than level %d.", formatHeading(h), maxDepth),
+				rng:     &scan.Range{StartLine: h.Line, StartCol: 1, EndLine: h.Line, EndCol: 1},
+			})
+			break
+		}
+	}
+	return failures
+}
+
+func (c *compiledRule) issue(ctx Context, entry scan.ConfigEntry, failure assertionFailure) Issue {
+	title := c.spec.Title
+	if title == "" {
+		title = "Custom rule " + c.spec.ID
+	}
+	message := c.spec.Message
+	if message == "" {
+		message = failure.message
+	}
+	return Issue{
+		RuleID:     c.spec.ID,
+		Severity:   c.severity,
+		Title:      title,
+		Message:    message,
+		Suggestion: c.spec.Suggestion,
+		Range:      failure.rng,
+		Paths:      []Path{redactPath(ctx, entry.Path, entry.Scope)},
+		Tools:      toolsForEntry(entry),
+		Data:       c.data,
+		Evidence: map[string]any{
+			"assertion": failure.kind,
+			"expected":  failure.value,
+		},
+	}
+}
+
+// markdownHeadings returns ATX headings outside frontmatter and fenced code blocks.
+func markdownHeadings(content string) []heading {
+	lines := strings.Split(content, "\n")
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if trimmed := strings.TrimSpace(lines[i]); trimmed == "---" || trimmed == "..." {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	var headings []heading
+	fence := ""
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) > 3 {
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			switch {
+			case fence == "":
+				fence = marker
+			case strings.HasPrefix(trimmed, fence):
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		if parsed, ok := parseHeadingLine(trimmed); ok {
+			parsed.Line = i + 1
+			headings = append(headings, parsed)
+		}
+	}
+	return headings
+}
+
+func fenceMarker(line string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+func parseHeadingLine(line string) (heading, bool) {
+	line = strings.TrimSpace(line)
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return heading{}, false
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return heading{}, false
+	}
+	text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), "#"))
+	if text == "" {
+		return heading{}, false
+	}
+	return heading{Level: level, Text: text}, true
+}
+
+func hasHeading(headings []heading, want heading) bool {
+	for _, h := range headings {
+		if h.Level == want.Level && strings.EqualFold(h.Text, want.Text) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatHeading(h heading) string {
+	return strings.Repeat("#", h.Level) + " " + h.Text
+}
+
+func rangeForOffsets(content string, start, end int) *scan.Range {
+	startLine, startCol := lineColumn(content, start)
+	endLine, endCol := lineColumn(content, end)
+	return &scan.Range{StartLine: startLine, StartCol: startCol, EndLine: endLine, EndCol: endCol}
+}
+
+func lineColumn(content string, offset int) (int, int) {
+	prefix := content[:offset]
+	line := strings.Count(prefix, "\n") + 1
+	lineStart := strings.LastIndex(prefix, "\n") + 1
+	return line, utf8.RuneCountInString(prefix[lineStart:]) + 1
+}
+
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(strings.TrimSpace(candidate), value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/scan"
+
+	"github.com/spf13/afero"
+)
+
+const testRulePack = `rules:
+  - id: ORG001
+    title: AGENTS.md needs testing guidance
+    severity: error
+    match:
+      toolIds: [codex]
+      scopes: [repo]
+      paths: ["**/AGENTS.md"]
+    assert:
+      headings:
+        required: ["## Testing"]
+  - id: ORG002
+    title: No sudo
+    match:
+      paths: ["CLAUDE.md"]
+    assert:
+      forbiddenPatterns: ['\bsudo\b']
+      frontmatter:
+        required: [owner]
+      maxBytes: 10
+`
+
+func TestLoadRulePacks(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/repo/.markdowntown/rules/org.yaml", []byte(testRulePack), 0o644); err != nil {
+		t.Fatalf("write pack: %v", err)
+	}
+	if err := afero.WriteFile(fs, "/repo/.markdowntown/rules/README.md", []byte("ignored"), 0o644); err != nil {
+		t.Fatalf("write readme: %v", err)
+	}
+
+	rules, _, err := LoadRulePacks(fs, "/repo/.markdowntown/rules", RulePackOptions{Required: true})
+	if err != nil {
+		t.Fatalf("LoadRulePacks: %v", err)
+	}
+	if len(rules) != 2 || rules[0].ID != "ORG001" || rules[0].Severity != SeverityError || rules[1].Severity != SeverityWarning {
+		t.Fatalf("unexpected rules: %#v", rules)
+	}
+
+	if rules, _, err := LoadRulePacks(fs, "/missing", RulePackOptions{}); err != nil || rules != nil {
+		t.Fatalf("expected missing optional dir to be ignored, got %v %v", rules, err)
+	}
+	if _, _, err := LoadRulePacks(fs, "/missing", RulePackOptions{Required: true}); err == nil {
+		t.Fatalf("expected missing required dir to fail")
+	}
+}
+
+func TestCompileRulesValidation(t *testing.T) {
+	cases := map[string]string{
+		"missing id":       "rules:\n  - assert:\n      maxBytes: 1\n",
+		"reserved id":      "rules:\n  - id: MD099\n    assert:\n      maxBytes: 1\n",
+		"no assertions":    "rules:\n  - id: ORG1\n",
+		"bad regex":        "rules:\n  - id: ORG1\n    assert:\n      requiredPatterns: ['(']\n",
+		"bad heading":      "rules:\n  - id: ORG1\n    assert:\n      headings:\n        required: [Testing]\n",
+		"bad severity":     "rules:\n  - id: ORG1\n    severity: fatal\n    assert:\n      maxBytes: 1\n",
+		"bad scope":        "rules:\n  - id: ORG1\n    match:\n      scopes: [team]\n    assert:\n      maxBytes: 1\n",
+		"duplicate id":     "rules:\n  - id: ORG1\n    assert:\n      maxBytes: 1\n  - id: org1\n    assert:\n      maxBytes: 2\n",
+		"unknown field":    "rules:\n  - id: ORG1\n    asert:\n      maxBytes: 1\n",
+		"bad path pattern": "rules:\n  - id: ORG1\n    match:\n      paths: ['[']\n    assert:\n      maxBytes: 1\n",
+	}
+	for name, pack := range cases {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := ParseRulePack([]byte(pack))
+			if err == nil {
+				_, err = CompileRules(parsed.Rules)
+			}
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
+
+func TestCustomRulesRun(t *testing.T) {
+	pack, err := ParseRulePack([]byte(testRulePack))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	rules, err := CompileRules(pack.Rules)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	agents := "---\nname: x\n---\n# Agents\n```md\n## Testing\n```\n"
+	claude := "Line one\nthen run sudo make\n"
+	size := int64(len(claude))
+	ctx := Context{Scan: scan.Output{
+		RepoRoot: "/repo",
+		Configs: []scan.ConfigEntry{
+			{Path: "/repo/pkg/AGENTS.md", Scope: "repo", Content: &agents, Tools: []scan.ToolEntry{{ToolID: "codex", Kind: "instructions"}}},
+			{Path: "/repo/other/AGENTS.md", Scope: "repo", Content: &agents, Tools: []scan.ToolEntry{{ToolID: "copilot", Kind: "instructions"}}},
+			{Path: "/repo/CLAUDE.md", Scope: "repo", Content: &claude, SizeBytes: &size, Frontmatter: map[string]any{}},
+		},
+	}}
+
+	issues := RunRules(ctx, rules)
+	byAssertion := make(map[string]Issue)
+	for _, issue := range issues {
+		byAssertion[issue.Evidence["assertion"].(string)] = issue
+	}
+	if len(issues) != 4 {
+		t.Fatalf("expected 4 issues, got %#v", issues)
+	}
+
+	heading, ok := byAssertion["requiredHeading"]
+	if !ok || heading.RuleID != "ORG001" || heading.Paths[0].Path != "/repo/pkg/AGENTS.md" {
+		t.Fatalf("expected heading inside code fence to be ignored, got %#v", heading)
+	}
+	if data, ok := heading.Data.(RuleData); !ok || data.Category != "custom" {
+		t.Fatalf("expected custom rule data, got %#v", heading.Data)
+	}
+
+	forbidden := byAssertion["forbiddenPattern"]
+	if forbidden.Range == nil || forbidden.Range.StartLine != 2 || forbidden.Range.StartCol != 10 || forbidden.Range.EndCol != 14 {
+		t.Fatalf("unexpected forbidden range: %#v", forbidden.Range)
+	}
+	if !strings.Contains(byAssertion["frontmatterRequired"].Message, `"owner"`) {
+		t.Fatalf("unexpected frontmatter message: %q", byAssertion["frontmatterRequired"].Message)
+	}
+	if _, ok := byAssertion["maxBytes"]; !ok {
+		t.Fatalf("expected maxBytes issue")
+	}
+}
+
+func TestCustomRulesSkipContentWhenMissing(t *testing.T) {
+	rules, err := CompileRules([]CustomRuleSpec{{ID: "ORG1", Assert: CustomAssert{RequiredPatterns: []string{"x"}}}})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	issues := RunRules(Context{Scan: scan.Output{Configs: []scan.ConfigEntry{{Path: "/repo/AGENTS.md", Scope: "repo"}}}}, rules)
+	if len(issues) != 0 {
+		t.Fatalf("expected content assertions to be skipped without content, got %#v", issues)
+	}
+}
+
+func TestMarkdownHeadings(t *testing.T) {
+	content := "---\ntitle: x\n---\n# Title\n\n    ## indented code\n~~~\n## fenced\n~~~\n### Deep ###\n#NoSpace\n"
+	headings := markdownHeadings(content)
+	if len(headings) != 2 {
+		t.Fatalf("unexpected headings: %#v", headings)
+	}
+	if headings[0] != (heading{Level: 1, Text: "Title", Line: 4}) || headings[1] != (heading{Level: 3, Text: "Deep", Line: 10}) {
+		t.Fatalf("unexpected headings: %#v", headings)
+	}
+}
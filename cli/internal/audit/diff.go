@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffOutput is the top-level schema for comparing two audits.
+type DiffOutput struct {
+	SchemaVersion string      `json:"schemaVersion"`
+	Audit         Meta        `json:"audit"`
+	Base          DiffSide    `json:"base"`
+	Head          DiffSide    `json:"head"`
+	Summary       DiffSummary `json:"summary"`
+	Added         []Issue     `json:"added"`
+	Resolved      []Issue     `json:"resolved"`
+	Unchanged     []Issue     `json:"unchanged"`
+}
+
+// DiffSide describes one side of an audit diff.
+type DiffSide struct {
+	// Source is "scan", "ref", or "worktree".
+	Source  string  `json:"source"`
+	Input   string  `json:"input,omitempty"`
+	Commit  string  `json:"commit,omitempty"`
+	Summary Summary `json:"summary"`
+}
+
+// DiffSummary provides per-severity counts for added and resolved issues.
+type DiffSummary struct {
+	Added     SeverityCounts `json:"added"`
+	Resolved  SeverityCounts `json:"resolved"`
+	Unchanged int            `json:"unchanged"`
+}
+
+// DiffIssues matches issues by fingerprint and classifies them as added, resolved, or unchanged.
+// Issues sharing a fingerprint are matched one-to-one so duplicates are counted.
+func DiffIssues(base []Issue, head []Issue) (added []Issue, resolved []Issue, unchanged []Issue) {
+	pending := make(map[string][]Issue, len(base))
+	for _, issue := range base {
+		key := diffKey(issue)
+		pending[key] = append(pending[key], issue)
+	}
+
+	added = make([]Issue, 0)
+	unchanged = make([]Issue, 0)
+	for _, issue := range head {
+		key := diffKey(issue)
+		if matches := pending[key]; len(matches) > 0 {
+			pending[key] = matches[1:]
+			unchanged = append(unchanged, issue)
+			continue
+		}
+		added = append(added, issue)
+	}
+
+	resolved = make([]Issue, 0)
+	for _, issue := range base {
+		key := diffKey(issue)
+		if matches := pending[key]; len(matches) > 0 {
+			pending[key] = matches[1:]
+			resolved = append(resolved, issue)
+		}
+	}
+
+	sortIssues(added)
+	sortIssues(resolved)
+	sortIssues(unchanged)
+	return added, resolved, unchanged
+}
+
+// BuildDiffSummary returns counts for the classified issues.
+func BuildDiffSummary(added []Issue, resolved []Issue, unchanged []Issue) DiffSummary {
+	return DiffSummary{
+		Added:     BuildSummary(added).IssueCounts,
+		Resolved:  BuildSummary(resolved).IssueCounts,
+		Unchanged: len(unchanged),
+	}
+}
+
+func diffKey(issue Issue) string {
+	if issue.Fingerprint != "" {
+		return issue.Fingerprint
+	}
+	return FingerprintIssue(issue)
+}
+
+// RenderDiffMarkdown renders an audit diff as Markdown suitable for a PR comment.
+func RenderDiffMarkdown(output DiffOutput) string {
+	var builder strings.Builder
+	builder.WriteString("# markdowntown audit diff\n\n")
+	_, _ = fmt.Fprintf(&builder, "Base: %s\n", diffSideLabel(output.Base))
+	_, _ = fmt.Fprintf(&builder, "Head: %s\n\n", diffSideLabel(output.Head))
+
+	added := output.Summary.Added
+	resolved := output.Summary.Resolved
+	builder.WriteString("| | Errors | Warnings | Info |\n")
+	builder.WriteString("| --- | --- | --- | --- |\n")
+	_, _ = fmt.Fprintf(&builder, "| Added | %d | %d | %d |\n", added.Error, added.Warning, added.Info)
+	_, _ = fmt.Fprintf(&builder, "| Resolved | %d | %d | %d |\n", resolved.Error, resolved.Warning, resolved.Info)
+	_, _ = fmt.Fprintf(&builder, "\nUnchanged: %d\n", output.Summary.Unchanged)
+
+	writeDiffSection(&builder, "Added", output.Added)
+	writeDiffSection(&builder, "Resolved", output.Resolved)
+
+	if len(output.Added) == 0 && len(output.Resolved) == 0 {
+		builder.WriteString("\nNo AI config issues were added or resolved.\n")
+	}
+	return builder.String()
+}
+
+func writeDiffSection(builder *strings.Builder, title string, issues []Issue) {
+	if len(issues) == 0 {
+		return
+	}
+	builder.WriteString("\n## " + title + "\n\n")
+	for _, issue := range issues {
+		line := fmt.Sprintf("- **%s** [%s] %s", issue.Severity, issue.RuleID, issueTitle(issue))
+		if label := issuePathLabel(issue); label != "" {
+			line += ": `" + label + "`"
+		}
+		builder.WriteString(line + "\n")
+		if issue.Suggestion != "" {
+			builder.WriteString("  - Suggestion: " + issue.Suggestion + "\n")
+		}
+	}
+}
+
+func diffSideLabel(side DiffSide) string {
+	label := side.Source
+	if side.Input != "" {
+		label += " `" + side.Input + "`"
+	}
+	if side.Commit != "" {
+		short := side.Commit
+		if len(short) > 12 {
+			short = short[:12]
+		}
+		label += " (" + short + ")"
+	}
+	return label
+}
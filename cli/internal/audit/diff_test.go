@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffIssues(t *testing.T) {
+	kept := Issue{RuleID: "MD001", Severity: SeverityError, Title: "Conflict", Fingerprint: "sha256:kept", Paths: []Path{{Path: "./a.md", Scope: "repo"}}}
+	fixed := Issue{RuleID: "MD004", Severity: SeverityWarning, Title: "Empty", Fingerprint: "sha256:fixed", Paths: []Path{{Path: "./b.md", Scope: "repo"}}}
+	introduced := Issue{RuleID: "MD003", Severity: SeverityError, Title: "Invalid", Fingerprint: "sha256:new", Paths: []Path{{Path: "./c.md", Scope: "repo"}}}
+
+	added, resolved, unchanged := DiffIssues([]Issue{kept, fixed, kept}, []Issue{kept, introduced})
+	if len(added) != 1 || added[0].Fingerprint != "sha256:new" {
+		t.Fatalf("unexpected added: %#v", added)
+	}
+	if len(resolved) != 2 {
+		t.Fatalf("expected duplicate and fixed issues resolved, got %#v", resolved)
+	}
+	if len(unchanged) != 1 || unchanged[0].Fingerprint != "sha256:kept" {
+		t.Fatalf("unexpected unchanged: %#v", unchanged)
+	}
+
+	summary := BuildDiffSummary(added, resolved, unchanged)
+	if summary.Added.Error != 1 || summary.Resolved.Error != 1 || summary.Resolved.Warning != 1 || summary.Unchanged != 1 {
+		t.Fatalf("unexpected summary: %#v", summary)
+	}
+}
+
+func TestDiffIssuesComputesMissingFingerprints(t *testing.T) {
+	issue := Issue{RuleID: "MD004", Severity: SeverityWarning, Paths: []Path{{Path: "./a.md", Scope: "repo"}}}
+	withFingerprint := issue
+	withFingerprint.Fingerprint = FingerprintIssue(issue)
+
+	added, resolved, unchanged := DiffIssues([]Issue{issue}, []Issue{withFingerprint})
+	if len(added) != 0 || len(resolved) != 0 || len(unchanged) != 1 {
+		t.Fatalf("expected match, got added=%d resolved=%d unchanged=%d", len(added), len(resolved), len(unchanged))
+	}
+}
+
+func TestRenderDiffMarkdown(t *testing.T) {
+	added := []Issue{{RuleID: "MD003", Severity: SeverityError, Title: "Invalid", Suggestion: "Fix YAML", Paths: []Path{{Path: "./c.md", Scope: "repo"}}}}
+	output := DiffOutput{
+		Base:    DiffSide{Source: "ref", Input: "main", Commit: "0123456789abcdef"},
+		Head:    DiffSide{Source: "worktree"},
+		Summary: BuildDiffSummary(added, nil, nil),
+		Added:   added,
+	}
+
+	markdown := RenderDiffMarkdown(output)
+	if !strings.Contains(markdown, "Base: ref `main` (0123456789ab)") {
+		t.Fatalf("expected base label, got:\n%s", markdown)
+	}
+	if !strings.Contains(markdown, "| Added | 1 | 0 | 0 |") {
+		t.Fatalf("expected added counts, got:\n%s", markdown)
+	}
+	if !strings.Contains(markdown, "- **error** [MD003] Invalid: `./c.md`") {
+		t.Fatalf("expected added issue line, got:\n%s", markdown)
+	}
+	if strings.Contains(markdown, "## Resolved") {
+		t.Fatalf("did not expect resolved section")
+	}
+}
@@ -0,0 +1,338 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"markdowntown-cli/internal/scan"
+)
+
+// PluginProtocolVersion is the JSON protocol version sent to rule plugins.
+const PluginProtocolVersion = "1"
+
+const (
+	defaultPluginTimeout     = 30 * time.Second
+	defaultPluginMaxOutput   = 4 << 20
+	defaultPluginMaxIssues   = 1000
+	defaultPluginMaxMemoryMB = 512
+	pluginStderrLimit        = 16 << 10
+	pluginCategory           = "plugin"
+)
+
+// PluginSpec declares an out-of-process rule in a rule pack.
+type PluginSpec struct {
+	ID             string   `yaml:"id"`
+	Command        []string `yaml:"command"`
+	Severity       string   `yaml:"severity"`
+	Timeout        string   `yaml:"timeout"`
+	MaxOutputBytes int64    `yaml:"maxOutputBytes"`
+	MaxIssues      int      `yaml:"maxIssues"`
+	MaxMemoryMB    int      `yaml:"maxMemoryMB"`
+	Content        bool     `yaml:"content"`
+	Category       string   `yaml:"category"`
+	DocURL         string   `yaml:"docUrl"`
+	Tags           []string `yaml:"tags"`
+}
+
+// PluginRequest is written to a plugin's stdin.
+// Paths in Scan are redacted the same way as audit output; content is only
+// included when the plugin opts in and the scan captured it.
+type PluginRequest struct {
+	ProtocolVersion string      `json:"protocolVersion"`
+	RuleID          string      `json:"ruleId"`
+	Redaction       RedactMode  `json:"redaction"`
+	Scan            scan.Output `json:"scan"`
+}
+
+// PluginResponse is read from a plugin's stdout.
+type PluginResponse struct {
+	Issues []Issue `json:"issues"`
+}
+
+type pluginRule struct {
+	id        string
+	command   []string
+	dir       string
+	severity  Severity
+	timeout   time.Duration
+	maxOutput int64
+	maxIssues int
+	maxMemory uint64
+	content   bool
+	data      RuleData
+}
+
+// compilePlugin validates a plugin spec. Relative commands resolve against baseDir.
+func compilePlugin(spec PluginSpec, baseDir string) (*pluginRule, error) {
+	id := strings.TrimSpace(spec.ID)
+	if id == "" {
+		return nil, fmt.Errorf("plugin id is required")
+	}
+	if !customRuleIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("plugin %s: id must start with a letter and contain only letters, digits, '.', '_' or '-'", id)
+	}
+	if len(spec.Command) == 0 || strings.TrimSpace(spec.Command[0]) == "" {
+		return nil, fmt.Errorf("plugin %s: command is required", id)
+	}
+
+	rule := &pluginRule{
+		id:        id,
+		command:   append([]string(nil), spec.Command...),
+		dir:       baseDir,
+		severity:  SeverityWarning,
+		timeout:   defaultPluginTimeout,
+		maxOutput: defaultPluginMaxOutput,
+		maxIssues: defaultPluginMaxIssues,
+		maxMemory: uint64(defaultPluginMaxMemoryMB) << 20,
+		content:   spec.Content,
+	}
+	if strings.ContainsRune(rule.command[0], '/') && !filepath.IsAbs(rule.command[0]) {
+		rule.command[0] = filepath.Join(baseDir, filepath.FromSlash(rule.command[0]))
+	}
+	if strings.TrimSpace(spec.Severity) != "" {
+		severity, err := ParseSeverity(spec.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", id, err)
+		}
+		rule.severity = severity
+	}
+	if spec.Timeout != "" {
+		timeout, err := time.ParseDuration(spec.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("plugin %s: invalid timeout %q", id, spec.Timeout)
+		}
+		rule.timeout = timeout
+	}
+	switch {
+	case spec.MaxOutputBytes < 0:
+		return nil, fmt.Errorf("plugin %s: maxOutputBytes must be >= 0", id)
+	case spec.MaxOutputBytes > 0:
+		rule.maxOutput = spec.MaxOutputBytes
+	}
+	switch {
+	case spec.MaxIssues < 0:
+		return nil, fmt.Errorf("plugin %s: maxIssues must be >= 0", id)
+	case spec.MaxIssues > 0:
+		rule.maxIssues = spec.MaxIssues
+	}
+	switch {
+	case spec.MaxMemoryMB < 0:
+		return nil, fmt.Errorf("plugin %s: maxMemoryMB must be >= 0", id)
+	case spec.MaxMemoryMB > 0:
+		rule.maxMemory = uint64(spec.MaxMemoryMB) << 20
+	}
+
+	category := spec.Category
+	if category == "" {
+		category = pluginCategory
+	}
+	rule.data = RuleData{Category: category, DocURL: spec.DocURL, Tags: spec.Tags}
+	return rule, nil
+}
+
+func (p *pluginRule) rule() Rule {
+	return Rule{ID: p.id, Severity: p.severity, RunContext: p.run, Source: RuleSourcePlugin, Data: p.data}
+}
+
+func (p *pluginRule) run(goCtx context.Context, ctx Context) ([]Issue, error) {
+	request := PluginRequest{
+		ProtocolVersion: PluginProtocolVersion,
+		RuleID:          p.id,
+		Redaction:       RedactNever,
+		Scan:            pluginScan(ctx, p.content),
+	}
+	if ctx.Redactor != nil {
+		request.Redaction = ctx.Redactor.mode
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(goCtx, p.timeout)
+	defer cancel()
+
+	// #nosec G204 -- plugins are configured explicitly and only run with opt-in.
+	cmd := exec.CommandContext(runCtx, p.command[0], p.command[1:]...)
+	cmd.Dir = p.dir
+	cmd.Env = append(os.Environ(), "MARKDOWNTOWN_PLUGIN_PROTOCOL="+PluginProtocolVersion)
+	cmd.Stdin = bytes.NewReader(payload)
+	stdout := &limitedBuffer{limit: p.maxOutput}
+	stderr := &limitedBuffer{limit: pluginStderrLimit, truncate: true}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start plugin: %w", err)
+	}
+	applyPluginLimits(cmd.Process.Pid, p.maxMemory, p.timeout)
+	waitErr := cmd.Wait()
+
+	switch {
+	case goCtx.Err() != nil:
+		return nil, goCtx.Err()
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		return nil, fmt.Errorf("plugin timed out after %s", p.timeout)
+	case stdout.exceeded:
+		return nil, fmt.Errorf("plugin output exceeded %d bytes", p.maxOutput)
+	case waitErr != nil:
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("plugin failed: %w: %s", waitErr, message)
+		}
+		return nil, fmt.Errorf("plugin failed: %w", waitErr)
+	}
+
+	return p.decode(stdout.Bytes())
+}
+
+func (p *pluginRule) decode(data []byte) ([]Issue, error) {
+	var response PluginResponse
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid plugin response: %w", err)
+	}
+	if len(response.Issues) > p.maxIssues {
+		return nil, fmt.Errorf("plugin returned %d issues; limit is %d", len(response.Issues), p.maxIssues)
+	}
+
+	issues := make([]Issue, 0, len(response.Issues))
+	for i, issue := range response.Issues {
+		if err := p.validateIssue(&issue); err != nil {
+			return nil, fmt.Errorf("invalid plugin issue %d: %w", i, err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// validateIssue checks a plugin issue and fills in rule-owned fields.
+func (p *pluginRule) validateIssue(issue *Issue) error {
+	if issue.RuleID != "" && !strings.EqualFold(issue.RuleID, p.id) {
+		return fmt.Errorf("ruleId %q does not match plugin id %q", issue.RuleID, p.id)
+	}
+	issue.RuleID = p.id
+	if issue.Severity == "" {
+		issue.Severity = p.severity
+	} else if _, err := ParseSeverity(string(issue.Severity)); err != nil {
+		return err
+	}
+	if strings.TrimSpace(issue.Message) == "" {
+		return fmt.Errorf("message is required")
+	}
+	if len(issue.Paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+	for _, path := range issue.Paths {
+		if strings.TrimSpace(path.Path) == "" {
+			return fmt.Errorf("path is required")
+		}
+		switch path.Scope {
+		case scan.ScopeRepo, scan.ScopeUser, scan.ScopeGlobal:
+		default:
+			return fmt.Errorf("invalid scope %q", path.Scope)
+		}
+	}
+	if r := issue.Range; r != nil {
+		if r.StartLine < 1 || r.StartCol < 1 || r.EndLine < r.StartLine || r.EndCol < 1 {
+			return fmt.Errorf("invalid range")
+		}
+	}
+	issue.Fingerprint = ""
+	issue.Data = p.data
+	return nil
+}
+
+// pluginScan prepares the scan sent to plugins: paths are redacted like audit
+// output and content is dropped unless requested.
+func pluginScan(ctx Context, includeContent bool) scan.Output {
+	output := ctx.Scan
+	redactor := ctx.Redactor
+	repoRoot := output.RepoRoot
+	if redactor == nil {
+		if !includeContent {
+			output.Configs = withoutContent(output.Configs)
+		}
+		return output
+	}
+
+	output.RepoRoot = "."
+	scans := make([]scan.Root, 0, len(output.Scans))
+	for _, root := range output.Scans {
+		root.Root = redactor.RedactPath(root.Root, root.Scope).Path
+		scans = append(scans, root)
+	}
+	output.Scans = scans
+
+	configs := make([]scan.ConfigEntry, 0, len(output.Configs))
+	for _, entry := range output.Configs {
+		entry.Path = redactor.RedactPath(entry.Path, entry.Scope).Path
+		entry.Resolved = ""
+		if !includeContent {
+			entry.Content = nil
+		}
+		configs = append(configs, entry)
+	}
+	output.Configs = configs
+
+	warnings := make([]scan.Warning, 0, len(output.Warnings))
+	for _, warning := range output.Warnings {
+		if warning.Path != "" {
+			warning.Path = redactWarningPath(redactor, repoRoot, warning.Path)
+		}
+		warnings = append(warnings, warning)
+	}
+	output.Warnings = warnings
+	return output
+}
+
+func withoutContent(entries []scan.ConfigEntry) []scan.ConfigEntry {
+	out := make([]scan.ConfigEntry, 0, len(entries))
+	for _, entry := range entries {
+		entry.Content = nil
+		out = append(out, entry)
+	}
+	return out
+}
+
+// limitedBuffer stores up to limit bytes. When truncate is false, writes past
+// the limit fail so the plugin is stopped early. The buffer is not embedded so
+// io.Copy cannot bypass Write through ReadFrom.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	truncate bool
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - int64(b.buf.Len())
+	if int64(len(p)) <= remaining {
+		return b.buf.Write(p)
+	}
+	b.exceeded = true
+	if remaining > 0 {
+		_, _ = b.buf.Write(p[:remaining])
+	}
+	if b.truncate {
+		return len(p), nil
+	}
+	return 0, errors.New("output limit exceeded")
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
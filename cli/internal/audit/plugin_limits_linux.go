@@ -0,0 +1,19 @@
+//go:build linux && !js
+
+package audit
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// applyPluginLimits caps plugin address space and CPU time. Limits are applied
+// right after start, so they are best-effort for very short-lived processes.
+func applyPluginLimits(pid int, maxMemory uint64, timeout time.Duration) {
+	if maxMemory > 0 {
+		_ = unix.Prlimit(pid, unix.RLIMIT_AS, &unix.Rlimit{Cur: maxMemory, Max: maxMemory}, nil)
+	}
+	cpuSeconds := uint64(timeout/time.Second) + 1
+	_ = unix.Prlimit(pid, unix.RLIMIT_CPU, &unix.Rlimit{Cur: cpuSeconds, Max: cpuSeconds}, nil)
+}
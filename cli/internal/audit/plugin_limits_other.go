@@ -0,0 +1,9 @@
+//go:build !linux || js
+
+package audit
+
+import "time"
+
+// applyPluginLimits is a no-op where per-process limits are unavailable; the
+// timeout and output caps still apply.
+func applyPluginLimits(_ int, _ uint64, _ time.Duration) {}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/scan"
+
+	"github.com/spf13/afero"
+)
+
+func writePluginScript(t *testing.T, dir string, name string, body string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts require a POSIX shell")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o700); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+}
+
+func writePluginPack(t *testing.T, dir string, pack string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "plugins.yaml"), []byte(pack), 0o600); err != nil {
+		t.Fatalf("write pack: %v", err)
+	}
+}
+
+func pluginTestContext(repoRoot string) Context {
+	content := "# secret content\n"
+	return Context{
+		Scan: scan.Output{
+			RepoRoot: repoRoot,
+			Configs: []scan.ConfigEntry{{
+				Path:    filepath.Join(repoRoot, "AGENTS.md"),
+				Scope:   scan.ScopeRepo,
+				Content: &content,
+			}},
+		},
+		Redactor: NewRedactor(repoRoot, "", "", RedactAlways),
+	}
+}
+
+func loadPluginRule(t *testing.T, dir string) Rule {
+	t.Helper()
+	rules, warnings, err := LoadRulePacks(afero.NewOsFs(), dir, RulePackOptions{Required: true, AllowPlugins: true})
+	if err != nil {
+		t.Fatalf("LoadRulePacks: %v", err)
+	}
+	if len(rules) != 1 || len(warnings) != 0 {
+		t.Fatalf("unexpected rules %#v warnings %v", rules, warnings)
+	}
+	return rules[0]
+}
+
+func TestPluginRuleProtocol(t *testing.T) {
+	dir := t.TempDir()
+	writePluginScript(t, dir, "plugin.sh", `cat > request.json
+echo '{"issues":[{"ruleId":"ORG-PLUGIN","title":"From plugin","message":"Plugin finding","paths":[{"path":"./AGENTS.md","scope":"repo"}],"range":{"startLine":1,"startCol":1,"endLine":1,"endCol":5}}]}'
+`)
+	writePluginPack(t, dir, `plugins:
+  - id: ORG-PLUGIN
+    command: ["./plugin.sh"]
+    severity: error
+`)
+
+	rule := loadPluginRule(t, dir)
+	repoRoot := t.TempDir()
+	issues, err := rule.EvaluateContext(context.Background(), pluginTestContext(repoRoot))
+	if err != nil {
+		t.Fatalf("EvaluateContext: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected one issue, got %#v", issues)
+	}
+	issue := issues[0]
+	if issue.RuleID != "ORG-PLUGIN" || issue.Severity != SeverityError || issue.Range == nil || issue.Data.(RuleData).Category != pluginCategory {
+		t.Fatalf("unexpected issue: %#v", issue)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "request.json"))
+	if err != nil {
+		t.Fatalf("read request: %v", err)
+	}
+	var request PluginRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	if request.ProtocolVersion != PluginProtocolVersion || request.RuleID != "ORG-PLUGIN" || request.Redaction != RedactAlways {
+		t.Fatalf("unexpected request header: %#v", request)
+	}
+	if strings.Contains(string(data), repoRoot) {
+		t.Fatalf("expected repo root to be redacted, got %s", data)
+	}
+	if strings.Contains(string(data), "secret content") {
+		t.Fatalf("expected content to be omitted, got %s", data)
+	}
+	if len(request.Scan.Configs) != 1 || request.Scan.Configs[0].Path != "./AGENTS.md" {
+		t.Fatalf("unexpected configs: %#v", request.Scan.Configs)
+	}
+}
+
+func TestPluginRuleContentOptIn(t *testing.T) {
+	dir := t.TempDir()
+	writePluginScript(t, dir, "plugin.sh", `cat > request.json
+echo '{"issues":[]}'
+`)
+	writePluginPack(t, dir, `plugins:
+  - id: ORG-PLUGIN
+    command: ["./plugin.sh"]
+    content: true
+`)
+
+	rule := loadPluginRule(t, dir)
+	if _, err := rule.EvaluateContext(context.Background(), pluginTestContext(t.TempDir())); err != nil {
+		t.Fatalf("EvaluateContext: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "request.json"))
+	if err != nil {
+		t.Fatalf("read request: %v", err)
+	}
+	if !strings.Contains(string(data), "secret content") {
+		t.Fatalf("expected content when plugin opts in, got %s", data)
+	}
+}
+
+func TestPluginRuleFailures(t *testing.T) {
+	cases := []struct {
+		name   string
+		script string
+		extra  string
+		want   string
+	}{
+		{name: "timeout", script: "exec sleep 5\n", extra: "    timeout: 200ms\n", want: "timed out"},
+		{name: "exit", script: "echo broken >&2\nexit 3\n", want: "broken"},
+		{name: "output limit", script: "head -c 4096 /dev/zero\n", extra: "    maxOutputBytes: 100\n", want: "exceeded 100 bytes"},
+		{name: "unknown field", script: `echo '{"issues":[],"extra":true}'` + "\n", want: "unknown field"},
+		{name: "missing message", script: `echo '{"issues":[{"paths":[{"path":"a","scope":"repo"}]}]}'` + "\n", want: "message is required"},
+		{name: "bad scope", script: `echo '{"issues":[{"message":"m","paths":[{"path":"a","scope":"elsewhere"}]}]}'` + "\n", want: "invalid scope"},
+		{name: "bad severity", script: `echo '{"issues":[{"severity":"fatal","message":"m","paths":[{"path":"a","scope":"repo"}]}]}'` + "\n", want: "invalid severity"},
+		{name: "other rule", script: `echo '{"issues":[{"ruleId":"MD001","message":"m","paths":[{"path":"a","scope":"repo"}]}]}'` + "\n", want: "does not match"},
+		{name: "too many issues", script: `echo '{"issues":[{"message":"m","paths":[{"path":"a","scope":"repo"}]},{"message":"m","paths":[{"path":"a","scope":"repo"}]}]}'` + "\n", extra: "    maxIssues: 1\n", want: "limit is 1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writePluginScript(t, dir, "plugin.sh", tc.script)
+			writePluginPack(t, dir, "plugins:\n  - id: ORG-PLUGIN\n    command: [\"./plugin.sh\"]\n"+tc.extra)
+
+			rule := loadPluginRule(t, dir)
+			_, err := rule.EvaluateContext(context.Background(), pluginTestContext(t.TempDir()))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadRulePacksPluginsDisabled(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	pack := "plugins:\n  - id: ORG-PLUGIN\n    command: [\"./plugin.sh\"]\n"
+	if err := afero.WriteFile(fs, "/rules/plugins.yaml", []byte(pack), 0o644); err != nil {
+		t.Fatalf("write pack: %v", err)
+	}
+
+	rules, warnings, err := LoadRulePacks(fs, "/rules", RulePackOptions{Required: true})
+	if err != nil {
+		t.Fatalf("LoadRulePacks: %v", err)
+	}
+	if len(rules) != 0 || len(warnings) != 1 || !strings.Contains(warnings[0], "ORG-PLUGIN") {
+		t.Fatalf("expected plugin to be skipped with a warning, got %#v %v", rules, warnings)
+	}
+}
+
+func TestLoadRulePacksPluginValidation(t *testing.T) {
+	cases := map[string]string{
+		"reserved":    "plugins:\n  - id: MD001\n    command: [\"x\"]\n",
+		"duplicate":   "rules:\n  - id: ORG1\n    assert:\n      maxBytes: 1\nplugins:\n  - id: org1\n    command: [\"x\"]\n",
+		"no command":  "plugins:\n  - id: ORG1\n",
+		"bad timeout": "plugins:\n  - id: ORG1\n    command: [\"x\"]\n    timeout: soon\n",
+		"unknown key": "plugins:\n  - id: ORG1\n    command: [\"x\"]\n    shell: true\n",
+	}
+	for name, pack := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if err := afero.WriteFile(fs, "/rules/pack.yaml", []byte(pack), 0o644); err != nil {
+				t.Fatalf("write pack: %v", err)
+			}
+			if _, _, err := LoadRulePacks(fs, "/rules", RulePackOptions{AllowPlugins: true}); err == nil {
+				t.Fatalf("expected validation error")
+			}
+		})
+	}
+}
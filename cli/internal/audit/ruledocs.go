@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed ruledocs/*.md
+var ruleDocsFS embed.FS
+
+// RuleDoc is the embedded long-form documentation for a built-in rule.
+type RuleDoc struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Markdown is the full document, including the "# ID: Title" heading.
+	Markdown string `json:"markdown"`
+}
+
+// LookupRuleDoc returns the embedded documentation for a rule ID (case-insensitive).
+func LookupRuleDoc(ruleID string) (RuleDoc, bool) {
+	id := strings.ToUpper(strings.TrimSpace(ruleID))
+	if id == "" || strings.ContainsAny(id, "/\\.") {
+		return RuleDoc{}, false
+	}
+	data, err := ruleDocsFS.ReadFile("ruledocs/" + id + ".md")
+	if err != nil {
+		return RuleDoc{}, false
+	}
+	return parseRuleDoc(id, string(data)), true
+}
+
+// RuleDocIDs lists rule IDs with embedded documentation in sorted order.
+func RuleDocIDs() []string {
+	entries, err := fs.ReadDir(ruleDocsFS, "ruledocs")
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if path.Ext(name) != ".md" {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".md"))
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func parseRuleDoc(id string, markdown string) RuleDoc {
+	doc := RuleDoc{ID: id, Markdown: markdown}
+	first, _, _ := strings.Cut(markdown, "\n")
+	first = strings.TrimSpace(strings.TrimPrefix(first, "#"))
+	if _, title, ok := strings.Cut(first, ":"); ok {
+		doc.Title = strings.TrimSpace(title)
+	}
+	return doc
+}
+
+// Rule sources reported by DescribeRules.
+const (
+	RuleSourceBuiltin = "builtin"
+	RuleSourceCustom  = "custom"
+	RuleSourcePlugin  = "plugin"
+)
+
+// RuleInfo summarizes a rule for listings.
+type RuleInfo struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title,omitempty"`
+	Severity   Severity `json:"severity"`
+	Category   string   `json:"category,omitempty"`
+	QuickFixes []string `json:"quickFixes,omitempty"`
+	Source     string   `json:"source"`
+	Enabled    bool     `json:"enabled"`
+	HasDoc     bool     `json:"hasDoc"`
+}
+
+// DescribeRules returns listing metadata for rules in order. A rule is
+// enabled when its ID appears in enabled (typically the FilterRules result).
+func DescribeRules(rules []Rule, enabled []Rule) []RuleInfo {
+	active := make(map[string]struct{}, len(enabled))
+	for _, rule := range enabled {
+		active[strings.ToUpper(rule.ID)] = struct{}{}
+	}
+
+	infos := make([]RuleInfo, 0, len(rules))
+	for _, rule := range rules {
+		info := RuleInfo{ID: rule.ID, Title: rule.Title, Severity: rule.Severity, Source: rule.Source}
+		data := rule.Data
+		if info.Source == "" {
+			info.Source = RuleSourceBuiltin
+			data = ruleMetadata[rule.ID]
+		}
+		if doc, ok := LookupRuleDoc(rule.ID); ok && info.Source == RuleSourceBuiltin {
+			info.HasDoc = true
+			if info.Title == "" {
+				info.Title = doc.Title
+			}
+		}
+		info.Category = data.Category
+		info.QuickFixes = data.QuickFixes
+		_, info.Enabled = active[strings.ToUpper(rule.ID)]
+		infos = append(infos, info)
+	}
+	return infos
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleDocsCoverBuiltinRules(t *testing.T) {
+	ids := append([]string{"MD000"}, ruleIDs(DefaultRules())...)
+	for _, id := range ids {
+		doc, ok := LookupRuleDoc(id)
+		if !ok {
+			t.Fatalf("missing docs for %s", id)
+		}
+		if doc.Title == "" {
+			t.Fatalf("%s: missing title", id)
+		}
+		for _, section := range []string{"## Why it matters", "## Bad", "## Good", "## How to fix"} {
+			if !strings.Contains(doc.Markdown, section) {
+				t.Fatalf("%s: missing section %q", id, section)
+			}
+		}
+	}
+	if len(RuleDocIDs()) != len(ids) {
+		t.Fatalf("unexpected doc IDs: %v", RuleDocIDs())
+	}
+}
+
+func TestLookupRuleDoc(t *testing.T) {
+	doc, ok := LookupRuleDoc(" md001 ")
+	if !ok || doc.ID != "MD001" || doc.Title != "Config conflict" {
+		t.Fatalf("unexpected doc: %#v %v", doc, ok)
+	}
+	for _, id := range []string{"", "MD999", "../rules", "ruledocs/MD001"} {
+		if _, ok := LookupRuleDoc(id); ok {
+			t.Fatalf("expected no doc for %q", id)
+		}
+	}
+}
+
+func TestDescribeRules(t *testing.T) {
+	custom := Rule{ID: "ORG1", Severity: SeverityError, Source: RuleSourceCustom, Title: "Org rule", Data: RuleData{Category: "org"}}
+	rules := append(DefaultRules(), custom)
+	enabled, err := FilterRules(rules, nil, []string{"MD002"})
+	if err != nil {
+		t.Fatalf("FilterRules: %v", err)
+	}
+
+	infos := DescribeRules(rules, enabled)
+	byID := make(map[string]RuleInfo, len(infos))
+	for _, info := range infos {
+		byID[info.ID] = info
+	}
+	if info := byID["MD002"]; info.Enabled || info.Source != RuleSourceBuiltin || info.Category != "scope" || len(info.QuickFixes) != 1 || !info.HasDoc {
+		t.Fatalf("unexpected MD002 info: %#v", info)
+	}
+	if info := byID["ORG1"]; !info.Enabled || info.Title != "Org rule" || info.Category != "org" || info.HasDoc {
+		t.Fatalf("unexpected custom info: %#v", info)
+	}
+}
+
+func ruleIDs(rules []Rule) []string {
+	ids := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
@@ -1,6 +1,7 @@
 package audit
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -17,17 +18,42 @@ type Context struct {
 }
 
 // Rule describes a rule evaluator.
+// RunContext is set for rules that can fail or need cancellation, such as plugins.
 type Rule struct {
-	ID       string
-	Severity Severity
-	Run      func(Context) []Issue
+	ID         string
+	Severity   Severity
+	Run        func(Context) []Issue
+	RunContext func(context.Context, Context) ([]Issue, error)
+	// Source, Title, and Data describe rules loaded from rule packs. Built-in
+	// rules leave them empty and use ruleMetadata and the embedded docs.
+	Source string
+	Title  string
+	Data   RuleData
 }
 
 // Evaluate runs the rule for the provided context.
+// Errors from RunContext rules are dropped; use EvaluateContext to observe them.
 func (r Rule) Evaluate(ctx Context) []Issue {
+	if r.Run == nil && r.RunContext != nil {
+		issues, _ := r.RunContext(context.Background(), ctx)
+		return issues
+	}
 	return r.Run(ctx)
 }
 
+// EvaluateContext runs the rule with cancellation and error reporting.
+func (r Rule) EvaluateContext(goCtx context.Context, ctx Context) ([]Issue, error) {
+	if r.RunContext != nil {
+		return r.RunContext(goCtx, ctx)
+	}
+	return r.Run(ctx), nil
+}
+
+// RuleID returns the rule identifier.
+func (r Rule) RuleID() string {
+	return r.ID
+}
+
 const ruleDocURL = "docs/audit-spec-v1.md"
 
 var ruleMetadata = map[string]RuleData{
@@ -43,6 +69,7 @@ var ruleMetadata = map[string]RuleData{
 	"MD010": {Category: "discovery", DocURL: ruleDocURL},
 	"MD011": {Category: "content", DocURL: ruleDocURL, Tags: []string{"unnecessary"}},
 	"MD012": {Category: "validity", DocURL: ruleDocURL, QuickFixes: []string{"insert-frontmatter-id"}},
+	"MD015": {Category: "validity", DocURL: ruleDocURL, QuickFixes: []string{"replace-toolid"}},
 	"MD018": {Category: "content", DocURL: ruleDocURL},
 	"MD013": {Category: "scope", DocURL: ruleDocURL, Tags: []string{"unnecessary"}},
 }
@@ -71,6 +98,7 @@ func DefaultRules() []Rule {
 		{ID: "MD012", Severity: SeverityWarning, Run: ruleMissingFrontmatterID},
 		{ID: "MD018", Severity: SeverityWarning, Run: ruleOversizedConfig},
 		{ID: "MD013", Severity: SeverityInfo, Run: ruleShadowedConfig},
+		{ID: "MD015", Severity: SeverityWarning, Run: ruleUnknownToolID},
 	}
 }
 
@@ -638,6 +666,54 @@ func ruleMissingFrontmatterID(ctx Context) []Issue {
 	return issues
 }
 
+// ruleUnknownToolID flags a frontmatter toolId that is not in the registry
+// when a close registry ID can be suggested as its replacement.
+func ruleUnknownToolID(ctx Context) []Issue {
+	toolIDs := RegistryToolIDs(ctx.Registry)
+	known := make(map[string]struct{}, len(toolIDs))
+	for _, id := range toolIDs {
+		known[strings.ToLower(id)] = struct{}{}
+	}
+
+	var issues []Issue
+	for _, entry := range ctx.Scan.Configs {
+		raw, ok := entry.Frontmatter["toolId"].(string)
+		if !ok {
+			continue
+		}
+		toolID := strings.TrimSpace(raw)
+		if toolID == "" {
+			continue
+		}
+		if _, ok := known[strings.ToLower(toolID)]; ok {
+			continue
+		}
+		replacement := ClosestToolID(toolID, toolIDs)
+		if replacement == "" {
+			continue
+		}
+		issue := Issue{
+			RuleID:     "MD015",
+			Severity:   SeverityWarning,
+			Title:      "Unknown toolId",
+			Message:    fmt.Sprintf("Unknown toolId: %s", toolID),
+			Suggestion: fmt.Sprintf("Replace with %s.", replacement),
+			Paths:      []Path{redactPath(ctx, entry.Path, entry.Scope)},
+			Tools:      toolsForEntry(entry),
+			Data:       ruleData("MD015"),
+			Evidence: map[string]any{
+				"toolId":      toolID,
+				"replacement": replacement,
+			},
+		}
+		if location, ok := entry.FrontmatterLocations["toolId"]; ok {
+			issue.Range = &location
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
 func hasFrontmatterIdentifier(frontmatter map[string]any, keys []string) bool {
 	for _, key := range keys {
 		if len(frontmatterValues(frontmatter, key)) > 0 {
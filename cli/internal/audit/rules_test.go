@@ -470,6 +470,30 @@ func TestRuleMissingFrontmatterIDSkipsPresentKey(t *testing.T) {
 	}
 }
 
+func TestRuleUnknownToolID(t *testing.T) {
+	reg := scan.Registry{Patterns: []scan.Pattern{{ToolID: "claude-code"}, {ToolID: "codex"}}}
+	typo := configEntry("/repo/.claude/rules/style.md", "repo", "claude-code", "rules")
+	typo.Frontmatter = map[string]any{"toolId": "claude-cod"}
+	typo.FrontmatterLocations = map[string]scan.Range{"toolId": {StartLine: 2, StartCol: 1, EndLine: 2, EndCol: 7}}
+	known := configEntry("/repo/.claude/rules/known.md", "repo", "claude-code", "rules")
+	known.Frontmatter = map[string]any{"toolId": "Codex"}
+	unrelated := configEntry("/repo/.claude/rules/other.md", "repo", "claude-code", "rules")
+	unrelated.Frontmatter = map[string]any{"toolId": "something-else"}
+
+	issues := ruleUnknownToolID(testContext([]scan.ConfigEntry{typo, known, unrelated}, reg))
+	if len(issues) != 1 {
+		t.Fatalf("expected one issue, got %#v", issues)
+	}
+	issue := issues[0]
+	if issue.RuleID != "MD015" || issue.Evidence["replacement"] != "claude-code" || issue.Evidence["toolId"] != "claude-cod" {
+		t.Fatalf("unexpected issue: %#v", issue)
+	}
+	if issue.Range == nil || issue.Range.StartLine != 2 {
+		t.Fatalf("expected toolId range, got %#v", issue.Range)
+	}
+	requireRuleData(t, issue, "validity")
+}
+
 func TestRuleOversizedConfig(t *testing.T) {
 	largeSize := int64(2 * 1024 * 1024) // 2MB
 	entry := configEntry("/repo/AGENTS.md", "repo", "codex", "instructions")
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"sort"
+	"strings"
+
+	"markdowntown-cli/internal/scan"
+)
+
+// ClosestToolID returns the option nearest to target by edit distance, or ""
+// when target is too short or no option is close enough to suggest.
+func ClosestToolID(target string, options []string) string {
+	target = strings.TrimSpace(strings.ToLower(target))
+	if target == "" || len(options) == 0 {
+		return ""
+	}
+	if len(target) < 3 {
+		return ""
+	}
+	best := ""
+	bestDistance := -1
+	for _, option := range options {
+		normalized := strings.ToLower(option)
+		if normalized == "" {
+			continue
+		}
+		dist := levenshteinDistance(target, normalized)
+		if bestDistance == -1 || dist < bestDistance {
+			bestDistance = dist
+			best = option
+		}
+	}
+	if bestDistance < 0 {
+		return ""
+	}
+	maxDistance := 2
+	if len(target) >= 8 {
+		maxDistance = 3
+	}
+	if bestDistance > maxDistance {
+		return ""
+	}
+	return best
+}
+
+// RegistryToolIDs returns the sorted, distinct tool IDs in the registry.
+func RegistryToolIDs(registry scan.Registry) []string {
+	seen := make(map[string]struct{})
+	for _, pattern := range registry.Patterns {
+		if pattern.ToolID == "" {
+			continue
+		}
+		seen[pattern.ToolID] = struct{}{}
+	}
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func levenshteinDistance(a string, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return len(b)
+	}
+	if b == "" {
+		return len(a)
+	}
+
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := 0; j <= len(b); j++ {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 0
+			if a[i-1] != b[j-1] {
+				cost = 1
+			}
+			del := previous[j] + 1
+			ins := current[j-1] + 1
+			sub := previous[j-1] + cost
+			current[j] = minInt(del, minInt(ins, sub))
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
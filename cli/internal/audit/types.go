@@ -29,9 +29,17 @@ type Output struct {
 
 // Meta captures metadata for the audit run.
 type Meta struct {
-	ToolVersion    string `json:"toolVersion"`
-	AuditStartedAt int64  `json:"auditStartedAt"`
-	GeneratedAt    int64  `json:"generatedAt"`
+	ToolVersion    string       `json:"toolVersion"`
+	AuditStartedAt int64        `json:"auditStartedAt"`
+	GeneratedAt    int64        `json:"generatedAt"`
+	RuleTimings    []RuleTiming `json:"ruleTimings,omitempty"`
+}
+
+// RuleTiming records how long a single rule took to run.
+type RuleTiming struct {
+	RuleID     string  `json:"ruleId"`
+	DurationMs float64 `json:"durationMs"`
+	Issues     int     `json:"issues"`
 }
 
 // SourceScan captures the scan metadata that audit consumes.
@@ -7,6 +7,8 @@ import (
 	"markdowntown-cli/internal/audit"
 	"markdowntown-cli/internal/instructions"
 	"markdowntown-cli/internal/scan"
+
+	"github.com/spf13/afero"
 )
 
 // Engine defines the interface for retrieving context for a file.
@@ -22,6 +24,10 @@ type ResolveOptions struct {
 	FilePath string
 	Clients  []instructions.Client // e.g. [ClientCodex, ClientGemini]
 	Registry *scan.Registry        // Optional: run validation if provided
+	// Fs replaces the OS filesystem when set, e.g. with a commit loaded by
+	// scan.LoadGitTree. CheckIgnore should then answer from the same tree.
+	Fs          afero.Fs
+	CheckIgnore func(repoRoot string, paths []string) (map[string]bool, error)
 }
 
 // UnifiedResolution contains the resolution results for multiple clients.
@@ -87,8 +93,10 @@ func (e *Resolver) ResolveContext(ctx context.Context, opts ResolveOptions) (Uni
 		}
 
 		res, err := adapter.Resolve(instructions.ResolveOptions{
-			RepoRoot:   opts.RepoRoot,
-			TargetPath: opts.FilePath,
+			RepoRoot:    opts.RepoRoot,
+			TargetPath:  opts.FilePath,
+			Fs:          opts.Fs,
+			CheckIgnore: opts.CheckIgnore,
 		})
 
 		unified.Results[client] = ClientResult{
@@ -97,7 +105,7 @@ func (e *Resolver) ResolveContext(ctx context.Context, opts ResolveOptions) (Uni
 		}
 
 		if opts.Registry != nil && err == nil {
-			issues, _ := ValidateResolution(&res, *opts.Registry)
+			issues, _ := ValidateResolution(&res, *opts.Registry, opts.Fs)
 			unified.Diagnostics[client] = issues
 		}
 	}
@@ -20,64 +20,59 @@ type SearchResult struct {
 }
 
 // SearchInstructions searches for a query across all instruction files in the repo and user scope.
-func SearchInstructions(ctx context.Context, repoRoot string, registry scan.Registry, query string) ([]SearchResult, error) {
+// Files are read from fs, or from the OS filesystem when fs is nil.
+func SearchInstructions(ctx context.Context, fs afero.Fs, repoRoot string, registry scan.Registry, query string) ([]SearchResult, error) {
 	if query == "" {
 		return nil, nil
 	}
 
-	if err := ctx.Err(); err != nil {
-		return nil, err
-	}
+	var results []SearchResult
+	queryLower := strings.ToLower(query)
 
-	// 1. Scan to find all instruction files
-	// ... (scan options)
-	// ...
-	// Note: scan.Scan currently doesn't accept context, but we check it before and after.
-	scanRes, err := scan.Scan(scan.Options{
+	// Entries are searched as the scan streams them, directory by directory.
+	// Only cancellation of ctx stops the walk early.
+	_, err := scan.ScanContext(ctx, scan.Options{
 		RepoRoot:       repoRoot,
 		Registry:       registry,
 		IncludeContent: true,
-		Fs:             afero.NewOsFs(),
-	})
+		Fs:             fileSystem(fs),
+	}, scan.Sink{Entry: func(entry scan.ConfigEntry) {
+		results = append(results, searchEntry(entry, queryLower)...)
+	}})
 	if err != nil {
 		return nil, err
 	}
 
-	var results []SearchResult
-	queryLower := strings.ToLower(query)
-
-	for _, entry := range scanRes.Entries {
-		if err := ctx.Err(); err != nil {
-			return nil, err
-		}
+	return results, nil
+}
 
-		if entry.Content == nil {
-			continue
-		}
+func searchEntry(entry scan.ConfigEntry, queryLower string) []SearchResult {
+	if entry.Content == nil {
+		return nil
+	}
 
-		// We need to map scan entries back to clients.
-		// A single entry might belong to multiple clients.
-		clients := clientsForEntry(entry)
+	// We need to map scan entries back to clients.
+	// A single entry might belong to multiple clients.
+	clients := clientsForEntry(entry)
 
-		scanner := bufio.NewScanner(strings.NewReader(*entry.Content))
-		lineNum := 0
-		for scanner.Scan() {
-			lineNum++
-			line := scanner.Text()
-			if strings.Contains(strings.ToLower(line), queryLower) {
-				for _, client := range clients {
-					results = append(results, SearchResult{
-						Client: client,
-						Path:   entry.Path,
-						Line:   lineNum,
-						Text:   strings.TrimSpace(line),
-					})
-				}
+	var results []SearchResult
+	scanner := bufio.NewScanner(strings.NewReader(*entry.Content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if strings.Contains(strings.ToLower(line), queryLower) {
+			for _, client := range clients {
+				results = append(results, SearchResult{
+					Client: client,
+					Path:   entry.Path,
+					Line:   lineNum,
+					Text:   strings.TrimSpace(line),
+				})
 			}
 		}
 	}
-
-	return results, nil
+	return results
 }
 
 func clientsForEntry(entry scan.ConfigEntry) []instructions.Client {
@@ -28,7 +28,7 @@ func TestSearchInstructions(t *testing.T) {
 
 	registry, _, _ := scan.LoadRegistry()
 
-	results, err := SearchInstructions(context.Background(), tempDir, registry, "TypeScript")
+	results, err := SearchInstructions(context.Background(), nil, tempDir, registry, "TypeScript")
 	if err != nil {
 		t.Fatalf("SearchInstructions failed: %v", err)
 	}
@@ -58,3 +58,13 @@ func TestSearchInstructions(t *testing.T) {
 		t.Errorf("missing Claude result")
 	}
 }
+
+func TestSearchInstructionsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	registry, _, _ := scan.LoadRegistry()
+	if _, err := SearchInstructions(ctx, nil, t.TempDir(), registry, "TypeScript"); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
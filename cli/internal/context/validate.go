@@ -14,7 +14,8 @@ import (
 )
 
 // ValidateResolution runs audit rules on the instruction files in a resolution.
-func ValidateResolution(res *instructions.Resolution, registry scan.Registry) ([]audit.Issue, error) {
+// Files are read from fs, or from the OS filesystem when fs is nil.
+func ValidateResolution(res *instructions.Resolution, registry scan.Registry, fs afero.Fs) ([]audit.Issue, error) {
 	if res == nil || len(res.Applied) == 0 {
 		return nil, nil
 	}
@@ -59,7 +60,7 @@ func ValidateResolution(res *instructions.Resolution, registry scan.Registry) ([
 		StdinPaths:     paths,
 		Registry:       registry,
 		IncludeContent: false, // Metadata only for audit
-		Fs:             afero.NewOsFs(),
+		Fs:             fileSystem(fs),
 	})
 	if err != nil {
 		return nil, err
@@ -123,3 +124,10 @@ func ValidateResolution(res *instructions.Resolution, registry scan.Registry) ([
 
 	return filtered, nil
 }
+
+func fileSystem(fs afero.Fs) afero.Fs {
+	if fs != nil {
+		return fs
+	}
+	return afero.NewOsFs()
+}
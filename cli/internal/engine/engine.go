@@ -1,13 +1,35 @@
 // Package engine provides shared rule execution helpers.
 package engine
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+)
 
 // Rule is the minimal interface required to execute a rule set.
 type Rule[TContext any, TIssue any] interface {
 	Evaluate(TContext) []TIssue
 }
 
+// ContextRule is implemented by rules that honor cancellation or can fail,
+// such as rules that run out of process.
+type ContextRule[TContext any, TIssue any] interface {
+	EvaluateContext(context.Context, TContext) ([]TIssue, error)
+}
+
+// IdentifiedRule exposes a rule ID for timing and error reporting.
+type IdentifiedRule interface {
+	RuleID() string
+}
+
+// RuleTiming records how long a rule took and how many issues it produced.
+type RuleTiming struct {
+	RuleID   string
+	Duration time.Duration
+	Issues   int
+}
+
 // Run executes rules in order and aggregates the results.
 func Run[TContext any, TIssue any, TRule Rule[TContext, TIssue]](ctx TContext, rules []TRule) []TIssue {
 	var issues []TIssue
@@ -17,14 +39,47 @@ func Run[TContext any, TIssue any, TRule Rule[TContext, TIssue]](ctx TContext, r
 	return issues
 }
 
-// RunWithContext executes rules in order and returns early if the context ends.
+// RunWithContext executes rules in order and returns early if the context ends
+// or a rule fails.
 func RunWithContext[TContext any, TIssue any, TRule Rule[TContext, TIssue]](ctx context.Context, runCtx TContext, rules []TRule) ([]TIssue, error) {
+	issues, _, err := RunTimed[TContext, TIssue](ctx, runCtx, rules)
+	return issues, err
+}
+
+// RunTimed executes rules like RunWithContext and records per-rule timing.
+// Rules implementing ContextRule receive ctx; others are evaluated directly.
+func RunTimed[TContext any, TIssue any, TRule Rule[TContext, TIssue]](ctx context.Context, runCtx TContext, rules []TRule) ([]TIssue, []RuleTiming, error) {
 	var issues []TIssue
-	for _, rule := range rules {
+	timings := make([]RuleTiming, 0, len(rules))
+	for i, rule := range rules {
 		if err := ctx.Err(); err != nil {
-			return issues, err
+			return issues, timings, err
+		}
+		startedAt := time.Now()
+		found, err := evaluate[TContext, TIssue](ctx, runCtx, rule)
+		timing := RuleTiming{RuleID: ruleID(rule, i), Duration: time.Since(startedAt), Issues: len(found)}
+		timings = append(timings, timing)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return issues, timings, ctxErr
+			}
+			return issues, timings, fmt.Errorf("rule %s: %w", timing.RuleID, err)
 		}
-		issues = append(issues, rule.Evaluate(runCtx)...)
+		issues = append(issues, found...)
+	}
+	return issues, timings, nil
+}
+
+func evaluate[TContext any, TIssue any](ctx context.Context, runCtx TContext, rule Rule[TContext, TIssue]) ([]TIssue, error) {
+	if contextual, ok := any(rule).(ContextRule[TContext, TIssue]); ok {
+		return contextual.EvaluateContext(ctx, runCtx)
+	}
+	return rule.Evaluate(runCtx), nil
+}
+
+func ruleID(rule any, index int) string {
+	if identified, ok := rule.(IdentifiedRule); ok {
+		return identified.RuleID()
 	}
-	return issues, nil
+	return fmt.Sprintf("#%d", index)
 }
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -61,3 +62,56 @@ func TestRunWithContextCompletes(t *testing.T) {
 		t.Fatalf("unexpected issues: %#v", got)
 	}
 }
+
+type contextRule struct {
+	id  string
+	out []int
+	err error
+}
+
+func (r contextRule) Evaluate(_ int) []int {
+	return nil
+}
+
+func (r contextRule) EvaluateContext(_ context.Context, _ int) ([]int, error) {
+	return r.out, r.err
+}
+
+func (r contextRule) RuleID() string {
+	return r.id
+}
+
+func TestRunTimedRecordsRules(t *testing.T) {
+	rules := []contextRule{
+		{id: "A", out: []int{1, 2}},
+		{id: "B", out: []int{3}},
+	}
+
+	got, timings, err := RunTimed[int, int](context.Background(), 0, rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Fatalf("unexpected issues: %#v", got)
+	}
+	if len(timings) != 2 || timings[0].RuleID != "A" || timings[0].Issues != 2 || timings[1].RuleID != "B" || timings[1].Issues != 1 {
+		t.Fatalf("unexpected timings: %#v", timings)
+	}
+}
+
+func TestRunTimedWrapsRuleError(t *testing.T) {
+	failure := errors.New("boom")
+	rules := []contextRule{
+		{id: "A", out: []int{1}},
+		{id: "PLUGIN", err: failure},
+		{id: "C", out: []int{3}},
+	}
+
+	got, timings, err := RunTimed[int, int](context.Background(), 0, rules)
+	if !errors.Is(err, failure) || err.Error() != "rule PLUGIN: boom" {
+		t.Fatalf("expected wrapped rule error, got %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{1}) || len(timings) != 2 {
+		t.Fatalf("expected partial results, got %#v %#v", got, timings)
+	}
+}
@@ -0,0 +1,136 @@
+package fix
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Change records the content of a file before and after fixes are applied.
+type Change struct {
+	Path    string   `json:"path"`
+	Created bool     `json:"created"`
+	Before  string   `json:"-"`
+	After   string   `json:"-"`
+	Fixes   []string `json:"fixes"`
+}
+
+// Plan merges fixes into per-file changes without touching the filesystem.
+// Fixes that duplicate or overlap an earlier fix are returned as skipped.
+func Plan(fs afero.Fs, fixes []Fix) ([]Change, []Fix) {
+	type pending struct {
+		change Change
+		edits  []TextEdit
+	}
+	files := make(map[string]*pending)
+	seen := make(map[string]struct{})
+	var skipped []Fix
+
+	for _, candidate := range fixes {
+		key := fixKey(candidate)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		staged := make(map[string]*pending)
+		ok := true
+		for _, file := range candidate.Files {
+			current, exists := staged[file.Path]
+			if !exists {
+				current = files[file.Path]
+			}
+			if current == nil {
+				current = &pending{change: Change{Path: file.Path}}
+				data, err := afero.ReadFile(fs, file.Path)
+				switch {
+				case err == nil:
+					current.change.Before = string(data)
+				case os.IsNotExist(err) && file.Create:
+					current.change.Created = true
+				default:
+					ok = false
+				}
+			} else if file.Create && current.change.Created {
+				// Another fix already creates this file.
+				ok = false
+			}
+			if !ok {
+				break
+			}
+			edits := append(append([]TextEdit(nil), current.edits...), file.Edits...)
+			after, err := ApplyEdits(current.change.Before, edits)
+			if err != nil {
+				ok = false
+				break
+			}
+			next := &pending{change: current.change, edits: edits}
+			next.change.After = after
+			next.change.Fixes = append(append([]string(nil), current.change.Fixes...), candidate.Title)
+			staged[file.Path] = next
+		}
+		if !ok {
+			skipped = append(skipped, candidate)
+			continue
+		}
+		seen[key] = struct{}{}
+		for path, value := range staged {
+			files[path] = value
+		}
+	}
+
+	changes := make([]Change, 0, len(files))
+	for _, value := range files {
+		if value.change.After == value.change.Before && !value.change.Created {
+			continue
+		}
+		changes = append(changes, value.change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, skipped
+}
+
+// Write persists planned changes, creating parent directories for new files.
+func Write(fs afero.Fs, changes []Change) error {
+	for _, change := range changes {
+		mode := os.FileMode(0o644)
+		if info, err := fs.Stat(change.Path); err == nil {
+			mode = info.Mode().Perm()
+		} else if change.Created {
+			if err := fs.MkdirAll(filepath.Dir(change.Path), 0o755); err != nil {
+				return err
+			}
+		}
+		if err := afero.WriteFile(fs, change.Path, []byte(change.After), mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff renders the planned changes as a unified diff relative to repoRoot.
+func Diff(repoRoot string, changes []Change) string {
+	var builder strings.Builder
+	for _, change := range changes {
+		name := change.Path
+		if rel, ok := RelativeRepoPath(repoRoot, change.Path); ok {
+			name = rel
+		}
+		oldName := "a/" + name
+		if change.Created {
+			oldName = "/dev/null"
+		}
+		builder.WriteString(UnifiedDiff(oldName, "b/"+name, change.Before, change.After))
+	}
+	return builder.String()
+}
+
+func fixKey(value Fix) string {
+	encoded, _ := json.Marshal(value.Files)
+	return value.ID + "\x00" + string(encoded)
+}
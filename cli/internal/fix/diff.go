@@ -0,0 +1,201 @@
+package fix
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around each hunk.
+	diffContext = 3
+	// maxDiffCells bounds the LCS table; larger changes fall back to a replace hunk.
+	maxDiffCells = 4 * 1024 * 1024
+)
+
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff renders a unified diff between before and after.
+// Created files use /dev/null as the old name. It returns "" when the contents match.
+func UnifiedDiff(oldName string, newName string, before string, after string) string {
+	if before == after {
+		return ""
+	}
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", oldName, newName)
+	for _, hunk := range buildHunks(ops) {
+		writeHunk(&builder, ops[hunk[0]:hunk[1]], hunk[2], hunk[3])
+	}
+	return builder.String()
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func diffLines(a []string, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: ' ', text: line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: ' ', text: line})
+	}
+	return ops
+}
+
+func diffMiddle(a []string, b []string) []diffOp {
+	ops := make([]diffOp, 0, len(a)+len(b))
+	if len(a)*len(b) > maxDiffCells || len(a) == 0 || len(b) == 0 {
+		for _, line := range a {
+			ops = append(ops, diffOp{kind: '-', text: line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{kind: '+', text: line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] holds the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: '-', text: a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{kind: '-', text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{kind: '+', text: b[j]})
+	}
+	return ops
+}
+
+// buildHunks returns [startOp, endOp, oldStartLine, newStartLine] tuples.
+func buildHunks(ops []diffOp) [][4]int {
+	var hunks [][4]int
+	oldLine, newLine := 1, 1
+	start := -1
+	var hunkOld, hunkNew int
+	lastChange := -1
+	for idx, op := range ops {
+		if op.kind != ' ' {
+			if start == -1 || idx-lastChange > 2*diffContext {
+				if start != -1 {
+					hunks = append(hunks, [4]int{start, minInt(lastChange+diffContext+1, len(ops)), hunkOld, hunkNew})
+				}
+				start = maxInt(idx-diffContext, 0)
+				hunkOld = oldLine - (idx - start)
+				hunkNew = newLine - (idx - start)
+			}
+			lastChange = idx
+		}
+		switch op.kind {
+		case ' ':
+			oldLine++
+			newLine++
+		case '-':
+			oldLine++
+		case '+':
+			newLine++
+		}
+	}
+	if start != -1 {
+		hunks = append(hunks, [4]int{start, minInt(lastChange+diffContext+1, len(ops)), hunkOld, hunkNew})
+	}
+	return hunks
+}
+
+func writeHunk(builder *strings.Builder, ops []diffOp, oldStart int, newStart int) {
+	oldCount, newCount := 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(builder, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, op := range ops {
+		builder.WriteByte(op.kind)
+		builder.WriteString(op.text)
+		if !strings.HasSuffix(op.text, "\n") {
+			builder.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start int, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,446 @@
+// Package fix generates text edits that resolve audit issues.
+package fix
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Fix identifiers shared by the CLI and LSP quick fixes.
+const (
+	AllowGitignore             = "allow-gitignore"
+	RemoveFrontmatter          = "remove-frontmatter"
+	InsertPlaceholder          = "insert-placeholder"
+	CreateRepoConfig           = "create-repo-config"
+	RemoveDuplicateFrontmatter = "remove-duplicate-frontmatter"
+	InsertFrontmatterID        = "insert-frontmatter-id"
+	ReplaceToolID              = "replace-toolid"
+	DisableRule                = "disable-rule"
+)
+
+// Fix titles shown to users.
+const (
+	TitleRemoveFrontmatter                = "Remove invalid frontmatter block"
+	TitleInsertPlaceholder                = "Insert placeholder instructions"
+	TitleAllowGitignoreEntry              = "Allow this config in .gitignore"
+	TitleCreateRepoPrefix                 = "Create repo config at "
+	TitleRemoveDuplicateFrontmatterPrefix = "Remove duplicate frontmatter "
+	TitleInsertFrontmatterPrefix          = "Insert frontmatter "
+	TitleReplaceToolIDPrefix              = "Replace toolId with "
+	TitleDisableRulePrefix                = "Disable rule "
+)
+
+// Position is a zero-based line and UTF-16 character offset.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a half-open span between two positions.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// TextEdit replaces the text in Range with NewText.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// FileEdit groups text edits for a single file.
+type FileEdit struct {
+	Path   string     `json:"path"`
+	Create bool       `json:"create,omitempty"`
+	Edits  []TextEdit `json:"edits"`
+}
+
+// Fix is a titled set of file edits that resolves an issue.
+type Fix struct {
+	ID     string     `json:"id"`
+	RuleID string     `json:"ruleId,omitempty"`
+	Title  string     `json:"title"`
+	Safe   bool       `json:"safe"`
+	Files  []FileEdit `json:"files"`
+}
+
+// Target describes the file and issue details a fix operates on.
+type Target struct {
+	RuleID   string
+	Path     string
+	RepoRoot string
+	Content  string
+	Range    Range
+	Evidence map[string]any
+	Data     map[string]any
+}
+
+// DefaultForRule returns the built-in quick fixes for a rule ID.
+func DefaultForRule(ruleID string) []string {
+	switch ruleID {
+	case "MD002":
+		return []string{AllowGitignore}
+	case "MD003":
+		return []string{RemoveFrontmatter}
+	case "MD004":
+		return []string{InsertPlaceholder}
+	case "MD005":
+		return []string{CreateRepoConfig}
+	case "MD007":
+		return []string{RemoveDuplicateFrontmatter}
+	case "MD012":
+		return []string{InsertFrontmatterID}
+	case "MD015":
+		return []string{ReplaceToolID}
+	default:
+		return nil
+	}
+}
+
+// IsSafe reports whether a fix only adds content and never deletes user text.
+func IsSafe(fixID string) bool {
+	switch fixID {
+	case AllowGitignore, InsertPlaceholder, CreateRepoConfig, InsertFrontmatterID, ReplaceToolID:
+		return true
+	default:
+		return false
+	}
+}
+
+// Generate builds the fix identified by fixID for target, or nil when it does not apply.
+func Generate(fs afero.Fs, fixID string, target Target) *Fix {
+	var result *Fix
+	switch fixID {
+	case RemoveFrontmatter:
+		result = removeFrontmatter(target)
+	case InsertPlaceholder:
+		result = insertPlaceholder(target)
+	case AllowGitignore:
+		result = allowGitignore(fs, target)
+	case CreateRepoConfig:
+		result = createRepoConfig(fs, target)
+	case RemoveDuplicateFrontmatter:
+		result = removeDuplicateFrontmatter(target)
+	case InsertFrontmatterID:
+		result = insertFrontmatterID(target)
+	case ReplaceToolID:
+		result = replaceToolID(target)
+	case DisableRule:
+		result = disableRule(fs, target)
+	default:
+		return nil
+	}
+	if result == nil {
+		return nil
+	}
+	result.ID = fixID
+	result.RuleID = target.RuleID
+	result.Safe = IsSafe(fixID)
+	return result
+}
+
+func removeFrontmatter(target Target) *Fix {
+	fmRange := FrontmatterBlockRange(target.Content)
+	if fmRange == nil {
+		return nil
+	}
+	return singleEdit(TitleRemoveFrontmatter, target.Path, TextEdit{Range: *fmRange})
+}
+
+func insertPlaceholder(target Target) *Fix {
+	if strings.TrimSpace(target.Content) != "" {
+		return nil
+	}
+	stub := StubContentForPath(target.Path)
+	if stub == "" {
+		return nil
+	}
+	return singleEdit(TitleInsertPlaceholder, target.Path, TextEdit{NewText: stub})
+}
+
+func allowGitignore(fs afero.Fs, target Target) *Fix {
+	if target.RepoRoot == "" {
+		return nil
+	}
+	rel, ok := RelativeRepoPath(target.RepoRoot, target.Path)
+	if !ok {
+		return nil
+	}
+	return GitignoreEntry(fs, "!"+rel, filepath.Join(target.RepoRoot, ".gitignore"))
+}
+
+// GitignoreEntry appends entry to the gitignore file at path, creating it when missing.
+func GitignoreEntry(fs afero.Fs, entry string, path string) *Fix {
+	if entry == "" || entry == "!" {
+		return nil
+	}
+	content := ""
+	if data, err := afero.ReadFile(fs, path); err == nil {
+		content = string(data)
+	}
+	if HasGitignoreEntry(content, entry) {
+		return nil
+	}
+
+	insert := entry + "\n"
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		insert = "\n" + entry + "\n"
+	}
+
+	file := FileEdit{Path: path}
+	if _, err := fs.Stat(path); err == nil {
+		pos := EndPosition(content)
+		file.Edits = []TextEdit{{Range: Range{Start: pos, End: pos}, NewText: insert}}
+	} else {
+		file.Create = true
+		file.Edits = []TextEdit{{NewText: insert}}
+	}
+	return &Fix{ID: AllowGitignore, Title: TitleAllowGitignoreEntry, Safe: true, Files: []FileEdit{file}}
+}
+
+func createRepoConfig(fs afero.Fs, target Target) *Fix {
+	candidate := firstString(target.Evidence["candidatePaths"])
+	if candidate == "" || target.RepoRoot == "" {
+		return nil
+	}
+	if IsGlobPath(candidate) {
+		return nil
+	}
+	absPath := filepath.Join(target.RepoRoot, filepath.FromSlash(candidate))
+	if _, err := fs.Stat(absPath); err == nil {
+		return nil
+	}
+	parent := filepath.Dir(absPath)
+	if info, err := fs.Stat(parent); err != nil || !info.IsDir() {
+		return nil
+	}
+	stub := StubContentForPath(absPath)
+	if stub == "" {
+		return nil
+	}
+	return &Fix{
+		Title: TitleCreateRepoPrefix + candidate,
+		Files: []FileEdit{{Path: absPath, Create: true, Edits: []TextEdit{{NewText: stub}}}},
+	}
+}
+
+func removeDuplicateFrontmatter(target Target) *Fix {
+	fmRange := FrontmatterBlockRange(target.Content)
+	if fmRange == nil {
+		return nil
+	}
+	field := firstString(target.Evidence["field"])
+	value := firstString(target.Evidence["value"])
+
+	lineIndex := target.Range.Start.Line
+	if !frontmatterLineHasField(target.Content, lineIndex, fmRange, field) {
+		// The issue range may point at another config in the same group.
+		lineIndex = frontmatterFieldLine(target.Content, fmRange, field)
+		if lineIndex < 0 {
+			return nil
+		}
+	}
+	deleteRange := LineDeleteRange(target.Content, lineIndex)
+	if deleteRange == nil {
+		return nil
+	}
+	title := TitleRemoveDuplicateFrontmatterPrefix + "entry"
+	if field != "" && value != "" {
+		title = fmt.Sprintf("%s%s=%s", TitleRemoveDuplicateFrontmatterPrefix, field, value)
+	} else if field != "" {
+		title = TitleRemoveDuplicateFrontmatterPrefix + field
+	}
+	return singleEdit(title, target.Path, TextEdit{Range: *deleteRange})
+}
+
+func frontmatterLineHasField(content string, line int, fmRange *Range, field string) bool {
+	lineText, ok := LineText(content, line)
+	if !ok || line > fmRange.End.Line {
+		return false
+	}
+	if field == "" {
+		return true
+	}
+	lowerLine := strings.ToLower(strings.TrimSpace(lineText))
+	return strings.Contains(lowerLine, strings.ToLower(field)+":")
+}
+
+func frontmatterFieldLine(content string, fmRange *Range, field string) int {
+	if field == "" {
+		return -1
+	}
+	prefix := strings.ToLower(field) + ":"
+	for line := 1; line < fmRange.End.Line; line++ {
+		lineText, ok := LineText(content, line)
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(lineText)), prefix) {
+			return line
+		}
+	}
+	return -1
+}
+
+func insertFrontmatterID(target Target) *Fix {
+	required := stringSlice(target.Evidence["requiredKeys"])
+	if len(required) == 0 {
+		return nil
+	}
+	key := required[0]
+	value := IdentifierFromPath(target.Path)
+	if strings.TrimSpace(value) == "" {
+		value = "change-me"
+	}
+
+	edit := TextEdit{NewText: fmt.Sprintf("---\n%s: %s\n---\n\n", key, value)}
+	if closingLine, ok := frontmatterClosingLine(target.Content); ok {
+		pos := Position{Line: closingLine}
+		edit = TextEdit{Range: Range{Start: pos, End: pos}, NewText: fmt.Sprintf("%s: %s\n", key, value)}
+	} else if frontmatterHasOpeningLine(target.Content) {
+		return nil
+	}
+	return singleEdit(TitleInsertFrontmatterPrefix+key, target.Path, edit)
+}
+
+func replaceToolID(target Target) *Fix {
+	replacement := firstString(target.Data["replacement"])
+	if replacement == "" {
+		return nil
+	}
+	current := TextAtRange(target.Content, target.Range)
+	if current == "" {
+		return nil
+	}
+	if strings.TrimSpace(current) == strings.TrimSpace(replacement) {
+		return nil
+	}
+	original := firstString(target.Data["toolId"])
+	if original != "" && strings.TrimSpace(current) != original {
+		return nil
+	}
+	return singleEdit(TitleReplaceToolIDPrefix+replacement, target.Path, TextEdit{Range: target.Range, NewText: replacement})
+}
+
+func disableRule(fs afero.Fs, target Target) *Fix {
+	if target.RuleID == "" || target.RepoRoot == "" {
+		return nil
+	}
+	settingsPath := filepath.Join(target.RepoRoot, ".vscode", "settings.json")
+	if info, err := fs.Stat(filepath.Dir(settingsPath)); err != nil || !info.IsDir() {
+		return nil
+	}
+
+	content := ""
+	exists := false
+	if data, err := afero.ReadFile(fs, settingsPath); err == nil {
+		content = string(data)
+		exists = true
+	} else if !os.IsNotExist(err) {
+		return nil
+	}
+
+	settings := map[string]any{}
+	if strings.TrimSpace(content) != "" {
+		if err := json.Unmarshal([]byte(content), &settings); err != nil {
+			return nil
+		}
+	}
+
+	key := "markdowntown.diagnostics.rulesDisabled"
+	rules := stringSlice(settings[key])
+	for _, rule := range rules {
+		if rule == target.RuleID {
+			return nil
+		}
+	}
+	rules = append(rules, target.RuleID)
+	rules = dedupeStrings(rules)
+	sort.Strings(rules)
+	settings[key] = rules
+
+	payload, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil
+	}
+	payload = append(payload, '\n')
+
+	file := FileEdit{Path: settingsPath}
+	if exists {
+		file.Edits = []TextEdit{{Range: Range{End: EndPosition(content)}, NewText: string(payload)}}
+	} else {
+		file.Create = true
+		file.Edits = []TextEdit{{NewText: string(payload)}}
+	}
+	return &Fix{Title: TitleDisableRulePrefix + target.RuleID, Files: []FileEdit{file}}
+}
+
+func singleEdit(title string, path string, edit TextEdit) *Fix {
+	return &Fix{Title: title, Files: []FileEdit{{Path: path, Edits: []TextEdit{edit}}}}
+}
+
+func firstString(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case fmt.Stringer:
+		return typed.String()
+	case []string:
+		if len(typed) > 0 {
+			return typed[0]
+		}
+		return ""
+	case []any:
+		for _, item := range typed {
+			if str, ok := item.(string); ok && str != "" {
+				return str
+			}
+		}
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func stringSlice(value any) []string {
+	switch typed := value.(type) {
+	case []string:
+		return typed
+	case []any:
+		parts := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if str, ok := item.(string); ok && str != "" {
+				parts = append(parts, str)
+			}
+		}
+		return parts
+	case string:
+		if typed != "" {
+			return []string{typed}
+		}
+	}
+	return nil
+}
+
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]struct{})
+	deduped := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		deduped = append(deduped, value)
+	}
+	return deduped
+}
@@ -0,0 +1,196 @@
+package fix
+
+import (
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/audit"
+	"markdowntown-cli/internal/scan"
+
+	"github.com/spf13/afero"
+)
+
+func TestGenerateInsertPlaceholder(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	got := Generate(fs, InsertPlaceholder, Target{RuleID: "MD004", Path: "/repo/AGENTS.md"})
+	if got == nil {
+		t.Fatalf("expected fix")
+	}
+	if !got.Safe || got.RuleID != "MD004" || got.Title != TitleInsertPlaceholder {
+		t.Fatalf("unexpected fix metadata: %#v", got)
+	}
+	if len(got.Files) != 1 || got.Files[0].Edits[0].NewText != "# Instructions\n" {
+		t.Fatalf("unexpected edits: %#v", got.Files)
+	}
+
+	if Generate(fs, InsertPlaceholder, Target{Path: "/repo/AGENTS.md", Content: "hello"}) != nil {
+		t.Fatalf("expected no fix for non-empty content")
+	}
+}
+
+func TestGenerateAllowGitignore(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/repo/.gitignore", []byte("AGENTS.md"), 0o644); err != nil {
+		t.Fatalf("write gitignore: %v", err)
+	}
+	got := Generate(fs, AllowGitignore, Target{Path: "/repo/AGENTS.md", RepoRoot: "/repo"})
+	if got == nil {
+		t.Fatalf("expected fix")
+	}
+	changes, skipped := Plan(fs, []Fix{*got})
+	if len(skipped) != 0 || len(changes) != 1 {
+		t.Fatalf("unexpected plan: %#v %#v", changes, skipped)
+	}
+	if changes[0].After != "AGENTS.md\n!AGENTS.md\n" {
+		t.Fatalf("unexpected gitignore: %q", changes[0].After)
+	}
+
+	if err := afero.WriteFile(fs, "/repo/.gitignore", []byte(changes[0].After), 0o644); err != nil {
+		t.Fatalf("write gitignore: %v", err)
+	}
+	if Generate(fs, AllowGitignore, Target{Path: "/repo/AGENTS.md", RepoRoot: "/repo"}) != nil {
+		t.Fatalf("expected no fix when entry already exists")
+	}
+}
+
+func TestGenerateRemoveDuplicateFrontmatter(t *testing.T) {
+	content := "---\nname: shared\ndescription: x\n---\nBody\n"
+	target := Target{
+		Path:     "/repo/a.md",
+		Content:  content,
+		Evidence: map[string]any{"field": "name", "value": "shared"},
+	}
+	got := Generate(afero.NewMemMapFs(), RemoveDuplicateFrontmatter, target)
+	if got == nil {
+		t.Fatalf("expected fix")
+	}
+	if got.Safe {
+		t.Fatalf("expected duplicate frontmatter removal to be unsafe")
+	}
+	after, err := ApplyEdits(content, got.Files[0].Edits)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if after != "---\ndescription: x\n---\nBody\n" {
+		t.Fatalf("unexpected content: %q", after)
+	}
+}
+
+func TestGenerateInsertFrontmatterID(t *testing.T) {
+	target := Target{
+		Path:     "/repo/.github/prompts/build.prompt.md",
+		Content:  "---\ndescription: x\n---\nBody\n",
+		Evidence: map[string]any{"requiredKeys": []any{"name"}},
+	}
+	got := Generate(afero.NewMemMapFs(), InsertFrontmatterID, target)
+	if got == nil {
+		t.Fatalf("expected fix")
+	}
+	after, err := ApplyEdits(target.Content, got.Files[0].Edits)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if after != "---\ndescription: x\nname: build\n---\nBody\n" {
+		t.Fatalf("unexpected content: %q", after)
+	}
+}
+
+func TestForIssueReplacesUnknownToolID(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	content := "---\ntoolId: claude-cod\n---\n# Style\n"
+	if err := afero.WriteFile(fs, "/repo/.claude/rules/style.md", []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	issue := audit.Issue{
+		RuleID:   "MD015",
+		Range:    &scan.Range{StartLine: 2, StartCol: 1, EndLine: 2, EndCol: 7},
+		Paths:    []audit.Path{{Path: ".claude/rules/style.md", Scope: scan.ScopeRepo}},
+		Data:     audit.RuleData{QuickFixes: []string{ReplaceToolID}},
+		Evidence: map[string]any{"toolId": "claude-cod", "replacement": "claude-code"},
+	}
+
+	fixes := ForIssue(fs, "/repo", issue)
+	if len(fixes) != 1 || !fixes[0].Safe {
+		t.Fatalf("expected one safe fix, got %#v", fixes)
+	}
+	updated, err := ApplyEdits(content, fixes[0].Files[0].Edits)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if updated != "---\ntoolId: claude-code\n---\n# Style\n" {
+		t.Fatalf("unexpected content: %q", updated)
+	}
+}
+
+func TestApplyEditsRejectsOverlap(t *testing.T) {
+	edits := []TextEdit{
+		{Range: Range{Start: Position{Line: 0, Character: 0}, End: Position{Line: 0, Character: 3}}, NewText: "x"},
+		{Range: Range{Start: Position{Line: 0, Character: 2}, End: Position{Line: 0, Character: 4}}, NewText: "y"},
+	}
+	if _, err := ApplyEdits("abcdef", edits); err == nil {
+		t.Fatalf("expected overlap error")
+	}
+}
+
+func TestPlanSkipsDuplicatesAndConflicts(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/repo/a.md", []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	replace := Fix{ID: "a", Title: "replace", Files: []FileEdit{{
+		Path:  "/repo/a.md",
+		Edits: []TextEdit{{Range: Range{End: Position{Character: 5}}, NewText: "bye"}},
+	}}}
+	conflict := Fix{ID: "b", Title: "conflict", Files: []FileEdit{{
+		Path:  "/repo/a.md",
+		Edits: []TextEdit{{Range: Range{Start: Position{Character: 1}, End: Position{Character: 2}}, NewText: "E"}},
+	}}}
+	created := Fix{ID: "c", Title: "create", Files: []FileEdit{{
+		Path:   "/repo/new.md",
+		Create: true,
+		Edits:  []TextEdit{{NewText: "# New\n"}},
+	}}}
+
+	changes, skipped := Plan(fs, []Fix{replace, replace, conflict, created})
+	if len(skipped) != 1 || skipped[0].ID != "b" {
+		t.Fatalf("expected conflicting fix to be skipped, got %#v", skipped)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %#v", changes)
+	}
+	if changes[0].Path != "/repo/a.md" || changes[0].After != "bye\n" || len(changes[0].Fixes) != 1 {
+		t.Fatalf("unexpected change: %#v", changes[0])
+	}
+	if !changes[1].Created || changes[1].After != "# New\n" {
+		t.Fatalf("unexpected created change: %#v", changes[1])
+	}
+
+	if err := Write(fs, changes); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	data, err := afero.ReadFile(fs, "/repo/new.md")
+	if err != nil || string(data) != "# New\n" {
+		t.Fatalf("expected created file, got %q (%v)", data, err)
+	}
+
+	diff := Diff("/repo", changes)
+	if !strings.Contains(diff, "--- a/a.md\n+++ b/a.md\n") || !strings.Contains(diff, "--- /dev/null\n+++ b/new.md\n") {
+		t.Fatalf("unexpected diff headers:\n%s", diff)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "one\ntwo\nthree\nfour\nfive\nsix\nseven\n"
+	after := "one\ntwo\nthree\nFOUR\nfive\nsix\nseven\n"
+	want := "--- a/x\n+++ b/x\n@@ -1,7 +1,7 @@\n one\n two\n three\n-four\n+FOUR\n five\n six\n seven\n"
+	if got := UnifiedDiff("a/x", "b/x", before, after); got != want {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+	if got := UnifiedDiff("a/x", "b/x", before, before); got != "" {
+		t.Fatalf("expected empty diff, got %q", got)
+	}
+	got := UnifiedDiff("a/x", "b/x", "a", "b")
+	if !strings.Contains(got, "-a\n\\ No newline at end of file\n+b\n\\ No newline at end of file\n") {
+		t.Fatalf("expected no-newline markers, got:\n%s", got)
+	}
+}
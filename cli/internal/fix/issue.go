@@ -0,0 +1,147 @@
+package fix
+
+import (
+	"path/filepath"
+	"strings"
+
+	"markdowntown-cli/internal/audit"
+	"markdowntown-cli/internal/scan"
+
+	"github.com/spf13/afero"
+)
+
+// ForIssue builds the fixes available for an audit issue.
+// Only repo-scoped, unredacted paths under repoRoot are considered.
+func ForIssue(fs afero.Fs, repoRoot string, issue audit.Issue) []Fix {
+	fixIDs := QuickFixesForIssue(issue)
+	if len(fixIDs) == 0 || repoRoot == "" {
+		return nil
+	}
+
+	evidence := issue.Evidence
+	if evidence == nil {
+		evidence = map[string]any{}
+	}
+	rng := RangeFromScan(issue.Range)
+	paths := repoPaths(repoRoot, issue.Paths)
+
+	var fixes []Fix
+	for _, fixID := range fixIDs {
+		for _, path := range targetPaths(fixID, paths) {
+			content := ""
+			if path != "" {
+				data, err := afero.ReadFile(fs, path)
+				if err != nil {
+					continue
+				}
+				content = string(data)
+			}
+			target := Target{
+				RuleID:   issue.RuleID,
+				Path:     path,
+				RepoRoot: repoRoot,
+				Content:  content,
+				Range:    rng,
+				Evidence: evidence,
+				Data:     issueData(issue),
+			}
+			if fixID == ReplaceToolID {
+				// Audit ranges point at the frontmatter key; the edit replaces its value.
+				if value, ok := frontmatterValueRange(content, "toolId"); ok {
+					target.Range = value
+				}
+			}
+			if generated := Generate(fs, fixID, target); generated != nil {
+				fixes = append(fixes, *generated)
+			}
+		}
+	}
+	return fixes
+}
+
+// issueData returns the fix inputs carried by an issue: the diagnostic data
+// map for LSP issues, or the evidence for audit issues.
+func issueData(issue audit.Issue) map[string]any {
+	if data, ok := issue.Data.(map[string]any); ok {
+		return data
+	}
+	return issue.Evidence
+}
+
+func frontmatterValueRange(content, key string) (Range, bool) {
+	parsed, ok, err := scan.ParseFrontmatter([]byte(content))
+	if err != nil || !ok || parsed == nil {
+		return Range{}, false
+	}
+	value, ok := parsed.Values[key]
+	if !ok {
+		return Range{}, false
+	}
+	return RangeFromScan(&value), true
+}
+
+// QuickFixesForIssue returns the quick fix IDs advertised by an issue's rule data.
+func QuickFixesForIssue(issue audit.Issue) []string {
+	var fixIDs []string
+	switch data := issue.Data.(type) {
+	case audit.RuleData:
+		fixIDs = data.QuickFixes
+	case *audit.RuleData:
+		if data != nil {
+			fixIDs = data.QuickFixes
+		}
+	case map[string]any:
+		fixIDs = stringSlice(data["quickFixes"])
+	}
+	if len(fixIDs) == 0 {
+		fixIDs = DefaultForRule(issue.RuleID)
+	}
+	return dedupeStrings(fixIDs)
+}
+
+// RangeFromScan converts a one-based scan range into a zero-based fix range.
+func RangeFromScan(r *scan.Range) Range {
+	if r == nil || r.StartLine <= 0 {
+		return Range{}
+	}
+	return Range{
+		Start: Position{Line: r.StartLine - 1, Character: maxInt(r.StartCol-1, 0)},
+		End:   Position{Line: maxInt(r.EndLine-1, 0), Character: maxInt(r.EndCol-1, 0)},
+	}
+}
+
+func repoPaths(repoRoot string, paths []audit.Path) []string {
+	resolved := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if path.Scope != scan.ScopeRepo || path.Redacted || path.Path == "" {
+			continue
+		}
+		value := filepath.FromSlash(path.Path)
+		if !filepath.IsAbs(value) {
+			value = filepath.Join(repoRoot, strings.TrimPrefix(value, "."+string(filepath.Separator)))
+		}
+		if _, ok := RelativeRepoPath(repoRoot, value); !ok {
+			continue
+		}
+		resolved = append(resolved, filepath.Clean(value))
+	}
+	return resolved
+}
+
+func targetPaths(fixID string, paths []string) []string {
+	switch fixID {
+	case CreateRepoConfig:
+		// The target comes from evidence; the issue paths are user/global configs.
+		return []string{""}
+	case RemoveDuplicateFrontmatter:
+		// Keep the identifier on the first config and remove it from the rest.
+		if len(paths) < 2 {
+			return nil
+		}
+		return paths[1:]
+	case DisableRule:
+		return nil
+	default:
+		return paths
+	}
+}
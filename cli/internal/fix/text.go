@@ -0,0 +1,273 @@
+package fix
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// FrontmatterBlockRange returns the range covering the leading frontmatter block.
+func FrontmatterBlockRange(content string) *Range {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(strings.TrimRight(lines[0], "\r")) != "---" {
+		return nil
+	}
+
+	endLine := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(strings.TrimRight(lines[i], "\r")) == "---" {
+			endLine = i
+			break
+		}
+	}
+	if endLine == -1 {
+		return nil
+	}
+
+	end := endLine
+	endChar := UTF16Len(lines[endLine])
+	if endLine+1 < len(lines) {
+		end = endLine + 1
+		endChar = 0
+	}
+
+	return &Range{End: Position{Line: end, Character: endChar}}
+}
+
+func frontmatterClosingLine(content string) (int, bool) {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 {
+		return 0, false
+	}
+	if strings.TrimSpace(strings.TrimRight(lines[0], "\r")) != "---" {
+		return 0, false
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(strings.TrimRight(lines[i], "\r")) == "---" {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func frontmatterHasOpeningLine(content string) bool {
+	lines := strings.Split(content, "\n")
+	if len(lines) == 0 {
+		return false
+	}
+	return strings.TrimSpace(strings.TrimRight(lines[0], "\r")) == "---"
+}
+
+// LineText returns the text of a zero-based line without its line terminator.
+func LineText(content string, line int) (string, bool) {
+	lines := strings.Split(content, "\n")
+	if line < 0 || line >= len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[line], "\r"), true
+}
+
+// LineDeleteRange returns the range that removes a whole line including its newline.
+func LineDeleteRange(content string, line int) *Range {
+	lines := strings.Split(content, "\n")
+	if line < 0 || line >= len(lines) {
+		return nil
+	}
+	if line+1 < len(lines) {
+		return &Range{Start: Position{Line: line}, End: Position{Line: line + 1}}
+	}
+	text := strings.TrimRight(lines[line], "\r")
+	return &Range{Start: Position{Line: line}, End: Position{Line: line, Character: UTF16Len(text)}}
+}
+
+// EndPosition returns the position just past the last character of content.
+func EndPosition(content string) Position {
+	lines := strings.Split(content, "\n")
+	line := len(lines) - 1
+	return Position{Line: line, Character: UTF16Len(lines[line])}
+}
+
+// TextAtRange returns the text covered by a single-line range.
+func TextAtRange(content string, rng Range) string {
+	if rng.Start.Line != rng.End.Line {
+		return ""
+	}
+	lineText, ok := LineText(content, rng.Start.Line)
+	if !ok {
+		return ""
+	}
+	runes := []rune(lineText)
+	start := rng.Start.Character
+	end := rng.End.Character
+	if start < 0 || end < 0 || start > end || end > len(runes) {
+		return ""
+	}
+	return string(runes[start:end])
+}
+
+// UTF16Len returns the length of value in UTF-16 code units.
+func UTF16Len(value string) int {
+	if value == "" {
+		return 0
+	}
+	return len(utf16.Encode([]rune(value)))
+}
+
+// OffsetForPosition converts a position into a byte offset within content.
+func OffsetForPosition(content string, pos Position) int {
+	targetLine := pos.Line
+	targetCol := pos.Character
+	if targetLine < 0 || targetCol < 0 {
+		return 0
+	}
+
+	line := 0
+	col := 0
+	offset := 0
+	for _, r := range content {
+		if line == targetLine && col >= targetCol {
+			return offset
+		}
+		if r == '\n' {
+			if line == targetLine {
+				return offset
+			}
+			line++
+			col = 0
+			offset += utf8.RuneLen(r)
+			continue
+		}
+		colWidth := utf16.RuneLen(r)
+		if colWidth < 0 {
+			colWidth = 1
+		}
+		col += colWidth
+		offset += utf8.RuneLen(r)
+		if line == targetLine && col >= targetCol {
+			return offset
+		}
+	}
+	return len(content)
+}
+
+// ApplyEdits applies non-overlapping text edits to content.
+func ApplyEdits(content string, edits []TextEdit) (string, error) {
+	type span struct {
+		start int
+		end   int
+		text  string
+		order int
+	}
+	spans := make([]span, 0, len(edits))
+	for i, edit := range edits {
+		start := OffsetForPosition(content, edit.Range.Start)
+		end := OffsetForPosition(content, edit.Range.End)
+		if start > end {
+			return content, fmt.Errorf("invalid edit range %d:%d-%d:%d", edit.Range.Start.Line, edit.Range.Start.Character, edit.Range.End.Line, edit.Range.End.Character)
+		}
+		spans = append(spans, span{start: start, end: end, text: edit.NewText, order: i})
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].order < spans[j].order
+	})
+	for i := 1; i < len(spans); i++ {
+		if spans[i].start < spans[i-1].end {
+			return content, fmt.Errorf("overlapping edits at offset %d", spans[i].start)
+		}
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, s := range spans {
+		builder.WriteString(content[last:s.start])
+		builder.WriteString(s.text)
+		last = s.end
+	}
+	builder.WriteString(content[last:])
+	return builder.String(), nil
+}
+
+// StubContentForPath returns placeholder content appropriate for the file type.
+func StubContentForPath(path string) string {
+	lower := strings.ToLower(path)
+	switch strings.ToLower(filepath.Ext(lower)) {
+	case ".md", ".markdown", ".mdx":
+		return "# Instructions\n"
+	case ".json":
+		return "{\n}\n"
+	case ".yml", ".yaml", ".toml":
+		return "# TODO: add instructions\n"
+	case ".txt":
+		return "TODO: add instructions\n"
+	default:
+		if strings.HasSuffix(lower, ".prompt.md") || strings.HasSuffix(lower, ".instructions.md") {
+			return "# Instructions\n"
+		}
+		return ""
+	}
+}
+
+// IdentifierFromPath derives a frontmatter identifier from a config path.
+func IdentifierFromPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	base := filepath.Base(path)
+	lower := strings.ToLower(base)
+	if lower == "skill.md" || lower == "skill" {
+		base = filepath.Base(filepath.Dir(path))
+	} else {
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	base = strings.TrimSuffix(base, ".prompt")
+	base = strings.TrimSuffix(base, ".instructions")
+	return strings.TrimSpace(base)
+}
+
+// HasGitignoreEntry reports whether the gitignore content already lists entry.
+func HasGitignoreEntry(content string, entry string) bool {
+	if entry == "" {
+		return true
+	}
+	lines := strings.Split(content, "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) == entry {
+			return true
+		}
+		if strings.TrimSpace(line) == "!/"+strings.TrimPrefix(entry, "!") {
+			return true
+		}
+	}
+	return false
+}
+
+// RelativeRepoPath returns path relative to repoRoot using forward slashes.
+func RelativeRepoPath(repoRoot string, path string) (string, bool) {
+	if repoRoot == "" || path == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(repoRoot, path)
+	if err != nil {
+		return "", false
+	}
+	if strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	rel = strings.TrimPrefix(rel, "./")
+	return rel, rel != ""
+}
+
+// IsGlobPath reports whether path contains glob metacharacters.
+func IsGlobPath(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
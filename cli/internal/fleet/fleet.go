@@ -0,0 +1,258 @@
+// Package fleet aggregates scan and audit results across many repositories.
+package fleet
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"markdowntown-cli/internal/audit"
+	"markdowntown-cli/internal/scan"
+)
+
+// Report is the combined scan-fleet output.
+type Report struct {
+	SchemaVersion string  `json:"schemaVersion"`
+	ToolVersion   string  `json:"toolVersion"`
+	GeneratedAt   int64   `json:"generatedAt"`
+	Rules         Rules   `json:"rules"`
+	Summary       Summary `json:"summary"`
+	Repos         []Repo  `json:"repos"`
+}
+
+// Rules records the rule packs every repo was audited with. Repo-local rule
+// packs are never loaded, since sparse clones of git URLs do not check them
+// out, so a repo audits the same whether it is listed by path or by URL.
+type Rules struct {
+	RulesDir      string `json:"rulesDir,omitempty"`
+	RepoRulePacks bool   `json:"repoRulePacks"`
+}
+
+// Repo captures the scan and audit result for one repository.
+type Repo struct {
+	Source           string               `json:"source"`
+	Remote           bool                 `json:"remote"`
+	RepoRoot         string               `json:"repoRoot,omitempty"`
+	Error            string               `json:"error,omitempty"`
+	TotalConfigs     int                  `json:"totalConfigs"`
+	ByTool           map[string]int       `json:"byTool"`
+	InstructionFiles int                  `json:"instructionFiles"`
+	InstructionBytes int64                `json:"instructionBytes"`
+	IssueCounts      audit.SeverityCounts `json:"issueCounts"`
+	IssuesByRule     map[string]int       `json:"issuesByRule"`
+	Audit            *audit.Output        `json:"audit,omitempty"`
+}
+
+// Summary aggregates adoption and audit results across repos.
+type Summary struct {
+	Repos               int                  `json:"repos"`
+	Scanned             int                  `json:"scanned"`
+	Failed              int                  `json:"failed"`
+	ToolAdoption        map[string]int       `json:"toolAdoption"`
+	IssueCounts         audit.SeverityCounts `json:"issueCounts"`
+	IssuesByRule        map[string]int       `json:"issuesByRule"`
+	MissingInstructions []string             `json:"missingInstructions"`
+	SizeOutliers        []Outlier            `json:"sizeOutliers"`
+}
+
+// Outlier flags a repo whose total instruction size falls outside the
+// interquartile fences of the fleet.
+type Outlier struct {
+	Source           string `json:"source"`
+	InstructionBytes int64  `json:"instructionBytes"`
+	// Direction is "high" or "low".
+	Direction string `json:"direction"`
+}
+
+// instructionKinds are the pattern kinds counted as instructions.
+var instructionKinds = map[string]bool{"instructions": true, "rules": true}
+
+// minOutlierRepos is the smallest sample for which outliers are reported.
+const minOutlierRepos = 4
+
+var scpLikeURL = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9._-]+:`)
+
+// IsRemote reports whether source is a git URL rather than a local path.
+func IsRemote(source string) bool {
+	return strings.Contains(source, "://") || scpLikeURL.MatchString(source)
+}
+
+// ParseReposFile reads one repo per line. Blank lines and lines starting with
+// # are skipped, and duplicates are dropped.
+func ParseReposFile(r io.Reader) ([]string, error) {
+	var sources []string
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, ok := seen[line]; ok {
+			continue
+		}
+		seen[line] = struct{}{}
+		sources = append(sources, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// Run calls fn for each source with at most limit calls in flight and returns
+// the results in source order.
+func Run(sources []string, limit int, fn func(source string) Repo) []Repo {
+	if limit < 1 {
+		limit = 1
+	}
+	results := make([]Repo, len(sources))
+	var group errgroup.Group
+	group.SetLimit(limit)
+	for i, source := range sources {
+		group.Go(func() error {
+			results[i] = fn(source)
+			return nil
+		})
+	}
+	_ = group.Wait()
+	return results
+}
+
+// NewRepo summarizes the scan and audit output for one repository.
+func NewRepo(source string, output scan.Output, auditOutput audit.Output) Repo {
+	repo := Repo{
+		Source:       source,
+		Remote:       IsRemote(source),
+		RepoRoot:     output.RepoRoot,
+		ByTool:       map[string]int{},
+		IssueCounts:  auditOutput.Summary.IssueCounts,
+		IssuesByRule: map[string]int{},
+		Audit:        &auditOutput,
+	}
+	if output.Summary != nil {
+		repo.TotalConfigs = output.Summary.TotalConfigs
+		for tool, count := range output.Summary.ByTool {
+			repo.ByTool[tool] = count
+		}
+	}
+	for _, entry := range output.Configs {
+		if !isInstruction(entry) {
+			continue
+		}
+		repo.InstructionFiles++
+		if entry.SizeBytes != nil {
+			repo.InstructionBytes += *entry.SizeBytes
+		}
+	}
+	for _, issue := range auditOutput.Issues {
+		repo.IssuesByRule[issue.RuleID]++
+	}
+	return repo
+}
+
+// FailedRepo records a repository that could not be scanned or audited.
+func FailedRepo(source string, err error) Repo {
+	return Repo{
+		Source:       source,
+		Remote:       IsRemote(source),
+		Error:        err.Error(),
+		ByTool:       map[string]int{},
+		IssuesByRule: map[string]int{},
+	}
+}
+
+func isInstruction(entry scan.ConfigEntry) bool {
+	for _, tool := range entry.Tools {
+		if instructionKinds[tool.Kind] {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildSummary aggregates per-repo results. Tool adoption counts repos, not
+// files.
+func BuildSummary(repos []Repo) Summary {
+	summary := Summary{
+		Repos:               len(repos),
+		ToolAdoption:        map[string]int{},
+		IssuesByRule:        map[string]int{},
+		MissingInstructions: []string{},
+		SizeOutliers:        []Outlier{},
+	}
+	var sized []Repo
+	for _, repo := range repos {
+		if repo.Error != "" {
+			summary.Failed++
+			continue
+		}
+		summary.Scanned++
+		for tool, count := range repo.ByTool {
+			if count > 0 {
+				summary.ToolAdoption[tool]++
+			}
+		}
+		for rule, count := range repo.IssuesByRule {
+			summary.IssuesByRule[rule] += count
+		}
+		summary.IssueCounts.Error += repo.IssueCounts.Error
+		summary.IssueCounts.Warning += repo.IssueCounts.Warning
+		summary.IssueCounts.Info += repo.IssueCounts.Info
+		if repo.InstructionFiles == 0 {
+			summary.MissingInstructions = append(summary.MissingInstructions, repo.Source)
+			continue
+		}
+		sized = append(sized, repo)
+	}
+	sort.Strings(summary.MissingInstructions)
+	summary.SizeOutliers = sizeOutliers(sized)
+	return summary
+}
+
+// sizeOutliers applies Tukey fences (1.5 x IQR) to instruction sizes.
+func sizeOutliers(repos []Repo) []Outlier {
+	outliers := []Outlier{}
+	if len(repos) < minOutlierRepos {
+		return outliers
+	}
+	sizes := make([]float64, len(repos))
+	for i, repo := range repos {
+		sizes[i] = float64(repo.InstructionBytes)
+	}
+	sort.Float64s(sizes)
+	q1 := quantile(sizes, 0.25)
+	q3 := quantile(sizes, 0.75)
+	spread := 1.5 * (q3 - q1)
+	for _, repo := range repos {
+		size := float64(repo.InstructionBytes)
+		switch {
+		case size > q3+spread:
+			outliers = append(outliers, Outlier{Source: repo.Source, InstructionBytes: repo.InstructionBytes, Direction: "high"})
+		case size < q1-spread:
+			outliers = append(outliers, Outlier{Source: repo.Source, InstructionBytes: repo.InstructionBytes, Direction: "low"})
+		}
+	}
+	sort.Slice(outliers, func(i, j int) bool {
+		if outliers[i].InstructionBytes != outliers[j].InstructionBytes {
+			return outliers[i].InstructionBytes > outliers[j].InstructionBytes
+		}
+		return outliers[i].Source < outliers[j].Source
+	})
+	return outliers
+}
+
+// quantile interpolates linearly between the closest ranks of sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,153 @@
+package fleet
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"markdowntown-cli/internal/audit"
+	"markdowntown-cli/internal/scan"
+)
+
+func TestParseReposFile(t *testing.T) {
+	input := "# fleet\n./app\n\nhttps://example.com/org/api.git\n./app\n  git@example.com:org/web.git  \n"
+	got, err := ParseReposFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseReposFile: %v", err)
+	}
+	want := []string{"./app", "https://example.com/org/api.git", "git@example.com:org/web.git"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseReposFile = %v, want %v", got, want)
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	cases := map[string]bool{
+		"https://example.com/org/repo.git": true,
+		"file:///srv/repo.git":             true,
+		"git@example.com:org/repo.git":     true,
+		"./repo":                           false,
+		"/srv/repos/app":                   false,
+		"C:/repos/app":                     false,
+	}
+	for source, want := range cases {
+		if got := IsRemote(source); got != want {
+			t.Errorf("IsRemote(%q) = %v, want %v", source, got, want)
+		}
+	}
+}
+
+func TestRunBoundsConcurrencyAndKeepsOrder(t *testing.T) {
+	sources := []string{"a", "b", "c", "d", "e", "f"}
+	var inFlight, peak atomic.Int32
+	repos := Run(sources, 2, func(source string) Repo {
+		current := inFlight.Add(1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		defer inFlight.Add(-1)
+		return Repo{Source: source}
+	})
+	if peak.Load() > 2 {
+		t.Fatalf("expected at most 2 concurrent calls, got %d", peak.Load())
+	}
+	for i, repo := range repos {
+		if repo.Source != sources[i] {
+			t.Fatalf("expected results in source order, got %v at %d", repo.Source, i)
+		}
+	}
+}
+
+func TestNewRepo(t *testing.T) {
+	size := int64(120)
+	output := scan.Output{
+		RepoRoot: "/repo",
+		Summary:  &scan.Summary{TotalConfigs: 2, ByTool: map[string]int{"codex": 1, "cursor": 1}},
+		Configs: []scan.ConfigEntry{
+			{Path: "/repo/AGENTS.md", SizeBytes: &size, Tools: []scan.ToolEntry{{ToolID: "codex", Kind: "instructions"}}},
+			{Path: "/repo/.cursor/mcp.json", Tools: []scan.ToolEntry{{ToolID: "cursor", Kind: "config"}}},
+		},
+	}
+	auditOutput := audit.Output{
+		Summary: audit.Summary{IssueCounts: audit.SeverityCounts{Warning: 2}},
+		Issues:  []audit.Issue{{RuleID: "MD004"}, {RuleID: "MD004"}},
+	}
+	repo := NewRepo("./repo", output, auditOutput)
+	if repo.InstructionFiles != 1 || repo.InstructionBytes != 120 {
+		t.Fatalf("unexpected instruction totals: %+v", repo)
+	}
+	if repo.IssuesByRule["MD004"] != 2 || repo.IssueCounts.Warning != 2 {
+		t.Fatalf("unexpected issue totals: %+v", repo)
+	}
+	if repo.ByTool["cursor"] != 1 || repo.Remote {
+		t.Fatalf("unexpected repo fields: %+v", repo)
+	}
+}
+
+func TestBuildSummary(t *testing.T) {
+	repos := []Repo{
+		{Source: "a", ByTool: map[string]int{"codex": 2}, InstructionFiles: 1, InstructionBytes: 100, IssuesByRule: map[string]int{"MD001": 1}, IssueCounts: audit.SeverityCounts{Error: 1}},
+		{Source: "b", ByTool: map[string]int{"codex": 1, "claude-code": 1}, InstructionFiles: 1, InstructionBytes: 110, IssuesByRule: map[string]int{"MD001": 2}},
+		{Source: "c", ByTool: map[string]int{"claude-code": 1}, InstructionFiles: 1, InstructionBytes: 120, IssuesByRule: map[string]int{}},
+		{Source: "d", ByTool: map[string]int{"codex": 1}, InstructionFiles: 1, InstructionBytes: 130, IssuesByRule: map[string]int{}},
+		{Source: "e", ByTool: map[string]int{"codex": 1}, InstructionFiles: 2, InstructionBytes: 5000, IssuesByRule: map[string]int{}},
+		{Source: "f", ByTool: map[string]int{}, IssuesByRule: map[string]int{}},
+		FailedRepo("g", errors.New("clone failed\ndetails")),
+	}
+	summary := BuildSummary(repos)
+	if summary.Repos != 7 || summary.Scanned != 6 || summary.Failed != 1 {
+		t.Fatalf("unexpected counts: %+v", summary)
+	}
+	if summary.ToolAdoption["codex"] != 4 || summary.ToolAdoption["claude-code"] != 2 {
+		t.Fatalf("unexpected adoption: %v", summary.ToolAdoption)
+	}
+	if summary.IssuesByRule["MD001"] != 3 || summary.IssueCounts.Error != 1 {
+		t.Fatalf("unexpected issue totals: %+v", summary)
+	}
+	if !reflect.DeepEqual(summary.MissingInstructions, []string{"f"}) {
+		t.Fatalf("unexpected missing instructions: %v", summary.MissingInstructions)
+	}
+	want := []Outlier{{Source: "e", InstructionBytes: 5000, Direction: "high"}}
+	if !reflect.DeepEqual(summary.SizeOutliers, want) {
+		t.Fatalf("unexpected outliers: %+v", summary.SizeOutliers)
+	}
+
+	report := Report{Rules: Rules{RulesDir: "org-rules"}, Summary: summary, Repos: repos}
+	md := RenderMarkdown(report)
+	for _, fragment := range []string{"Repos: 6 scanned, 1 failed", "Rules: built-in + org-rules (repo rule packs ignored)", "| codex | 4 |", "## Missing instructions\n\n- f", "- e: 5000 bytes (high)", "- g: clone failed\n"} {
+		if !strings.Contains(md, fragment) {
+			t.Fatalf("markdown missing %q:\n%s", fragment, md)
+		}
+	}
+
+	var csvOut bytes.Buffer
+	if err := WriteCSV(&csvOut, report); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != len(repos)+1 {
+		t.Fatalf("expected header plus %d rows, got %d", len(repos), len(lines))
+	}
+	if lines[2] != "b,ok,0,1,110,0,0,0,claude-code=1;codex=1,false,," {
+		t.Fatalf("unexpected csv row: %s", lines[2])
+	}
+	if !strings.HasPrefix(lines[7], "g,failed,") || !strings.HasSuffix(lines[7], ",clone failed") {
+		t.Fatalf("unexpected failed row: %s", lines[7])
+	}
+}
+
+func TestBuildSummarySkipsOutliersForSmallFleets(t *testing.T) {
+	repos := []Repo{
+		{Source: "a", InstructionFiles: 1, InstructionBytes: 10},
+		{Source: "b", InstructionFiles: 1, InstructionBytes: 10000},
+	}
+	if outliers := BuildSummary(repos).SizeOutliers; len(outliers) != 0 {
+		t.Fatalf("expected no outliers for small fleet, got %+v", outliers)
+	}
+}
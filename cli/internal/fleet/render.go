@@ -0,0 +1,153 @@
+package fleet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RenderMarkdown renders the fleet summary as deterministic Markdown.
+func RenderMarkdown(report Report) string {
+	var builder strings.Builder
+	summary := report.Summary
+	builder.WriteString("# markdowntown fleet\n\n")
+	_, _ = fmt.Fprintf(&builder, "Repos: %d scanned, %d failed\n", summary.Scanned, summary.Failed)
+	counts := summary.IssueCounts
+	_, _ = fmt.Fprintf(&builder, "Issues: %d errors, %d warnings, %d info\n", counts.Error, counts.Warning, counts.Info)
+	rules := "built-in"
+	if report.Rules.RulesDir != "" {
+		rules += " + " + report.Rules.RulesDir
+	}
+	if !report.Rules.RepoRulePacks {
+		rules += " (repo rule packs ignored)"
+	}
+	_, _ = fmt.Fprintf(&builder, "Rules: %s\n", rules)
+
+	builder.WriteString("\n## Tool adoption\n\n")
+	if len(summary.ToolAdoption) == 0 {
+		builder.WriteString("No tools detected.\n")
+	} else {
+		builder.WriteString("| Tool | Repos |\n| --- | --- |\n")
+		for _, key := range sortedByCount(summary.ToolAdoption) {
+			_, _ = fmt.Fprintf(&builder, "| %s | %d |\n", key, summary.ToolAdoption[key])
+		}
+	}
+
+	if len(summary.IssuesByRule) > 0 {
+		builder.WriteString("\n## Issues by rule\n\n")
+		builder.WriteString("| Rule | Issues |\n| --- | --- |\n")
+		for _, key := range sortedByCount(summary.IssuesByRule) {
+			_, _ = fmt.Fprintf(&builder, "| %s | %d |\n", key, summary.IssuesByRule[key])
+		}
+	}
+
+	if len(summary.MissingInstructions) > 0 {
+		builder.WriteString("\n## Missing instructions\n\n")
+		for _, source := range summary.MissingInstructions {
+			builder.WriteString("- " + source + "\n")
+		}
+	}
+
+	if len(summary.SizeOutliers) > 0 {
+		builder.WriteString("\n## Instruction size outliers\n\n")
+		for _, outlier := range summary.SizeOutliers {
+			_, _ = fmt.Fprintf(&builder, "- %s: %d bytes (%s)\n", outlier.Source, outlier.InstructionBytes, outlier.Direction)
+		}
+	}
+
+	if summary.Failed > 0 {
+		builder.WriteString("\n## Failed\n\n")
+		for _, repo := range report.Repos {
+			if repo.Error != "" {
+				builder.WriteString("- " + repo.Source + ": " + firstLine(repo.Error) + "\n")
+			}
+		}
+	}
+
+	builder.WriteString("\n## Repos\n\n")
+	builder.WriteString("| Repo | Configs | Instruction bytes | Errors | Warnings | Info | Tools |\n")
+	builder.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+	for _, repo := range report.Repos {
+		if repo.Error != "" {
+			continue
+		}
+		_, _ = fmt.Fprintf(&builder, "| %s | %d | %d | %d | %d | %d | %s |\n",
+			repo.Source, repo.TotalConfigs, repo.InstructionBytes,
+			repo.IssueCounts.Error, repo.IssueCounts.Warning, repo.IssueCounts.Info,
+			strings.Join(sortedKeys(repo.ByTool), ", "))
+	}
+	return builder.String()
+}
+
+// WriteCSV writes one row per repo.
+func WriteCSV(w io.Writer, report Report) error {
+	missing := make(map[string]bool, len(report.Summary.MissingInstructions))
+	for _, source := range report.Summary.MissingInstructions {
+		missing[source] = true
+	}
+	outliers := make(map[string]string, len(report.Summary.SizeOutliers))
+	for _, outlier := range report.Summary.SizeOutliers {
+		outliers[outlier.Source] = outlier.Direction
+	}
+
+	writer := csv.NewWriter(w)
+	header := []string{"source", "status", "total_configs", "instruction_files", "instruction_bytes", "errors", "warnings", "info", "tools", "missing_instructions", "size_outlier", "error"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, repo := range report.Repos {
+		status := "ok"
+		if repo.Error != "" {
+			status = "failed"
+		}
+		tools := make([]string, 0, len(repo.ByTool))
+		for _, tool := range sortedKeys(repo.ByTool) {
+			tools = append(tools, tool+"="+strconv.Itoa(repo.ByTool[tool]))
+		}
+		row := []string{
+			repo.Source,
+			status,
+			strconv.Itoa(repo.TotalConfigs),
+			strconv.Itoa(repo.InstructionFiles),
+			strconv.FormatInt(repo.InstructionBytes, 10),
+			strconv.Itoa(repo.IssueCounts.Error),
+			strconv.Itoa(repo.IssueCounts.Warning),
+			strconv.Itoa(repo.IssueCounts.Info),
+			strings.Join(tools, ";"),
+			strconv.FormatBool(missing[repo.Source]),
+			outliers[repo.Source],
+			firstLine(repo.Error),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func sortedKeys(values map[string]int) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedByCount orders keys by descending count, then name.
+func sortedByCount(values map[string]int) []string {
+	keys := sortedKeys(values)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return values[keys[i]] > values[keys[j]]
+	})
+	return keys
+}
+
+func firstLine(value string) string {
+	line, _, _ := strings.Cut(value, "\n")
+	return line
+}
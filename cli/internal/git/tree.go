@@ -0,0 +1,175 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tree entry modes reported by git ls-tree.
+const (
+	ModeSymlink   = "120000"
+	ModeSubmodule = "160000"
+)
+
+// TreeEntry is a file listed by ListTree. Submodules are reported with
+// ModeSubmodule and an empty Object.
+type TreeEntry struct {
+	Path   string
+	Mode   string
+	Object string
+}
+
+// ResolveCommit returns the full commit hash for a revision.
+func ResolveCommit(repoRoot string, ref string) (string, error) {
+	if strings.TrimSpace(ref) == "" || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid git ref: %q", ref)
+	}
+	stdout, err := runGit(repoRoot, nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown git ref %q: %w", ref, err)
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+// ListTree returns every file in a commit, with slash-separated paths
+// relative to the repository root.
+func ListTree(repoRoot string, commit string) ([]TreeEntry, error) {
+	stdout, err := runGit(repoRoot, nil, "ls-tree", "-r", "-z", "--full-tree", commit)
+	if err != nil {
+		return nil, err
+	}
+	var entries []TreeEntry
+	for _, record := range strings.Split(stdout, "\x00") {
+		if record == "" {
+			continue
+		}
+		meta, path, ok := strings.Cut(record, "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected ls-tree output: %q", record)
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected ls-tree output: %q", record)
+		}
+		entry := TreeEntry{Path: path, Mode: fields[0], Object: fields[2]}
+		if fields[1] == "commit" {
+			entry.Mode = ModeSubmodule
+			entry.Object = ""
+		} else if fields[1] != "blob" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// CommitTime returns the committer timestamp of a commit.
+func CommitTime(repoRoot string, commit string) (time.Time, error) {
+	stdout, err := runGit(repoRoot, nil, "show", "-s", "--format=%ct", commit)
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(stdout), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected commit time %q: %w", strings.TrimSpace(stdout), err)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// ReadBlobs streams the content of each object through a single
+// git cat-file --batch process, calling fn in request order.
+func ReadBlobs(repoRoot string, objects []string, fn func(object string, data []byte) error) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	cmd := exec.Command("git", "cat-file", "--batch")
+	cmd.Dir = repoRoot
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return ErrGitNotFound
+		}
+		return err
+	}
+
+	writeErr := make(chan error, 1)
+	go func() {
+		writer := bufio.NewWriter(stdin)
+		for _, object := range objects {
+			if _, err := writer.WriteString(object + "\n"); err != nil {
+				writeErr <- err
+				_ = stdin.Close()
+				return
+			}
+		}
+		err := writer.Flush()
+		if closeErr := stdin.Close(); err == nil {
+			err = closeErr
+		}
+		writeErr <- err
+	}()
+
+	readErr := readBatch(bufio.NewReader(stdout), objects, fn)
+	if readErr != nil {
+		// Drain so the writer and git can exit before Wait.
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+	waitErr := cmd.Wait()
+	if err := <-writeErr; err != nil && readErr == nil && waitErr == nil {
+		return err
+	}
+	if readErr != nil {
+		return readErr
+	}
+	if waitErr != nil {
+		return &commandError{command: "cat-file --batch", exitCode: cmd.ProcessState.ExitCode(), stderr: strings.TrimSpace(stderr.String()), cause: waitErr}
+	}
+	return nil
+}
+
+func readBatch(reader *bufio.Reader, objects []string, fn func(object string, data []byte) error) error {
+	for _, object := range objects {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("cat-file %s: %w", object, err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) == 2 && fields[1] == "missing" {
+			return fmt.Errorf("git object %s missing", object)
+		}
+		if len(fields) != 3 {
+			return fmt.Errorf("unexpected cat-file header: %q", strings.TrimSpace(header))
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil || size < 0 {
+			return fmt.Errorf("unexpected cat-file header: %q", strings.TrimSpace(header))
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return fmt.Errorf("cat-file %s: %w", object, err)
+		}
+		if _, err := reader.Discard(1); err != nil {
+			return fmt.Errorf("cat-file %s: %w", object, err)
+		}
+		if err := fn(object, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
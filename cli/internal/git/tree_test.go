@@ -0,0 +1,81 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestListTreeAndReadBlobs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	execGit(t, repo, "init")
+	execGit(t, repo, "config", "user.email", "test@example.com")
+	execGit(t, repo, "config", "user.name", "Test")
+	if err := os.MkdirAll(filepath.Join(repo, "docs"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFile(t, filepath.Join(repo, "AGENTS.md"), "root")
+	writeFile(t, filepath.Join(repo, "docs", "AGENTS.md"), "root")
+	writeFile(t, filepath.Join(repo, "docs", "empty.md"), "")
+	execGit(t, repo, "add", ".")
+	execGit(t, repo, "commit", "-m", "first")
+
+	commit, err := ResolveCommit(repo, "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveCommit: %v", err)
+	}
+	entries, err := ListTree(repo, commit)
+	if err != nil {
+		t.Fatalf("ListTree: %v", err)
+	}
+	if len(entries) != 3 || entries[0].Path != "AGENTS.md" || entries[1].Path != "docs/AGENTS.md" {
+		t.Fatalf("unexpected entries: %#v", entries)
+	}
+	if entries[0].Object != entries[1].Object {
+		t.Fatalf("expected identical content to share a blob")
+	}
+
+	objects := []string{entries[0].Object, entries[2].Object}
+	var got []string
+	err = ReadBlobs(repo, objects, func(object string, data []byte) error {
+		got = append(got, object+"="+string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadBlobs: %v", err)
+	}
+	want := []string{entries[0].Object + "=root", entries[2].Object + "="}
+	if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if _, err := CommitTime(repo, commit); err != nil {
+		t.Fatalf("CommitTime: %v", err)
+	}
+}
+
+func TestReadBlobsMissingObject(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	execGit(t, repo, "init")
+	err := ReadBlobs(repo, []string{"0123456789012345678901234567890123456789"}, func(string, []byte) error {
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("expected missing object error")
+	}
+}
+
+func TestResolveCommitRejectsOptions(t *testing.T) {
+	if _, err := ResolveCommit(t.TempDir(), "--output=x"); err == nil {
+		t.Fatalf("expected option-like ref to be rejected")
+	}
+}
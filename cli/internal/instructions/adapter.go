@@ -4,6 +4,8 @@ package instructions
 import (
 	"fmt"
 	"strings"
+
+	"github.com/spf13/afero"
 )
 
 // Client identifies a supported instruction client.
@@ -89,6 +91,11 @@ type ResolveOptions struct {
 	Cwd        string
 	TargetPath string
 	Settings   map[string]bool
+	// Fs supplies instruction files; nil reads the host filesystem. User
+	// scope files are only found if Fs contains them.
+	Fs afero.Fs
+	// CheckIgnore reports gitignored paths; nil runs git check-ignore.
+	CheckIgnore func(repoRoot string, paths []string) (map[string]bool, error)
 }
 
 // Resolution captures the resolved instruction chain and metadata.
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/spf13/afero"
+
 	"markdowntown-cli/internal/scan"
 )
 
@@ -46,6 +48,7 @@ func (a ClaudeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		maxDepth = defaultClaudeMaxImportDepth
 	}
 
+	fsys := fileSystem(opts)
 	targetRel, err := ensureTargetRel(repoRoot, targetPath)
 	if err != nil {
 		return res, err
@@ -57,7 +60,7 @@ func (a ClaudeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 	userHome, err := os.UserHomeDir()
 	if err == nil {
 		userClaude := filepath.Join(userHome, ".claude", claudeFile)
-		userFile, err := instructionFileWithScope(userClaude, ScopeUser, ReasonPrimary)
+		userFile, err := instructionFileWithScope(fsys, userClaude, ScopeUser, ReasonPrimary)
 		if err != nil {
 			return res, err
 		}
@@ -66,7 +69,7 @@ func (a ClaudeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		}
 
 		userRulesDir := filepath.Join(userHome, ".claude", claudeRulesFolder)
-		userRules, warnings, err := collectClaudeRules(userRulesDir, targetRel, ScopeUser, maxDepth)
+		userRules, warnings, err := collectClaudeRules(fsys, userRulesDir, targetRel, ScopeUser, maxDepth)
 		if err != nil {
 			return res, err
 		}
@@ -84,7 +87,7 @@ func (a ClaudeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		return res, err
 	}
 	for _, dir := range dirs {
-		projectFile, err := instructionFileWithScope(filepath.Join(dir, claudeFile), ScopeRepo, ReasonPrimary)
+		projectFile, err := instructionFileWithScope(fsys, filepath.Join(dir, claudeFile), ScopeRepo, ReasonPrimary)
 		if err != nil {
 			return res, err
 		}
@@ -94,14 +97,14 @@ func (a ClaudeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 	}
 
 	projectRulesDir := filepath.Join(repoRoot, ".claude", claudeRulesFolder)
-	projectRules, warnings, err := collectClaudeRules(projectRulesDir, targetRel, ScopeRepo, maxDepth)
+	projectRules, warnings, err := collectClaudeRules(fsys, projectRulesDir, targetRel, ScopeRepo, maxDepth)
 	if err != nil {
 		return res, err
 	}
 	res.Warnings = append(res.Warnings, warnings...)
 	res.Applied = append(res.Applied, projectRules...)
 
-	localFile, err := instructionFileWithScope(filepath.Join(repoRoot, claudeLocalFile), ScopeRepo, ReasonPrimary)
+	localFile, err := instructionFileWithScope(fsys, filepath.Join(repoRoot, claudeLocalFile), ScopeRepo, ReasonPrimary)
 	if err != nil {
 		return res, err
 	}
@@ -113,8 +116,8 @@ func (a ClaudeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 	return res, nil
 }
 
-func collectClaudeRules(rootDir, targetRel string, scope Scope, maxDepth int) ([]InstructionFile, []string, error) {
-	info, err := os.Stat(rootDir)
+func collectClaudeRules(fsys afero.Fs, rootDir, targetRel string, scope Scope, maxDepth int) ([]InstructionFile, []string, error) {
+	info, err := fsys.Stat(rootDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil, nil
@@ -129,7 +132,7 @@ func collectClaudeRules(rootDir, targetRel string, scope Scope, maxDepth int) ([
 	var files []InstructionFile
 	var warnings []string
 
-	err = filepath.WalkDir(rootDir, func(path string, entry os.DirEntry, walkErr error) error {
+	err = afero.Walk(fsys, rootDir, func(path string, entry os.FileInfo, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
@@ -139,7 +142,7 @@ func collectClaudeRules(rootDir, targetRel string, scope Scope, maxDepth int) ([
 		if !strings.HasSuffix(entry.Name(), ".md") {
 			return nil
 		}
-		items, warns, err := loadClaudeRule(rootDir, path, targetRel, scope, maxDepth, visited, 0)
+		items, warns, err := loadClaudeRule(fsys, rootDir, path, targetRel, scope, maxDepth, visited, 0)
 		if err != nil {
 			return err
 		}
@@ -154,14 +157,14 @@ func collectClaudeRules(rootDir, targetRel string, scope Scope, maxDepth int) ([
 	return files, warnings, nil
 }
 
-func loadClaudeRule(rootDir, path, targetRel string, scope Scope, maxDepth int, visited map[string]struct{}, depth int) ([]InstructionFile, []string, error) {
+func loadClaudeRule(fsys afero.Fs, rootDir, path, targetRel string, scope Scope, maxDepth int, visited map[string]struct{}, depth int) ([]InstructionFile, []string, error) {
 	if _, ok := visited[path]; ok {
 		return nil, []string{"claude rule import cycle: " + path}, nil
 	}
 	visited[path] = struct{}{}
 
 	// #nosec G304 -- path comes from validated instruction discovery.
-	content, err := os.ReadFile(path)
+	content, err := afero.ReadFile(fsys, path)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -179,7 +182,7 @@ func loadClaudeRule(rootDir, path, targetRel string, scope Scope, maxDepth int,
 		return nil, nil, nil
 	}
 
-	file, err := instructionFileWithScope(path, scope, ReasonPrimary)
+	file, err := instructionFileWithScope(fsys, path, scope, ReasonPrimary)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -204,7 +207,7 @@ func loadClaudeRule(rootDir, path, targetRel string, scope Scope, maxDepth int,
 			warnings = append(warnings, "claude import outside rules dir: "+imp)
 			continue
 		}
-		items, warns, err := loadClaudeRule(rootDir, resolved, targetRel, scope, maxDepth, visited, depth+1)
+		items, warns, err := loadClaudeRule(fsys, rootDir, resolved, targetRel, scope, maxDepth, visited, depth+1)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -10,6 +10,8 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+
+	"github.com/spf13/afero"
 )
 
 const (
@@ -41,12 +43,13 @@ func (CodexAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		return Resolution{}, err
 	}
 
+	fsys := fileSystem(opts)
 	codexHome, err := resolveCodexHome()
 	if err != nil {
 		return Resolution{}, err
 	}
 
-	cfg, cfgPath, err := loadCodexConfig(codexHome)
+	cfg, cfgPath, err := loadCodexConfig(fsys, codexHome)
 	if err != nil {
 		return Resolution{}, err
 	}
@@ -72,7 +75,7 @@ func (CodexAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 	}
 
 	if codexHome != "" {
-		userInstruction, err := resolveCodexInstruction(codexHome, ScopeUser, nil, 0, false)
+		userInstruction, err := resolveCodexInstruction(fsys, codexHome, ScopeUser, nil, 0, false)
 		if err != nil {
 			return resolution, err
 		}
@@ -87,7 +90,7 @@ func (CodexAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 	}
 
 	for _, dir := range dirs {
-		instruction, err := resolveCodexInstruction(dir, ScopeRepo, cfg.ProjectDocFallbackFilenames, cfg.ProjectDocMaxBytes, true)
+		instruction, err := resolveCodexInstruction(fsys, dir, ScopeRepo, cfg.ProjectDocFallbackFilenames, cfg.ProjectDocMaxBytes, true)
 		if err != nil {
 			return resolution, err
 		}
@@ -117,7 +120,7 @@ func resolveCodexHome() (string, error) {
 	return filepath.Join(home, ".codex"), nil
 }
 
-func loadCodexConfig(codexHome string) (codexConfig, string, error) {
+func loadCodexConfig(fsys afero.Fs, codexHome string) (codexConfig, string, error) {
 	cfg := codexConfig{
 		ProjectDocMaxBytes: defaultCodexProjectDocMaxBytes,
 		MaxBytesSource:     "default",
@@ -129,7 +132,7 @@ func loadCodexConfig(codexHome string) (codexConfig, string, error) {
 
 	path := filepath.Join(codexHome, codexConfigFilename)
 	// #nosec G304 -- path is derived from codex home/config file.
-	data, err := os.ReadFile(path)
+	data, err := afero.ReadFile(fsys, path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return cfg, "", nil
@@ -238,7 +241,7 @@ func parseStringArray(value string) ([]string, error) {
 	return out, nil
 }
 
-func resolveCodexInstruction(dir string, scope Scope, fallback []string, maxBytes int64, allowFallback bool) (*InstructionFile, error) {
+func resolveCodexInstruction(fsys afero.Fs, dir string, scope Scope, fallback []string, maxBytes int64, allowFallback bool) (*InstructionFile, error) {
 	candidates := []struct {
 		name   string
 		reason InstructionReason
@@ -262,7 +265,7 @@ func resolveCodexInstruction(dir string, scope Scope, fallback []string, maxByte
 
 	for _, candidate := range candidates {
 		path := filepath.Join(dir, candidate.name)
-		info, err := os.Stat(path)
+		info, err := fsys.Stat(path)
 		if err != nil {
 			if os.IsNotExist(err) {
 				continue
@@ -355,7 +358,7 @@ func normalizeResolvePaths(opts ResolveOptions) (string, string, string, error)
 	}
 
 	if repoRoot != "" {
-		info, err := os.Stat(repoRoot)
+		info, err := fileSystem(opts).Stat(repoRoot)
 		if err != nil {
 			return "", "", "", err
 		}
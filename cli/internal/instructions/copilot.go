@@ -37,13 +37,14 @@ func (a CopilotAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		agent = defaultCopilotAgent
 	}
 
+	fsys := fileSystem(opts)
 	targetRel, err := ensureTargetRel(repoRoot, targetPath)
 	if err != nil {
 		return res, err
 	}
 
 	repoWidePath := repoWideCopilotFile(repoRoot)
-	repoWideFile, err := instructionFile(repoWidePath, ReasonPrimary)
+	repoWideFile, err := instructionFile(fsys, repoWidePath, ReasonPrimary)
 	if err != nil {
 		return res, err
 	}
@@ -51,7 +52,7 @@ func (a CopilotAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		res.Applied = append(res.Applied, *repoWideFile)
 	}
 
-	scopedFiles, warnings, err := collectInstructionFiles(instructionDir(repoRoot), filepath.ToSlash(targetRel), agent)
+	scopedFiles, warnings, err := collectInstructionFiles(fsys, instructionDir(repoRoot), filepath.ToSlash(targetRel), agent)
 	if err != nil {
 		return res, err
 	}
@@ -72,7 +73,7 @@ func (a CopilotAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		targetDir = filepath.Dir(targetPath)
 	}
 
-	agentFile, err := nearestAncestorFile(targetDir, repoRoot, "AGENTS.md", ReasonPrimary)
+	agentFile, err := nearestAncestorFile(fsys, targetDir, repoRoot, "AGENTS.md", ReasonPrimary)
 	if err != nil {
 		return res, err
 	}
@@ -80,7 +81,7 @@ func (a CopilotAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		res.Applied = append(res.Applied, *agentFile)
 	}
 
-	claudeFile, err := instructionFile(filepath.Join(repoRoot, "CLAUDE.md"), ReasonPrimary)
+	claudeFile, err := instructionFile(fsys, filepath.Join(repoRoot, "CLAUDE.md"), ReasonPrimary)
 	if err != nil {
 		return res, err
 	}
@@ -88,7 +89,7 @@ func (a CopilotAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		res.Applied = append(res.Applied, *claudeFile)
 	}
 
-	geminiFile, err := instructionFile(filepath.Join(repoRoot, "GEMINI.md"), ReasonPrimary)
+	geminiFile, err := instructionFile(fsys, filepath.Join(repoRoot, "GEMINI.md"), ReasonPrimary)
 	if err != nil {
 		return res, err
 	}
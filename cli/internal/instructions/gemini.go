@@ -1,13 +1,14 @@
 package instructions
 
 import (
-	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/spf13/afero"
+
 	"markdowntown-cli/internal/git"
 )
 
@@ -50,12 +51,13 @@ func (a GeminiAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		OrderGuarantee: OrderDeterministic,
 	}
 
+	fsys := fileSystem(opts)
 	filenames := normalizeGeminiFilenames(a.Filenames)
 
 	if home, err := os.UserHomeDir(); err == nil {
 		userDir := filepath.Join(home, geminiDirName)
 		for _, name := range filenames {
-			file, err := instructionFileWithScope(filepath.Join(userDir, name), ScopeUser, ReasonPrimary)
+			file, err := instructionFileWithScope(fsys, filepath.Join(userDir, name), ScopeUser, ReasonPrimary)
 			if err != nil {
 				return res, err
 			}
@@ -71,7 +73,7 @@ func (a GeminiAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 	}
 	for _, dir := range dirs {
 		for _, name := range filenames {
-			file, err := instructionFileWithScope(filepath.Join(dir, name), ScopeRepo, ReasonPrimary)
+			file, err := instructionFileWithScope(fsys, filepath.Join(dir, name), ScopeRepo, ReasonPrimary)
 			if err != nil {
 				return res, err
 			}
@@ -81,7 +83,7 @@ func (a GeminiAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		}
 	}
 
-	subtree, err := collectGeminiSubtreeFiles(repoRoot, cwd, filenames)
+	subtree, err := collectGeminiSubtreeFiles(fsys, checkIgnore(opts), repoRoot, cwd, filenames)
 	if err != nil {
 		return res, err
 	}
@@ -114,14 +116,14 @@ func normalizeGeminiFilenames(input []string) []string {
 	return out
 }
 
-func collectGeminiSubtreeFiles(repoRoot, cwd string, filenames []string) ([]InstructionFile, error) {
-	patterns, err := loadGeminiIgnore(repoRoot)
+func collectGeminiSubtreeFiles(fsys afero.Fs, ignore func(string, []string) (map[string]bool, error), repoRoot, cwd string, filenames []string) ([]InstructionFile, error) {
+	patterns, err := loadGeminiIgnore(fsys, repoRoot)
 	if err != nil {
 		return nil, err
 	}
 
 	var candidates []string
-	err = filepath.WalkDir(cwd, func(path string, entry fs.DirEntry, walkErr error) error {
+	err = afero.Walk(fsys, cwd, func(path string, entry os.FileInfo, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
@@ -160,7 +162,7 @@ func collectGeminiSubtreeFiles(repoRoot, cwd string, filenames []string) ([]Inst
 		return nil, nil
 	}
 
-	ignored, err := git.CheckIgnore(repoRoot, filtered)
+	ignored, err := ignore(repoRoot, filtered)
 	if err != nil {
 		return nil, err
 	}
@@ -171,7 +173,7 @@ func collectGeminiSubtreeFiles(repoRoot, cwd string, filenames []string) ([]Inst
 		if ignored[candidate] {
 			continue
 		}
-		file, err := instructionFileWithScope(candidate, ScopeRepo, ReasonPrimary)
+		file, err := instructionFileWithScope(fsys, candidate, ScopeRepo, ReasonPrimary)
 		if err != nil {
 			return nil, err
 		}
@@ -192,10 +194,10 @@ func matchesGeminiFilename(name string, filenames []string) bool {
 	return false
 }
 
-func loadGeminiIgnore(repoRoot string) ([]string, error) {
+func loadGeminiIgnore(fsys afero.Fs, repoRoot string) ([]string, error) {
 	path := filepath.Join(repoRoot, geminiIgnoreFilename)
 	// #nosec G304 -- path is derived from repo root and known filename.
-	data, err := os.ReadFile(path)
+	data, err := afero.ReadFile(fsys, path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -2,13 +2,14 @@ package instructions
 
 import (
 	"fmt"
-	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
+	"github.com/spf13/afero"
 
+	"markdowntown-cli/internal/git"
 	"markdowntown-cli/internal/scan"
 )
 
@@ -17,12 +18,28 @@ const (
 	copilotInstructionsDir  = "instructions"
 )
 
-func instructionFile(path string, reason InstructionReason) (*InstructionFile, error) {
-	return instructionFileWithScope(path, ScopeRepo, reason)
+// fileSystem returns the filesystem instruction files are read from.
+func fileSystem(opts ResolveOptions) afero.Fs {
+	if opts.Fs != nil {
+		return opts.Fs
+	}
+	return afero.NewOsFs()
+}
+
+// checkIgnore returns the gitignore checker for opts.
+func checkIgnore(opts ResolveOptions) func(string, []string) (map[string]bool, error) {
+	if opts.CheckIgnore != nil {
+		return opts.CheckIgnore
+	}
+	return git.CheckIgnore
+}
+
+func instructionFile(fsys afero.Fs, path string, reason InstructionReason) (*InstructionFile, error) {
+	return instructionFileWithScope(fsys, path, ScopeRepo, reason)
 }
 
-func instructionFileWithScope(path string, scope Scope, reason InstructionReason) (*InstructionFile, error) {
-	info, err := os.Stat(path)
+func instructionFileWithScope(fsys afero.Fs, path string, scope Scope, reason InstructionReason) (*InstructionFile, error) {
+	info, err := fsys.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil //nolint:nilnil
@@ -43,11 +60,11 @@ func instructionFileWithScope(path string, scope Scope, reason InstructionReason
 	}, nil
 }
 
-func nearestAncestorFile(startDir, repoRoot, name string, reason InstructionReason) (*InstructionFile, error) {
+func nearestAncestorFile(fsys afero.Fs, startDir, repoRoot, name string, reason InstructionReason) (*InstructionFile, error) {
 	dir := startDir
 	for {
 		candidate := filepath.Join(dir, name)
-		file, err := instructionFile(candidate, reason)
+		file, err := instructionFile(fsys, candidate, reason)
 		if err != nil {
 			return nil, err
 		}
@@ -66,8 +83,8 @@ func nearestAncestorFile(startDir, repoRoot, name string, reason InstructionReas
 	return nil, nil //nolint:nilnil
 }
 
-func collectInstructionFiles(rootDir, targetRel, agent string) ([]InstructionFile, []string, error) {
-	info, err := os.Stat(rootDir)
+func collectInstructionFiles(fsys afero.Fs, rootDir, targetRel, agent string) ([]InstructionFile, []string, error) {
+	info, err := fsys.Stat(rootDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil, nil
@@ -86,7 +103,7 @@ func collectInstructionFiles(rootDir, targetRel, agent string) ([]InstructionFil
 	var warnings []string
 	agent = strings.ToLower(strings.TrimSpace(agent))
 
-	err = filepath.WalkDir(rootDir, func(path string, entry fs.DirEntry, walkErr error) error {
+	err = afero.Walk(fsys, rootDir, func(path string, entry os.FileInfo, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
@@ -97,7 +114,7 @@ func collectInstructionFiles(rootDir, targetRel, agent string) ([]InstructionFil
 			return nil
 		}
 
-		applyTo, excludeAgents, err := parseInstructionFrontmatter(path)
+		applyTo, excludeAgents, err := parseInstructionFrontmatter(fsys, path)
 		if err != nil {
 			return err
 		}
@@ -108,7 +125,7 @@ func collectInstructionFiles(rootDir, targetRel, agent string) ([]InstructionFil
 			return nil
 		}
 
-		file, err := instructionFile(path, ReasonFallback)
+		file, err := instructionFile(fsys, path, ReasonFallback)
 		if err != nil {
 			return err
 		}
@@ -124,9 +141,9 @@ func collectInstructionFiles(rootDir, targetRel, agent string) ([]InstructionFil
 	return files, warnings, nil
 }
 
-func parseInstructionFrontmatter(path string) ([]string, []string, error) {
+func parseInstructionFrontmatter(fsys afero.Fs, path string) ([]string, []string, error) {
 	// #nosec G304 -- path is discovered from known instruction locations.
-	content, err := os.ReadFile(path)
+	content, err := afero.ReadFile(fsys, path)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -248,9 +265,9 @@ func ensureTargetRel(repoRoot, targetPath string) (string, error) {
 	return rel, nil
 }
 
-func collectAgentFiles(repoRoot string) ([]InstructionFile, error) {
+func collectAgentFiles(fsys afero.Fs, repoRoot string) ([]InstructionFile, error) {
 	var files []InstructionFile
-	err := filepath.WalkDir(repoRoot, func(path string, entry fs.DirEntry, walkErr error) error {
+	err := afero.Walk(fsys, repoRoot, func(path string, entry os.FileInfo, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
 		}
@@ -260,7 +277,7 @@ func collectAgentFiles(repoRoot string) ([]InstructionFile, error) {
 		if entry.Name() != "AGENTS.md" {
 			return nil
 		}
-		file, err := instructionFile(path, ReasonPrimary)
+		file, err := instructionFile(fsys, path, ReasonPrimary)
 		if err != nil {
 			return err
 		}
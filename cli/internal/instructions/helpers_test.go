@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/spf13/afero"
 )
 
 func TestNormalizeStringSlice(t *testing.T) {
@@ -80,7 +82,7 @@ body`
 		t.Fatalf("write file: %v", err)
 	}
 
-	applyTo, exclude, err := parseInstructionFrontmatter(path)
+	applyTo, exclude, err := parseInstructionFrontmatter(afero.NewOsFs(), path)
 	if err != nil {
 		t.Fatalf("parseInstructionFrontmatter: %v", err)
 	}
@@ -113,7 +115,7 @@ func TestCollectAgentFiles(t *testing.T) {
 		t.Fatalf("write file: %v", err)
 	}
 
-	files, err := collectAgentFiles(repo)
+	files, err := collectAgentFiles(afero.NewOsFs(), repo)
 	if err != nil {
 		t.Fatalf("collectAgentFiles: %v", err)
 	}
@@ -159,7 +161,7 @@ body`
 		t.Fatalf("write file: %v", err)
 	}
 
-	files, warnings, err := collectInstructionFiles(root, "src/main.go", "")
+	files, warnings, err := collectInstructionFiles(afero.NewOsFs(), root, "src/main.go", "")
 	if err != nil {
 		t.Fatalf("collectInstructionFiles: %v", err)
 	}
@@ -170,7 +172,7 @@ body`
 		t.Fatalf("expected 1 file, got %d", len(files))
 	}
 
-	_, warnings, err = collectInstructionFiles(root, "", "")
+	_, warnings, err = collectInstructionFiles(afero.NewOsFs(), root, "", "")
 	if err != nil {
 		t.Fatalf("collectInstructionFiles: %v", err)
 	}
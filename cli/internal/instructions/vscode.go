@@ -33,6 +33,7 @@ func (VSCodeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 		OrderGuarantee: OrderUndefined,
 	}
 
+	fsys := fileSystem(opts)
 	settings := opts.Settings
 	targetRel, err := ensureTargetRel(repoRoot, targetPath)
 	if err != nil {
@@ -45,7 +46,7 @@ func (VSCodeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 	}
 
 	if instructionFilesEnabled {
-		repoWideFile, err := instructionFile(repoWideCopilotFile(repoRoot), ReasonPrimary)
+		repoWideFile, err := instructionFile(fsys, repoWideCopilotFile(repoRoot), ReasonPrimary)
 		if err != nil {
 			return res, err
 		}
@@ -53,7 +54,7 @@ func (VSCodeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 			res.Applied = append(res.Applied, *repoWideFile)
 		}
 
-		scopedFiles, warnings, err := collectInstructionFiles(instructionDir(repoRoot), filepath.ToSlash(targetRel), "")
+		scopedFiles, warnings, err := collectInstructionFiles(fsys, instructionDir(repoRoot), filepath.ToSlash(targetRel), "")
 		if err != nil {
 			return res, err
 		}
@@ -67,7 +68,7 @@ func (VSCodeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 	}
 
 	if agentsEnabled {
-		rootAgent, err := instructionFile(filepath.Join(repoRoot, "AGENTS.md"), ReasonPrimary)
+		rootAgent, err := instructionFile(fsys, filepath.Join(repoRoot, "AGENTS.md"), ReasonPrimary)
 		if err != nil {
 			return res, err
 		}
@@ -82,7 +83,7 @@ func (VSCodeAdapter) Resolve(opts ResolveOptions) (Resolution, error) {
 	}
 
 	if nestedEnabled {
-		nestedAgents, err := collectAgentFiles(repoRoot)
+		nestedAgents, err := collectAgentFiles(fsys, repoRoot)
 		if err != nil {
 			return res, err
 		}
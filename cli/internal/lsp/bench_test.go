@@ -1,6 +1,7 @@
 package lsp
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -31,7 +32,7 @@ func BenchmarkDiagnosticsLargeMarkdown(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		result, registry, err := s.scanForDiagnostics(filePath, repoRoot)
+		result, registry, err := s.scanForDiagnostics(context.Background(), filePath, repoRoot)
 		if err != nil {
 			b.Fatalf("scanForDiagnostics: %v", err)
 		}
@@ -41,7 +42,7 @@ func BenchmarkDiagnosticsLargeMarkdown(b *testing.B) {
 			Registry: registry,
 			Redactor: redactor,
 		}
-		issues := audit.RunRules(auditCtx, s.rulesForSettings(settings))
+		issues := audit.RunRules(auditCtx, s.rulesForSettings(settings, ""))
 		_ = s.diagnosticsForIssues(issues, uri, filePath, repoRoot, settings, caps)
 	}
 }
@@ -1,28 +1,23 @@
 package lsp
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
-	"strings"
 
 	"markdowntown-cli/internal/audit"
+	"markdowntown-cli/internal/fix"
 
-	"github.com/spf13/afero"
 	protocol "github.com/tliron/glsp/protocol_3_16"
 )
 
 const (
-	quickFixAllowGitignore      = "allow-gitignore"
-	quickFixRemoveFrontmatter   = "remove-frontmatter"
-	quickFixInsertPlaceholder   = "insert-placeholder"
-	quickFixCreateRepoConfig    = "create-repo-config"
-	quickFixRemoveDuplicateFM   = "remove-duplicate-frontmatter"
-	quickFixInsertFrontmatterID = "insert-frontmatter-id"
-	quickFixReplaceToolID       = "replace-toolid"
-	quickFixDisableRule         = "disable-rule"
+	quickFixAllowGitignore      = fix.AllowGitignore
+	quickFixRemoveFrontmatter   = fix.RemoveFrontmatter
+	quickFixInsertPlaceholder   = fix.InsertPlaceholder
+	quickFixCreateRepoConfig    = fix.CreateRepoConfig
+	quickFixRemoveDuplicateFM   = fix.RemoveDuplicateFrontmatter
+	quickFixInsertFrontmatterID = fix.InsertFrontmatterID
+	quickFixReplaceToolID       = fix.ReplaceToolID
+	quickFixDisableRule         = fix.DisableRule
 )
 
 type codeActionRequest struct {
@@ -134,24 +129,7 @@ func diagnosticQuickFixes(diag protocol.Diagnostic) []string {
 }
 
 func defaultQuickFixesForRule(ruleID string) []string {
-	switch ruleID {
-	case "MD002":
-		return []string{quickFixAllowGitignore}
-	case "MD003":
-		return []string{quickFixRemoveFrontmatter}
-	case "MD004":
-		return []string{quickFixInsertPlaceholder}
-	case "MD005":
-		return []string{quickFixCreateRepoConfig}
-	case "MD007":
-		return []string{quickFixRemoveDuplicateFM}
-	case "MD012":
-		return []string{quickFixInsertFrontmatterID}
-	case "MD015":
-		return []string{quickFixReplaceToolID}
-	default:
-		return nil
-	}
+	return fix.DefaultForRule(ruleID)
 }
 
 func dedupeStrings(values []string) []string {
@@ -171,54 +149,12 @@ func dedupeStrings(values []string) []string {
 }
 
 func codeActionRemoveFrontmatter(diag protocol.Diagnostic, uri string, content string) *protocol.CodeAction {
-	fmRange := frontmatterBlockRange(content)
-	if fmRange == nil {
-		return nil
-	}
-	kind := protocol.CodeActionKindQuickFix
-	action := protocol.CodeAction{
-		Title:       actionTitleRemoveFrontmatter,
-		Kind:        &kind,
-		Diagnostics: []protocol.Diagnostic{diag},
-		Edit: &protocol.WorkspaceEdit{
-			Changes: map[string][]protocol.TextEdit{
-				uri: {
-					{
-						Range:   *fmRange,
-						NewText: "",
-					},
-				},
-			},
-		},
-	}
-	return &action
+	path := uriPath(uri)
+	return codeActionFromFix(diag, uri, path, fix.Generate(nil, fix.RemoveFrontmatter, fixTarget(diag, path, "", content)))
 }
 
 func codeActionInsertPlaceholder(diag protocol.Diagnostic, uri string, path string, content string) *protocol.CodeAction {
-	if strings.TrimSpace(content) != "" {
-		return nil
-	}
-	stub := stubContentForPath(path)
-	if stub == "" {
-		return nil
-	}
-	kind := protocol.CodeActionKindQuickFix
-	action := protocol.CodeAction{
-		Title:       actionTitleInsertPlaceholder,
-		Kind:        &kind,
-		Diagnostics: []protocol.Diagnostic{diag},
-		Edit: &protocol.WorkspaceEdit{
-			Changes: map[string][]protocol.TextEdit{
-				uri: {
-					{
-						Range:   protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: protocol.Position{Line: 0, Character: 0}},
-						NewText: stub,
-					},
-				},
-			},
-		},
-	}
-	return &action
+	return codeActionFromFix(diag, uri, path, fix.Generate(nil, fix.InsertPlaceholder, fixTarget(diag, path, "", content)))
 }
 
 func (s *Server) codeActionAllowGitignore(diag protocol.Diagnostic, path string) *protocol.CodeAction {
@@ -226,444 +162,141 @@ func (s *Server) codeActionAllowGitignore(diag protocol.Diagnostic, path string)
 	if repoRoot == "" {
 		return nil
 	}
-	rel, ok := relativeRepoPath(repoRoot, path)
-	if !ok {
-		return nil
-	}
-	entry := "!" + rel
-	gitignorePath := filepath.Join(repoRoot, ".gitignore")
-	return quickFixGitignore(s.fs, entry, gitignorePath, diag)
+	return codeActionFromFix(diag, pathToURI(path), path, fix.Generate(s.fs, fix.AllowGitignore, fixTarget(diag, path, repoRoot, "")))
 }
 
 func (s *Server) codeActionCreateRepoConfig(diag protocol.Diagnostic, path string) *protocol.CodeAction {
-	candidate := candidateRepoPathFromDiagnostic(diag)
-	if candidate == "" {
-		return nil
-	}
 	repoRoot := repoRootForPath(s.rootPath, path)
 	if repoRoot == "" {
 		return nil
 	}
-	if isGlobPath(candidate) {
-		return nil
-	}
-	absPath := filepath.Join(repoRoot, filepath.FromSlash(candidate))
-	if _, err := s.fs.Stat(absPath); err == nil {
-		return nil
-	}
-	parent := filepath.Dir(absPath)
-	if info, err := s.fs.Stat(parent); err != nil || !info.IsDir() {
-		return nil
-	}
-	stub := stubContentForPath(absPath)
-	if stub == "" {
-		return nil
-	}
-	uri := pathToURI(absPath)
-	kind := protocol.CodeActionKindQuickFix
-	action := protocol.CodeAction{
-		Title:       actionTitleCreateRepoPrefix + candidate,
-		Kind:        &kind,
-		Diagnostics: []protocol.Diagnostic{diag},
-		Edit: &protocol.WorkspaceEdit{
-			DocumentChanges: []any{
-				protocol.CreateFile{
-					Kind:    "create",
-					URI:     uri,
-					Options: &protocol.CreateFileOptions{IgnoreIfExists: boolPtr(true)},
-				},
-				protocol.TextDocumentEdit{
-					TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
-						TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
-					},
-					Edits: []any{
-						protocol.TextEdit{
-							Range:   protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: protocol.Position{Line: 0, Character: 0}},
-							NewText: stub,
-						},
-					},
-				},
-			},
-		},
-	}
-	return &action
+	return codeActionFromFix(diag, pathToURI(path), path, fix.Generate(s.fs, fix.CreateRepoConfig, fixTarget(diag, path, repoRoot, "")))
 }
 
 func codeActionRemoveDuplicateFrontmatter(diag protocol.Diagnostic, uri string, content string) *protocol.CodeAction {
-	lineIndex := int(diag.Range.Start.Line)
-	lineText, ok := lineTextAt(content, lineIndex)
-	if !ok {
-		return nil
-	}
-	if fmRange := frontmatterBlockRange(content); fmRange == nil || lineIndex > int(fmRange.End.Line) {
-		return nil
-	}
-	field := diagnosticEvidenceString(diag, "field")
-	value := diagnosticEvidenceString(diag, "value")
-	lowerLine := strings.ToLower(strings.TrimSpace(lineText))
-	if field != "" && !strings.Contains(lowerLine, strings.ToLower(field)+":") {
-		return nil
-	}
-	deleteRange := lineDeleteRange(content, lineIndex)
-	if deleteRange == nil {
-		return nil
-	}
-	title := actionTitleRemoveDuplicateFrontmatterPrefix + "entry"
-	if field != "" && value != "" {
-		title = fmt.Sprintf("%s%s=%s", actionTitleRemoveDuplicateFrontmatterPrefix, field, value)
-	} else if field != "" {
-		title = actionTitleRemoveDuplicateFrontmatterPrefix + field
-	}
-	kind := protocol.CodeActionKindQuickFix
-	action := protocol.CodeAction{
-		Title:       title,
-		Kind:        &kind,
-		Diagnostics: []protocol.Diagnostic{diag},
-		Edit: &protocol.WorkspaceEdit{
-			Changes: map[string][]protocol.TextEdit{
-				uri: {
-					{
-						Range:   *deleteRange,
-						NewText: "",
-					},
-				},
-			},
-		},
-	}
-	return &action
+	path := uriPath(uri)
+	return codeActionFromFix(diag, uri, path, fix.Generate(nil, fix.RemoveDuplicateFrontmatter, fixTarget(diag, path, "", content)))
 }
 
 func codeActionInsertFrontmatter(diag protocol.Diagnostic, uri string, path string, content string) *protocol.CodeAction {
-	required := diagnosticEvidenceStrings(diag, "requiredKeys")
-	if len(required) == 0 {
-		return nil
-	}
-	key := required[0]
-	value := identifierFromPath(path)
-	if strings.TrimSpace(value) == "" {
-		value = "change-me"
-	}
-
-	insertRange := protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: protocol.Position{Line: 0, Character: 0}}
-	insertText := fmt.Sprintf("---\n%s: %s\n---\n\n", key, value)
-	if closingLine, ok := frontmatterClosingLine(content); ok {
-		insertRange = protocol.Range{
-			Start: protocol.Position{Line: clampToUint32(closingLine), Character: 0},
-			End:   protocol.Position{Line: clampToUint32(closingLine), Character: 0},
-		}
-		insertText = fmt.Sprintf("%s: %s\n", key, value)
-	} else if frontmatterHasOpeningLine(content) {
-		return nil
-	}
-
-	kind := protocol.CodeActionKindQuickFix
-	action := protocol.CodeAction{
-		Title:       actionTitleInsertFrontmatterPrefix + key,
-		Kind:        &kind,
-		Diagnostics: []protocol.Diagnostic{diag},
-		Edit: &protocol.WorkspaceEdit{
-			Changes: map[string][]protocol.TextEdit{
-				uri: {
-					{
-						Range:   insertRange,
-						NewText: insertText,
-					},
-				},
-			},
-		},
-	}
-	return &action
+	return codeActionFromFix(diag, uri, path, fix.Generate(nil, fix.InsertFrontmatterID, fixTarget(diag, path, "", content)))
 }
 
 func codeActionReplaceToolID(diag protocol.Diagnostic, uri string, content string) *protocol.CodeAction {
-	replacement := diagnosticDataString(diag, "replacement")
-	if replacement == "" {
-		return nil
-	}
-	current := textAtRange(content, diag.Range)
-	if current == "" {
-		return nil
-	}
-	if strings.TrimSpace(current) == strings.TrimSpace(replacement) {
-		return nil
-	}
-	original := diagnosticDataString(diag, "toolId")
-	if original != "" && strings.TrimSpace(current) != original {
-		return nil
-	}
-	kind := protocol.CodeActionKindQuickFix
-	action := protocol.CodeAction{
-		Title:       actionTitleReplaceToolIDPrefix + replacement,
-		Kind:        &kind,
-		Diagnostics: []protocol.Diagnostic{diag},
-		Edit: &protocol.WorkspaceEdit{
-			Changes: map[string][]protocol.TextEdit{
-				uri: {
-					{
-						Range:   diag.Range,
-						NewText: replacement,
-					},
-				},
-			},
-		},
-	}
-	return &action
+	path := uriPath(uri)
+	return codeActionFromFix(diag, uri, path, fix.Generate(nil, fix.ReplaceToolID, fixTarget(diag, path, "", content)))
 }
 
 func (s *Server) codeActionDisableRule(diag protocol.Diagnostic, path string) *protocol.CodeAction {
-	ruleID := diagnosticRuleID(diag)
-	if ruleID == "" {
-		return nil
-	}
 	repoRoot := repoRootForPath(s.rootPath, path)
 	if repoRoot == "" {
 		return nil
 	}
-	settingsPath := filepath.Join(repoRoot, ".vscode", "settings.json")
-	settingsDir := filepath.Dir(settingsPath)
-	if info, err := s.fs.Stat(settingsDir); err != nil || !info.IsDir() {
-		return nil
-	}
-
-	content := ""
-	exists := false
-	if data, err := afero.ReadFile(s.fs, settingsPath); err == nil {
-		content = string(data)
-		exists = true
-	} else if err != nil && !os.IsNotExist(err) {
-		return nil
-	}
+	target := fixTarget(diag, path, repoRoot, "")
+	target.RuleID = diagnosticRuleID(diag)
+	return codeActionFromFix(diag, pathToURI(path), path, fix.Generate(s.fs, fix.DisableRule, target))
+}
 
-	settings := map[string]any{}
-	if strings.TrimSpace(content) != "" {
-		if err := json.Unmarshal([]byte(content), &settings); err != nil {
-			return nil
-		}
+// fixTarget maps diagnostic data onto the shared fix target.
+func fixTarget(diag protocol.Diagnostic, path string, repoRoot string, content string) fix.Target {
+	target := fix.Target{
+		RuleID:   diagnosticRuleID(diag),
+		Path:     path,
+		RepoRoot: repoRoot,
+		Content:  content,
+		Range: fix.Range{
+			Start: fix.Position{Line: int(diag.Range.Start.Line), Character: int(diag.Range.Start.Character)},
+			End:   fix.Position{Line: int(diag.Range.End.Line), Character: int(diag.Range.End.Character)},
+		},
+		Evidence: map[string]any{},
+		Data:     map[string]any{},
 	}
-
-	key := "markdowntown.diagnostics.rulesDisabled"
-	rules := parseStringSlice(settings[key])
-	for _, rule := range rules {
-		if rule == ruleID {
-			return nil
+	if data, ok := diag.Data.(map[string]any); ok {
+		target.Data = data
+		if evidence, ok := data["evidence"].(map[string]any); ok {
+			target.Evidence = evidence
 		}
 	}
-	rules = append(rules, ruleID)
-	rules = dedupeStrings(rules)
-	sort.Strings(rules)
-	settings[key] = rules
+	return target
+}
 
-	payload, err := json.MarshalIndent(settings, "", "  ")
-	if err != nil {
+// codeActionFromFix converts a shared fix into an LSP quick fix action.
+// Edits to the diagnostic's document use Changes; other files use DocumentChanges.
+func codeActionFromFix(diag protocol.Diagnostic, uri string, path string, generated *fix.Fix) *protocol.CodeAction {
+	if generated == nil || len(generated.Files) == 0 {
 		return nil
 	}
-	payload = append(payload, '\n')
-
 	kind := protocol.CodeActionKindQuickFix
 	action := protocol.CodeAction{
-		Title:       actionTitleDisableRulePrefix + ruleID,
+		Title:       generated.Title,
 		Kind:        &kind,
 		Diagnostics: []protocol.Diagnostic{diag},
 		Edit:        &protocol.WorkspaceEdit{},
 	}
-
-	if exists {
-		end := endPositionForContent(content)
-		action.Edit.Changes = map[string][]protocol.TextEdit{
-			pathToURI(settingsPath): {
-				{
-					Range:   protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: end},
-					NewText: string(payload),
-				},
-			},
-		}
-		return &action
-	}
-
-	uri := pathToURI(settingsPath)
-	action.Edit.DocumentChanges = []any{
-		protocol.CreateFile{
-			Kind:    "create",
-			URI:     uri,
-			Options: &protocol.CreateFileOptions{IgnoreIfExists: boolPtr(true)},
-		},
-		protocol.TextDocumentEdit{
-			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
-				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
-			},
-			Edits: []any{
-				protocol.TextEdit{
-					Range:   protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: protocol.Position{Line: 0, Character: 0}},
-					NewText: string(payload),
-				},
-			},
-		},
-	}
-	return &action
-}
-
-func diagnosticRuleID(diag protocol.Diagnostic) string {
-	if diag.Code != nil {
-		if value, ok := diag.Code.Value.(string); ok && value != "" {
-			return value
-		}
-	}
-	if diag.Data != nil {
-		if data, ok := diag.Data.(map[string]any); ok {
-			if value, ok := data["ruleId"].(string); ok {
-				return value
-			}
+	for _, file := range generated.Files {
+		fileURI := uri
+		if file.Path != path {
+			fileURI = pathToURI(file.Path)
 		}
-	}
-	return ""
-}
-
-func candidateRepoPathFromDiagnostic(diag protocol.Diagnostic) string {
-	if diag.Data == nil {
-		return ""
-	}
-	data, ok := diag.Data.(map[string]any)
-	if !ok {
-		return ""
-	}
-	evidence, ok := data["evidence"].(map[string]any)
-	if !ok {
-		return ""
-	}
-	raw, ok := evidence["candidatePaths"]
-	if !ok {
-		return ""
-	}
-	switch typed := raw.(type) {
-	case []string:
-		if len(typed) > 0 {
-			return typed[0]
+		edits := make([]protocol.TextEdit, 0, len(file.Edits))
+		for _, edit := range file.Edits {
+			edits = append(edits, protocol.TextEdit{Range: protocolRange(edit.Range), NewText: edit.NewText})
 		}
-	case []any:
-		for _, item := range typed {
-			if s, ok := item.(string); ok && s != "" {
-				return s
+		if !file.Create {
+			if action.Edit.Changes == nil {
+				action.Edit.Changes = map[string][]protocol.TextEdit{}
 			}
+			action.Edit.Changes[fileURI] = append(action.Edit.Changes[fileURI], edits...)
+			continue
 		}
-	}
-	return ""
-}
-
-func diagnosticEvidenceValue(diag protocol.Diagnostic, key string) (any, bool) {
-	if diag.Data == nil {
-		return nil, false
-	}
-	data, ok := diag.Data.(map[string]any)
-	if !ok {
-		return nil, false
-	}
-	evidence, ok := data["evidence"].(map[string]any)
-	if !ok {
-		return nil, false
-	}
-	value, ok := evidence[key]
-	return value, ok
-}
-
-func diagnosticEvidenceString(diag protocol.Diagnostic, key string) string {
-	value, ok := diagnosticEvidenceValue(diag, key)
-	if !ok {
-		return ""
-	}
-	switch typed := value.(type) {
-	case string:
-		return typed
-	case fmt.Stringer:
-		return typed.String()
-	case []string:
-		if len(typed) > 0 {
-			return typed[0]
-		}
-	case []any:
-		for _, item := range typed {
-			if str, ok := item.(string); ok && str != "" {
-				return str
-			}
+		anyEdits := make([]any, 0, len(edits))
+		for _, edit := range edits {
+			anyEdits = append(anyEdits, edit)
 		}
-	}
-	return fmt.Sprintf("%v", value)
-}
-
-func quickFixGitignore(fs afero.Fs, entry string, gitignorePath string, diag protocol.Diagnostic) *protocol.CodeAction {
-	if entry == "!" {
-		return nil
-	}
-	uri := pathToURI(gitignorePath)
-	kind := protocol.CodeActionKindQuickFix
-
-	content := ""
-	if data, err := afero.ReadFile(fs, gitignorePath); err == nil {
-		content = string(data)
-	}
-
-	if hasGitignoreEntry(content, entry) {
-		return nil
-	}
-
-	insert := entry + "\n"
-	if content != "" && !strings.HasSuffix(content, "\n") {
-		insert = "\n" + entry + "\n"
-	}
-
-	edit := &protocol.WorkspaceEdit{}
-	if _, err := fs.Stat(gitignorePath); err == nil {
-		pos := endPositionForContent(content)
-		edit.Changes = map[string][]protocol.TextEdit{
-			uri: {
-				{
-					Range:   protocol.Range{Start: pos, End: pos},
-					NewText: insert,
-				},
-			},
-		}
-	} else {
-		edit.DocumentChanges = []any{
+		action.Edit.DocumentChanges = append(action.Edit.DocumentChanges,
 			protocol.CreateFile{
 				Kind:    "create",
-				URI:     uri,
+				URI:     fileURI,
 				Options: &protocol.CreateFileOptions{IgnoreIfExists: boolPtr(true)},
 			},
 			protocol.TextDocumentEdit{
 				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
-					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: uri},
-				},
-				Edits: []any{
-					protocol.TextEdit{
-						Range:   protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: protocol.Position{Line: 0, Character: 0}},
-						NewText: insert,
-					},
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: fileURI},
 				},
+				Edits: anyEdits,
 			},
-		}
+		)
 	}
+	return &action
+}
 
-	action := protocol.CodeAction{
-		Title:       actionTitleAllowGitignoreEntry,
-		Kind:        &kind,
-		Diagnostics: []protocol.Diagnostic{diag},
-		Edit:        edit,
+func protocolRange(rng fix.Range) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: clampToUint32(rng.Start.Line), Character: clampToUint32(rng.Start.Character)},
+		End:   protocol.Position{Line: clampToUint32(rng.End.Line), Character: clampToUint32(rng.End.Character)},
 	}
-	return &action
 }
 
-func textAtRange(content string, rng protocol.Range) string {
-	if rng.Start.Line != rng.End.Line {
+func uriPath(uri string) string {
+	path, err := urlToPath(uri)
+	if err != nil {
 		return ""
 	}
-	lineText, ok := lineTextAt(content, int(rng.Start.Line))
-	if !ok {
-		return ""
+	return path
+}
+
+func diagnosticRuleID(diag protocol.Diagnostic) string {
+	if diag.Code != nil {
+		if value, ok := diag.Code.Value.(string); ok && value != "" {
+			return value
+		}
 	}
-	runes := []rune(lineText)
-	start := int(rng.Start.Character)
-	end := int(rng.End.Character)
-	if start < 0 || end < 0 || start > end || end > len(runes) {
-		return ""
+	if diag.Data != nil {
+		if data, ok := diag.Data.(map[string]any); ok {
+			if value, ok := data["ruleId"].(string); ok {
+				return value
+			}
+		}
 	}
-	return string(runes[start:end])
+	return ""
 }
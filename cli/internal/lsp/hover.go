@@ -0,0 +1,53 @@
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"markdowntown-cli/internal/audit"
+
+	protocol "github.com/tliron/glsp/protocol_3_16"
+)
+
+// ruleDocHover returns embedded rule documentation for published diagnostics
+// under the cursor, so rule help is available offline.
+func (s *Server) ruleDocHover(uri string, pos protocol.Position) string {
+	s.publishedMu.Lock()
+	diagnostics := s.published[uri]
+	s.publishedMu.Unlock()
+
+	seen := make(map[string]struct{})
+	var sections []string
+	for _, diag := range diagnostics {
+		if diag.Code == nil || !diagnosticCovers(diag.Range, pos) {
+			continue
+		}
+		ruleID := fmt.Sprint(diag.Code.Value)
+		if _, ok := seen[ruleID]; ok {
+			continue
+		}
+		seen[ruleID] = struct{}{}
+		if doc, ok := audit.LookupRuleDoc(ruleID); ok {
+			sections = append(sections, strings.TrimSpace(doc.Markdown))
+		}
+	}
+	return strings.Join(sections, "\n\n---\n\n")
+}
+
+// diagnosticCovers reports whether pos falls inside r. Empty ranges, used for
+// file-level diagnostics, cover their whole line.
+func diagnosticCovers(r protocol.Range, pos protocol.Position) bool {
+	if r.Start == r.End {
+		return pos.Line == r.Start.Line
+	}
+	if pos.Line < r.Start.Line || pos.Line > r.End.Line {
+		return false
+	}
+	if pos.Line == r.Start.Line && pos.Character < r.Start.Character {
+		return false
+	}
+	if pos.Line == r.End.Line && pos.Character > r.End.Character {
+		return false
+	}
+	return true
+}
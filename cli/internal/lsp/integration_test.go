@@ -498,6 +498,74 @@ func TestDiagnosticsRulesDisabled(t *testing.T) {
 	}
 }
 
+func TestDiagnosticsCustomRulePack(t *testing.T) {
+	s := NewServer("0.1.0")
+	s.Debounce = 50 * time.Millisecond
+	repoRoot := t.TempDir()
+	runGit(t, repoRoot, "init")
+	setRegistryEnv(t)
+
+	rulesDir := filepath.Join(repoRoot, ".markdowntown", "rules")
+	if err := os.MkdirAll(rulesDir, 0o755); err != nil {
+		t.Fatalf("mkdir rules: %v", err)
+	}
+	pack := "rules:\n  - id: ORG001\n    title: No sudo\n    match:\n      paths: [\"GEMINI.md\"]\n    assert:\n      forbiddenPatterns: [\"\\\\bsudo\\\\b\"]\n"
+	if err := os.WriteFile(filepath.Join(rulesDir, "org.yaml"), []byte(pack), 0o600); err != nil {
+		t.Fatalf("write rule pack: %v", err)
+	}
+
+	clientConn, serverConn := net.Pipe()
+	t.Cleanup(func() {
+		_ = clientConn.Close()
+		_ = serverConn.Close()
+	})
+
+	serverRPC := newServerRPC(t, s, serverConn)
+	t.Cleanup(func() {
+		_ = serverRPC.Close()
+	})
+
+	diagnostics := make(chan protocol.PublishDiagnosticsParams, 2)
+	clientRPC := newClientRPC(t, clientConn, diagnostics)
+	t.Cleanup(func() {
+		_ = clientRPC.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rootURI := pathToURL(repoRoot)
+	var initResult protocol.InitializeResult
+	if err := clientRPC.Call(ctx, protocol.MethodInitialize, protocol.InitializeParams{
+		RootURI: &rootURI,
+	}, &initResult); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	if err := clientRPC.Notify(ctx, protocol.MethodInitialized, protocol.InitializedParams{}); err != nil {
+		t.Fatalf("initialized notify failed: %v", err)
+	}
+
+	uri := pathToURL(filepath.Join(repoRoot, "GEMINI.md"))
+	content := "# Hello\nRun sudo make install.\n"
+	if err := os.WriteFile(filepath.Join(repoRoot, "GEMINI.md"), []byte(content), 0o600); err != nil {
+		t.Fatalf("write GEMINI.md: %v", err)
+	}
+	if err := clientRPC.Notify(ctx, protocol.MethodTextDocumentDidOpen, protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:  uri,
+			Text: content,
+		},
+	}); err != nil {
+		t.Fatalf("didOpen notify failed: %v", err)
+	}
+
+	params := waitForDiagnostics(t, diagnostics, uri)
+	diag := requireDiagnostic(t, params.Diagnostics, "ORG001")
+	if diag.Range.Start.Line != 1 || diag.Range.Start.Character != 4 {
+		t.Fatalf("unexpected ORG001 range: %#v", diag.Range)
+	}
+}
+
 func TestDiagnosticsSeverityOverride(t *testing.T) {
 	s := NewServer("0.1.0")
 	s.Debounce = 50 * time.Millisecond
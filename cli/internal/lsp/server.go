@@ -2,6 +2,7 @@
 package lsp
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -16,6 +17,7 @@ import (
 	"unicode/utf8"
 
 	"markdowntown-cli/internal/audit"
+	"markdowntown-cli/internal/fix"
 	"markdowntown-cli/internal/scan"
 
 	"github.com/spf13/afero"
@@ -67,6 +69,7 @@ type Server struct {
 	// Diagnostics state
 	diagnosticsMu    sync.Mutex
 	diagnosticTimers map[string]*time.Timer
+	diagnosticRuns   map[string]*diagnosticRun
 	Debounce         time.Duration
 
 	// Cache state
@@ -76,6 +79,8 @@ type Server struct {
 	scanCache        map[string]scan.Result
 	versionMu        sync.Mutex
 	documentVersions map[string]protocol.Integer
+	publishedMu      sync.Mutex
+	published        map[string][]protocol.Diagnostic
 }
 
 // NewServer constructs a new LSP server.
@@ -86,12 +91,14 @@ func NewServer(version string) *Server {
 		overlay:           afero.NewMemMapFs(),
 		base:              afero.NewOsFs(),
 		diagnosticTimers:  make(map[string]*time.Timer),
+		diagnosticRuns:    make(map[string]*diagnosticRun),
 		Debounce:          0,
 		settings:          defaultSettings,
 		lastValidSettings: defaultSettings,
 		frontmatterCache:  make(map[string]*scan.ParsedFrontmatter),
 		scanCache:         make(map[string]scan.Result),
 		documentVersions:  make(map[string]protocol.Integer),
+		published:         make(map[string][]protocol.Diagnostic),
 	}
 	s.fs = afero.NewCopyOnWriteFs(s.base, s.overlay)
 
@@ -181,6 +188,10 @@ func (s *Server) shutdown(_ *glsp.Context) error {
 		}
 		delete(s.diagnosticTimers, uri)
 	}
+	for uri, run := range s.diagnosticRuns {
+		run.cancel()
+		delete(s.diagnosticRuns, uri)
+	}
 	s.diagnosticsMu.Unlock()
 
 	// Wait for all in-flight tasks to complete
@@ -436,12 +447,20 @@ func (s *Server) didClose(_ *glsp.Context, params *protocol.DidCloseTextDocument
 		delete(s.diagnosticTimers, params.TextDocument.URI)
 		commonlog.GetLogger(serverName).Debugf("Debounce timer canceled on close for %s", params.TextDocument.URI)
 	}
+	if run, ok := s.diagnosticRuns[params.TextDocument.URI]; ok {
+		run.cancel()
+		delete(s.diagnosticRuns, params.TextDocument.URI)
+	}
 	s.diagnosticsMu.Unlock()
 
 	s.versionMu.Lock()
 	delete(s.documentVersions, params.TextDocument.URI)
 	s.versionMu.Unlock()
 
+	s.publishedMu.Lock()
+	delete(s.published, params.TextDocument.URI)
+	s.publishedMu.Unlock()
+
 	s.scanCacheMu.Lock()
 	s.scanCache = make(map[string]scan.Result)
 	s.scanCacheMu.Unlock()
@@ -450,12 +469,32 @@ func (s *Server) didClose(_ *glsp.Context, params *protocol.DidCloseTextDocument
 }
 
 func (s *Server) hover(_ *glsp.Context, params *protocol.HoverParams) (*protocol.Hover, error) {
+	var sections []string
+	if docs := s.ruleDocHover(params.TextDocument.URI, params.Position); docs != "" {
+		sections = append(sections, docs)
+	}
+	if tool := s.toolIDHover(params); tool != "" {
+		sections = append(sections, tool)
+	}
+	if len(sections) == 0 {
+		return nil, nil //nolint:nilnil // Valid LSP response for no hover
+	}
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  protocol.MarkupKindMarkdown,
+			Value: strings.Join(sections, "\n\n---\n\n"),
+		},
+	}, nil
+}
+
+// toolIDHover describes the registry tool when hovering a frontmatter toolId.
+func (s *Server) toolIDHover(params *protocol.HoverParams) string {
 	s.cacheMu.Lock()
 	parsed := s.frontmatterCache[params.TextDocument.URI]
 	s.cacheMu.Unlock()
 
 	if parsed == nil {
-		return nil, nil //nolint:nilnil
+		return ""
 	}
 
 	line := int(params.Position.Line + 1)
@@ -468,34 +507,28 @@ func (s *Server) hover(_ *glsp.Context, params *protocol.HoverParams) (*protocol
 		}
 	}
 
-	if foundKey == "" {
-		return nil, nil //nolint:nilnil // Valid LSP response for no hover
+	if foundKey == "" || !strings.Contains(foundKey, "toolId") {
+		return ""
 	}
 
-	if strings.Contains(foundKey, "toolId") {
-		val, ok := getValue(parsed.Data, foundKey)
-		if ok {
-			toolID, _ := val.(string)
-			if toolID != "" {
-				registry, _, err := scan.LoadRegistry()
-				if err == nil {
-					for _, p := range registry.Patterns {
-						if p.ToolID == toolID {
-							content := fmt.Sprintf("**%s**\n\n%s\n\nDocs: %s", p.ToolName, p.Notes, strings.Join(p.Docs, ", "))
-							return &protocol.Hover{
-								Contents: protocol.MarkupContent{
-									Kind:  protocol.MarkupKindMarkdown,
-									Value: content,
-								},
-							}, nil
-						}
-					}
-				}
-			}
+	val, ok := getValue(parsed.Data, foundKey)
+	if !ok {
+		return ""
+	}
+	toolID, _ := val.(string)
+	if toolID == "" {
+		return ""
+	}
+	registry, _, err := scan.LoadRegistry()
+	if err != nil {
+		return ""
+	}
+	for _, p := range registry.Patterns {
+		if p.ToolID == toolID {
+			return fmt.Sprintf("**%s**\n\n%s\n\nDocs: %s", p.ToolName, p.Notes, strings.Join(p.Docs, ", "))
 		}
 	}
-
-	return nil, nil //nolint:nilnil
+	return ""
 }
 
 func (s *Server) definition(_ *glsp.Context, params *protocol.DefinitionParams) (any, error) {
@@ -592,11 +625,43 @@ func (s *Server) triggerDiagnostics(context *glsp.Context, uri string) {
 	s.diagnosticTimers[uri] = timer
 }
 
+// diagnosticRun tracks an in-flight diagnostics pass so a newer edit or a
+// close can cancel its scan.
+type diagnosticRun struct {
+	cancel context.CancelFunc
+}
+
+// beginDiagnosticRun cancels any pass still running for uri and registers a
+// new one. The returned func releases it.
+func (s *Server) beginDiagnosticRun(uri string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &diagnosticRun{cancel: cancel}
+
+	s.diagnosticsMu.Lock()
+	if previous, ok := s.diagnosticRuns[uri]; ok {
+		previous.cancel()
+		commonlog.GetLogger(serverName).Debugf("Diagnostics run superseded for %s", uri)
+	}
+	s.diagnosticRuns[uri] = run
+	s.diagnosticsMu.Unlock()
+
+	return ctx, func() {
+		cancel()
+		s.diagnosticsMu.Lock()
+		if s.diagnosticRuns[uri] == run {
+			delete(s.diagnosticRuns, uri)
+		}
+		s.diagnosticsMu.Unlock()
+	}
+}
+
 func (s *Server) runDiagnostics(context *glsp.Context, uri string) {
 	path, err := urlToPath(uri)
 	if err != nil {
 		return
 	}
+	ctx, done := s.beginDiagnosticRun(uri)
+	defer done()
 
 	settings := s.currentSettings()
 	caps := s.diagnosticCapsSnapshot()
@@ -611,7 +676,11 @@ func (s *Server) runDiagnostics(context *glsp.Context, uri string) {
 	}
 	repoRoot = filepath.Clean(repoRoot)
 
-	result, registry, err := s.scanForDiagnostics(path, repoRoot)
+	result, registry, err := s.scanForDiagnostics(ctx, path, repoRoot)
+	if ctx.Err() != nil {
+		// A newer run (or close) superseded this one; its results are stale.
+		return
+	}
 	if err != nil {
 		if errors.Is(err, scan.ErrRegistryNotFound) ||
 			errors.Is(err, scan.ErrRegistryPathMissing) ||
@@ -642,8 +711,11 @@ func (s *Server) runDiagnostics(context *glsp.Context, uri string) {
 		Redactor: redactor,
 	}
 
-	rules := s.rulesForSettings(settings)
-	issues := audit.RunRules(auditCtx, rules)
+	rules := s.rulesForSettings(settings, repoRoot)
+	issues := runDiagnosticRules(ctx, auditCtx, rules)
+	// MD015 is reported from the editor buffer below; the audit rule only sees
+	// the frontmatter saved on disk.
+	issues = dropRuleIssues(issues, "MD015")
 	issues = applySeverityOverridesToIssues(issues, settings.Diagnostics.SeverityOverrides)
 	s.logDiagnosticsSummary(issues)
 
@@ -651,11 +723,14 @@ func (s *Server) runDiagnostics(context *glsp.Context, uri string) {
 	if diag := s.unknownToolIDDiagnostic(uri, path, registry, settings, caps); diag != nil {
 		diagnostics = append(diagnostics, *diag)
 	}
+	if ctx.Err() != nil {
+		return
+	}
 
 	s.publishDiagnostics(context, uri, diagnostics)
 }
 
-func (s *Server) scanForDiagnostics(path string, repoRoot string) (scan.Result, scan.Registry, error) {
+func (s *Server) scanForDiagnostics(ctx context.Context, path string, repoRoot string) (scan.Result, scan.Registry, error) {
 	repoOnly := true
 	var userRoots []string
 	if userRoot, ok := userRootForPath(path); ok {
@@ -676,7 +751,7 @@ func (s *Server) scanForDiagnostics(path string, repoRoot string) (scan.Result,
 		stdinPaths = append(stdinPaths, path)
 	}
 
-	result, err := scan.Scan(scan.Options{
+	result, err := scan.ScanContext(ctx, scan.Options{
 		RepoRoot:       repoRoot,
 		RepoOnly:       repoOnly,
 		IncludeContent: true,
@@ -684,7 +759,7 @@ func (s *Server) scanForDiagnostics(path string, repoRoot string) (scan.Result,
 		UserRoots:      userRoots,
 		Registry:       registry,
 		Fs:             s.fs,
-	})
+	}, scan.Sink{})
 	if err != nil {
 		return scan.Result{}, registry, err
 	}
@@ -695,8 +770,44 @@ func (s *Server) scanForDiagnostics(path string, repoRoot string) (scan.Result,
 	return result, registry, nil
 }
 
-func (s *Server) rulesForSettings(settings Settings) []audit.Rule {
+// runDiagnosticRules evaluates rules, logging failing rules (such as plugins)
+// instead of dropping every diagnostic.
+func runDiagnosticRules(ctx context.Context, auditCtx audit.Context, rules []audit.Rule) []audit.Issue {
+	var issues []audit.Issue
+	for _, rule := range rules {
+		if ctx.Err() != nil {
+			break
+		}
+		found, err := rule.EvaluateContext(ctx, auditCtx)
+		if err != nil {
+			commonlog.GetLogger(serverName).Warningf("rule %s failed: %v", rule.ID, err)
+			continue
+		}
+		issues = append(issues, found...)
+	}
+	return issues
+}
+
+func (s *Server) rulesForSettings(settings Settings, repoRoot string) []audit.Rule {
 	rules := audit.DefaultRules()
+	rulesDir := settings.Diagnostics.RulesDir
+	required := rulesDir != ""
+	if rulesDir == "" && repoRoot != "" {
+		rulesDir = filepath.Join(repoRoot, filepath.FromSlash(audit.DefaultRulesDir))
+	} else if rulesDir != "" && !filepath.IsAbs(rulesDir) && repoRoot != "" {
+		rulesDir = filepath.Join(repoRoot, rulesDir)
+	}
+	if rulesDir != "" {
+		options := audit.RulePackOptions{Required: required, AllowPlugins: settings.Diagnostics.AllowPlugins}
+		if custom, warnings, err := audit.LoadRulePacks(s.fs, rulesDir, options); err != nil {
+			commonlog.GetLogger(serverName).Warningf("custom rules ignored: %v", err)
+		} else {
+			for _, warning := range warnings {
+				commonlog.GetLogger(serverName).Warningf("%s", warning)
+			}
+			rules = append(rules, custom...)
+		}
+	}
 	if len(settings.Diagnostics.SeverityOverrides) > 0 {
 		if updated, err := audit.ApplySeverityOverrides(rules, settings.Diagnostics.SeverityOverrides); err != nil {
 			commonlog.GetLogger(serverName).Warningf("severity overrides ignored: %v", err)
@@ -714,6 +825,16 @@ func (s *Server) rulesForSettings(settings Settings) []audit.Rule {
 	return rules
 }
 
+func dropRuleIssues(issues []audit.Issue, ruleID string) []audit.Issue {
+	kept := issues[:0]
+	for _, issue := range issues {
+		if issue.RuleID != ruleID {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
+
 func applySeverityOverridesToIssues(issues []audit.Issue, overrides map[string]audit.Severity) []audit.Issue {
 	if len(overrides) == 0 {
 		return issues
@@ -799,14 +920,14 @@ func (s *Server) unknownToolIDDiagnostic(uri string, path string, registry scan.
 		return nil
 	}
 
-	toolIDs := registryToolIDs(registry)
+	toolIDs := audit.RegistryToolIDs(registry)
 	for _, id := range toolIDs {
 		if strings.EqualFold(id, toolID) {
 			return nil
 		}
 	}
 
-	replacement := closestToolID(toolID, toolIDs)
+	replacement := audit.ClosestToolID(toolID, toolIDs)
 	if replacement == "" {
 		return nil
 	}
@@ -887,22 +1008,6 @@ func (s *Server) frontmatterForURI(uri string, path string) *scan.ParsedFrontmat
 	return parsed
 }
 
-func registryToolIDs(registry scan.Registry) []string {
-	seen := make(map[string]struct{})
-	for _, pattern := range registry.Patterns {
-		if pattern.ToolID == "" {
-			continue
-		}
-		seen[pattern.ToolID] = struct{}{}
-	}
-	ids := make([]string, 0, len(seen))
-	for id := range seen {
-		ids = append(ids, id)
-	}
-	sort.Strings(ids)
-	return ids
-}
-
 func frontmatterValueRange(parsed *scan.ParsedFrontmatter, key string) protocol.Range {
 	if parsed == nil {
 		return protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: protocol.Position{Line: 0, Character: 0}}
@@ -973,6 +1078,9 @@ func (s *Server) publishDiagnostics(context *glsp.Context, uri string, diagnosti
 	if diagnostics == nil {
 		diagnostics = []protocol.Diagnostic{}
 	}
+	s.publishedMu.Lock()
+	s.published[uri] = diagnostics
+	s.publishedMu.Unlock()
 	context.Notify(protocol.ServerTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
 		URI:         uri,
 		Diagnostics: diagnostics,
@@ -1229,22 +1337,6 @@ func (s *Server) codeAction(_ *glsp.Context, params *protocol.CodeActionParams)
 	return actions, nil
 }
 
-func hasGitignoreEntry(content string, entry string) bool {
-	if entry == "" {
-		return true
-	}
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		if strings.TrimSpace(line) == entry {
-			return true
-		}
-		if strings.TrimSpace(line) == "!/"+strings.TrimPrefix(entry, "!") {
-			return true
-		}
-	}
-	return false
-}
-
 func repoRootForPath(root string, path string) string {
 	if root != "" {
 		return filepath.Clean(root)
@@ -1322,38 +1414,6 @@ func expandHomePath(path string) string {
 	return path
 }
 
-func isGlobPath(path string) bool {
-	return strings.ContainsAny(path, "*?[")
-}
-
-func stubContentForPath(path string) string {
-	lower := strings.ToLower(path)
-	switch strings.ToLower(filepath.Ext(lower)) {
-	case ".md", ".markdown", ".mdx":
-		return "# Instructions\n"
-	case ".json":
-		return "{\n}\n"
-	case ".yml", ".yaml", ".toml":
-		return "# TODO: add instructions\n"
-	case ".txt":
-		return "TODO: add instructions\n"
-	default:
-		if strings.HasSuffix(lower, ".prompt.md") || strings.HasSuffix(lower, ".instructions.md") {
-			return "# Instructions\n"
-		}
-		return ""
-	}
-}
-
-func endPositionForContent(content string) protocol.Position {
-	lines := strings.Split(content, "\n")
-	if len(lines) == 0 {
-		return protocol.Position{Line: 0, Character: 0}
-	}
-	line := len(lines) - 1
-	return protocol.Position{Line: clampToUint32(line), Character: utf16Len(lines[line])}
-}
-
 func boolPtr(value bool) *bool {
 	return &value
 }
@@ -1377,62 +1437,12 @@ func pathToURI(path string) string {
 }
 
 func frontmatterBlockRange(content string) *protocol.Range {
-	lines := strings.Split(content, "\n")
-	if len(lines) == 0 {
-		return nil
-	}
-	if strings.TrimSpace(strings.TrimRight(lines[0], "\r")) != "---" {
-		return nil
-	}
-
-	endLine := -1
-	for i := 1; i < len(lines); i++ {
-		if strings.TrimSpace(strings.TrimRight(lines[i], "\r")) == "---" {
-			endLine = i
-			break
-		}
-	}
-	if endLine == -1 {
+	rng := fix.FrontmatterBlockRange(content)
+	if rng == nil {
 		return nil
 	}
-
-	end := endLine
-	endChar := utf16Len(lines[endLine])
-	if endLine+1 < len(lines) {
-		end = endLine + 1
-		endChar = 0
-	}
-
-	return &protocol.Range{
-		Start: protocol.Position{Line: 0, Character: 0},
-		End:   protocol.Position{Line: clampToUint32(end), Character: endChar},
-	}
-}
-
-func lineTextAt(content string, line int) (string, bool) {
-	lines := strings.Split(content, "\n")
-	if line < 0 || line >= len(lines) {
-		return "", false
-	}
-	return strings.TrimRight(lines[line], "\r"), true
-}
-
-func lineDeleteRange(content string, line int) *protocol.Range {
-	lines := strings.Split(content, "\n")
-	if line < 0 || line >= len(lines) {
-		return nil
-	}
-	if line+1 < len(lines) {
-		return &protocol.Range{
-			Start: protocol.Position{Line: clampToUint32(line), Character: 0},
-			End:   protocol.Position{Line: clampToUint32(line + 1), Character: 0},
-		}
-	}
-	text := strings.TrimRight(lines[line], "\r")
-	return &protocol.Range{
-		Start: protocol.Position{Line: clampToUint32(line), Character: 0},
-		End:   protocol.Position{Line: clampToUint32(line), Character: utf16Len(text)},
-	}
+	converted := protocolRange(*rng)
+	return &converted
 }
 
 func decodeContentChange(change any) (protocol.TextDocumentContentChangeEvent, bool) {
@@ -1596,13 +1606,6 @@ func offsetForPosition(content string, pos protocol.Position) int {
 	return len(content)
 }
 
-func utf16Len(value string) uint32 {
-	if value == "" {
-		return 0
-	}
-	return clampToUint32(len(utf16.Encode([]rune(value))))
-}
-
 // RunServer runs the LSP server with the given tool version.
 func RunServer(v string) error {
 	commonlog.Configure(1, nil)
@@ -191,6 +191,43 @@ func TestDidCloseClearsDebounceTimer(t *testing.T) {
 	}
 }
 
+func TestDiagnosticRunSuperseded(t *testing.T) {
+	s := NewServer("0.1.0")
+	path := filepath.Join(t.TempDir(), "AGENTS.md")
+	if err := afero.WriteFile(s.overlay, path, []byte("# Agents"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	uri := pathToURL(path)
+
+	first, doneFirst := s.beginDiagnosticRun(uri)
+	second, doneSecond := s.beginDiagnosticRun(uri)
+	if first.Err() == nil {
+		t.Fatalf("expected newer run to cancel the previous one")
+	}
+	if second.Err() != nil {
+		t.Fatalf("expected newer run to stay active")
+	}
+
+	// Releasing a superseded run leaves the current one registered.
+	doneFirst()
+	s.diagnosticsMu.Lock()
+	_, ok := s.diagnosticRuns[uri]
+	s.diagnosticsMu.Unlock()
+	if !ok {
+		t.Fatalf("expected current run to remain registered")
+	}
+
+	if err := s.didClose(nil, &protocol.DidCloseTextDocumentParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	}); err != nil {
+		t.Fatalf("didClose: %v", err)
+	}
+	if second.Err() == nil {
+		t.Fatalf("expected close to cancel the in-flight run")
+	}
+	doneSecond()
+}
+
 func TestRunDiagnosticsWithError(t *testing.T) {
 	s := NewServer("0.1.0")
 	repoRoot := t.TempDir()
@@ -276,6 +313,42 @@ func TestHover(t *testing.T) {
 	}
 }
 
+func TestHoverRuleDocs(t *testing.T) {
+	s := NewServer("0.1.0")
+	uri := pathToURL(filepath.Join(t.TempDir(), "AGENTS.md"))
+	code := protocol.IntegerOrString{Value: "MD004"}
+	s.published[uri] = []protocol.Diagnostic{{
+		Range: protocol.Range{Start: protocol.Position{Line: 0, Character: 0}, End: protocol.Position{Line: 0, Character: 0}},
+		Code:  &code,
+	}}
+
+	hoverAt := func(line protocol.UInteger) *protocol.Hover {
+		t.Helper()
+		hover, err := s.hover(nil, &protocol.HoverParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Position:     protocol.Position{Line: line, Character: 3},
+			},
+		})
+		if err != nil {
+			t.Fatalf("hover failed: %v", err)
+		}
+		return hover
+	}
+
+	hover := hoverAt(0)
+	if hover == nil {
+		t.Fatal("expected hover result")
+	}
+	markup := hover.Contents.(protocol.MarkupContent)
+	if !strings.Contains(markup.Value, "# MD004: Empty config file") || !strings.Contains(markup.Value, "## How to fix") {
+		t.Errorf("expected MD004 docs, got %s", markup.Value)
+	}
+	if hover := hoverAt(3); hover != nil {
+		t.Errorf("expected no hover outside the diagnostic, got %#v", hover)
+	}
+}
+
 func TestDefinitionRegistry(t *testing.T) {
 	s := NewServer("0.1.0")
 	repoRoot := t.TempDir()
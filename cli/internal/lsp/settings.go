@@ -32,6 +32,8 @@ type DiagnosticsSettings struct {
 	IncludeRelatedInfo bool
 	IncludeEvidence    bool
 	RedactPaths        audit.RedactMode
+	RulesDir           string
+	AllowPlugins       bool
 }
 
 // DefaultSettings returns the baseline configuration.
@@ -103,6 +105,13 @@ func ParseSettings(input any) (Settings, []string) {
 		}
 	}
 
+	if value, ok := readString(diag, "rulesDir"); ok {
+		settings.Diagnostics.RulesDir = value
+	}
+	if value, ok := readBool(diag, "allowPlugins"); ok {
+		settings.Diagnostics.AllowPlugins = value
+	}
+
 	if value, ok := diag["rulesEnabled"]; ok {
 		settings.Diagnostics.RulesEnabled = parseStringSlice(value)
 	}
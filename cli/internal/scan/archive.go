@@ -0,0 +1,344 @@
+package scan
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Archive formats accepted by LoadArchive.
+const (
+	ArchiveTar   = "tar"
+	ArchiveTarGz = "tar.gz"
+	ArchiveZip   = "zip"
+)
+
+// Default archive safety limits, used when the matching ArchiveOptions field
+// is zero.
+const (
+	DefaultArchiveMaxFiles      = 50000
+	DefaultArchiveMaxFileBytes  = 64 << 20
+	DefaultArchiveMaxTotalBytes = 1 << 30
+	DefaultArchiveMaxRatio      = 200
+)
+
+// archiveRatioFloor is the uncompressed size below which the compression
+// ratio is not checked, so tiny highly compressible files are not rejected.
+const archiveRatioFloor = 1 << 20
+
+// ErrArchiveLimit reports an archive that exceeds a safety limit.
+var ErrArchiveLimit = errors.New("archive exceeds safety limit")
+
+// ArchiveOptions configures LoadArchive.
+type ArchiveOptions struct {
+	// Format is tar, tar.gz, or zip. Empty detects the format from the
+	// archive's leading bytes.
+	Format string
+	// Root is the absolute path the archive is mounted at. Scan output paths
+	// are reported under it.
+	Root string
+	// StripComponents drops this many leading path elements from every
+	// entry, like tar --strip-components. Entries with no elements left are
+	// skipped.
+	StripComponents int
+	// MaxFiles caps the number of regular files.
+	MaxFiles int
+	// MaxFileBytes caps the uncompressed size of a single file.
+	MaxFileBytes int64
+	// MaxTotalBytes caps the uncompressed size of all files.
+	MaxTotalBytes int64
+	// MaxRatio caps the ratio of uncompressed to compressed bytes.
+	MaxRatio int64
+}
+
+// Archive is a tar or zip archive extracted into a read-only in-memory
+// filesystem. Fs holds the archive's files under Root.
+type Archive struct {
+	Fs     afero.Fs
+	Root   string
+	Format string
+	ignore *ignoreMatcher
+}
+
+// LoadArchiveFile opens path and loads it with LoadArchive. Root defaults to
+// the archive's absolute path, so entries are reported as
+// /path/to/repo.tar.gz/AGENTS.md.
+func LoadArchiveFile(archivePath string, opts ArchiveOptions) (*Archive, error) {
+	file, err := os.Open(archivePath) //nolint:gosec // user-provided archive path
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("archive is a directory: %s", archivePath)
+	}
+	if opts.Root == "" {
+		abs, err := filepath.Abs(archivePath)
+		if err != nil {
+			return nil, err
+		}
+		opts.Root = abs
+	}
+	return LoadArchive(file, info.Size(), opts)
+}
+
+// LoadArchive extracts a tar, tar.gz, or zip archive into memory. Entries
+// with absolute paths or ".." elements are rejected, symlinks, hard links,
+// and special files are skipped, and the configured limits are enforced on
+// the bytes actually read rather than on header sizes.
+func LoadArchive(r io.ReaderAt, size int64, opts ArchiveOptions) (*Archive, error) {
+	if opts.Root == "" || !filepath.IsAbs(opts.Root) {
+		return nil, fmt.Errorf("archive root must be an absolute path")
+	}
+	if opts.StripComponents < 0 {
+		return nil, fmt.Errorf("strip-components must be >= 0")
+	}
+	opts = archiveDefaults(opts)
+
+	format := opts.Format
+	if format == "" {
+		detected, err := detectArchiveFormat(r, size)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	mem := afero.NewMemMapFs()
+	root := filepath.Clean(opts.Root)
+	if err := mem.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	loader := &archiveLoader{fs: mem, root: root, opts: opts, ignore: &ignoreMatcher{}, compressed: size}
+
+	var err error
+	switch format {
+	case ArchiveTar:
+		err = loader.loadTar(io.NewSectionReader(r, 0, size))
+	case ArchiveTarGz:
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(io.NewSectionReader(r, 0, size))
+		if err == nil {
+			err = loader.loadTar(gz)
+			_ = gz.Close()
+		}
+	case ArchiveZip:
+		err = loader.loadZip(r, size)
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %q (valid: tar, tar.gz, zip)", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Archive{Fs: afero.NewReadOnlyFs(mem), Root: root, Format: format, ignore: loader.ignore}, nil
+}
+
+// ApplyGitignore populates gitignored flags for repo-scope entries using the
+// archive's .gitignore files.
+func (a *Archive) ApplyGitignore(result Result) Result {
+	return applyTreeIgnore(result, a.Root, a.ignore)
+}
+
+func archiveDefaults(opts ArchiveOptions) ArchiveOptions {
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultArchiveMaxFiles
+	}
+	if opts.MaxFileBytes <= 0 {
+		opts.MaxFileBytes = DefaultArchiveMaxFileBytes
+	}
+	if opts.MaxTotalBytes <= 0 {
+		opts.MaxTotalBytes = DefaultArchiveMaxTotalBytes
+	}
+	if opts.MaxRatio <= 0 {
+		opts.MaxRatio = DefaultArchiveMaxRatio
+	}
+	return opts
+}
+
+// detectArchiveFormat sniffs gzip and zip magic numbers and the ustar marker.
+func detectArchiveFormat(r io.ReaderAt, size int64) (string, error) {
+	header := make([]byte, 512)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	header = header[:n]
+	switch {
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return ArchiveTarGz, nil
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return ArchiveZip, nil
+	case len(header) >= 262 && bytes.HasPrefix(header[257:], []byte("ustar")):
+		return ArchiveTar, nil
+	case size == 0:
+		return "", fmt.Errorf("archive is empty")
+	}
+	return "", fmt.Errorf("unrecognized archive format (valid: tar, tar.gz, zip)")
+}
+
+type archiveLoader struct {
+	fs         afero.Fs
+	root       string
+	opts       ArchiveOptions
+	ignore     *ignoreMatcher
+	compressed int64
+	files      int
+	total      int64
+}
+
+func (l *archiveLoader) loadTar(r io.Reader) error {
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read tar: %w", err)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := l.addDir(header.Name); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA: //nolint:staticcheck // TypeRegA appears in old archives
+			if err := l.addFile(header.Name, reader, header.ModTime); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (l *archiveLoader) loadZip(r io.ReaderAt, size int64) error {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("read zip: %w", err)
+	}
+	for _, file := range reader.File {
+		mode := file.Mode()
+		if mode.IsDir() {
+			if err := l.addDir(file.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		if !mode.IsRegular() {
+			continue
+		}
+		if err := l.addZipFile(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *archiveLoader) addZipFile(file *zip.File) error {
+	body, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("read zip entry %s: %w", file.Name, err)
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	return l.addFile(file.Name, body, file.Modified)
+}
+
+// entryPath validates an archive entry name and maps it under the root. ok is
+// false when strip-components consumes the whole name.
+func (l *archiveLoader) entryPath(name string) (string, bool, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" {
+		return "", false, fmt.Errorf("archive entry has an absolute path: %s", name)
+	}
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			return "", false, fmt.Errorf("archive entry escapes root: %s", name)
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) <= l.opts.StripComponents {
+		return "", false, nil
+	}
+	parts = parts[l.opts.StripComponents:]
+	target := filepath.Join(l.root, filepath.FromSlash(path.Join(parts...)))
+	if !isWithinRoot(target, l.root) {
+		return "", false, fmt.Errorf("archive entry escapes root: %s", name)
+	}
+	return target, true, nil
+}
+
+func (l *archiveLoader) addDir(name string) error {
+	target, ok, err := l.entryPath(name)
+	if err != nil || !ok {
+		return err
+	}
+	return l.fs.MkdirAll(target, 0o755)
+}
+
+func (l *archiveLoader) addFile(name string, r io.Reader, modTime time.Time) error {
+	target, ok, err := l.entryPath(name)
+	if err != nil || !ok {
+		return err
+	}
+	l.files++
+	if l.files > l.opts.MaxFiles {
+		return fmt.Errorf("%w: more than %d files", ErrArchiveLimit, l.opts.MaxFiles)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, l.opts.MaxFileBytes+1))
+	if err != nil {
+		return fmt.Errorf("read archive entry %s: %w", name, err)
+	}
+	if int64(len(data)) > l.opts.MaxFileBytes {
+		return fmt.Errorf("%w: %s is larger than %d bytes", ErrArchiveLimit, name, l.opts.MaxFileBytes)
+	}
+	l.total += int64(len(data))
+	if l.total > l.opts.MaxTotalBytes {
+		return fmt.Errorf("%w: more than %d bytes uncompressed", ErrArchiveLimit, l.opts.MaxTotalBytes)
+	}
+	if l.total > archiveRatioFloor && l.total > l.compressed*l.opts.MaxRatio {
+		return fmt.Errorf("%w: compression ratio above %d", ErrArchiveLimit, l.opts.MaxRatio)
+	}
+
+	if err := l.fs.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(l.fs, target, data, 0o644); err != nil {
+		return err
+	}
+	if !modTime.IsZero() {
+		if err := l.fs.Chtimes(target, modTime, modTime); err != nil {
+			return err
+		}
+	}
+	if filepath.Base(target) == ".gitignore" {
+		rel, err := filepath.Rel(l.root, filepath.Dir(target))
+		if err != nil {
+			return err
+		}
+		l.ignore.add(filepath.ToSlash(rel), data)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package scan
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+type archiveFile struct {
+	name string
+	body string
+}
+
+func buildTar(t *testing.T, files []archiveFile, compress bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		out = gz
+	}
+	writer := tar.NewWriter(out)
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0o644, Size: int64(len(file.body)), Typeflag: tar.TypeReg}
+		if strings.HasSuffix(file.name, "/") {
+			header = &tar.Header{Name: file.name, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("tar header: %v", err)
+		}
+		if _, err := writer.Write([]byte(file.body)); err != nil {
+			t.Fatalf("tar write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("tar close: %v", err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatalf("gzip close: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func buildZip(t *testing.T, files []archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := writer.Create(file.name)
+		if err != nil {
+			t.Fatalf("zip create: %v", err)
+		}
+		if _, err := w.Write([]byte(file.body)); err != nil {
+			t.Fatalf("zip write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func loadTestArchive(data []byte, opts ArchiveOptions) (*Archive, error) {
+	if opts.Root == "" {
+		opts.Root = filepath.FromSlash("/archive")
+	}
+	return LoadArchive(bytes.NewReader(data), int64(len(data)), opts)
+}
+
+func TestLoadArchiveFormats(t *testing.T) {
+	files := []archiveFile{
+		{name: "repo-main/", body: ""},
+		{name: "repo-main/AGENTS.md", body: "# Agents\n"},
+		{name: "repo-main/.gitignore", body: "vendor/\n"},
+		{name: "repo-main/vendor/AGENTS.md", body: "# Vendored\n"},
+	}
+	cases := map[string][]byte{
+		ArchiveTar:   buildTar(t, files, false),
+		ArchiveTarGz: buildTar(t, files, true),
+		ArchiveZip:   buildZip(t, files),
+	}
+	for format, data := range cases {
+		t.Run(format, func(t *testing.T) {
+			archive, err := loadTestArchive(data, ArchiveOptions{StripComponents: 1})
+			if err != nil {
+				t.Fatalf("LoadArchive: %v", err)
+			}
+			if archive.Format != format {
+				t.Fatalf("expected format %s, got %s", format, archive.Format)
+			}
+			content, err := afero.ReadFile(archive.Fs, filepath.Join(archive.Root, "AGENTS.md"))
+			if err != nil || string(content) != "# Agents\n" {
+				t.Fatalf("expected stripped AGENTS.md, got %q (%v)", content, err)
+			}
+			if err := afero.WriteFile(archive.Fs, filepath.Join(archive.Root, "new.md"), []byte("x"), 0o600); err == nil {
+				t.Fatalf("expected archive filesystem to be read-only")
+			}
+
+			result, err := Scan(Options{
+				RepoRoot:       archive.Root,
+				RepoOnly:       true,
+				IncludeContent: true,
+				Registry:       watchRegistry(),
+				Fs:             archive.Fs,
+			})
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			result = archive.ApplyGitignore(result)
+			ignored := map[string]bool{}
+			for _, entry := range result.Entries {
+				ignored[entry.Path] = entry.Gitignored
+			}
+			if len(ignored) != 2 || ignored[filepath.Join(archive.Root, "AGENTS.md")] || !ignored[filepath.Join(archive.Root, "vendor", "AGENTS.md")] {
+				t.Fatalf("unexpected scan entries: %v", ignored)
+			}
+		})
+	}
+}
+
+func TestLoadArchiveRejectsTraversal(t *testing.T) {
+	cases := map[string][]byte{
+		"tar dotdot":  buildTar(t, []archiveFile{{name: "../evil.md", body: "x"}}, false),
+		"tar nested":  buildTar(t, []archiveFile{{name: "repo/../../evil.md", body: "x"}}, false),
+		"tar abs":     buildTar(t, []archiveFile{{name: "/etc/evil.md", body: "x"}}, false),
+		"zip dotdot":  buildZip(t, []archiveFile{{name: "../evil.md", body: "x"}}),
+		"zip windows": buildZip(t, []archiveFile{{name: `..\evil.md`, body: "x"}}),
+	}
+	for name, data := range cases {
+		if _, err := loadTestArchive(data, ArchiveOptions{}); err == nil {
+			t.Errorf("%s: expected traversal to be rejected", name)
+		}
+	}
+}
+
+func TestLoadArchiveLimits(t *testing.T) {
+	bomb := buildTar(t, []archiveFile{{name: "big.md", body: strings.Repeat("a", 4<<20)}}, true)
+	if _, err := loadTestArchive(bomb, ArchiveOptions{}); !errors.Is(err, ErrArchiveLimit) {
+		t.Fatalf("expected compression ratio limit, got %v", err)
+	}
+
+	files := []archiveFile{{name: "a.md", body: "aaaa"}, {name: "b.md", body: "bbbb"}}
+	data := buildZip(t, files)
+	limits := []ArchiveOptions{
+		{MaxFiles: 1},
+		{MaxFileBytes: 3},
+		{MaxTotalBytes: 6},
+	}
+	for _, opts := range limits {
+		if _, err := loadTestArchive(data, opts); !errors.Is(err, ErrArchiveLimit) {
+			t.Errorf("expected limit error for %+v, got %v", opts, err)
+		}
+	}
+	if _, err := loadTestArchive(data, ArchiveOptions{MaxFiles: 2, MaxFileBytes: 4, MaxTotalBytes: 8}); err != nil {
+		t.Fatalf("expected archive within limits to load: %v", err)
+	}
+}
+
+func TestLoadArchiveUnknownFormat(t *testing.T) {
+	if _, err := loadTestArchive([]byte("not an archive"), ArchiveOptions{}); err == nil {
+		t.Fatalf("expected unrecognized format error")
+	}
+	if _, err := loadTestArchive(buildZip(t, nil), ArchiveOptions{Format: "rar"}); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
@@ -0,0 +1,351 @@
+package scan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// cacheFormatVersion is bumped when the on-disk cache layout changes.
+const cacheFormatVersion = 2
+
+// cacheRacyWindow excludes recently modified paths from the cache so a change
+// within the filesystem's timestamp granularity is never missed.
+const cacheRacyWindow = 2 * time.Second
+
+// CacheKey identifies the scan configuration a cache file belongs to. A cache
+// whose key does not match is discarded.
+type CacheKey struct {
+	RepoRoot        string
+	RegistryVersion string
+	ToolVersion     string
+}
+
+// CacheStats reports cache effectiveness for a scan.
+type CacheStats struct {
+	DirHits     int  `json:"dirHits"`
+	DirMisses   int  `json:"dirMisses"`
+	FileHits    int  `json:"fileHits"`
+	FileMisses  int  `json:"fileMisses"`
+	Invalidated bool `json:"invalidated,omitempty"`
+}
+
+// Cache persists directory listings and file hashes between scans.
+// Unchanged directories (same mtime and inode) skip ReadDir and are only
+// re-entered for subdirectories, symlinks, and files that matched a pattern;
+// unchanged files (same size, mtime, and inode) reuse their hash and parsed
+// frontmatter. Global scope and stdin paths are never cached.
+type Cache struct {
+	path    string
+	key     CacheKey
+	started time.Time
+
+	mu    sync.Mutex
+	prev  cacheData
+	next  cacheData
+	stats CacheStats
+}
+
+type cacheData struct {
+	Version         int                   `json:"version"`
+	RepoRoot        string                `json:"repoRoot"`
+	RegistryVersion string                `json:"registryVersion"`
+	ToolVersion     string                `json:"toolVersion"`
+	PatternsHash    string                `json:"patternsHash"`
+	Dirs            map[string]cachedDir  `json:"dirs"`
+	Files           map[string]cachedFile `json:"files"`
+}
+
+type cachedDir struct {
+	MtimeNs int64  `json:"mtimeNs"`
+	Inode   uint64 `json:"inode,omitempty"`
+	// Children lists entry names that must be revisited; regular files that
+	// matched no pattern are omitted.
+	Children []string `json:"children"`
+}
+
+type cachedFile struct {
+	Size                 int64            `json:"size"`
+	MtimeNs              int64            `json:"mtimeNs"`
+	Inode                uint64           `json:"inode,omitempty"`
+	Sha256               string           `json:"sha256"`
+	Binary               bool             `json:"binary,omitempty"`
+	Frontmatter          map[string]any   `json:"frontmatter,omitempty"`
+	FrontmatterLocations map[string]Range `json:"frontmatterLocations,omitempty"`
+	FrontmatterError     *string          `json:"frontmatterError,omitempty"`
+	References           []Reference      `json:"references,omitempty"`
+	ReferencesParsed     bool             `json:"referencesParsed,omitempty"`
+}
+
+// DefaultCachePath returns the cache file for repoRoot under
+// $XDG_CACHE_HOME/markdowntown/scan (default ~/.cache).
+func DefaultCachePath(repoRoot string) (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".cache")
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(repoRoot)))
+	return filepath.Join(base, "markdowntown", "scan", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// LoadCache reads the cache at path. A missing, unreadable, or mismatched
+// cache yields an empty cache; Stats reports Invalidated when a previous cache
+// was discarded.
+func LoadCache(path string, key CacheKey) *Cache {
+	cache := &Cache{path: path, key: key, started: time.Now()}
+	cache.prev = newCacheData(key)
+	cache.next = newCacheData(key)
+
+	// #nosec G304 -- path is the markdowntown cache file.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	var stored cacheData
+	if err := json.Unmarshal(data, &stored); err != nil ||
+		stored.Version != cacheFormatVersion ||
+		stored.RepoRoot != key.RepoRoot ||
+		stored.RegistryVersion != key.RegistryVersion ||
+		stored.ToolVersion != key.ToolVersion {
+		cache.stats.Invalidated = true
+		return cache
+	}
+	if stored.Dirs == nil {
+		stored.Dirs = map[string]cachedDir{}
+	}
+	if stored.Files == nil {
+		stored.Files = map[string]cachedFile{}
+	}
+	cache.prev = stored
+	return cache
+}
+
+func newCacheData(key CacheKey) cacheData {
+	return cacheData{
+		Version:         cacheFormatVersion,
+		RepoRoot:        key.RepoRoot,
+		RegistryVersion: key.RegistryVersion,
+		ToolVersion:     key.ToolVersion,
+		Dirs:            map[string]cachedDir{},
+		Files:           map[string]cachedFile{},
+	}
+}
+
+// Save writes the entries seen during the last scan, dropping paths that were
+// not visited.
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	data, err := json.Marshal(c.next)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".scan-cache-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// Stats returns hit and miss counts for the last scan.
+func (c *Cache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// prepare discards cached listings when the compiled patterns differ, since
+// listings omit files that did not match.
+func (c *Cache) prepare(patterns []CompiledPattern) {
+	sources := make([]Pattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		sources = append(sources, pattern.Pattern)
+	}
+	data, _ := json.Marshal(sources)
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started = time.Now()
+	c.next.PatternsHash = hash
+	if c.prev.PatternsHash != hash {
+		if len(c.prev.Dirs) > 0 || len(c.prev.Files) > 0 {
+			c.stats.Invalidated = true
+		}
+		c.prev.Dirs = map[string]cachedDir{}
+		c.prev.Files = map[string]cachedFile{}
+	}
+}
+
+func (c *Cache) racy(info os.FileInfo) bool {
+	return !info.ModTime().Before(c.started.Add(-cacheRacyWindow))
+}
+
+func dirCacheKey(scope string, root string, path string) string {
+	return scope + "\x00" + root + "\x00" + path
+}
+
+// dirChildren returns the cached children of an unchanged directory.
+func (c *Cache) dirChildren(key string, info os.FileInfo) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.prev.Dirs[key]
+	inode, _ := inodeFromInfo(info)
+	if !ok || cached.MtimeNs != info.ModTime().UnixNano() || cached.Inode != inode {
+		c.stats.DirMisses++
+		return nil, false
+	}
+	c.stats.DirHits++
+	c.next.Dirs[key] = cached
+	return cached.Children, true
+}
+
+func (c *Cache) storeDir(key string, info os.FileInfo, children []string) {
+	if c.racy(info) {
+		return
+	}
+	inode, _ := inodeFromInfo(info)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next.Dirs[key] = cachedDir{MtimeNs: info.ModTime().UnixNano(), Inode: inode, Children: children}
+}
+
+// file returns the cached content metadata for an unchanged file.
+func (c *Cache) file(path string, info os.FileInfo) (cachedFile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.prev.Files[path]
+	inode, _ := inodeFromInfo(info)
+	if !ok || cached.Size != info.Size() || cached.MtimeNs != info.ModTime().UnixNano() || cached.Inode != inode {
+		c.stats.FileMisses++
+		return cachedFile{}, false
+	}
+	c.stats.FileHits++
+	c.next.Files[path] = cached
+	return cached, true
+}
+
+func (c *Cache) storeFile(path string, info os.FileInfo, entry *ConfigEntry, graph bool) {
+	if entry.Error != nil || entry.Sha256 == nil || entry.SizeBytes == nil || *entry.SizeBytes != info.Size() || c.racy(info) {
+		return
+	}
+	inode, _ := inodeFromInfo(info)
+	cached := cachedFile{
+		Size:                 info.Size(),
+		MtimeNs:              info.ModTime().UnixNano(),
+		Inode:                inode,
+		Sha256:               *entry.Sha256,
+		Binary:               entry.ContentSkipped != nil,
+		Frontmatter:          entry.Frontmatter,
+		FrontmatterLocations: entry.FrontmatterLocations,
+		FrontmatterError:     entry.FrontmatterError,
+		References:           entry.References,
+		ReferencesParsed:     graph,
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.next.Files[path] = cached
+}
+
+// populateEntryContentCached fills entry from the cache when the file is
+// unchanged, reading it only when content or unparsed references are needed.
+func populateEntryContentCached(fs afero.Fs, cache *Cache, entry *ConfigEntry, resolvedPath string, root string, includeContent bool, graph bool) {
+	if cache == nil || entry.Scope == ScopeGlobal || entry.FromStdin {
+		populateEntryContent(fs, entry, resolvedPath, root, includeContent, graph)
+		return
+	}
+	info, err := safeStat(fs, resolvedPath)
+	if err != nil {
+		populateEntryContent(fs, entry, resolvedPath, root, includeContent, graph)
+		return
+	}
+	if cached, ok := cache.file(resolvedPath, info); ok && applyCachedFile(fs, entry, cached, resolvedPath, root, includeContent, graph) {
+		return
+	}
+	populateEntryContent(fs, entry, resolvedPath, root, includeContent, graph)
+	cache.storeFile(resolvedPath, info, entry, graph)
+}
+
+func applyCachedFile(fs afero.Fs, entry *ConfigEntry, cached cachedFile, resolvedPath string, root string, includeContent bool, graph bool) bool {
+	if graph && !cached.ReferencesParsed {
+		return false
+	}
+	var content *string
+	if includeContent && !cached.Binary {
+		data, err := safeReadFile(fs, root, resolvedPath)
+		if err != nil || int64(len(data)) != cached.Size {
+			return false
+		}
+		text := string(data)
+		content = &text
+	}
+
+	size := cached.Size
+	sha := cached.Sha256
+	entry.SizeBytes = &size
+	entry.Sha256 = &sha
+	if size == 0 {
+		warning := "empty"
+		entry.Warning = &warning
+	}
+	entry.Frontmatter = cached.Frontmatter
+	entry.FrontmatterLocations = cached.FrontmatterLocations
+	entry.FrontmatterError = cached.FrontmatterError
+	entry.References = cached.References
+	if cached.Binary {
+		skipped := "binary"
+		entry.ContentSkipped = &skipped
+		return true
+	}
+	entry.Content = content
+	return true
+}
+
+func inodeFromInfo(info os.FileInfo) (uint64, bool) {
+	if info == nil {
+		return 0, false
+	}
+	sys := info.Sys()
+	if sys == nil {
+		return 0, false
+	}
+	value := reflect.ValueOf(sys)
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if !value.IsValid() || value.Kind() != reflect.Struct {
+		return 0, false
+	}
+	return uintFromStatField(value.FieldByName("Ino"))
+}
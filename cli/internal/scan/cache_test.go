@@ -0,0 +1,172 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// agePaths backdates paths so they fall outside the cache's racy window.
+func agePaths(t *testing.T, when time.Time, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		if err := os.Chtimes(path, when, when); err != nil {
+			t.Fatalf("chtimes %s: %v", path, err)
+		}
+	}
+}
+
+func writeCacheFixture(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func cachedScan(t *testing.T, repo string, cachePath string, registry Registry) (Result, CacheStats) {
+	t.Helper()
+	cache := LoadCache(cachePath, CacheKey{RepoRoot: repo, RegistryVersion: registry.Version, ToolVersion: "test"})
+	result, err := Scan(Options{RepoRoot: repo, RepoOnly: true, IncludeContent: true, Registry: registry, Cache: cache})
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("save cache: %v", err)
+	}
+	return result, cache.Stats()
+}
+
+func entryByPath(t *testing.T, result Result, path string) ConfigEntry {
+	t.Helper()
+	for _, entry := range result.Entries {
+		if entry.Path == path {
+			return entry
+		}
+	}
+	t.Fatalf("missing entry %s in %#v", path, result.Entries)
+	return ConfigEntry{}
+}
+
+func TestScanCacheReusesUnchangedPaths(t *testing.T) {
+	repo := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	rootConfig := filepath.Join(repo, "AGENTS.md")
+	docsConfig := filepath.Join(repo, "docs", "AGENTS.md")
+	writeCacheFixture(t, rootConfig, "---\ntitle: Root\n---\n# Root\n")
+	writeCacheFixture(t, docsConfig, "# Docs\n")
+	writeCacheFixture(t, filepath.Join(repo, "docs", "notes.txt"), "notes")
+	old := time.Now().Add(-time.Hour)
+	agePaths(t, old, rootConfig, docsConfig, filepath.Join(repo, "docs", "notes.txt"), filepath.Join(repo, "docs"), repo)
+
+	registry := watchRegistry()
+	first, stats := cachedScan(t, repo, cachePath, registry)
+	if stats.DirHits != 0 || stats.FileHits != 0 || stats.FileMisses != 2 {
+		t.Fatalf("unexpected cold stats: %#v", stats)
+	}
+
+	second, stats := cachedScan(t, repo, cachePath, registry)
+	if stats.DirHits != 2 || stats.DirMisses != 0 || stats.FileHits != 2 || stats.FileMisses != 0 {
+		t.Fatalf("unexpected warm stats: %#v", stats)
+	}
+	if len(second.Entries) != len(first.Entries) {
+		t.Fatalf("expected %d entries, got %d", len(first.Entries), len(second.Entries))
+	}
+	cold := entryByPath(t, first, rootConfig)
+	warm := entryByPath(t, second, rootConfig)
+	if *warm.Sha256 != *cold.Sha256 || warm.Frontmatter["title"] != "Root" || warm.Content == nil || *warm.Content != *cold.Content {
+		t.Fatalf("cached entry differs: cold %#v warm %#v", cold, warm)
+	}
+
+	// Changing a file and adding one to a directory only misses those paths.
+	writeCacheFixture(t, docsConfig, "# Docs v2\n")
+	writeCacheFixture(t, filepath.Join(repo, "docs", "api", "AGENTS.md"), "# API\n")
+	newer := old.Add(time.Minute)
+	agePaths(t, newer, docsConfig, filepath.Join(repo, "docs", "api", "AGENTS.md"), filepath.Join(repo, "docs", "api"), filepath.Join(repo, "docs"))
+
+	third, stats := cachedScan(t, repo, cachePath, registry)
+	if stats.DirHits != 1 || stats.DirMisses != 2 || stats.FileHits != 1 || stats.FileMisses != 2 {
+		t.Fatalf("unexpected incremental stats: %#v", stats)
+	}
+	if *entryByPath(t, third, docsConfig).Sha256 == *entryByPath(t, second, docsConfig).Sha256 {
+		t.Fatalf("expected changed hash for %s", docsConfig)
+	}
+	entryByPath(t, third, filepath.Join(repo, "docs", "api", "AGENTS.md"))
+}
+
+func TestScanCacheParsesReferencesForGraph(t *testing.T) {
+	repo := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	config := filepath.Join(repo, "AGENTS.md")
+	writeCacheFixture(t, config, "[guide](docs/guide.md)\n")
+	agePaths(t, time.Now().Add(-time.Hour), config, repo)
+	registry := watchRegistry()
+
+	// A cache written without the graph has no references to reuse.
+	plain, _ := cachedScan(t, repo, cachePath, registry)
+	if refs := entryByPath(t, plain, config).References; refs != nil {
+		t.Fatalf("expected no references without graph, got %+v", refs)
+	}
+	cache := LoadCache(cachePath, CacheKey{RepoRoot: repo, RegistryVersion: registry.Version, ToolVersion: "test"})
+	result, err := Scan(Options{RepoRoot: repo, RepoOnly: true, Registry: registry, Cache: cache, Graph: true})
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if refs := entryByPath(t, result, config).References; len(refs) != 1 {
+		t.Fatalf("expected 1 reference, got %+v", refs)
+	}
+}
+
+func TestScanCacheSkipsRecentPaths(t *testing.T) {
+	repo := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	writeCacheFixture(t, filepath.Join(repo, "AGENTS.md"), "# Root\n")
+
+	registry := watchRegistry()
+	cachedScan(t, repo, cachePath, registry)
+	_, stats := cachedScan(t, repo, cachePath, registry)
+	if stats.DirHits != 0 || stats.FileHits != 0 {
+		t.Fatalf("expected recently modified paths to bypass the cache, got %#v", stats)
+	}
+}
+
+func TestScanCacheInvalidation(t *testing.T) {
+	repo := t.TempDir()
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	config := filepath.Join(repo, "AGENTS.md")
+	writeCacheFixture(t, config, "# Root\n")
+	agePaths(t, time.Now().Add(-time.Hour), config, repo)
+
+	registry := watchRegistry()
+	cachedScan(t, repo, cachePath, registry)
+
+	changedPatterns := watchRegistry()
+	changedPatterns.Patterns[0].Paths = append(changedPatterns.Patterns[0].Paths, "**/CLAUDE.md")
+	if _, stats := cachedScan(t, repo, cachePath, changedPatterns); !stats.Invalidated || stats.DirHits != 0 || stats.FileHits != 0 {
+		t.Fatalf("expected pattern change to invalidate cache, got %#v", stats)
+	}
+
+	newVersion := changedPatterns
+	newVersion.Version = "2"
+	if _, stats := cachedScan(t, repo, cachePath, newVersion); !stats.Invalidated || stats.FileHits != 0 {
+		t.Fatalf("expected registry version change to invalidate cache, got %#v", stats)
+	}
+	if _, stats := cachedScan(t, repo, cachePath, newVersion); stats.Invalidated || stats.FileHits != 1 {
+		t.Fatalf("expected warm cache after invalidation, got %#v", stats)
+	}
+}
+
+func TestDefaultCachePath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/cache-home")
+	first, err := DefaultCachePath("/repo/a")
+	if err != nil {
+		t.Fatalf("DefaultCachePath: %v", err)
+	}
+	second, _ := DefaultCachePath("/repo/b")
+	if filepath.Dir(first) != filepath.Join("/tmp/cache-home", "markdowntown", "scan") || first == second {
+		t.Fatalf("unexpected cache paths %s %s", first, second)
+	}
+}
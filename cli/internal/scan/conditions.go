@@ -0,0 +1,169 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// PatternConditions restricts when a matched pattern applies. Every listed
+// requirement must hold.
+type PatternConditions struct {
+	// RequiresFile lists paths that must exist, relative to the matched
+	// file's directory unless absolute or starting with ~/.
+	RequiresFile []string `json:"requiresFile,omitempty"`
+	// RequiresSetting lists VS Code settings. "key" requires a truthy value
+	// and "key=value" an exact match.
+	RequiresSetting []string `json:"requiresSetting,omitempty"`
+	// RequiresEnv lists environment variables. "NAME" requires a non-empty
+	// value and "NAME=value" an exact match.
+	RequiresEnv []string `json:"requiresEnv,omitempty"`
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateConditions reports malformed condition entries.
+func validateConditions(conditions *PatternConditions) error {
+	if conditions == nil {
+		return nil
+	}
+	for _, path := range conditions.RequiresFile {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("conditions.requiresFile: empty path")
+		}
+	}
+	for _, setting := range conditions.RequiresSetting {
+		key, _, _ := strings.Cut(setting, "=")
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("conditions.requiresSetting: empty setting name in %q", setting)
+		}
+	}
+	for _, env := range conditions.RequiresEnv {
+		name, _, _ := strings.Cut(env, "=")
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("conditions.requiresEnv: invalid variable name in %q", env)
+		}
+	}
+	return nil
+}
+
+// conditionEnv evaluates pattern conditions for one walk. VS Code settings
+// are read on first use.
+type conditionEnv struct {
+	fs            afero.Fs
+	settingsPaths []string
+	lookupEnv     func(string) (string, bool)
+
+	once     sync.Once
+	settings map[string]any
+}
+
+// newConditionEnv reads settings from the repo's .vscode/settings.json and,
+// unless the scan is repo-only, from settings.json in each user root. Repo
+// settings take precedence.
+func newConditionEnv(fs afero.Fs, opts Options) *conditionEnv {
+	var paths []string
+	if !opts.RepoOnly {
+		userRoots := opts.UserRoots
+		if len(userRoots) == 0 {
+			userRoots = DefaultUserRoots()
+		}
+		for _, root := range userRoots {
+			if strings.TrimSpace(root) == "" {
+				continue
+			}
+			paths = append(paths, filepath.Join(expandHomePath(root), "settings.json"))
+		}
+	}
+	if opts.RepoRoot != "" {
+		paths = append(paths, filepath.Join(opts.RepoRoot, ".vscode", "settings.json"))
+	}
+	return &conditionEnv{fs: fs, settingsPaths: paths, lookupEnv: os.LookupEnv}
+}
+
+func (c *conditionEnv) loadSettings() map[string]any {
+	c.once.Do(func() {
+		c.settings = make(map[string]any)
+		for _, path := range c.settingsPaths {
+			data, err := afero.ReadFile(c.fs, path)
+			if err != nil {
+				continue
+			}
+			var parsed map[string]any
+			if err := json.Unmarshal(stripJSONCTrailingCommas(stripJSONCComments(data)), &parsed); err != nil {
+				continue
+			}
+			for key, value := range parsed {
+				c.settings[key] = value
+			}
+		}
+	})
+	return c.settings
+}
+
+// met reports whether conditions hold for the file at absPath. A nil
+// environment skips evaluation.
+func (c *conditionEnv) met(conditions *PatternConditions, absPath string) bool {
+	if c == nil || conditions == nil {
+		return true
+	}
+	for _, required := range conditions.RequiresFile {
+		target := filepath.FromSlash(expandHomePath(required))
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(absPath), target)
+		}
+		if _, err := c.fs.Stat(target); err != nil {
+			return false
+		}
+	}
+	for _, required := range conditions.RequiresEnv {
+		name, want, exact := strings.Cut(required, "=")
+		value, ok := c.lookupEnv(name)
+		if !ok || (exact && value != want) || (!exact && value == "") {
+			return false
+		}
+	}
+	if len(conditions.RequiresSetting) > 0 {
+		settings := c.loadSettings()
+		for _, required := range conditions.RequiresSetting {
+			key, want, exact := strings.Cut(required, "=")
+			value, ok := settings[key]
+			if !ok {
+				return false
+			}
+			if exact {
+				if fmt.Sprint(value) != want {
+					return false
+				}
+			} else if !truthySetting(value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func truthySetting(value any) bool {
+	switch typed := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return typed
+	case string:
+		return typed != ""
+	case float64:
+		return typed != 0
+	case []any:
+		return len(typed) > 0
+	case map[string]any:
+		return len(typed) > 0
+	default:
+		return true
+	}
+}
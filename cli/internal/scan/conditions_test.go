@@ -0,0 +1,123 @@
+package scan
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestMatcherExclude(t *testing.T) {
+	compiled, err := CompilePatterns(Registry{Patterns: []Pattern{{
+		ID:      "rules",
+		Scope:   "repo",
+		Paths:   []string{".cursor/rules/**/*"},
+		Exclude: []string{"**/README.md", "**/*.PNG"},
+	}}})
+	if err != nil {
+		t.Fatalf("CompilePatterns: %v", err)
+	}
+	cases := map[string]bool{
+		".cursor/rules/main.mdc":         true,
+		".cursor/rules/readme.md":        false,
+		".cursor/rules/nested/README.md": false,
+		".cursor/rules/img/diagram.png":  false,
+	}
+	for rel, want := range cases {
+		matched, _, err := compiled[0].Match("/repo/"+rel, rel)
+		if err != nil {
+			t.Fatalf("Match %s: %v", rel, err)
+		}
+		if matched != want {
+			t.Fatalf("Match %s = %v, want %v", rel, matched, want)
+		}
+	}
+}
+
+func TestConditionEnvMet(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	root := filepath.FromSlash("/repo")
+	userRoot := filepath.FromSlash("/home/user/Code/User")
+	files := map[string]string{
+		filepath.Join(root, ".vscode", "settings.json"): `{
+  // repo settings win
+  "chat.promptFiles": true,
+  "editor.mode": "strict",
+}`,
+		filepath.Join(userRoot, "settings.json"): `{"chat.promptFiles": false, "user.only": ["a"]}`,
+		filepath.Join(root, "pkg", "go.mod"):     "module x\n",
+	}
+	for path, content := range files {
+		if err := afero.WriteFile(fs, path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := newConditionEnv(fs, Options{RepoRoot: root, UserRoots: []string{userRoot}})
+	env.lookupEnv = func(name string) (string, bool) {
+		switch name {
+		case "CI":
+			return "true", true
+		case "EMPTY":
+			return "", true
+		}
+		return "", false
+	}
+
+	file := filepath.Join(root, "pkg", "AGENTS.md")
+	cases := []struct {
+		name       string
+		conditions PatternConditions
+		want       bool
+	}{
+		{"sibling file", PatternConditions{RequiresFile: []string{"go.mod"}}, true},
+		{"missing file", PatternConditions{RequiresFile: []string{"package.json"}}, false},
+		{"absolute file", PatternConditions{RequiresFile: []string{filepath.Join(root, ".vscode", "settings.json")}}, true},
+		{"truthy setting", PatternConditions{RequiresSetting: []string{"chat.promptFiles"}}, true},
+		{"user setting", PatternConditions{RequiresSetting: []string{"user.only"}}, true},
+		{"setting value", PatternConditions{RequiresSetting: []string{"editor.mode=strict"}}, true},
+		{"setting mismatch", PatternConditions{RequiresSetting: []string{"editor.mode=loose"}}, false},
+		{"missing setting", PatternConditions{RequiresSetting: []string{"missing.key"}}, false},
+		{"env set", PatternConditions{RequiresEnv: []string{"CI"}}, true},
+		{"env value", PatternConditions{RequiresEnv: []string{"CI=false"}}, false},
+		{"env empty", PatternConditions{RequiresEnv: []string{"EMPTY"}}, false},
+		{"all required", PatternConditions{RequiresFile: []string{"go.mod"}, RequiresEnv: []string{"UNSET"}}, false},
+	}
+	for _, tc := range cases {
+		conditions := tc.conditions
+		if got := env.met(&conditions, file); got != tc.want {
+			t.Fatalf("%s: met = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	repoOnly := newConditionEnv(fs, Options{RepoRoot: root, RepoOnly: true})
+	if repoOnly.met(&PatternConditions{RequiresSetting: []string{"user.only"}}, file) {
+		t.Fatalf("expected user settings to be ignored for repo-only scans")
+	}
+}
+
+func TestScanPatternConditions(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	root := filepath.FromSlash("/repo")
+	for _, rel := range []string{"AGENTS.md", "svc/AGENTS.md", "svc/package.json"} {
+		if err := afero.WriteFile(fs, filepath.Join(root, filepath.FromSlash(rel)), []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registry := Registry{Patterns: []Pattern{
+		{ID: "agents", ToolID: "codex", ToolName: "Codex", Kind: "instructions", Scope: "repo", Paths: []string{"**/AGENTS.md"}, Type: "glob"},
+		{ID: "agents-node", ToolID: "node-agent", ToolName: "Node Agent", Kind: "instructions", Scope: "repo", Paths: []string{"**/AGENTS.md"}, Type: "glob",
+			Conditions: &PatternConditions{RequiresFile: []string{"package.json"}}},
+	}}
+	result, err := Scan(Options{RepoRoot: root, RepoOnly: true, Registry: registry, Fs: fs})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	tools := make(map[string]int)
+	for _, entry := range result.Entries {
+		rel, _ := filepath.Rel(root, entry.Path)
+		tools[filepath.ToSlash(rel)] = len(entry.Tools)
+	}
+	if tools["AGENTS.md"] != 1 || tools["svc/AGENTS.md"] != 2 {
+		t.Fatalf("unexpected tool counts: %v", tools)
+	}
+}
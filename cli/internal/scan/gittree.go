@@ -0,0 +1,337 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/spf13/afero"
+
+	"markdowntown-cli/internal/git"
+)
+
+// GitTree is a commit loaded into memory so it can be scanned without a
+// checkout. Fs holds the commit's files under Root, the repository's path on
+// disk, so scan output paths match a working-tree scan.
+type GitTree struct {
+	Fs     afero.Fs
+	Root   string
+	Commit string
+	ignore *ignoreMatcher
+}
+
+// LoadGitTree loads ref into an in-memory filesystem using git ls-tree and
+// git cat-file. Only files a scan with reg reads (see SparsePatterns) are read
+// up front; every other file is an empty stub whose blob is read the first
+// time it is opened, so walks and stats stay cheap. Symlinks are skipped and
+// submodules become empty directories. File mtimes are set to the commit
+// time.
+func LoadGitTree(repoRoot string, ref string, reg Registry) (*GitTree, error) {
+	root, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	root = filepath.Clean(root)
+
+	commit, err := git.ResolveCommit(root, ref)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := git.ListTree(root, commit)
+	if err != nil {
+		return nil, err
+	}
+	committedAt, err := git.CommitTime(root, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Regex registries cannot be narrowed, so every blob is read up front.
+	var wanted *ignoreMatcher
+	if patterns := SparsePatterns(reg); patterns != nil {
+		wanted = &ignoreMatcher{}
+		wanted.add("", []byte(strings.Join(patterns, "\n")))
+	}
+
+	mem := afero.NewMemMapFs()
+	fs := &gitTreeFs{Fs: mem, repoRoot: root, committedAt: committedAt, lazy: make(map[string]string)}
+	if err := mem.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	paths := make(map[string][]string)
+	var objects []string
+	ignore := &ignoreMatcher{}
+	for _, entry := range entries {
+		target := filepath.Join(root, filepath.FromSlash(entry.Path))
+		if !isWithinRoot(target, root) {
+			return nil, fmt.Errorf("tree entry escapes repo root: %s", entry.Path)
+		}
+		switch entry.Mode {
+		case git.ModeSymlink:
+			continue
+		case git.ModeSubmodule:
+			if err := mem.MkdirAll(target, 0o755); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if wanted != nil && !wanted.ignored(entry.Path) {
+			if err := fs.writeFile(target, nil); err != nil {
+				return nil, err
+			}
+			fs.lazy[target] = entry.Object
+			continue
+		}
+		if _, ok := paths[entry.Object]; !ok {
+			objects = append(objects, entry.Object)
+		}
+		paths[entry.Object] = append(paths[entry.Object], entry.Path)
+	}
+
+	err = git.ReadBlobs(root, objects, func(object string, data []byte) error {
+		for _, rel := range paths[object] {
+			if err := fs.writeFile(filepath.Join(root, filepath.FromSlash(rel)), data); err != nil {
+				return err
+			}
+			if path.Base(rel) == ".gitignore" {
+				ignore.add(path.Dir(rel), data)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitTree{Fs: fs, Root: root, Commit: commit, ignore: ignore}, nil
+}
+
+// gitTreeFs is the in-memory commit. Files in lazy are empty stubs until
+// opened, when their blob is read from the repository.
+type gitTreeFs struct {
+	afero.Fs
+	repoRoot    string
+	committedAt time.Time
+
+	mu sync.Mutex
+	// lazy maps stub paths to the blob holding their content.
+	lazy map[string]string
+}
+
+// Open reads a stub's blob before opening it.
+func (f *gitTreeFs) Open(name string) (afero.File, error) {
+	if err := f.load(name); err != nil {
+		return nil, err
+	}
+	return f.Fs.Open(name)
+}
+
+// OpenFile reads a stub's blob before opening it.
+func (f *gitTreeFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if err := f.load(name); err != nil {
+		return nil, err
+	}
+	return f.Fs.OpenFile(name, flag, perm)
+}
+
+// LstatIfPossible reports stubs as they are; stat does not read blobs.
+func (f *gitTreeFs) LstatIfPossible(name string) (os.FileInfo, bool, error) {
+	if lstater, ok := f.Fs.(afero.Lstater); ok {
+		return lstater.LstatIfPossible(name)
+	}
+	info, err := f.Fs.Stat(name)
+	return info, false, err
+}
+
+func (f *gitTreeFs) load(name string) error {
+	name = filepath.Clean(name)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	object, ok := f.lazy[name]
+	if !ok {
+		return nil
+	}
+	err := git.ReadBlobs(f.repoRoot, []string{object}, func(_ string, data []byte) error {
+		return f.writeFile(name, data)
+	})
+	if err != nil {
+		return err
+	}
+	delete(f.lazy, name)
+	return nil
+}
+
+func (f *gitTreeFs) writeFile(target string, data []byte) error {
+	if err := f.Fs.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := afero.WriteFile(f.Fs, target, data, 0o644); err != nil {
+		return err
+	}
+	return f.Fs.Chtimes(target, f.committedAt, f.committedAt)
+}
+
+// ApplyGitignore populates gitignored flags for repo-scope entries using the
+// commit's .gitignore files. Ignore rules outside the tree (.git/info/exclude,
+// core.excludesFile) are not consulted.
+func (t *GitTree) ApplyGitignore(result Result) Result {
+	return applyTreeIgnore(result, t.Root, t.ignore)
+}
+
+// applyTreeIgnore sets gitignored flags for repo-scope entries under root
+// from .gitignore rules read out of an in-memory tree.
+func applyTreeIgnore(result Result, root string, ignore *ignoreMatcher) Result {
+	for i := range result.Entries {
+		entry := &result.Entries[i]
+		if entry.Scope != ScopeRepo {
+			continue
+		}
+		rel, err := filepath.Rel(root, entry.Path)
+		if err != nil || rel == "." || !isWithinRoot(entry.Path, root) {
+			continue
+		}
+		entry.Gitignored = ignore.ignored(filepath.ToSlash(rel))
+	}
+	return result
+}
+
+// CheckIgnore reports which paths the commit's .gitignore files ignore. It
+// matches git.CheckIgnore so tree-backed callers can swap it in.
+func (t *GitTree) CheckIgnore(repoRoot string, paths []string) (map[string]bool, error) {
+	ignored := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		target := p
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(repoRoot, target)
+		}
+		rel, err := filepath.Rel(t.Root, target)
+		if err != nil || rel == "." || !isWithinRoot(target, t.Root) {
+			continue
+		}
+		ignored[p] = t.ignore.ignored(filepath.ToSlash(rel))
+	}
+	return ignored, nil
+}
+
+// ignoreMatcher evaluates .gitignore rules read from a tree.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	// base is the slash-separated directory holding the .gitignore ("" for
+	// the root).
+	base     string
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+func (m *ignoreMatcher) add(dir string, data []byte) {
+	if dir == "." {
+		dir = ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rule, ok := parseIgnoreLine(dir, line); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+	// Rules from deeper directories take precedence, so keep them after
+	// their parents; within one file, order is preserved.
+	sortIgnoreRules(m.rules)
+}
+
+func parseIgnoreLine(base string, line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimIgnoreTrailingSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	rule.pattern = line
+	return rule, true
+}
+
+func trimIgnoreTrailingSpace(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return strings.ReplaceAll(line, `\ `, " ")
+}
+
+func sortIgnoreRules(rules []ignoreRule) {
+	depth := func(base string) int {
+		if base == "" {
+			return 0
+		}
+		return strings.Count(base, "/") + 1
+	}
+	// Stable insertion sort: rule counts are small and order within a depth
+	// must not change.
+	for i := 1; i < len(rules); i++ {
+		for j := i; j > 0 && depth(rules[j].base) < depth(rules[j-1].base); j-- {
+			rules[j], rules[j-1] = rules[j-1], rules[j]
+		}
+	}
+}
+
+// ignored reports whether rel (a slash-separated file path) is ignored. As in
+// git, a file inside an ignored directory cannot be re-included.
+func (m *ignoreMatcher) ignored(rel string) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.match(rel, false)
+}
+
+func (m *ignoreMatcher) match(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		candidate := rel
+		if rule.base != "" {
+			if !strings.HasPrefix(rel, rule.base+"/") {
+				continue
+			}
+			candidate = strings.TrimPrefix(rel, rule.base+"/")
+		}
+		if !rule.anchored {
+			candidate = path.Base(candidate)
+		}
+		if ok, err := doublestar.Match(rule.pattern, candidate); err == nil && ok {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,112 @@
+package scan
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	matcher := &ignoreMatcher{}
+	matcher.add(".", []byte("# comment\n*.log\nbuild/\n/root-only.md\n!keep.log\ndocs/**/draft.md\n!build/keep.md\n"))
+	matcher.add("pkg", []byte("local.md\n!build\n"))
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "debug.log", want: true},
+		{path: "nested/debug.log", want: true},
+		{path: "keep.log", want: false},
+		{path: "build/AGENTS.md", want: true},
+		{path: "build", want: false},
+		{path: "root-only.md", want: true},
+		{path: "sub/root-only.md", want: false},
+		{path: "docs/a/b/draft.md", want: true},
+		{path: "docs/draft.md", want: true},
+		{path: "pkg/local.md", want: true},
+		{path: "local.md", want: false},
+		// A file in an ignored directory cannot be re-included.
+		{path: "build/keep.md", want: true},
+		{path: "pkg/build/AGENTS.md", want: false},
+		{path: "AGENTS.md", want: false},
+	}
+	for _, tt := range tests {
+		if got := matcher.ignored(tt.path); got != tt.want {
+			t.Errorf("ignored(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadGitTreeScan(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoRoot := t.TempDir()
+	execGit(t, repoRoot, "init")
+	execGit(t, repoRoot, "config", "user.email", "test@example.com")
+	execGit(t, repoRoot, "config", "user.name", "Test")
+	writeTestFile(t, filepath.Join(repoRoot, "AGENTS.md"), "# Committed\n")
+	writeTestFile(t, filepath.Join(repoRoot, ".gitignore"), "vendor/\n")
+	writeTestFile(t, filepath.Join(repoRoot, "vendor", "AGENTS.md"), "# Vendored\n")
+	writeTestFile(t, filepath.Join(repoRoot, "docs", "guide.md"), "# Guide\n")
+	execGit(t, repoRoot, "add", "-f", ".")
+	execGit(t, repoRoot, "commit", "-m", "first")
+
+	// Working tree changes must not leak into the ref scan.
+	writeTestFile(t, filepath.Join(repoRoot, "AGENTS.md"), "# Working tree\n")
+	writeTestFile(t, filepath.Join(repoRoot, "docs", "AGENTS.md"), "# Untracked\n")
+
+	tree, err := LoadGitTree(repoRoot, "HEAD", watchRegistry())
+	if err != nil {
+		t.Fatalf("LoadGitTree: %v", err)
+	}
+	registry := watchRegistry()
+	result, err := Scan(Options{
+		RepoRoot:       tree.Root,
+		RepoOnly:       true,
+		IncludeContent: true,
+		Registry:       registry,
+		Fs:             tree.Fs,
+	})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	result = tree.ApplyGitignore(result)
+
+	byPath := map[string]ConfigEntry{}
+	for _, entry := range result.Entries {
+		byPath[entry.Path] = entry
+	}
+	if len(byPath) != 2 {
+		t.Fatalf("expected 2 entries, got %v", byPath)
+	}
+	root := byPath[filepath.Join(tree.Root, "AGENTS.md")]
+	if root.Content == nil || *root.Content != "# Committed\n" || root.SizeBytes == nil || *root.SizeBytes != int64(len("# Committed\n")) {
+		t.Fatalf("expected committed content, got %#v", root)
+	}
+	if root.Gitignored {
+		t.Fatalf("expected root AGENTS.md not ignored")
+	}
+	if !byPath[filepath.Join(tree.Root, "vendor", "AGENTS.md")].Gitignored {
+		t.Fatalf("expected vendor/AGENTS.md ignored by the tree's .gitignore")
+	}
+
+	// Files no pattern matches are stubs until opened.
+	guide := filepath.Join(tree.Root, "docs", "guide.md")
+	if info, err := tree.Fs.Stat(guide); err != nil || info.Size() != 0 {
+		t.Fatalf("expected unread stub for docs/guide.md, got %v (%v)", info, err)
+	}
+	if data, err := afero.ReadFile(tree.Fs, guide); err != nil || string(data) != "# Guide\n" {
+		t.Fatalf("expected docs/guide.md read on open, got %q (%v)", data, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(repoRoot, "AGENTS.md"))
+	if err != nil || string(data) != "# Working tree\n" {
+		t.Fatalf("expected working tree untouched, got %q (%v)", data, err)
+	}
+}
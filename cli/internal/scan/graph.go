@@ -0,0 +1,374 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// Graph edge kinds.
+const (
+	// EdgeClaudeImport is an @path import in a Claude Code memory file.
+	EdgeClaudeImport = "claude-import"
+	// EdgeGeminiImport is an @path import in a Gemini CLI context file.
+	EdgeGeminiImport = "gemini-import"
+	// EdgeAiderRead is an entry of the read: list in .aider.conf.yml.
+	EdgeAiderRead = "aider-read"
+	// EdgeMarkdownLink is a relative Markdown link.
+	EdgeMarkdownLink = "markdown-link"
+)
+
+// Graph warning codes.
+const (
+	WarningGraphDangling = "GRAPH_DANGLING_EDGE"
+	WarningGraphCycle    = "GRAPH_CYCLE"
+)
+
+// Graph lists references between config files found while reading content.
+type Graph struct {
+	Edges []Edge `json:"edges"`
+	// Cycles lists import cycles as sorted node paths. Markdown links are
+	// not considered, since mutual links between documents are common.
+	Cycles [][]string `json:"cycles"`
+}
+
+// Edge is one reference from a config file.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+	// Target is the reference as written in the source file.
+	Target   string `json:"target"`
+	Range    Range  `json:"range"`
+	Dangling bool   `json:"dangling,omitempty"`
+}
+
+// Reference is an unresolved reference parsed from a config file.
+type Reference struct {
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+	Range  Range  `json:"range"`
+}
+
+var (
+	markdownLinkPattern = regexp.MustCompile(`!?\[[^\]]*\]\(\s*(<[^>]*>|[^)\s]+)(?:\s+"[^"]*")?\s*\)`)
+	importPattern       = regexp.MustCompile(`(?:^|\s)@(\S+)`)
+	urlSchemePattern    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+)
+
+// parseReferences extracts imports, links, and read entries from a config
+// file's content. Which syntaxes apply depends on the matched tools.
+func parseReferences(path string, tools []ToolEntry, data []byte) []Reference {
+	importKind := ""
+	aider := false
+	for _, tool := range tools {
+		switch tool.ToolID {
+		case "claude-code":
+			importKind = EdgeClaudeImport
+		case "gemini-cli":
+			if importKind == "" {
+				importKind = EdgeGeminiImport
+			}
+		case "aider":
+			aider = true
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".mdc", ".markdown":
+		return parseMarkdownReferences(data, importKind)
+	case ".yml", ".yaml":
+		if aider {
+			return parseAiderReads(data)
+		}
+	}
+	return nil
+}
+
+func parseMarkdownReferences(data []byte, importKind string) []Reference {
+	var refs []Reference
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	lineNum := 0
+	fence := ""
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		line = maskInlineCode(line)
+
+		for _, match := range markdownLinkPattern.FindAllStringSubmatchIndex(line, -1) {
+			if line[match[0]] == '!' {
+				continue
+			}
+			target := strings.TrimSuffix(strings.TrimPrefix(line[match[2]:match[3]], "<"), ">")
+			target = localLinkTarget(target)
+			if target == "" {
+				continue
+			}
+			refs = append(refs, Reference{
+				Kind:   EdgeMarkdownLink,
+				Target: target,
+				Range:  Range{StartLine: lineNum, StartCol: match[0] + 1, EndLine: lineNum, EndCol: match[1] + 1},
+			})
+		}
+
+		if importKind == "" {
+			continue
+		}
+		for _, match := range importPattern.FindAllStringSubmatchIndex(line, -1) {
+			start := match[2] - 1
+			end := match[3]
+			target := line[match[2]:match[3]]
+			if target == "import" {
+				// Legacy "@import path" form.
+				rest := line[match[3]:]
+				fields := strings.Fields(rest)
+				if len(fields) == 0 {
+					continue
+				}
+				target = strings.Trim(fields[0], `"'`)
+				end = match[3] + strings.Index(rest, fields[0]) + len(fields[0])
+			}
+			target = strings.TrimRight(target, ".,;:)")
+			if !strings.ContainsAny(target, "/.") || urlSchemePattern.MatchString(target) {
+				continue
+			}
+			refs = append(refs, Reference{
+				Kind:   importKind,
+				Target: target,
+				Range:  Range{StartLine: lineNum, StartCol: start + 1, EndLine: lineNum, EndCol: end + 1},
+			})
+		}
+	}
+	return refs
+}
+
+// maskInlineCode blanks out `code spans` so columns stay aligned.
+func maskInlineCode(line string) string {
+	if !strings.Contains(line, "`") {
+		return line
+	}
+	masked := []byte(line)
+	inCode := false
+	for i, b := range masked {
+		if b == '`' {
+			inCode = !inCode
+			masked[i] = ' '
+			continue
+		}
+		if inCode {
+			masked[i] = ' '
+		}
+	}
+	return string(masked)
+}
+
+// localLinkTarget returns the file part of a relative link, or "" for
+// anchors, URLs, and other non-file targets.
+func localLinkTarget(target string) string {
+	if target == "" || strings.HasPrefix(target, "#") || urlSchemePattern.MatchString(target) {
+		return ""
+	}
+	if idx := strings.IndexAny(target, "#?"); idx >= 0 {
+		target = target[:idx]
+	}
+	return target
+}
+
+func parseAiderReads(data []byte) []Reference {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	var refs []Reference
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "read" {
+			continue
+		}
+		value := root.Content[i+1]
+		values := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			values = value.Content
+		}
+		for _, node := range values {
+			if node.Kind != yaml.ScalarNode || node.Value == "" {
+				continue
+			}
+			refs = append(refs, Reference{
+				Kind:   EdgeAiderRead,
+				Target: node.Value,
+				Range:  Range{StartLine: node.Line, StartCol: node.Column, EndLine: node.Line, EndCol: node.Column + len(node.Value)},
+			})
+		}
+	}
+	return refs
+}
+
+// resolveReference maps a reference target to an absolute path relative to
+// the referencing file. A leading ~/ expands to the home directory.
+func resolveReference(from string, target string) string {
+	target = filepath.FromSlash(target)
+	if strings.HasPrefix(target, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, target[2:])
+		}
+	}
+	if filepath.IsAbs(target) {
+		return filepath.Clean(target)
+	}
+	return filepath.Join(filepath.Dir(from), target)
+}
+
+// buildGraph resolves entry references into edges, marking targets missing
+// from fs as dangling, and reports dangling edges and import cycles as
+// warnings.
+func buildGraph(fs afero.Fs, entries []ConfigEntry, repoRoot string) (*Graph, []Warning) {
+	graph := &Graph{Edges: []Edge{}, Cycles: [][]string{}}
+	var warnings []Warning
+	for _, entry := range entries {
+		for _, ref := range entry.References {
+			to := resolveReference(entry.Path, ref.Target)
+			_, err := fs.Stat(to)
+			edge := Edge{From: entry.Path, To: to, Kind: ref.Kind, Target: ref.Target, Range: ref.Range, Dangling: err != nil}
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		left, right := graph.Edges[i], graph.Edges[j]
+		if left.From != right.From {
+			return sortPathKey(left.From) < sortPathKey(right.From)
+		}
+		if left.Range.StartLine != right.Range.StartLine {
+			return left.Range.StartLine < right.Range.StartLine
+		}
+		return left.Range.StartCol < right.Range.StartCol
+	})
+
+	for _, edge := range graph.Edges {
+		if edge.Dangling {
+			warnings = append(warnings, Warning{
+				Path:    edge.From,
+				Code:    WarningGraphDangling,
+				Message: fmt.Sprintf("%s target not found: %s (line %d)", edge.Kind, edge.Target, edge.Range.StartLine),
+			})
+		}
+	}
+
+	graph.Cycles = importCycles(graph.Edges)
+	for _, cycle := range graph.Cycles {
+		names := make([]string, len(cycle))
+		for i, node := range cycle {
+			names[i] = displayPath(node, repoRoot)
+		}
+		warnings = append(warnings, Warning{
+			Path:    cycle[0],
+			Code:    WarningGraphCycle,
+			Message: "import cycle between " + strings.Join(names, ", "),
+		})
+	}
+	return graph, warnings
+}
+
+// importCycles returns the strongly connected components of the import edges
+// that contain a cycle, using Tarjan's algorithm over sorted nodes.
+func importCycles(edges []Edge) [][]string {
+	adjacency := make(map[string][]string)
+	selfLoop := make(map[string]bool)
+	for _, edge := range edges {
+		if edge.Dangling || edge.Kind == EdgeMarkdownLink {
+			continue
+		}
+		adjacency[edge.From] = append(adjacency[edge.From], edge.To)
+		if edge.From == edge.To {
+			selfLoop[edge.From] = true
+		}
+	}
+	nodes := make([]string, 0, len(adjacency))
+	for node := range adjacency {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	cycles := [][]string{}
+
+	var visit func(node string)
+	visit = func(node string) {
+		indices[node] = index
+		lowlink[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+		for _, next := range adjacency[node] {
+			if _, seen := indices[next]; !seen {
+				visit(next)
+				lowlink[node] = min(lowlink[node], lowlink[next])
+			} else if onStack[next] {
+				lowlink[node] = min(lowlink[node], indices[next])
+			}
+		}
+		if lowlink[node] != indices[node] {
+			return
+		}
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == node {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop[node] {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+	for _, node := range nodes {
+		if _, seen := indices[node]; !seen {
+			visit(node)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
+
+// displayPath shortens path to be relative to repoRoot when it is inside.
+func displayPath(path string, repoRoot string) string {
+	if repoRoot != "" && isWithinRoot(path, repoRoot) {
+		if rel, err := filepath.Rel(repoRoot, path); err == nil && rel != "." {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
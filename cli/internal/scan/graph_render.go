@@ -0,0 +1,105 @@
+package scan
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// graphNodes returns every edge endpoint in sorted order.
+func graphNodes(graph *Graph) []string {
+	seen := make(map[string]struct{})
+	var nodes []string
+	for _, edge := range graph.Edges {
+		for _, node := range []string{edge.From, edge.To} {
+			if _, ok := seen[node]; ok {
+				continue
+			}
+			seen[node] = struct{}{}
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return sortPathKey(nodes[i]) < sortPathKey(nodes[j])
+	})
+	return nodes
+}
+
+func danglingNodes(graph *Graph) map[string]bool {
+	dangling := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		if edge.Dangling {
+			dangling[edge.To] = true
+		}
+	}
+	return dangling
+}
+
+// RenderGraphDOT renders the graph as Graphviz DOT. Node labels are relative
+// to repoRoot; dangling targets and their edges are dashed red.
+func RenderGraphDOT(graph *Graph, repoRoot string) string {
+	var builder strings.Builder
+	builder.WriteString("digraph markdowntown {\n")
+	builder.WriteString("  rankdir=LR;\n")
+	builder.WriteString("  node [shape=box];\n")
+	if graph == nil {
+		builder.WriteString("}\n")
+		return builder.String()
+	}
+	dangling := danglingNodes(graph)
+	for _, node := range graphNodes(graph) {
+		attrs := ""
+		if dangling[node] {
+			attrs = ", style=dashed, color=red"
+		}
+		_, _ = fmt.Fprintf(&builder, "  %s [label=%s%s];\n", strconv.Quote(node), strconv.Quote(displayPath(node, repoRoot)), attrs)
+	}
+	for _, edge := range graph.Edges {
+		attrs := ""
+		if edge.Dangling {
+			attrs = ", style=dashed, color=red"
+		}
+		_, _ = fmt.Fprintf(&builder, "  %s -> %s [label=%s%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), strconv.Quote(edge.Kind), attrs)
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// RenderGraphMermaid renders the graph as a Mermaid flowchart. Dangling
+// edges are dotted and their targets use the dangling class.
+func RenderGraphMermaid(graph *Graph, repoRoot string) string {
+	var builder strings.Builder
+	builder.WriteString("flowchart LR\n")
+	if graph == nil {
+		return builder.String()
+	}
+	ids := make(map[string]string)
+	dangling := danglingNodes(graph)
+	var danglingIDs []string
+	for i, node := range graphNodes(graph) {
+		id := "n" + strconv.Itoa(i)
+		ids[node] = id
+		_, _ = fmt.Fprintf(&builder, "  %s[\"%s\"]\n", id, mermaidLabel(displayPath(node, repoRoot)))
+		if dangling[node] {
+			danglingIDs = append(danglingIDs, id)
+		}
+	}
+	for _, edge := range graph.Edges {
+		arrow := "-->"
+		if edge.Dangling {
+			arrow = "-.->"
+		}
+		_, _ = fmt.Fprintf(&builder, "  %s %s|%s| %s\n", ids[edge.From], arrow, edge.Kind, ids[edge.To])
+	}
+	if len(danglingIDs) > 0 {
+		builder.WriteString("  classDef dangling stroke:#d00,stroke-dasharray:4\n")
+		_, _ = fmt.Fprintf(&builder, "  class %s dangling\n", strings.Join(danglingIDs, ","))
+	}
+	return builder.String()
+}
+
+// mermaidLabel escapes characters that end a quoted Mermaid label.
+func mermaidLabel(label string) string {
+	return strings.ReplaceAll(label, `"`, "#quot;")
+}
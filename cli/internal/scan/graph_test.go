@@ -0,0 +1,152 @@
+package scan
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestParseMarkdownReferences(t *testing.T) {
+	content := strings.Join([]string{
+		"# Memory",
+		"See [style](docs/style.md#naming) and [site](https://example.com).",
+		"![diagram](img/arch.png) [top](#memory) `[code](skip.md)`",
+		"Import @docs/rules.md, then @import ./legacy.md",
+		"Mail me@example.com or ping @alice.",
+		"```",
+		"@docs/fenced.md [fenced](fenced.md)",
+		"```",
+	}, "\n")
+	claude := []ToolEntry{{ToolID: "claude-code"}}
+	refs := parseReferences("/repo/CLAUDE.md", claude, []byte(content))
+
+	var got []string
+	for _, ref := range refs {
+		got = append(got, ref.Kind+" "+ref.Target)
+	}
+	want := []string{
+		"markdown-link docs/style.md",
+		"claude-import docs/rules.md",
+		"claude-import ./legacy.md",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("references = %v, want %v", got, want)
+	}
+	if refs[0].Range != (Range{StartLine: 2, StartCol: 5, EndLine: 2, EndCol: 34}) {
+		t.Fatalf("unexpected link range: %+v", refs[0].Range)
+	}
+	if refs[1].Range.StartLine != 4 || refs[1].Range.StartCol != 8 {
+		t.Fatalf("unexpected import range: %+v", refs[1].Range)
+	}
+
+	if refs := parseReferences("/repo/AGENTS.md", []ToolEntry{{ToolID: "codex"}}, []byte("@docs/rules.md\n")); len(refs) != 0 {
+		t.Fatalf("expected no imports for codex files, got %+v", refs)
+	}
+	gemini := parseReferences("/repo/GEMINI.md", []ToolEntry{{ToolID: "gemini-cli"}}, []byte("@./shared/style.md\n"))
+	if len(gemini) != 1 || gemini[0].Kind != EdgeGeminiImport {
+		t.Fatalf("expected gemini import, got %+v", gemini)
+	}
+}
+
+func TestParseAiderReads(t *testing.T) {
+	aider := []ToolEntry{{ToolID: "aider"}}
+	refs := parseReferences("/repo/.aider.conf.yml", aider, []byte("model: gpt\nread:\n  - CONVENTIONS.md\n  - docs/api.md\n"))
+	if len(refs) != 2 || refs[0].Target != "CONVENTIONS.md" || refs[1].Kind != EdgeAiderRead {
+		t.Fatalf("unexpected aider refs: %+v", refs)
+	}
+	if refs[0].Range != (Range{StartLine: 3, StartCol: 5, EndLine: 3, EndCol: 19}) {
+		t.Fatalf("unexpected aider range: %+v", refs[0].Range)
+	}
+	single := parseReferences("/repo/.aider.conf.yml", aider, []byte("read: CONVENTIONS.md\n"))
+	if len(single) != 1 {
+		t.Fatalf("expected scalar read entry, got %+v", single)
+	}
+}
+
+func TestScanGraph(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	root := filepath.FromSlash("/repo")
+	files := map[string]string{
+		"CLAUDE.md":                 "@.claude/rules/a.md\n[guide](docs/guide.md)\n[missing](docs/missing.md)\n",
+		".claude/rules/a.md":        "@import b.md\n",
+		".claude/rules/b.md":        "@import a.md\n",
+		"docs/guide.md":             "# Guide\n",
+		".aider.conf.yml":           "read: [CONVENTIONS.md]\n",
+		".github/copilot-instr.txt": "ignored\n",
+	}
+	for rel, content := range files {
+		if err := afero.WriteFile(fs, filepath.Join(root, filepath.FromSlash(rel)), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	registry := Registry{Patterns: []Pattern{
+		{ID: "claude-memory", ToolID: "claude-code", ToolName: "Claude Code", Kind: "instructions", Scope: "repo", Paths: []string{"CLAUDE.md"}, Type: "glob"},
+		{ID: "claude-rules", ToolID: "claude-code", ToolName: "Claude Code", Kind: "rules", Scope: "repo", Paths: []string{".claude/rules/*.md"}, Type: "glob"},
+		{ID: "aider-config-yml", ToolID: "aider", ToolName: "Aider", Kind: "config", Scope: "repo", Paths: []string{".aider.conf.yml"}, Type: "glob"},
+	}}
+
+	result, err := Scan(Options{RepoRoot: root, RepoOnly: true, Registry: registry, Fs: fs, Graph: true})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	output := BuildOutput(result, OutputOptions{RepoRoot: root})
+	graph := output.Graph
+	if graph == nil {
+		t.Fatal("expected graph in output")
+	}
+	if len(graph.Edges) != 6 {
+		t.Fatalf("expected 6 edges, got %+v", graph.Edges)
+	}
+	dangling := 0
+	for _, edge := range graph.Edges {
+		if edge.Dangling {
+			dangling++
+			if edge.Kind == EdgeMarkdownLink && edge.To != filepath.Join(root, "docs", "missing.md") {
+				t.Fatalf("unexpected dangling link: %+v", edge)
+			}
+		}
+	}
+	if dangling != 2 {
+		t.Fatalf("expected missing.md and CONVENTIONS.md to dangle, got %d", dangling)
+	}
+	wantCycle := [][]string{{filepath.Join(root, ".claude", "rules", "a.md"), filepath.Join(root, ".claude", "rules", "b.md")}}
+	if !reflect.DeepEqual(graph.Cycles, wantCycle) {
+		t.Fatalf("cycles = %v, want %v", graph.Cycles, wantCycle)
+	}
+
+	codes := map[string]int{}
+	for _, warning := range output.Warnings {
+		codes[warning.Code]++
+		if warning.Code == WarningGraphCycle && warning.Message != "import cycle between .claude/rules/a.md, .claude/rules/b.md" {
+			t.Fatalf("unexpected cycle message: %s", warning.Message)
+		}
+	}
+	if codes[WarningGraphDangling] != 2 || codes[WarningGraphCycle] != 1 {
+		t.Fatalf("unexpected graph warnings: %v", output.Warnings)
+	}
+
+	dot := RenderGraphDOT(graph, root)
+	if !strings.Contains(dot, `[label="CLAUDE.md"]`) || !strings.Contains(dot, `[label="markdown-link", style=dashed, color=red]`) {
+		t.Fatalf("unexpected dot output:\n%s", dot)
+	}
+	mermaid := RenderGraphMermaid(graph, root)
+	if !strings.HasPrefix(mermaid, "flowchart LR\n") || !strings.Contains(mermaid, "-.->|aider-read|") || !strings.Contains(mermaid, "class ") {
+		t.Fatalf("unexpected mermaid output:\n%s", mermaid)
+	}
+
+	plain, err := Scan(Options{RepoRoot: root, RepoOnly: true, Registry: registry, Fs: fs})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if plain.Graph != nil {
+		t.Fatalf("expected no graph unless requested")
+	}
+	for _, entry := range plain.Entries {
+		if entry.References != nil {
+			t.Fatalf("expected references parsed only for graph scans, got %+v for %s", entry.References, entry.Path)
+		}
+	}
+}
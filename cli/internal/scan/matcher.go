@@ -14,6 +14,9 @@ import (
 type CompiledPattern struct {
 	Pattern Pattern
 	Paths   []pathMatcher
+	// Exclude holds glob matchers for paths that must not match, even when
+	// a path in Paths does.
+	Exclude []pathMatcher
 }
 
 type pathMatcher struct {
@@ -41,6 +44,16 @@ func CompilePatterns(reg Registry) ([]CompiledPattern, error) {
 			}
 			cp.Paths = append(cp.Paths, pm)
 		}
+		for _, rawExclude := range pattern.Exclude {
+			pm, err := compilePath("glob", rawExclude)
+			if err != nil {
+				return nil, fmt.Errorf("pattern %s exclude (%s): %w", pattern.ID, rawExclude, err)
+			}
+			cp.Exclude = append(cp.Exclude, pm)
+		}
+		if err := validateConditions(pattern.Conditions); err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", pattern.ID, err)
+		}
 
 		compiled = append(compiled, cp)
 	}
@@ -49,7 +62,17 @@ func CompilePatterns(reg Registry) ([]CompiledPattern, error) {
 }
 
 // Match reports whether the compiled pattern matches the provided paths.
+// Exclude globs are checked first; conditions are not evaluated here.
 func (cp CompiledPattern) Match(absPath, relPath string) (bool, string, error) {
+	for _, pm := range cp.Exclude {
+		ok, err := pm.Match(absPath, relPath)
+		if err != nil {
+			return false, "", err
+		}
+		if ok {
+			return false, "", nil
+		}
+	}
 	for _, pm := range cp.Paths {
 		ok, err := pm.Match(absPath, relPath)
 		if err != nil {
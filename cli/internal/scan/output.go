@@ -46,6 +46,7 @@ func BuildOutput(result Result, opts OutputOptions) Output {
 		Scans:           result.Scans,
 		Configs:         configs,
 		Warnings:        warnings,
+		Graph:           result.Graph,
 	}
 }
 
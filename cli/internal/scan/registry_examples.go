@@ -0,0 +1,157 @@
+package scan
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// exampleRepoRoot anchors relative examples so absolute matchers see a
+// stable path.
+const exampleRepoRoot = "/repo"
+
+// PatternExamples lists paths a pattern must and must not match. Relative
+// paths are relative to the scan root of the pattern's scope; user and
+// global examples are usually absolute and may start with ~/.
+type PatternExamples struct {
+	ShouldMatch    []string `json:"shouldMatch,omitempty"`
+	ShouldNotMatch []string `json:"shouldNotMatch,omitempty"`
+}
+
+// ExampleCheckResult reports registry example checks.
+type ExampleCheckResult struct {
+	Passed       bool             `json:"passed"`
+	PatternCount int              `json:"patternCount"`
+	ExampleCount int              `json:"exampleCount"`
+	Untested     []string         `json:"untested"`
+	Failures     []ExampleFailure `json:"failures"`
+	Overlaps     []ExampleOverlap `json:"overlaps"`
+}
+
+// ExampleFailure is an example whose match result differs from its
+// expectation.
+type ExampleFailure struct {
+	PatternID string `json:"patternId"`
+	Path      string `json:"path"`
+	// Expected is "match" or "no-match".
+	Expected string `json:"expected"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ExampleOverlap is a shouldMatch example that other patterns for the same
+// tool and scope also match without listing it themselves.
+type ExampleOverlap struct {
+	Path       string   `json:"path"`
+	Scope      string   `json:"scope"`
+	ToolID     string   `json:"toolId"`
+	PatternIDs []string `json:"patternIds"`
+}
+
+// CheckRegistryExamples compiles the registry and checks every pattern's
+// examples. Conditions are not evaluated; only paths and exclude globs are.
+func CheckRegistryExamples(reg Registry) (ExampleCheckResult, error) {
+	compiled, err := CompilePatterns(reg)
+	if err != nil {
+		return ExampleCheckResult{}, err
+	}
+
+	result := ExampleCheckResult{
+		PatternCount: len(compiled),
+		Untested:     []string{},
+		Failures:     []ExampleFailure{},
+		Overlaps:     []ExampleOverlap{},
+	}
+	claimed := make(map[string]map[string]bool)
+	for _, cp := range compiled {
+		if cp.Pattern.Examples == nil {
+			continue
+		}
+		for _, example := range cp.Pattern.Examples.ShouldMatch {
+			if claimed[cp.Pattern.ID] == nil {
+				claimed[cp.Pattern.ID] = make(map[string]bool)
+			}
+			claimed[cp.Pattern.ID][example] = true
+		}
+	}
+
+	seenOverlaps := make(map[string]struct{})
+	for _, cp := range compiled {
+		examples := cp.Pattern.Examples
+		if examples == nil || len(examples.ShouldMatch)+len(examples.ShouldNotMatch) == 0 {
+			result.Untested = append(result.Untested, cp.Pattern.ID)
+			continue
+		}
+		for _, example := range examples.ShouldMatch {
+			result.ExampleCount++
+			matched, err := matchExample(cp, example)
+			if err != nil || !matched {
+				result.Failures = append(result.Failures, exampleFailure(cp.Pattern.ID, example, "match", err))
+				continue
+			}
+
+			ids := []string{cp.Pattern.ID}
+			ambiguous := false
+			for _, other := range compiled {
+				if other.Pattern.ID == cp.Pattern.ID || other.Pattern.Scope != cp.Pattern.Scope || other.Pattern.ToolID != cp.Pattern.ToolID {
+					continue
+				}
+				if ok, err := matchExample(other, example); err != nil || !ok {
+					continue
+				}
+				ids = append(ids, other.Pattern.ID)
+				if !claimed[other.Pattern.ID][example] {
+					ambiguous = true
+				}
+			}
+			if !ambiguous {
+				continue
+			}
+			sort.Strings(ids)
+			key := cp.Pattern.Scope + "\x00" + example + "\x00" + strings.Join(ids, ",")
+			if _, ok := seenOverlaps[key]; ok {
+				continue
+			}
+			seenOverlaps[key] = struct{}{}
+			result.Overlaps = append(result.Overlaps, ExampleOverlap{
+				Path:       example,
+				Scope:      cp.Pattern.Scope,
+				ToolID:     cp.Pattern.ToolID,
+				PatternIDs: ids,
+			})
+		}
+		for _, example := range examples.ShouldNotMatch {
+			result.ExampleCount++
+			matched, err := matchExample(cp, example)
+			if err != nil || matched {
+				result.Failures = append(result.Failures, exampleFailure(cp.Pattern.ID, example, "no-match", err))
+			}
+		}
+	}
+
+	sort.Strings(result.Untested)
+	result.Passed = len(result.Failures) == 0
+	return result, nil
+}
+
+// matchExample matches an example path the way the scanner would for a file
+// at that location.
+func matchExample(cp CompiledPattern, example string) (bool, error) {
+	expanded, err := expandRegistryPath(example)
+	if err != nil {
+		return false, err
+	}
+	absPath := expanded
+	if !filepath.IsAbs(expanded) {
+		absPath = filepath.Join(filepath.FromSlash(exampleRepoRoot), expanded)
+	}
+	matched, _, err := cp.Match(absPath, filepath.ToSlash(expanded))
+	return matched, err
+}
+
+func exampleFailure(patternID string, path string, expected string, err error) ExampleFailure {
+	failure := ExampleFailure{PatternID: patternID, Path: path, Expected: expected}
+	if err != nil {
+		failure.Error = err.Error()
+	}
+	return failure
+}
@@ -0,0 +1,70 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckRegistryExamples(t *testing.T) {
+	reg := Registry{Patterns: []Pattern{
+		{
+			ID: "rules-dir", ToolID: "tool", Scope: "repo", Paths: []string{".tool/rules/**/*"}, Exclude: []string{"**/*.png"},
+			Examples: &PatternExamples{
+				ShouldMatch:    []string{".tool/rules/a.md", ".tool/rules/extra.md"},
+				ShouldNotMatch: []string{".tool/rules/diagram.png", ".tool/rules/b.md"},
+			},
+		},
+		{
+			ID: "rules-extra", ToolID: "tool", Scope: "repo", Paths: []string{".tool/rules/extra.md"},
+			Examples: &PatternExamples{ShouldMatch: []string{".tool/rules/extra.md"}},
+		},
+		{
+			ID: "rules-md", ToolID: "tool", Scope: "repo", Paths: []string{".tool/**/a.md", ".tool/*.md"},
+			Examples: &PatternExamples{ShouldMatch: []string{".tool/notes.md"}},
+		},
+		{ID: "other-tool", ToolID: "other", Scope: "repo", Paths: []string{".tool/rules/a.md"}},
+		{ID: "user-config", ToolID: "tool", Scope: "user", Paths: []string{"~/.tool/config.json"},
+			Examples: &PatternExamples{ShouldMatch: []string{"~/.tool/config.json"}, ShouldNotMatch: []string{".tool/config.json"}}},
+	}}
+
+	result, err := CheckRegistryExamples(reg)
+	if err != nil {
+		t.Fatalf("CheckRegistryExamples: %v", err)
+	}
+	if result.Passed || result.ExampleCount != 8 || result.PatternCount != 5 {
+		t.Fatalf("unexpected summary: %+v", result)
+	}
+	wantFailures := []ExampleFailure{{PatternID: "rules-dir", Path: ".tool/rules/b.md", Expected: "no-match"}}
+	if !reflect.DeepEqual(result.Failures, wantFailures) {
+		t.Fatalf("failures = %+v, want %+v", result.Failures, wantFailures)
+	}
+	// rules-extra claims extra.md, so only a.md is ambiguous with rules-md.
+	wantOverlaps := []ExampleOverlap{{Path: ".tool/rules/a.md", Scope: "repo", ToolID: "tool", PatternIDs: []string{"rules-dir", "rules-md"}}}
+	if !reflect.DeepEqual(result.Overlaps, wantOverlaps) {
+		t.Fatalf("overlaps = %+v, want %+v", result.Overlaps, wantOverlaps)
+	}
+	if !reflect.DeepEqual(result.Untested, []string{"other-tool"}) {
+		t.Fatalf("untested = %v", result.Untested)
+	}
+
+	reg.Patterns[0].Exclude = []string{"[bad"}
+	if _, err := CheckRegistryExamples(reg); err == nil {
+		t.Fatalf("expected compile error")
+	}
+}
+
+func TestRegistryFixtureExamples(t *testing.T) {
+	result, err := CheckRegistryExamples(loadRegistryFixture(t))
+	if err != nil {
+		t.Fatalf("CheckRegistryExamples: %v", err)
+	}
+	if !result.Passed {
+		t.Fatalf("registry example failures: %+v", result.Failures)
+	}
+	if len(result.Overlaps) > 0 {
+		t.Fatalf("registry example overlaps: %+v", result.Overlaps)
+	}
+	if result.ExampleCount == 0 {
+		t.Fatalf("expected registry examples")
+	}
+}
@@ -172,6 +172,24 @@ func validatePatterns(reg Registry) []CheckDetail {
 				})
 			}
 		}
+		for _, rawExclude := range pattern.Exclude {
+			if _, err := compilePath("glob", rawExclude); err != nil {
+				details = append(details, CheckDetail{
+					PatternID: id,
+					Field:     "exclude",
+					Error:     err.Error(),
+					Pattern:   dumpPattern(pattern),
+				})
+			}
+		}
+		if err := validateConditions(pattern.Conditions); err != nil {
+			details = append(details, CheckDetail{
+				PatternID: id,
+				Field:     "conditions",
+				Error:     err.Error(),
+				Pattern:   dumpPattern(pattern),
+			})
+		}
 	}
 
 	return details
@@ -51,3 +51,34 @@ func TestValidateRegistrySuccess(t *testing.T) {
 		t.Fatalf("expected valid registry, got %+v", result)
 	}
 }
+
+func TestValidateRegistryExcludeAndConditions(t *testing.T) {
+	reg := Registry{
+		Version: "1",
+		Patterns: []Pattern{
+			{
+				ID:           "p1",
+				ToolID:       "tool-a",
+				ToolName:     "Tool A",
+				Kind:         "rules",
+				Scope:        "repo",
+				Paths:        []string{".rules/**/*"},
+				Exclude:      []string{"**/[.md"},
+				Conditions:   &PatternConditions{RequiresEnv: []string{"BAD-NAME"}, RequiresSetting: []string{"=true"}},
+				Type:         "glob",
+				LoadBehavior: "directory-glob",
+				Application:  "automatic",
+			},
+		},
+	}
+	details := validatePatterns(reg)
+	if len(details) != 2 {
+		t.Fatalf("expected exclude and conditions failures, got %+v", details)
+	}
+	if details[0].Field != "exclude" || details[1].Field != "conditions" {
+		t.Fatalf("unexpected fields: %+v", details)
+	}
+	if _, err := CompilePatterns(reg); err == nil {
+		t.Fatalf("expected CompilePatterns to reject invalid exclude")
+	}
+}
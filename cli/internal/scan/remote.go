@@ -1,37 +1,243 @@
 package scan
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
+// RemoteOptions configures CloneRemote.
+type RemoteOptions struct {
+	// Ref is a branch or tag to check out; empty uses the remote HEAD.
+	Ref string
+	// SparsePatterns limits the checkout to matching paths (see
+	// SparsePatterns). Empty checks out the full tree.
+	SparsePatterns []string
+	// CacheDir stores clones keyed by URL so later scans fetch into the
+	// existing clone instead of cloning again. Cached clones are kept.
+	CacheDir string
+	// Keep leaves a temporary clone on disk after Cleanup.
+	Keep bool
+}
+
+// RemoteClone describes a checked-out remote repository.
+type RemoteClone struct {
+	Dir string
+	// Filtered reports whether the server honored --filter=blob:none.
+	Filtered bool
+	// Sparse reports whether the checkout was limited to SparsePatterns.
+	Sparse bool
+	// Reused reports whether an existing clone in CacheDir was updated.
+	Reused bool
+	// Cleanup removes the clone unless it is cached or kept.
+	Cleanup func()
+}
+
 // CloneToTemp clones a git repository to a temporary directory.
 // It returns the temporary directory path, a cleanup function, and any error.
 func CloneToTemp(url string, ref string) (string, func(), error) {
-	tempDir, err := os.MkdirTemp("", "markdowntown-remote-")
+	clone, err := CloneRemote(url, RemoteOptions{Ref: ref})
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to create temp dir: %w", err)
+		return "", nil, err
+	}
+	return clone.Dir, clone.Cleanup, nil
+}
+
+// CloneRemote makes a shallow, blob-filtered clone of url and checks out only
+// the sparse patterns. Servers without partial clone support fall back to a
+// plain shallow clone, and git versions without non-cone sparse-checkout fall
+// back to a full checkout.
+func CloneRemote(url string, opts RemoteOptions) (RemoteClone, error) {
+	if opts.CacheDir != "" {
+		return cloneCached(url, opts)
 	}
 
+	tempDir, err := os.MkdirTemp("", "markdowntown-remote-")
+	if err != nil {
+		return RemoteClone{}, fmt.Errorf("failed to create temp dir: %w", err)
+	}
 	cleanup := func() {
 		_ = os.RemoveAll(tempDir)
 	}
 
-	// Shallow clone to save time/bandwidth
-	args := []string{"clone", "--depth", "1", url, tempDir}
-	if ref != "" {
-		args = []string{"clone", "--depth", "1", "--branch", ref, url, tempDir}
+	clone, err := cloneInto(url, tempDir, opts)
+	if err != nil {
+		cleanup() // Clean up empty/partial dir
+		return RemoteClone{}, err
+	}
+	clone.Cleanup = cleanup
+	if opts.Keep {
+		clone.Cleanup = func() {}
+	}
+	return clone, nil
+}
+
+func cloneCached(url string, opts RemoteOptions) (RemoteClone, error) {
+	if err := os.MkdirAll(opts.CacheDir, 0o700); err != nil {
+		return RemoteClone{}, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	sum := sha256.Sum256([]byte(url))
+	dir := filepath.Join(opts.CacheDir, hex.EncodeToString(sum[:8]))
+	noop := func() {}
+
+	if origin, err := remoteGit(dir, nil, "remote", "get-url", "origin"); err == nil && strings.TrimSpace(origin) == url {
+		clone, err := updateClone(dir, opts)
+		if err == nil {
+			clone.Cleanup = noop
+			return clone, nil
+		}
+		// A broken cache entry is replaced by a fresh clone.
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return RemoteClone{}, fmt.Errorf("failed to reset cached clone: %w", err)
+	}
+	clone, err := cloneInto(url, dir, opts)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return RemoteClone{}, err
+	}
+	clone.Cleanup = noop
+	return clone, nil
+}
+
+func cloneInto(url string, dir string, opts RemoteOptions) (RemoteClone, error) {
+	clone := RemoteClone{Dir: dir}
+
+	// Shallow clone to save time/bandwidth; blobs are fetched on checkout.
+	args := []string{"clone", "--depth", "1", "--no-checkout"}
+	if opts.Ref != "" {
+		args = append(args, "--branch", opts.Ref)
+	}
+	output, err := remoteGit("", nil, append(append(args, "--filter=blob:none"), url, dir)...)
+	if err != nil {
+		// Older git or transports that reject the filter outright.
+		_ = os.RemoveAll(dir)
+		output, err = remoteGit("", nil, append(args, url, dir)...)
+		if err != nil {
+			return clone, fmt.Errorf("git clone failed: %w\n%s", err, output)
+		}
+	} else {
+		clone.Filtered = !strings.Contains(output, "filtering not recognized")
+	}
+
+	clone.Sparse = setSparsePatterns(dir, opts.SparsePatterns)
+	if output, err := remoteGit(dir, nil, "checkout", "--force"); err != nil {
+		return clone, fmt.Errorf("git checkout failed: %w\n%s", err, output)
+	}
+	return clone, nil
+}
+
+func updateClone(dir string, opts RemoteOptions) (RemoteClone, error) {
+	clone := RemoteClone{Dir: dir, Reused: true}
+	promisor, _ := remoteGit(dir, nil, "config", "--get", "remote.origin.promisor")
+	clone.Filtered = strings.TrimSpace(promisor) == "true"
+
+	ref := opts.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if output, err := remoteGit(dir, nil, "fetch", "--depth", "1", "origin", ref); err != nil {
+		return clone, fmt.Errorf("git fetch failed: %w\n%s", err, output)
+	}
+	clone.Sparse = setSparsePatterns(dir, opts.SparsePatterns)
+	if !clone.Sparse {
+		_, _ = remoteGit(dir, nil, "sparse-checkout", "disable")
+	}
+	if output, err := remoteGit(dir, nil, "checkout", "--force", "--detach", "FETCH_HEAD"); err != nil {
+		return clone, fmt.Errorf("git checkout failed: %w\n%s", err, output)
+	}
+	if output, err := remoteGit(dir, nil, "clean", "-fdx"); err != nil {
+		return clone, fmt.Errorf("git clean failed: %w\n%s", err, output)
+	}
+	return clone, nil
+}
+
+// setSparsePatterns configures a non-cone sparse checkout. It reports false
+// when patterns are empty or git does not support them, leaving the full tree.
+func setSparsePatterns(dir string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
 	}
+	stdin := strings.NewReader(strings.Join(patterns, "\n") + "\n")
+	_, err := remoteGit(dir, stdin, "sparse-checkout", "set", "--no-cone", "--stdin")
+	return err == nil
+}
 
+func remoteGit(dir string, stdin io.Reader, args ...string) (string, error) {
 	cmd := exec.Command("git", args...) //nolint:gosec // trusted git arguments
+	cmd.Dir = dir
+	cmd.Stdin = stdin
 	// Prevent git from asking for credentials
 	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	return output.String(), err
+}
 
-	if output, err := cmd.CombinedOutput(); err != nil {
-		cleanup() // Clean up empty/partial dir
-		return "", nil, fmt.Errorf("git clone failed: %w\n%s", err, string(output))
+// SparsePatterns converts the registry's repo-scope glob paths into
+// sparse-checkout patterns. Paths are matched relative to every scan root,
+// and .code-workspace folders add roots below the repo root, so each path is
+// unanchored to match at any depth. Every .gitignore, the root workspace
+// files, .vscode/settings.json, and relative condition targets are included
+// so gitignore checks, workspace discovery, and pattern conditions still
+// work. It returns nil when a repo-scope pattern cannot be expressed (regex
+// paths, condition targets outside the matched file's directory), meaning a
+// full checkout is needed.
+func SparsePatterns(reg Registry) []string {
+	seen := map[string]struct{}{
+		"**/.gitignore":          {},
+		"/*.code-workspace":      {},
+		"/.vscode/settings.json": {},
+	}
+	for _, pattern := range reg.Patterns {
+		if pattern.Scope != ScopeRepo {
+			continue
+		}
+		patternType := strings.ToLower(strings.TrimSpace(pattern.Type))
+		if patternType != "" && patternType != "glob" {
+			return nil
+		}
+		paths := append([]string(nil), pattern.Paths...)
+		if pattern.Conditions != nil {
+			for _, target := range pattern.Conditions.RequiresFile {
+				target = strings.TrimSpace(filepath.ToSlash(target))
+				if target == ".." || strings.HasPrefix(target, "../") || strings.Contains(target, "/../") {
+					return nil
+				}
+				paths = append(paths, target)
+			}
+		}
+		for _, raw := range paths {
+			raw = strings.TrimSpace(filepath.ToSlash(raw))
+			if raw == "" || strings.HasPrefix(raw, "~") || strings.HasPrefix(raw, "$") || filepath.IsAbs(raw) || strings.HasPrefix(raw, "/") {
+				continue
+			}
+			seen[unanchoredSparsePattern(raw)] = struct{}{}
+		}
 	}
+	patterns := make([]string, 0, len(seen))
+	for pattern := range seen {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
 
-	return tempDir, cleanup, nil
+// unanchoredSparsePattern makes a relative glob match at any depth. Git
+// anchors patterns containing a slash, so those get a leading "**/".
+func unanchoredSparsePattern(raw string) string {
+	raw = strings.TrimPrefix(raw, "./")
+	if !strings.Contains(raw, "/") || strings.HasPrefix(raw, "**/") {
+		return raw
+	}
+	return "**/" + raw
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -84,3 +85,149 @@ func runGit(t *testing.T, dir string, args ...string) {
 		t.Fatalf("git %v failed: %v\nOutput: %s", args, err, out)
 	}
 }
+
+func TestCloneRemoteSparseCache(t *testing.T) {
+	originDir := t.TempDir()
+	runGit(t, originDir, "init")
+	runGit(t, originDir, "config", "user.email", "you@example.com")
+	runGit(t, originDir, "config", "user.name", "Your Name")
+	runGit(t, originDir, "checkout", "-b", "main")
+	writeTestFile(t, filepath.Join(originDir, "AGENTS.md"), "v1")
+	writeTestFile(t, filepath.Join(originDir, "docs", "guide.md"), "not a config")
+	writeTestFile(t, filepath.Join(originDir, ".gitignore"), "*.log\n")
+	runGit(t, originDir, "add", ".")
+	runGit(t, originDir, "commit", "-m", "Initial commit")
+
+	bareDir := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, originDir, "clone", "--bare", originDir, bareDir)
+	runGit(t, bareDir, "config", "uploadpack.allowFilter", "true")
+	url := "file://" + filepath.ToSlash(bareDir)
+
+	reg := Registry{Patterns: []Pattern{{ID: "agents", Scope: ScopeRepo, Paths: []string{"AGENTS.md"}}}}
+	opts := RemoteOptions{SparsePatterns: SparsePatterns(reg), CacheDir: t.TempDir()}
+
+	clone, err := CloneRemote(url, opts)
+	if err != nil {
+		t.Fatalf("CloneRemote: %v", err)
+	}
+	defer clone.Cleanup()
+	if !clone.Filtered || !clone.Sparse || clone.Reused {
+		t.Fatalf("expected fresh filtered sparse clone, got %+v", clone)
+	}
+	if data, err := os.ReadFile(filepath.Join(clone.Dir, "AGENTS.md")); err != nil || string(data) != "v1" {
+		t.Fatalf("expected AGENTS.md v1, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(clone.Dir, ".gitignore")); err != nil {
+		t.Fatalf("expected .gitignore in sparse checkout: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(clone.Dir, "docs", "guide.md")); !os.IsNotExist(err) {
+		t.Fatalf("expected docs/guide.md outside sparse checkout, got %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(originDir, "AGENTS.md"), "v2")
+	runGit(t, originDir, "commit", "-am", "Update")
+	runGit(t, originDir, "push", bareDir, "main")
+
+	again, err := CloneRemote(url, opts)
+	if err != nil {
+		t.Fatalf("CloneRemote reuse: %v", err)
+	}
+	if !again.Reused || again.Dir != clone.Dir {
+		t.Fatalf("expected cached clone reuse, got %+v", again)
+	}
+	if data, err := os.ReadFile(filepath.Join(again.Dir, "AGENTS.md")); err != nil || string(data) != "v2" {
+		t.Fatalf("expected updated AGENTS.md, got %q (%v)", data, err)
+	}
+}
+
+func TestCloneRemoteWithoutFilterSupport(t *testing.T) {
+	originDir := t.TempDir()
+	runGit(t, originDir, "init")
+	runGit(t, originDir, "config", "user.email", "you@example.com")
+	runGit(t, originDir, "config", "user.name", "Your Name")
+	writeTestFile(t, filepath.Join(originDir, "AGENTS.md"), "v1")
+	runGit(t, originDir, "add", ".")
+	runGit(t, originDir, "commit", "-m", "Initial commit")
+	bareDir := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, originDir, "clone", "--bare", originDir, bareDir)
+	runGit(t, bareDir, "config", "uploadpack.allowFilter", "false")
+
+	clone, err := CloneRemote("file://"+filepath.ToSlash(bareDir), RemoteOptions{SparsePatterns: []string{"/AGENTS.md"}})
+	if err != nil {
+		t.Fatalf("CloneRemote: %v", err)
+	}
+	defer clone.Cleanup()
+	if clone.Filtered {
+		t.Fatalf("expected unfiltered fallback clone")
+	}
+	if _, err := os.Stat(filepath.Join(clone.Dir, "AGENTS.md")); err != nil {
+		t.Fatalf("expected AGENTS.md: %v", err)
+	}
+}
+
+func TestSparsePatterns(t *testing.T) {
+	reg := Registry{Patterns: []Pattern{
+		{ID: "a", Scope: ScopeRepo, Paths: []string{"AGENTS.md", ".github/prompts/*.prompt.md"}},
+		{ID: "b", Scope: ScopeUser, Paths: []string{"~/.codex/AGENTS.md"}},
+	}}
+	reg.Patterns[0].Conditions = &PatternConditions{RequiresFile: []string{"package.json", "~/.codex/config.toml"}}
+	got := SparsePatterns(reg)
+	want := []string{"**/.github/prompts/*.prompt.md", "**/.gitignore", "/*.code-workspace", "/.vscode/settings.json", "AGENTS.md", "package.json"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SparsePatterns = %v, want %v", got, want)
+	}
+
+	parent := Registry{Patterns: []Pattern{{ID: "d", Scope: ScopeRepo, Paths: []string{"AGENTS.md"}, Conditions: &PatternConditions{RequiresFile: []string{"../go.mod"}}}}}
+	if got := SparsePatterns(parent); got != nil {
+		t.Fatalf("expected nil patterns for parent condition targets, got %v", got)
+	}
+
+	reg.Patterns = append(reg.Patterns, Pattern{ID: "c", Scope: ScopeRepo, Type: "regex", Paths: []string{"^x$"}})
+	if got := SparsePatterns(reg); got != nil {
+		t.Fatalf("expected nil patterns for regex paths, got %v", got)
+	}
+}
+
+func TestCloneRemoteSparseNestedWorkspace(t *testing.T) {
+	originDir := t.TempDir()
+	runGit(t, originDir, "init")
+	runGit(t, originDir, "config", "user.email", "you@example.com")
+	runGit(t, originDir, "config", "user.name", "Your Name")
+	writeTestFile(t, filepath.Join(originDir, "AGENTS.md"), "root")
+	writeTestFile(t, filepath.Join(originDir, "packages", "a", "AGENTS.md"), "nested")
+	writeTestFile(t, filepath.Join(originDir, "packages", "a", "main.go"), "package a")
+	writeTestFile(t, filepath.Join(originDir, "app.code-workspace"), `{"folders": [{"path": "."}, {"path": "packages/a"}]}`)
+	runGit(t, originDir, "add", ".")
+	runGit(t, originDir, "commit", "-m", "Initial commit")
+	bareDir := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, originDir, "clone", "--bare", originDir, bareDir)
+	runGit(t, bareDir, "config", "uploadpack.allowFilter", "true")
+
+	reg := Registry{Patterns: []Pattern{{ID: "agents", ToolID: "codex", Scope: ScopeRepo, Paths: []string{"AGENTS.md"}}}}
+	clone, err := CloneRemote("file://"+filepath.ToSlash(bareDir), RemoteOptions{SparsePatterns: SparsePatterns(reg)})
+	if err != nil {
+		t.Fatalf("CloneRemote: %v", err)
+	}
+	defer clone.Cleanup()
+	if !clone.Sparse {
+		t.Fatalf("expected sparse clone, got %+v", clone)
+	}
+	if _, err := os.Stat(filepath.Join(clone.Dir, "packages", "a", "main.go")); !os.IsNotExist(err) {
+		t.Fatalf("expected packages/a/main.go outside sparse checkout, got %v", err)
+	}
+
+	result, err := Scan(Options{RepoRoot: clone.Dir, RepoOnly: true, Registry: reg})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	nested := filepath.Join(clone.Dir, "packages", "a", "AGENTS.md")
+	found := false
+	for _, entry := range result.Entries {
+		if entry.Path == nested {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected %s from the workspace folder, got %+v", nested, result.Entries)
+	}
+}
@@ -28,13 +28,14 @@ type Result struct {
 	Scans    []Root
 	Entries  []ConfigEntry
 	Warnings []Warning
+	Graph    *Graph
 }
 
 var runtimeGOOS = runtime.GOOS
 var foldCaser = cases.Fold()
 
 // Scan discovers files across repo/user scopes and stdin paths.
-func scanRepoRoots(fs afero.Fs, repoRoots []string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, opts Options) []string {
+func scanRepoRoots(ctx context.Context, fs afero.Fs, repoRoots []string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, opts Options, stream *scanStream) []string {
 	repoRootsAbs := make([]string, 0, len(repoRoots))
 	repoRootSeen := make(map[string]struct{})
 	for _, root := range repoRoots {
@@ -51,6 +52,7 @@ func scanRepoRoots(fs afero.Fs, repoRoots []string, patterns []CompiledPattern,
 		repoRootSeen[absRoot] = struct{}{}
 		repoRootsAbs = append(repoRootsAbs, absRoot)
 	}
+	stream.setRoots(ScopeRepo, repoRootsAbs)
 
 	for _, root := range repoRootsAbs {
 		repoInfo, repoErr := fs.Stat(root)
@@ -60,14 +62,15 @@ func scanRepoRoots(fs afero.Fs, repoRoots []string, patterns []CompiledPattern,
 			result.Warnings = append(result.Warnings, warningForError(root, repoErr))
 		}
 		if repoExists {
-			walkState := newWalkState(fs, root, opts.Progress, ScopeRepo, opts, repoInfo)
+			walkState := newWalkState(ctx, fs, root, opts.Progress, ScopeRepo, opts, repoInfo)
+			walkState.stream = stream
 			scanDir(root, root, root, ScopeRepo, repoInfo, patterns, entries, result, walkState, false, false)
 		}
 	}
 	return repoRootsAbs
 }
 
-func scanUserRoots(fs afero.Fs, repoRoot string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, opts Options) []string {
+func scanUserRoots(ctx context.Context, fs afero.Fs, repoRoot string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, opts Options, stream *scanStream) []string {
 	var userRootsAbs []string
 	if opts.RepoOnly {
 		return userRootsAbs
@@ -89,6 +92,7 @@ func scanUserRoots(fs afero.Fs, repoRoot string, patterns []CompiledPattern, ent
 		}
 		absRoot = filepath.Clean(absRoot)
 		userRootsAbs = append(userRootsAbs, absRoot)
+		stream.setRoots(ScopeUser, userRootsAbs)
 		info, statErr := fs.Stat(absRoot)
 		exists := statErr == nil && info.IsDir()
 		result.Scans = append(result.Scans, Root{Scope: ScopeUser, Root: absRoot, Exists: exists})
@@ -98,13 +102,14 @@ func scanUserRoots(fs afero.Fs, repoRoot string, patterns []CompiledPattern, ent
 		if !exists {
 			continue
 		}
-		walkState := newWalkState(fs, absRoot, opts.Progress, ScopeUser, opts, info)
+		walkState := newWalkState(ctx, fs, absRoot, opts.Progress, ScopeUser, opts, info)
+		walkState.stream = stream
 		scanDir(absRoot, absRoot, absRoot, ScopeUser, info, patterns, entries, result, walkState, false, false)
 	}
 	return userRootsAbs
 }
 
-func scanGlobalRoots(fs afero.Fs, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, opts Options) []string {
+func scanGlobalRoots(ctx context.Context, fs afero.Fs, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, opts Options, stream *scanStream) []string {
 	var globalRootsAbs []string
 	if !opts.IncludeGlobal {
 		return globalRootsAbs
@@ -144,6 +149,7 @@ func scanGlobalRoots(fs afero.Fs, patterns []CompiledPattern, entries map[string
 		}
 		seen[absRoot] = struct{}{}
 		globalRootsAbs = append(globalRootsAbs, absRoot)
+		stream.setRoots(ScopeGlobal, globalRootsAbs)
 
 		info, statErr := fs.Stat(absRoot)
 		exists := statErr == nil && info.IsDir()
@@ -154,14 +160,15 @@ func scanGlobalRoots(fs afero.Fs, patterns []CompiledPattern, entries map[string
 		if !exists {
 			continue
 		}
-		walkState := newWalkState(fs, absRoot, opts.Progress, ScopeGlobal, opts, info)
+		walkState := newWalkState(ctx, fs, absRoot, opts.Progress, ScopeGlobal, opts, info)
+		walkState.stream = stream
 		scanDir(absRoot, absRoot, absRoot, ScopeGlobal, info, patterns, entries, result, walkState, false, false)
 	}
 
 	return globalRootsAbs
 }
 
-func scanStdinPaths(fs afero.Fs, paths []string, repoRootsAbs []string, userRootsAbs []string, globalRootsAbs []string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, opts Options) {
+func scanStdinPaths(ctx context.Context, fs afero.Fs, paths []string, repoRootsAbs []string, userRootsAbs []string, globalRootsAbs []string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, opts Options) {
 	for _, stdinPath := range paths {
 		stdinPath = strings.TrimSpace(stdinPath)
 		if stdinPath == "" {
@@ -183,18 +190,42 @@ func scanStdinPaths(fs afero.Fs, paths []string, repoRootsAbs []string, userRoot
 		if scope == ScopeGlobal {
 			rootInfo, _ = fs.Stat(root)
 		}
-		walkState := newWalkState(fs, root, opts.Progress, scope, opts, rootInfo)
+		walkState := newWalkState(ctx, fs, root, opts.Progress, scope, opts, rootInfo)
+		walkState.cache = nil
 		scanPath(absPath, absPath, root, scope, patterns, entries, result, walkState, true)
 	}
 }
 
+// Sink receives results while ScanContext runs. Callbacks are invoked on the
+// calling goroutine, one at a time; either may be nil.
+type Sink struct {
+	// Entry receives each config once its content has been read. Gitignore
+	// status is not yet applied.
+	Entry func(ConfigEntry)
+	// Warning receives scan warnings in the order they are recorded.
+	Warning func(Warning)
+}
+
 // Scan discovers files across repo/user scopes and stdin paths.
 func Scan(opts Options) (Result, error) {
+	return ScanContext(context.Background(), opts, Sink{})
+}
+
+// ScanContext is Scan with cancellation and streaming. Entries and warnings
+// are delivered to sink as the walk finishes each directory, and stdin paths
+// after the walk, so callers see results while long scans run. When ctx is
+// canceled the walk and content workers stop and the partial result is
+// returned with ctx.Err(). A file reached again later in the walk may gain
+// tools in the returned Result after it was delivered.
+func ScanContext(ctx context.Context, opts Options, sink Sink) (Result, error) {
 	var result Result
 
 	if strings.TrimSpace(opts.RepoRoot) == "" {
 		return result, fmt.Errorf("repo root required")
 	}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
 
 	fs := opts.Fs
 	if fs == nil {
@@ -206,6 +237,9 @@ func Scan(opts Options) (Result, error) {
 		return result, err
 	}
 	result.Warnings = append(result.Warnings, warnings...)
+	if opts.Cache != nil {
+		opts.Cache.prepare(patterns)
+	}
 
 	repoRoot, err := filepath.Abs(opts.RepoRoot)
 	if err != nil {
@@ -214,6 +248,7 @@ func Scan(opts Options) (Result, error) {
 	repoRoot = filepath.Clean(repoRoot)
 
 	entries := make(map[string]*ConfigEntry)
+	stream := newScanStream(ctx, fs, opts, sink, entries, &result)
 
 	repoRoots := []string{repoRoot}
 	workspaceRoots, workspaceWarnings := discoverWorkspaceRoots(fs, repoRoot)
@@ -224,21 +259,104 @@ func Scan(opts Options) (Result, error) {
 		repoRoots = append(repoRoots, workspaceRoots...)
 	}
 
-	repoRootsAbs := scanRepoRoots(fs, repoRoots, patterns, entries, &result, opts)
-	userRootsAbs := scanUserRoots(fs, repoRoot, patterns, entries, &result, opts)
-	globalRootsAbs := scanGlobalRoots(fs, patterns, entries, &result, opts)
-	scanStdinPaths(fs, opts.StdinPaths, repoRootsAbs, userRootsAbs, globalRootsAbs, patterns, entries, &result, opts)
-
-	populateEntriesContent(fs, entries, opts.IncludeContent, opts.ScanWorkers, repoRootsAbs, userRootsAbs, globalRootsAbs)
+	repoRootsAbs := scanRepoRoots(ctx, fs, repoRoots, patterns, entries, &result, opts, stream)
+	userRootsAbs := scanUserRoots(ctx, fs, repoRoot, patterns, entries, &result, opts, stream)
+	globalRootsAbs := scanGlobalRoots(ctx, fs, patterns, entries, &result, opts, stream)
+	stream.flush()
+	scanStdinPaths(ctx, fs, opts.StdinPaths, repoRootsAbs, userRootsAbs, globalRootsAbs, patterns, entries, &result, opts)
+	stream.flush()
 
 	for _, entry := range entries {
 		result.Entries = append(result.Entries, *entry)
 	}
+	if opts.Graph && ctx.Err() == nil {
+		graph, graphWarnings := buildGraph(fs, result.Entries, repoRoot)
+		result.Graph = graph
+		result.Warnings = append(result.Warnings, graphWarnings...)
+	}
+
+	return result, ctx.Err()
+}
+
+// scanStream populates content for entries found since the last flush and
+// hands them, plus new warnings, to the sink. The walker flushes after each
+// directory; the root lists grow as each scope's roots are resolved so
+// content is read relative to the right root. A nil stream ignores calls.
+type scanStream struct {
+	ctx         context.Context
+	fs          afero.Fs
+	opts        Options
+	sink        Sink
+	entries     map[string]*ConfigEntry
+	result      *Result
+	populated   map[string]struct{}
+	warnings    int
+	repoRoots   []string
+	userRoots   []string
+	globalRoots []string
+}
+
+func newScanStream(ctx context.Context, fs afero.Fs, opts Options, sink Sink, entries map[string]*ConfigEntry, result *Result) *scanStream {
+	return &scanStream{ctx: ctx, fs: fs, opts: opts, sink: sink, entries: entries, result: result, populated: make(map[string]struct{})}
+}
+
+func (s *scanStream) setRoots(scope string, roots []string) {
+	if s == nil {
+		return
+	}
+	switch scope {
+	case ScopeRepo:
+		s.repoRoots = roots
+	case ScopeUser:
+		s.userRoots = roots
+	case ScopeGlobal:
+		s.globalRoots = roots
+	}
+}
+
+func (s *scanStream) flush() {
+	if s == nil {
+		return
+	}
+	// Entries are only added, so equal counts mean nothing new to deliver.
+	if len(s.entries) == len(s.populated) && len(s.result.Warnings) == s.warnings {
+		return
+	}
+	pending := make(map[string]*ConfigEntry)
+	for key, entry := range s.entries {
+		if _, ok := s.populated[key]; !ok {
+			pending[key] = entry
+			s.populated[key] = struct{}{}
+		}
+	}
+	if s.ctx.Err() == nil {
+		populateEntriesContent(s.ctx, s.fs, s.opts.Cache, pending, s.opts.IncludeContent, s.opts.Graph, s.opts.ScanWorkers, s.repoRoots, s.userRoots, s.globalRoots)
+	}
+
+	if s.sink.Warning != nil {
+		for _, warning := range s.result.Warnings[s.warnings:] {
+			s.sink.Warning(warning)
+		}
+	}
+	s.warnings = len(s.result.Warnings)
 
-	return result, nil
+	if s.sink.Entry == nil || s.ctx.Err() != nil {
+		return
+	}
+	keys := make([]string, 0, len(pending))
+	for key := range pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry := *pending[key]
+		entry.Tools = append([]ToolEntry(nil), entry.Tools...)
+		sortTools(entry.Tools)
+		s.sink.Entry(entry)
+	}
 }
 
-func populateEntriesContent(fs afero.Fs, entries map[string]*ConfigEntry, includeContent bool, workers int, repoRoots, userRoots, globalRoots []string) {
+func populateEntriesContent(ctx context.Context, fs afero.Fs, cache *Cache, entries map[string]*ConfigEntry, includeContent bool, graph bool, workers int, repoRoots, userRoots, globalRoots []string) {
 	if len(entries) == 0 {
 		return
 	}
@@ -250,6 +368,9 @@ func populateEntriesContent(fs afero.Fs, entries map[string]*ConfigEntry, includ
 	}
 	if workers == 1 {
 		for _, entry := range entries {
+			if ctx.Err() != nil {
+				return
+			}
 			if entry == nil {
 				continue
 			}
@@ -258,29 +379,32 @@ func populateEntriesContent(fs afero.Fs, entries map[string]*ConfigEntry, includ
 				resolved = entry.Path
 			}
 			root := rootForScope(resolved, entry.Scope, repoRoots, userRoots, globalRoots)
-			populateEntryContent(fs, entry, resolved, root, includeContent)
+			populateEntryContentCached(fs, cache, entry, resolved, root, includeContent, graph)
 		}
 		return
 	}
 
 	sem := semaphore.NewWeighted(int64(workers))
-	group, ctx := errgroup.WithContext(context.Background())
+	group, groupCtx := errgroup.WithContext(ctx)
 	for _, entry := range entries {
 		entry := entry
 		if entry == nil {
 			continue
 		}
-		if err := sem.Acquire(ctx, 1); err != nil {
+		if err := sem.Acquire(groupCtx, 1); err != nil {
 			break
 		}
 		group.Go(func() error {
 			defer sem.Release(1)
+			if groupCtx.Err() != nil {
+				return nil
+			}
 			resolved := entry.Resolved
 			if resolved == "" {
 				resolved = entry.Path
 			}
 			root := rootForScope(resolved, entry.Scope, repoRoots, userRoots, globalRoots)
-			populateEntryContent(fs, entry, resolved, root, includeContent)
+			populateEntryContentCached(fs, cache, entry, resolved, root, includeContent, graph)
 			return nil
 		})
 	}
@@ -429,6 +553,7 @@ func isSpecialFile(info os.FileInfo) bool {
 }
 
 type walkState struct {
+	ctx         context.Context
 	fs          afero.Fs
 	root        string
 	scope       string
@@ -437,6 +562,9 @@ type walkState struct {
 	active      map[string]struct{}
 	activePaths map[string]struct{}
 	guard       guardState
+	cache       *Cache
+	conditions  *conditionEnv
+	stream      *scanStream
 }
 
 type guardState struct {
@@ -454,8 +582,9 @@ type guardState struct {
 	xdev         bool
 }
 
-func newWalkState(fs afero.Fs, root string, progress func(string), scope string, opts Options, rootInfo os.FileInfo) *walkState {
+func newWalkState(ctx context.Context, fs afero.Fs, root string, progress func(string), scope string, opts Options, rootInfo os.FileInfo) *walkState {
 	state := &walkState{
+		ctx:         ctx,
 		fs:          fs,
 		root:        root,
 		scope:       scope,
@@ -463,6 +592,10 @@ func newWalkState(fs afero.Fs, root string, progress func(string), scope string,
 		visited:     make(map[string]struct{}),
 		active:      make(map[string]struct{}),
 		activePaths: make(map[string]struct{}),
+		conditions:  newConditionEnv(fs, opts),
+	}
+	if scope != ScopeGlobal {
+		state.cache = opts.Cache
 	}
 	if scope == ScopeGlobal {
 		state.guard.maxFiles = opts.GlobalMaxFiles
@@ -481,6 +614,9 @@ func newWalkState(fs afero.Fs, root string, progress func(string), scope string,
 }
 
 func (state *walkState) guardStop() bool {
+	if state.ctx.Err() != nil {
+		return true
+	}
 	return state.scope == ScopeGlobal && state.guard.limitReached
 }
 
@@ -568,20 +704,63 @@ func scanDir(logicalPath string, actualPath string, root string, scope string, i
 	}
 	defer state.leaveDir(key, actualPath)
 
-	dirEntries, err := afero.ReadDir(state.fs, actualPath)
-	if err != nil {
-		result.Warnings = append(result.Warnings, warningForError(logicalPath, err))
+	names, ok := state.readDirNames(logicalPath, actualPath, info, patterns, result)
+	if !ok {
 		return
 	}
 
-	for _, entry := range dirEntries {
+	for _, name := range names {
 		if state.guardStop() {
 			return
 		}
-		entryActual := filepath.Join(actualPath, entry.Name())
-		entryLogical := filepath.Join(logicalPath, entry.Name())
+		entryActual := filepath.Join(actualPath, name)
+		entryLogical := filepath.Join(logicalPath, name)
 		scanPath(entryLogical, entryActual, root, scope, patterns, entries, result, state, fromStdin)
 	}
+	state.stream.flush()
+}
+
+// readDirNames lists the entries of a directory to visit. With a cache, an
+// unchanged directory reuses its previous listing, which omits regular files
+// that matched no pattern. Directories reached through symlinks are not cached
+// because matching depends on the logical path.
+func (state *walkState) readDirNames(logicalPath string, actualPath string, info os.FileInfo, patterns []CompiledPattern, result *Result) ([]string, bool) {
+	cache := state.cache
+	if logicalPath != actualPath {
+		cache = nil
+	}
+	key := dirCacheKey(state.scope, state.root, actualPath)
+	if cache != nil {
+		if names, ok := cache.dirChildren(key, info); ok {
+			return names, true
+		}
+	}
+
+	dirEntries, err := afero.ReadDir(state.fs, actualPath)
+	if err != nil {
+		result.Warnings = append(result.Warnings, warningForError(logicalPath, err))
+		return nil, false
+	}
+	names := make([]string, 0, len(dirEntries))
+	children := make([]string, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		names = append(names, entry.Name())
+		if cache == nil || !entry.Mode().IsRegular() {
+			children = append(children, entry.Name())
+			continue
+		}
+		path := filepath.Join(logicalPath, entry.Name())
+		rel, _ := filepath.Rel(state.root, path)
+		// Cached listings ignore conditions so they stay valid when settings,
+		// env, or sibling files change.
+		if len(matchTools(patterns, state.scope, path, filepath.ToSlash(rel), nil)) > 0 {
+			children = append(children, entry.Name())
+		}
+	}
+	if cache != nil {
+		cache.storeDir(key, info, children)
+	}
+	return names, true
 }
 
 func scanPath(logicalPath string, actualPath string, root string, scope string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, state *walkState, fromStdin bool) {
@@ -619,7 +798,7 @@ func scanPath(logicalPath string, actualPath string, root string, scope string,
 	if !state.allowFile(info, logicalPath, result) {
 		return
 	}
-	scanFile(logicalPath, actualPath, root, scope, patterns, entries, result, info, fromStdin)
+	scanFile(logicalPath, actualPath, root, scope, patterns, entries, result, info, fromStdin, state.conditions)
 }
 
 func resolveAndScanSymlink(logicalPath string, actualPath string, root string, scope string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, state *walkState, fromStdin bool) {
@@ -672,10 +851,10 @@ func resolveAndScanSymlink(logicalPath string, actualPath string, root string, s
 	if !state.allowFile(resolvedInfo, logicalPath, result) {
 		return
 	}
-	scanFile(logicalPath, resolved, root, scope, patterns, entries, result, resolvedInfo, fromStdin)
+	scanFile(logicalPath, resolved, root, scope, patterns, entries, result, resolvedInfo, fromStdin, state.conditions)
 }
 
-func scanFile(logicalPath string, resolvedPath string, root string, scope string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, info os.FileInfo, fromStdin bool) {
+func scanFile(logicalPath string, resolvedPath string, root string, scope string, patterns []CompiledPattern, entries map[string]*ConfigEntry, result *Result, info os.FileInfo, fromStdin bool, conditions *conditionEnv) {
 	if scope == ScopeGlobal && shouldSkipGlobalPath(root, resolvedPath, info) {
 		return
 	}
@@ -689,7 +868,7 @@ func scanFile(logicalPath string, resolvedPath string, root string, scope string
 	rel, _ := filepath.Rel(root, logicalPath)
 	rel = filepath.ToSlash(rel)
 
-	matches := matchTools(patterns, scope, absPath, rel)
+	matches := matchTools(patterns, scope, absPath, rel, conditions)
 	if len(matches) == 0 && !fromStdin {
 		return
 	}
@@ -734,7 +913,7 @@ func scanFile(logicalPath string, resolvedPath string, root string, scope string
 	}
 }
 
-func populateEntryContent(fs afero.Fs, entry *ConfigEntry, resolvedPath string, root string, includeContent bool) {
+func populateEntryContent(fs afero.Fs, entry *ConfigEntry, resolvedPath string, root string, includeContent bool, graph bool) {
 	// #nosec G304 -- resolvedPath comes from scan roots or stdin.
 	var data []byte
 	var err error
@@ -774,6 +953,9 @@ func populateEntryContent(fs afero.Fs, entry *ConfigEntry, resolvedPath string,
 		entry.Content = nil
 		return
 	}
+	if graph {
+		entry.References = parseReferences(resolvedPath, entry.Tools, data)
+	}
 
 	if includeContent {
 		content := string(data)
@@ -806,7 +988,9 @@ func errorCodeFor(err error) string {
 	}
 }
 
-func matchTools(patterns []CompiledPattern, scope string, absPath string, relPath string) []ToolEntry {
+// matchTools returns the tools whose patterns match the path. Pattern
+// conditions are evaluated against conditions; nil matches on paths only.
+func matchTools(patterns []CompiledPattern, scope string, absPath string, relPath string, conditions *conditionEnv) []ToolEntry {
 	var tools []ToolEntry
 	for _, compiled := range patterns {
 		if compiled.Pattern.Scope != scope {
@@ -816,6 +1000,9 @@ func matchTools(patterns []CompiledPattern, scope string, absPath string, relPat
 		if err != nil || !matched {
 			continue
 		}
+		if !conditions.met(compiled.Pattern.Conditions, absPath) {
+			continue
+		}
 		tools = append(tools, ToolEntry{
 			ToolID:           compiled.Pattern.ToolID,
 			ToolName:         compiled.Pattern.ToolName,
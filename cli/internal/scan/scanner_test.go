@@ -1,6 +1,7 @@
 package scan
 
 import (
+	"context"
 	"errors"
 	"io/fs"
 	"os"
@@ -86,7 +87,7 @@ func TestPopulateEntryContentMissingFile(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	missingPath := "/missing.txt"
 
-	populateEntryContent(fs, entry, missingPath, "", true)
+	populateEntryContent(fs, entry, missingPath, "", true, false)
 
 	if entry.Error == nil || *entry.Error != "ENOENT" {
 		t.Fatalf("expected ENOENT error, got %#v", entry.Error)
@@ -104,7 +105,7 @@ func TestPopulateEntryContentEmptyFile(t *testing.T) {
 	}
 
 	entry := &ConfigEntry{}
-	populateEntryContent(fs, entry, path, "", false)
+	populateEntryContent(fs, entry, path, "", false, false)
 
 	if entry.Warning == nil || *entry.Warning != "empty" {
 		t.Fatalf("expected empty warning, got %#v", entry.Warning)
@@ -120,7 +121,7 @@ func TestPopulateEntryContentFrontmatterError(t *testing.T) {
 	}
 
 	entry := &ConfigEntry{}
-	populateEntryContent(fs, entry, path, "", false)
+	populateEntryContent(fs, entry, path, "", false, false)
 
 	if entry.FrontmatterError == nil {
 		t.Fatalf("expected frontmatter error for missing delimiter")
@@ -140,17 +141,17 @@ func TestPopulateEntriesContentMissingAfterDiscovery(t *testing.T) {
 
 	entries := make(map[string]*ConfigEntry)
 	var result Result
-	scanRepoRoots(fs, []string{repoRoot}, patterns, entries, &result, Options{
+	scanRepoRoots(context.Background(), fs, []string{repoRoot}, patterns, entries, &result, Options{
 		RepoRoot: repoRoot,
 		Registry: testRegistry(),
-	})
+	}, nil)
 
 	if err := os.Remove(path); err != nil {
 		t.Fatalf("remove path: %v", err)
 	}
 
 	// Best-effort: missing files are recorded with ENOENT rather than failing the scan.
-	populateEntriesContent(fs, entries, true, 4, []string{repoRoot}, nil, nil)
+	populateEntriesContent(context.Background(), fs, nil, entries, true, false, 4, []string{repoRoot}, nil, nil)
 
 	var missing *ConfigEntry
 	for _, entry := range entries {
@@ -871,7 +872,7 @@ func TestGlobalXDevGuardrailSkipsDifferentDevice(t *testing.T) {
 		sys:  fakeStat{Dev: 1},
 		dir:  true,
 	}
-	state := newWalkState(afero.NewMemMapFs(), "/root", nil, ScopeGlobal, Options{
+	state := newWalkState(context.Background(), afero.NewMemMapFs(), "/root", nil, ScopeGlobal, Options{
 		GlobalXDev: true,
 	}, rootInfo)
 
@@ -1092,6 +1093,109 @@ func TestScanWithMemMapFs(t *testing.T) {
 	}
 }
 
+func streamRegistry() Registry {
+	registry := testRegistry()
+	user := registry.Patterns[0]
+	user.ID = "test-user"
+	user.Scope = ScopeUser
+	user.Paths = []string{"AGENTS.md"}
+	registry.Patterns = append(registry.Patterns, user)
+	return registry
+}
+
+func TestScanContextStreamsEntries(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("memfs scan tests use POSIX paths")
+	}
+	fs := afero.NewMemMapFs()
+	writeMemFile(t, fs, "/repo/README.md", "# Repo")
+	writeMemFile(t, fs, "/home/AGENTS.md", "# User")
+
+	var streamed []ConfigEntry
+	result, err := ScanContext(context.Background(), Options{
+		Fs:             fs,
+		RepoRoot:       "/repo",
+		UserRoots:      []string{"/home", "/missing"},
+		Registry:       streamRegistry(),
+		IncludeContent: true,
+	}, Sink{Entry: func(entry ConfigEntry) {
+		streamed = append(streamed, entry)
+	}})
+	if err != nil {
+		t.Fatalf("ScanContext: %v", err)
+	}
+	if len(streamed) != 2 || len(result.Entries) != 2 {
+		t.Fatalf("expected 2 streamed and returned entries, got %d and %d", len(streamed), len(result.Entries))
+	}
+	if streamed[0].Path != "/repo/README.md" || streamed[1].Path != "/home/AGENTS.md" {
+		t.Fatalf("expected repo entry before user entry, got %s then %s", streamed[0].Path, streamed[1].Path)
+	}
+	if streamed[0].Content == nil || *streamed[0].Content != "# Repo" || streamed[0].Sha256 == nil {
+		t.Fatalf("expected streamed entry with content, got %#v", streamed[0])
+	}
+}
+
+func TestScanContextCanceled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("memfs scan tests use POSIX paths")
+	}
+	fs := afero.NewMemMapFs()
+	writeMemFile(t, fs, "/repo/README.md", "# Repo")
+	writeMemFile(t, fs, "/home/AGENTS.md", "# User")
+	opts := Options{Fs: fs, RepoRoot: "/repo", UserRoots: []string{"/home"}, Registry: streamRegistry()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ScanContext(ctx, opts, Sink{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled for canceled context, got %v", err)
+	}
+
+	// Canceling from the sink stops the walk before the user roots.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	streamed := 0
+	result, err := ScanContext(ctx, opts, Sink{Entry: func(ConfigEntry) {
+		streamed++
+		cancel()
+	}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if streamed != 1 || len(result.Entries) != 1 || result.Entries[0].Path != "/repo/README.md" {
+		t.Fatalf("expected only the repo entry, got %d streamed and %#v", streamed, result.Entries)
+	}
+}
+
+func TestScanContextStreamsPerDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("memfs scan tests use POSIX paths")
+	}
+	fs := afero.NewMemMapFs()
+	writeMemFile(t, fs, "/repo/a/README.md", "# A")
+	writeMemFile(t, fs, "/repo/b/README.md", "# B")
+	registry := testRegistry()
+	registry.Patterns[0].Paths = []string{"**/README.md"}
+
+	// The first entry arrives once /repo/a is walked, so canceling there
+	// keeps /repo/b from being scanned.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var streamed []string
+	result, err := ScanContext(ctx, Options{Fs: fs, RepoRoot: "/repo", Registry: registry}, Sink{Entry: func(entry ConfigEntry) {
+		streamed = append(streamed, entry.Path)
+		cancel()
+	}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(streamed) != 1 || streamed[0] != "/repo/a/README.md" {
+		t.Fatalf("expected only /repo/a/README.md streamed, got %v", streamed)
+	}
+	if len(result.Entries) != 1 {
+		t.Fatalf("expected walk to stop after /repo/a, got %d entries", len(result.Entries))
+	}
+}
+
 func TestErrorCodeForExtended(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -29,6 +29,11 @@ type Options struct {
 	GlobalMaxBytes int64
 	GlobalXDev     bool
 	Fs             afero.Fs
+	// Cache, when set, reuses directory listings and file hashes from a
+	// previous scan. Callers save it after the scan.
+	Cache *Cache
+	// Graph resolves references between config files into Result.Graph.
+	Graph bool
 }
 
 // Registry describes the on-disk registry JSON structure.
@@ -39,19 +44,22 @@ type Registry struct {
 
 // Pattern represents a single tool pattern entry.
 type Pattern struct {
-	ID               string        `json:"id"`
-	ToolID           string        `json:"toolId"`
-	ToolName         string        `json:"toolName"`
-	Kind             string        `json:"kind"`
-	Scope            string        `json:"scope"`
-	Paths            []string      `json:"paths"`
-	Type             string        `json:"type"`
-	LoadBehavior     string        `json:"loadBehavior"`
-	Application      string        `json:"application"`
-	ApplicationField string        `json:"applicationField,omitempty"`
-	Notes            string        `json:"notes,omitempty"`
-	Hints            []PatternHint `json:"hints,omitempty"`
-	Docs             []string      `json:"docs"`
+	ID               string             `json:"id"`
+	ToolID           string             `json:"toolId"`
+	ToolName         string             `json:"toolName"`
+	Kind             string             `json:"kind"`
+	Scope            string             `json:"scope"`
+	Paths            []string           `json:"paths"`
+	Exclude          []string           `json:"exclude,omitempty"`
+	Conditions       *PatternConditions `json:"conditions,omitempty"`
+	Type             string             `json:"type"`
+	LoadBehavior     string             `json:"loadBehavior"`
+	Application      string             `json:"application"`
+	ApplicationField string             `json:"applicationField,omitempty"`
+	Notes            string             `json:"notes,omitempty"`
+	Hints            []PatternHint      `json:"hints,omitempty"`
+	Examples         *PatternExamples   `json:"examples,omitempty"`
+	Docs             []string           `json:"docs"`
 }
 
 // PatternHint captures structured activation hints for a tool.
@@ -73,6 +81,7 @@ type Output struct {
 	Scans           []Root        `json:"scans"`
 	Configs         []ConfigEntry `json:"configs"`
 	Warnings        []Warning     `json:"warnings"`
+	Graph           *Graph        `json:"graph,omitempty"`
 }
 
 // Summary provides aggregated counts from the scan results.
@@ -84,10 +93,11 @@ type Summary struct {
 
 // Timing captures scan timing metrics.
 type Timing struct {
-	DiscoveryMs int64 `json:"discoveryMs"`
-	HashingMs   int64 `json:"hashingMs"`
-	GitignoreMs int64 `json:"gitignoreMs"`
-	TotalMs     int64 `json:"totalMs"`
+	DiscoveryMs int64       `json:"discoveryMs"`
+	HashingMs   int64       `json:"hashingMs"`
+	GitignoreMs int64       `json:"gitignoreMs"`
+	TotalMs     int64       `json:"totalMs"`
+	Cache       *CacheStats `json:"cache,omitempty"`
 }
 
 // Root reports a scanned root and existence status.
@@ -116,6 +126,9 @@ type ConfigEntry struct {
 	Error                *string          `json:"error"`
 	Warning              *string          `json:"warning"`
 	Tools                []ToolEntry      `json:"tools"`
+	// References are imports and links parsed from the content; they are
+	// exported through Output.Graph.
+	References []Reference `json:"-"`
 }
 
 // ToolEntry captures tool metadata for a matched config.
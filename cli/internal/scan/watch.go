@@ -0,0 +1,568 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Watch event types.
+const (
+	WatchAdded   = "added"
+	WatchChanged = "changed"
+	WatchRemoved = "removed"
+)
+
+// DefaultWatchDebounce is the quiet period before changed paths are rescanned.
+const DefaultWatchDebounce = 200 * time.Millisecond
+
+// ErrWatchUnsupported is returned when the platform has no file notification backend.
+var ErrWatchUnsupported = errors.New("watch mode is not supported on this platform")
+
+// WatchEvent reports a config that was added, changed, or removed.
+// Entry holds the new state, or the last known state for removals.
+type WatchEvent struct {
+	Type    string      `json:"type"`
+	Path    string      `json:"path"`
+	Scope   string      `json:"scope"`
+	Sha256  *string     `json:"sha256"`
+	Initial bool        `json:"initial,omitempty"`
+	Time    int64       `json:"time"`
+	Entry   ConfigEntry `json:"entry"`
+}
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	Scan Options
+	// Debounce is the quiet period before rescanning (default DefaultWatchDebounce).
+	Debounce time.Duration
+	// Gitignore populates Gitignored for repo entries using git check-ignore.
+	Gitignore bool
+}
+
+// fsEvent is a raw notification from the platform watcher.
+type fsEvent struct {
+	Path     string
+	Overflow bool
+}
+
+// fsWatcher is implemented by platform notification backends.
+type fsWatcher interface {
+	// Add watches a single directory (not recursive).
+	Add(dir string) error
+	// Remove stops watching dir and every watched directory below it.
+	Remove(dir string)
+	Events() <-chan fsEvent
+	Errors() <-chan error
+	Close() error
+}
+
+// Watch scans once, emits an added event (Initial set) for every config, then
+// watches the scan roots and emits events for configs that change. Only the
+// paths reported by the platform watcher are rescanned. Watch returns nil when
+// ctx is canceled.
+func Watch(ctx context.Context, opts WatchOptions, emit func(WatchEvent) error) error {
+	watcher, err := newFSWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = watcher.Close()
+	}()
+
+	state, err := newWatchState(opts, watcher)
+	if err != nil {
+		return err
+	}
+	for _, event := range state.initialEvents() {
+		if err := emit(event); err != nil {
+			return err
+		}
+	}
+
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	pending := make(map[string]struct{})
+	full := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-watcher.Errors():
+			return err
+		case event := <-watcher.Events():
+			if event.Overflow {
+				full = true
+			} else {
+				pending[event.Path] = struct{}{}
+			}
+			timer.Reset(debounce)
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			pending = make(map[string]struct{})
+			if full {
+				paths = state.rootPaths()
+				full = false
+			}
+			for _, event := range state.apply(paths) {
+				if err := emit(event); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// watchState keeps the current scan result and rescans dirty paths.
+type watchState struct {
+	fs       afero.Fs
+	opts     WatchOptions
+	patterns []CompiledPattern
+	roots    []Root
+	repoRoot string
+	watcher  fsWatcher
+	entries  map[string]ConfigEntry
+	now      func() time.Time
+	// settingsPaths are the settings files requiresSetting conditions read.
+	settingsPaths []string
+}
+
+func newWatchState(opts WatchOptions, watcher fsWatcher) (*watchState, error) {
+	fs := opts.Scan.Fs
+	if fs == nil {
+		fs = afero.NewOsFs()
+		opts.Scan.Fs = fs
+	}
+	patterns, _, err := loadPatterns(fs, opts.Scan)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := Scan(opts.Scan)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := filepath.Abs(opts.Scan.RepoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	settingsOpts := opts.Scan
+	settingsOpts.RepoRoot = filepath.Clean(repoRoot)
+
+	state := &watchState{
+		fs:            fs,
+		opts:          opts,
+		patterns:      patterns,
+		roots:         result.Scans,
+		repoRoot:      filepath.Clean(repoRoot),
+		watcher:       watcher,
+		entries:       make(map[string]ConfigEntry, len(result.Entries)),
+		now:           time.Now,
+		settingsPaths: newConditionEnv(fs, settingsOpts).settingsPaths,
+	}
+	for _, entry := range state.gitignore(result.Entries) {
+		sortTools(entry.Tools)
+		state.entries[entry.Path] = entry
+	}
+	for _, root := range state.roots {
+		if root.Exists {
+			if err := state.watchTree(root.Root); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return state, nil
+}
+
+func (s *watchState) initialEvents() []WatchEvent {
+	events := make([]WatchEvent, 0, len(s.entries))
+	for _, path := range sortedKeys(s.entries) {
+		event := s.event(WatchAdded, s.entries[path])
+		event.Initial = true
+		events = append(events, event)
+	}
+	return events
+}
+
+func (s *watchState) rootPaths() []string {
+	paths := make([]string, 0, len(s.roots))
+	for _, root := range s.roots {
+		paths = append(paths, root.Root)
+	}
+	return paths
+}
+
+// apply rescans dirty paths and returns the resulting events in path order.
+func (s *watchState) apply(paths []string) []WatchEvent {
+	// Removed paths go first so the watches of a renamed directory are
+	// dropped before its new location is registered.
+	var removed, present []string
+	gitignoreDirty := false
+	dirty := collapsePaths(paths)
+	for _, path := range dirty {
+		if filepath.Base(path) == ".gitignore" {
+			gitignoreDirty = true
+		}
+		if _, err := lstat(s.fs, path); err != nil {
+			removed = append(removed, path)
+		} else {
+			present = append(present, path)
+		}
+	}
+
+	var events []WatchEvent
+	for _, path := range append(removed, present...) {
+		root, ok := s.rootFor(path)
+		if !ok || isGitInternal(root.Root, path) {
+			continue
+		}
+		events = append(events, s.rescan(root, path)...)
+	}
+	events = append(events, s.refreshConditions(dirty)...)
+	if gitignoreDirty && s.opts.Gitignore {
+		events = append(events, s.refreshGitignore(events)...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Path < events[j].Path
+	})
+	return events
+}
+
+// rescan walks path (a file or directory) and diffs the configs below it.
+func (s *watchState) rescan(root Root, path string) []WatchEvent {
+	found := make(map[string]*ConfigEntry)
+	var scratch Result
+
+	info, err := lstat(s.fs, path)
+	switch {
+	case err == nil:
+		walk := newWalkState(context.Background(), s.fs, root.Root, nil, root.Scope, s.opts.Scan, nil)
+		scanPath(path, path, root.Root, root.Scope, s.patterns, found, &scratch, walk, false)
+		if info.IsDir() {
+			_ = s.watchTree(path)
+		}
+	case s.watcher != nil:
+		s.watcher.Remove(path)
+	}
+
+	rootsAbs := s.scopeRoots()
+	var current []ConfigEntry
+	for _, entry := range found {
+		resolved := entry.Resolved
+		if resolved == "" {
+			resolved = entry.Path
+		}
+		populateEntryContent(s.fs, entry, resolved, rootForScope(resolved, entry.Scope, rootsAbs[ScopeRepo], rootsAbs[ScopeUser], rootsAbs[ScopeGlobal]), s.opts.Scan.IncludeContent, s.opts.Scan.Graph)
+		sortTools(entry.Tools)
+		current = append(current, *entry)
+	}
+	current = s.gitignore(current)
+
+	next := make(map[string]ConfigEntry, len(current))
+	for _, entry := range current {
+		next[entry.Path] = entry
+	}
+
+	var events []WatchEvent
+	for key, old := range s.entries {
+		if !pathWithin(path, key) {
+			continue
+		}
+		if _, ok := next[key]; !ok {
+			delete(s.entries, key)
+			events = append(events, s.event(WatchRemoved, old))
+		}
+	}
+	for key, entry := range next {
+		old, ok := s.entries[key]
+		s.entries[key] = entry
+		switch {
+		case !ok:
+			events = append(events, s.event(WatchAdded, entry))
+		case entryChanged(old, entry):
+			events = append(events, s.event(WatchChanged, entry))
+		}
+	}
+	return events
+}
+
+// refreshConditions rescans files whose pattern conditions may depend on a
+// dirty path: the files next to a created or deleted requiresFile target, or
+// every root when a settings file or a target that cannot be traced back to
+// the matched file's directory changes.
+func (s *watchState) refreshConditions(paths []string) []WatchEvent {
+	seen := make(map[string]struct{})
+	var dirs []string
+	all := false
+	for _, path := range paths {
+		if s.isRootPath(path) {
+			// The whole root was just rescanned.
+			continue
+		}
+		pathDirs, pathAll := s.conditionDirs(path)
+		all = all || pathAll
+		for _, dir := range pathDirs {
+			if _, ok := seen[dir]; !ok {
+				seen[dir] = struct{}{}
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+
+	var events []WatchEvent
+	if all {
+		for _, root := range s.roots {
+			if root.Exists {
+				events = append(events, s.rescan(root, root.Root)...)
+			}
+		}
+		return events
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		root, ok := s.rootFor(dir)
+		if !ok || isGitInternal(root.Root, dir) {
+			continue
+		}
+		children, err := afero.ReadDir(s.fs, dir)
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			if !child.IsDir() {
+				events = append(events, s.rescan(root, filepath.Join(dir, child.Name()))...)
+			}
+		}
+	}
+	return events
+}
+
+// conditionDirs returns the directories holding files whose requiresFile
+// conditions path could satisfy or break. all is set when path is a settings
+// file read by requiresSetting, an absolute target, or a target that leaves
+// the matched file's directory.
+func (s *watchState) conditionDirs(path string) (dirs []string, all bool) {
+	sep := string(filepath.Separator)
+	for _, compiled := range s.patterns {
+		conditions := compiled.Pattern.Conditions
+		if conditions == nil {
+			continue
+		}
+		if len(conditions.RequiresSetting) > 0 {
+			for _, settings := range s.settingsPaths {
+				if pathWithin(path, settings) {
+					return nil, true
+				}
+			}
+		}
+		for _, required := range conditions.RequiresFile {
+			target := filepath.Clean(filepath.FromSlash(expandHomePath(required)))
+			if filepath.IsAbs(target) {
+				if pathWithin(path, target) {
+					return nil, true
+				}
+				continue
+			}
+			parts := strings.Split(target, sep)
+			parents := 0
+			for parents < len(parts) && parts[parents] == ".." {
+				parents++
+			}
+			// path is the target itself or one of its directories.
+			for k := len(parts); k > parents; k-- {
+				suffix := sep + filepath.Join(parts[parents:k]...)
+				if !strings.HasSuffix(path, suffix) {
+					continue
+				}
+				if parents > 0 {
+					return nil, true
+				}
+				dirs = append(dirs, strings.TrimSuffix(path, suffix))
+				break
+			}
+		}
+	}
+	return dirs, false
+}
+
+func (s *watchState) isRootPath(path string) bool {
+	for _, root := range s.roots {
+		if root.Root == path {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshGitignore re-checks every repo entry after a .gitignore change,
+// skipping paths that already produced an event in this batch.
+func (s *watchState) refreshGitignore(seen []WatchEvent) []WatchEvent {
+	skip := make(map[string]struct{}, len(seen))
+	for _, event := range seen {
+		skip[event.Path] = struct{}{}
+	}
+	var repoEntries []ConfigEntry
+	for _, path := range sortedKeys(s.entries) {
+		if _, ok := skip[path]; ok {
+			continue
+		}
+		if entry := s.entries[path]; entry.Scope == ScopeRepo {
+			repoEntries = append(repoEntries, entry)
+		}
+	}
+	var events []WatchEvent
+	for _, entry := range s.gitignore(repoEntries) {
+		if entry.Gitignored != s.entries[entry.Path].Gitignored {
+			s.entries[entry.Path] = entry
+			events = append(events, s.event(WatchChanged, entry))
+		}
+	}
+	return events
+}
+
+func (s *watchState) gitignore(entries []ConfigEntry) []ConfigEntry {
+	if !s.opts.Gitignore || len(entries) == 0 {
+		return entries
+	}
+	updated, err := ApplyGitignore(Result{Entries: entries}, s.repoRoot)
+	if err != nil {
+		return entries
+	}
+	return updated.Entries
+}
+
+// watchTree registers dir and its subdirectories, skipping .git internals.
+// Symlinked directories are not followed.
+func (s *watchState) watchTree(dir string) error {
+	if s.watcher == nil {
+		return nil
+	}
+	return afero.Walk(s.fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if info.Name() == ".git" && path != dir {
+			return filepath.SkipDir
+		}
+		return s.watcher.Add(path)
+	})
+}
+
+// rootFor returns the first scan root containing path, matching Scan's
+// first-root-wins order.
+func (s *watchState) rootFor(path string) (Root, bool) {
+	for _, root := range s.roots {
+		if root.Exists && isWithinRoot(path, root.Root) {
+			return root, true
+		}
+	}
+	return Root{}, false
+}
+
+func (s *watchState) scopeRoots() map[string][]string {
+	roots := make(map[string][]string)
+	for _, root := range s.roots {
+		roots[root.Scope] = append(roots[root.Scope], root.Root)
+	}
+	return roots
+}
+
+func (s *watchState) event(kind string, entry ConfigEntry) WatchEvent {
+	return WatchEvent{
+		Type:   kind,
+		Path:   entry.Path,
+		Scope:  entry.Scope,
+		Sha256: entry.Sha256,
+		Time:   s.now().UnixMilli(),
+		Entry:  entry,
+	}
+}
+
+// collapsePaths cleans and sorts paths, dropping any path below another one.
+func collapsePaths(paths []string) []string {
+	cleaned := make([]string, 0, len(paths))
+	for _, path := range paths {
+		cleaned = append(cleaned, filepath.Clean(path))
+	}
+	sort.Strings(cleaned)
+	var out []string
+	for _, path := range cleaned {
+		if len(out) > 0 && pathWithin(out[len(out)-1], path) {
+			continue
+		}
+		out = append(out, path)
+	}
+	return out
+}
+
+// pathWithin reports whether path equals base or lies below it.
+func pathWithin(base string, path string) bool {
+	if path == base {
+		return true
+	}
+	prefix := base
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, prefix)
+}
+
+func isGitInternal(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if part == ".git" {
+			return true
+		}
+	}
+	return false
+}
+
+func entryChanged(old ConfigEntry, next ConfigEntry) bool {
+	return !equalStringPtr(old.Sha256, next.Sha256) ||
+		!equalStringPtr(old.Error, next.Error) ||
+		old.Gitignored != next.Gitignored ||
+		old.Scope != next.Scope ||
+		!reflect.DeepEqual(old.Tools, next.Tools)
+}
+
+func equalStringPtr(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sortedKeys(entries map[string]ConfigEntry) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,173 @@
+//go:build linux && !js
+
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_MODIFY | unix.IN_ATTRIB |
+	unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF |
+	unix.IN_ONLYDIR | unix.IN_DONT_FOLLOW | unix.IN_EXCL_UNLINK
+
+// inotifyWatcher watches directories with inotify. The descriptor is wrapped in
+// an *os.File so reads go through the runtime poller and Close unblocks them.
+type inotifyWatcher struct {
+	file   *os.File
+	fd     int
+	events chan fsEvent
+	errors chan error
+	done   chan struct{}
+
+	mu    sync.Mutex
+	paths map[int]string
+	wds   map[string]int
+}
+
+func newFSWatcher() (fsWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %w", err)
+	}
+	w := &inotifyWatcher{
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		fd:     fd,
+		events: make(chan fsEvent, 256),
+		errors: make(chan error, 1),
+		done:   make(chan struct{}),
+		paths:  make(map[int]string),
+		wds:    make(map[string]int),
+	}
+	go w.readLoop()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Add(dir string) error {
+	dir = filepath.Clean(dir)
+	wd, err := unix.InotifyAddWatch(w.fd, dir, inotifyMask)
+	if err != nil {
+		if errors.Is(err, unix.ENOSPC) {
+			return fmt.Errorf("inotify watch limit reached at %s (raise fs.inotify.max_user_watches): %w", dir, err)
+		}
+		if errors.Is(err, unix.ENOENT) || errors.Is(err, unix.ENOTDIR) || errors.Is(err, unix.EACCES) {
+			return nil
+		}
+		return fmt.Errorf("inotify watch %s: %w", dir, err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if previous, ok := w.paths[wd]; ok && previous != dir {
+		delete(w.wds, previous)
+	}
+	w.paths[wd] = dir
+	w.wds[dir] = wd
+	return nil
+}
+
+func (w *inotifyWatcher) Remove(dir string) {
+	dir = filepath.Clean(dir)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for path, wd := range w.wds {
+		if !pathWithin(dir, path) {
+			continue
+		}
+		_, _ = unix.InotifyRmWatch(w.fd, uint32(wd))
+		delete(w.wds, path)
+		delete(w.paths, wd)
+	}
+}
+
+func (w *inotifyWatcher) Events() <-chan fsEvent {
+	return w.events
+}
+
+func (w *inotifyWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+func (w *inotifyWatcher) Close() error {
+	select {
+	case <-w.done:
+		return nil
+	default:
+		close(w.done)
+	}
+	return w.file.Close()
+}
+
+func (w *inotifyWatcher) readLoop() {
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			select {
+			case <-w.done:
+			default:
+				w.errors <- fmt.Errorf("inotify read: %w", err)
+			}
+			return
+		}
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			nameEnd := nameStart + int(raw.Len)
+			if nameEnd > n {
+				break
+			}
+			name := string(trimNUL(buf[nameStart:nameEnd]))
+			offset = nameEnd
+
+			event, ok := w.translate(raw, name)
+			if !ok {
+				continue
+			}
+			select {
+			case w.events <- event:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+func (w *inotifyWatcher) translate(raw *unix.InotifyEvent, name string) (fsEvent, bool) {
+	if raw.Mask&unix.IN_Q_OVERFLOW != 0 {
+		return fsEvent{Overflow: true}, true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	dir, ok := w.paths[int(raw.Wd)]
+	if !ok {
+		return fsEvent{}, false
+	}
+	if raw.Mask&unix.IN_IGNORED != 0 {
+		delete(w.paths, int(raw.Wd))
+		if w.wds[dir] == int(raw.Wd) {
+			delete(w.wds, dir)
+		}
+		return fsEvent{}, false
+	}
+	if name == "" {
+		// Events on the directory itself (attribute changes, deletion) are
+		// also reported by its parent, except for roots.
+		return fsEvent{Path: dir}, true
+	}
+	return fsEvent{Path: filepath.Join(dir, name)}, true
+}
+
+func trimNUL(name []byte) []byte {
+	for i, b := range name {
+		if b == 0 {
+			return name[:i]
+		}
+	}
+	return name
+}
@@ -0,0 +1,7 @@
+//go:build !linux || js
+
+package scan
+
+func newFSWatcher() (fsWatcher, error) {
+	return nil, ErrWatchUnsupported
+}
@@ -0,0 +1,272 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+func watchRegistry() Registry {
+	registry := testRegistry()
+	registry.Patterns[0].Paths = []string{"**/AGENTS.md"}
+	return registry
+}
+
+func writeMemFile(t *testing.T, fs afero.Fs, path string, content string) {
+	t.Helper()
+	if err := fs.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestWatchState(t *testing.T, fs afero.Fs, repoRoot string) *watchState {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("memfs watch tests use POSIX paths")
+	}
+	state, err := newWatchState(WatchOptions{Scan: Options{
+		Fs:       fs,
+		RepoRoot: repoRoot,
+		RepoOnly: true,
+		Registry: watchRegistry(),
+	}}, nil)
+	if err != nil {
+		t.Fatalf("newWatchState: %v", err)
+	}
+	return state
+}
+
+func eventSummary(events []WatchEvent) []string {
+	out := make([]string, 0, len(events))
+	for _, event := range events {
+		out = append(out, event.Type+" "+filepath.ToSlash(event.Path))
+	}
+	return out
+}
+
+func assertEvents(t *testing.T, events []WatchEvent, want ...string) {
+	t.Helper()
+	got := eventSummary(events)
+	if len(got) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, got)
+		}
+	}
+}
+
+func TestWatchStateApply(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeMemFile(t, fs, "/repo/AGENTS.md", "# Root")
+	writeMemFile(t, fs, "/repo/docs/AGENTS.md", "# Docs")
+	writeMemFile(t, fs, "/repo/docs/notes.md", "# Notes")
+
+	state := newTestWatchState(t, fs, "/repo")
+	initial := state.initialEvents()
+	assertEvents(t, initial, "added /repo/AGENTS.md", "added /repo/docs/AGENTS.md")
+	if !initial[0].Initial || initial[0].Sha256 == nil {
+		t.Fatalf("expected initial event with sha256, got %#v", initial[0])
+	}
+	before := *initial[0].Sha256
+
+	// Rewriting identical content is not a change.
+	writeMemFile(t, fs, "/repo/AGENTS.md", "# Root")
+	assertEvents(t, state.apply([]string{"/repo/AGENTS.md"}))
+
+	writeMemFile(t, fs, "/repo/AGENTS.md", "# Root v2")
+	changed := state.apply([]string{"/repo/AGENTS.md", "/repo/docs/notes.md"})
+	assertEvents(t, changed, "changed /repo/AGENTS.md")
+	if changed[0].Sha256 == nil || *changed[0].Sha256 == before || changed[0].Initial {
+		t.Fatalf("expected new sha256, got %#v", changed[0])
+	}
+
+	writeMemFile(t, fs, "/repo/pkg/api/AGENTS.md", "# API")
+	assertEvents(t, state.apply([]string{"/repo/pkg"}), "added /repo/pkg/api/AGENTS.md")
+
+	if err := fs.Remove("/repo/pkg/api/AGENTS.md"); err != nil {
+		t.Fatal(err)
+	}
+	removed := state.apply([]string{"/repo/pkg/api/AGENTS.md"})
+	assertEvents(t, removed, "removed /repo/pkg/api/AGENTS.md")
+	if removed[0].Entry.Path != "/repo/pkg/api/AGENTS.md" {
+		t.Fatalf("expected removed event to carry last entry, got %#v", removed[0].Entry)
+	}
+	if len(state.entries) != 2 {
+		t.Fatalf("expected 2 tracked entries, got %d", len(state.entries))
+	}
+}
+
+func TestWatchStateDirectoryRename(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeMemFile(t, fs, "/repo/docs/AGENTS.md", "# Docs")
+	writeMemFile(t, fs, "/repo/docs/deep/AGENTS.md", "# Deep")
+
+	state := newTestWatchState(t, fs, "/repo")
+	if err := fs.Rename("/repo/docs", "/repo/guide"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A child path of the renamed directory is folded into its parent.
+	events := state.apply([]string{"/repo/guide", "/repo/docs", "/repo/docs/deep/AGENTS.md"})
+	assertEvents(t, events,
+		"removed /repo/docs/AGENTS.md",
+		"removed /repo/docs/deep/AGENTS.md",
+		"added /repo/guide/AGENTS.md",
+		"added /repo/guide/deep/AGENTS.md",
+	)
+}
+
+func TestWatchStateConditionTargets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("memfs watch tests use POSIX paths")
+	}
+	fs := afero.NewMemMapFs()
+	writeMemFile(t, fs, "/repo/pkg/AGENTS.md", "# Pkg")
+	writeMemFile(t, fs, "/repo/docs/AGENTS.md", "# Docs")
+
+	registry := watchRegistry()
+	registry.Patterns[0].Conditions = &PatternConditions{RequiresFile: []string{".agents/enabled"}}
+	settings := registry.Patterns[0]
+	settings.ID = "settings-gated"
+	settings.Paths = []string{"docs/AGENTS.md"}
+	settings.Conditions = &PatternConditions{RequiresSetting: []string{"agents.enabled"}}
+	registry.Patterns = append(registry.Patterns, settings)
+
+	state, err := newWatchState(WatchOptions{Scan: Options{Fs: fs, RepoRoot: "/repo", RepoOnly: true, Registry: registry}}, nil)
+	if err != nil {
+		t.Fatalf("newWatchState: %v", err)
+	}
+	assertEvents(t, state.initialEvents())
+
+	// Creating a sibling target enables the already-present config.
+	writeMemFile(t, fs, "/repo/pkg/.agents/enabled", "")
+	assertEvents(t, state.apply([]string{"/repo/pkg/.agents"}), "added /repo/pkg/AGENTS.md")
+
+	if err := fs.RemoveAll("/repo/pkg/.agents"); err != nil {
+		t.Fatal(err)
+	}
+	assertEvents(t, state.apply([]string{"/repo/pkg/.agents/enabled"}), "removed /repo/pkg/AGENTS.md")
+
+	// Editing settings re-checks requiresSetting patterns.
+	writeMemFile(t, fs, "/repo/.vscode/settings.json", `{"agents.enabled": true}`)
+	assertEvents(t, state.apply([]string{"/repo/.vscode/settings.json"}), "added /repo/docs/AGENTS.md")
+	writeMemFile(t, fs, "/repo/.vscode/settings.json", `{"agents.enabled": false}`)
+	assertEvents(t, state.apply([]string{"/repo/.vscode/settings.json"}), "removed /repo/docs/AGENTS.md")
+}
+
+func TestWatchStateIgnoresGitInternals(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeMemFile(t, fs, "/repo/AGENTS.md", "# Root")
+	state := newTestWatchState(t, fs, "/repo")
+
+	writeMemFile(t, fs, "/repo/.git/AGENTS.md", "# not a config")
+	assertEvents(t, state.apply([]string{"/repo/.git/AGENTS.md", "/outside/AGENTS.md"}))
+}
+
+func TestCollapsePaths(t *testing.T) {
+	got := collapsePaths([]string{"/a/b/c", "/a/b", "/a/bc", "/d/", "/a/b/"})
+	want := []string{"/a/b", "/a/bc", "/d"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestWatchStreamsEvents(t *testing.T) {
+	watcher, err := newFSWatcher()
+	if errors.Is(err, ErrWatchUnsupported) {
+		t.Skip("watch not supported on this platform")
+	}
+	if err != nil {
+		t.Fatalf("newFSWatcher: %v", err)
+	}
+	_ = watcher.Close()
+
+	repoRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoRoot, "AGENTS.md"), []byte("# Root"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan WatchEvent, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, WatchOptions{
+			Scan:     Options{RepoRoot: repoRoot, RepoOnly: true, Registry: watchRegistry()},
+			Debounce: 20 * time.Millisecond,
+		}, func(event WatchEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	next := func() WatchEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case err := <-done:
+			t.Fatalf("watch stopped: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for watch event")
+		}
+		return WatchEvent{}
+	}
+
+	if event := next(); event.Type != WatchAdded || !event.Initial {
+		t.Fatalf("expected initial added event, got %#v", event)
+	}
+
+	nested := filepath.Join(repoRoot, "docs", "api")
+	if err := os.MkdirAll(nested, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nested, "AGENTS.md"), []byte("# API"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if event := next(); event.Type != WatchAdded || event.Path != filepath.Join(nested, "AGENTS.md") {
+		t.Fatalf("expected added nested config, got %#v", event)
+	}
+
+	renamed := filepath.Join(repoRoot, "guide")
+	if err := os.Rename(filepath.Join(repoRoot, "docs"), renamed); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for len(got) < 2 {
+		event := next()
+		got[event.Type] = event.Path
+	}
+	if got[WatchRemoved] != filepath.Join(nested, "AGENTS.md") || got[WatchAdded] != filepath.Join(renamed, "api", "AGENTS.md") {
+		t.Fatalf("unexpected rename events: %v", got)
+	}
+
+	// The renamed directory is still watched under its new name.
+	if err := os.WriteFile(filepath.Join(renamed, "api", "AGENTS.md"), []byte("# API v2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if event := next(); event.Type != WatchChanged || event.Path != filepath.Join(renamed, "api", "AGENTS.md") {
+		t.Fatalf("expected changed event after rename, got %#v", event)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	scanhash "markdowntown-cli/internal/hash"
 )
@@ -44,6 +45,15 @@ func (c *FileCache) Put(url string, payload []byte) error {
 	return os.WriteFile(path, payload, 0o600)
 }
 
+// ModTime reports when the cached body for the URL was last written.
+func (c *FileCache) ModTime(url string) (time.Time, bool) {
+	info, err := os.Stat(c.pathFor(url))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return info.ModTime(), true
+}
+
 func (c *FileCache) pathFor(url string) string {
 	name := scanhash.SumHex([]byte(url)) + ".body"
 	return filepath.Join(c.root, name)
@@ -0,0 +1,141 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies how a claim differs between two snapshots.
+type ChangeKind string
+
+const (
+	// ChangeAdded marks a claim only present in the newer snapshot.
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved marks a claim only present in the older snapshot.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeStrength marks a claim whose MUST/SHOULD/MAY level changed.
+	ChangeStrength ChangeKind = "strength-changed"
+)
+
+// ClaimChange describes one claim difference. Previous* fields describe the
+// older snapshot and are empty for added claims; the others describe the
+// newer snapshot and hold the old values for removed claims.
+type ClaimChange struct {
+	Kind             ChangeKind        `json:"kind"`
+	ClaimID          string            `json:"claimId"`
+	PreviousClaimID  string            `json:"previousClaimId,omitempty"`
+	SectionID        string            `json:"sectionId"`
+	Text             string            `json:"text"`
+	Strength         NormativeStrength `json:"strength"`
+	PreviousStrength NormativeStrength `json:"previousStrength,omitempty"`
+	Span             ProofSpan         `json:"span"`
+	PreviousSpan     *ProofSpan        `json:"previousSpan,omitempty"`
+}
+
+// DiffClaims compares the claims of two snapshots of one source. Claim IDs
+// embed the snapshot, so claims are paired by their text with the normative
+// keyword masked, preferring the same section; a pair whose strength differs
+// is a strength change. Claims that moved between sections without other
+// edits are not reported.
+func DiffClaims(base, head []Claim) []ClaimChange {
+	remaining := make(map[string][]int, len(base))
+	for i, claim := range base {
+		key := claimChangeKey(claim.Text)
+		remaining[key] = append(remaining[key], i)
+	}
+	matched := make([]bool, len(base))
+
+	var changes []ClaimChange
+	for _, claim := range head {
+		key := claimChangeKey(claim.Text)
+		candidates := remaining[key]
+		if len(candidates) == 0 {
+			changes = append(changes, ClaimChange{
+				Kind:      ChangeAdded,
+				ClaimID:   claim.ID,
+				SectionID: claim.SectionID,
+				Text:      claim.Text,
+				Strength:  claim.Strength,
+				Span:      claimSpan(claim),
+			})
+			continue
+		}
+		pick := 0
+		for i, idx := range candidates {
+			if base[idx].SectionID == claim.SectionID {
+				pick = i
+				break
+			}
+		}
+		idx := candidates[pick]
+		remaining[key] = append(candidates[:pick:pick], candidates[pick+1:]...)
+		matched[idx] = true
+
+		previous := base[idx]
+		if previous.Strength == claim.Strength {
+			continue
+		}
+		previousSpan := claimSpan(previous)
+		changes = append(changes, ClaimChange{
+			Kind:             ChangeStrength,
+			ClaimID:          claim.ID,
+			PreviousClaimID:  previous.ID,
+			SectionID:        claim.SectionID,
+			Text:             claim.Text,
+			Strength:         claim.Strength,
+			PreviousStrength: previous.Strength,
+			Span:             claimSpan(claim),
+			PreviousSpan:     &previousSpan,
+		})
+	}
+
+	for i, claim := range base {
+		if matched[i] {
+			continue
+		}
+		changes = append(changes, ClaimChange{
+			Kind:      ChangeRemoved,
+			ClaimID:   claim.ID,
+			SectionID: claim.SectionID,
+			Text:      claim.Text,
+			Strength:  claim.Strength,
+			Span:      claimSpan(claim),
+		})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changeKindOrder(changes[i].Kind) < changeKindOrder(changes[j].Kind)
+		}
+		if changes[i].SectionID != changes[j].SectionID {
+			return changes[i].SectionID < changes[j].SectionID
+		}
+		return changes[i].Span.Start < changes[j].Span.Start
+	})
+	return changes
+}
+
+// claimChangeKey normalizes claim text with the normative keyword masked so
+// "MUST run tests" and "should run tests" pair up.
+func claimChangeKey(text string) string {
+	masked := normativeRE.ReplaceAllString(normalizeClaimText(text), "<modal>")
+	return strings.ToLower(masked)
+}
+
+func claimSpan(claim Claim) ProofSpan {
+	if len(claim.Proof.Spans) > 0 {
+		return claim.Proof.Spans[0]
+	}
+	return ProofSpan{SectionID: claim.SectionID}
+}
+
+func changeKindOrder(kind ChangeKind) int {
+	switch kind {
+	case ChangeStrength:
+		return 0
+	case ChangeAdded:
+		return 1
+	default:
+		return 2
+	}
+}
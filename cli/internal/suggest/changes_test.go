@@ -0,0 +1,42 @@
+package suggest
+
+import "testing"
+
+func TestDiffClaims(t *testing.T) {
+	source := Source{ID: "doc", URL: "https://example.com/doc.md", Client: "codex"}
+	extract := func(body, snapshot string) []Claim {
+		doc, err := NormalizeDocument(body, "markdown")
+		if err != nil {
+			t.Fatalf("normalize: %v", err)
+		}
+		return ExtractClaims(doc, source, snapshot)
+	}
+	base := extract("# Setup\n\nYou MUST run tests.\nYou SHOULD keep files small.\nYou MAY use emoji.\n\n# Style\n\nYou SHOULD use tabs.\n", "sha256:a")
+	head := extract("# Setup\n\nYou should run tests.\nYou SHOULD keep files small.\nYou MUST sign commits.\n\n# Layout\n\nYou SHOULD use tabs.\n", "sha256:b")
+
+	changes := DiffClaims(base, head)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", changes)
+	}
+
+	strength := changes[0]
+	if strength.Kind != ChangeStrength || strength.PreviousStrength != StrengthMust || strength.Strength != StrengthShould {
+		t.Fatalf("unexpected strength change: %+v", strength)
+	}
+	if strength.PreviousSpan == nil || strength.PreviousClaimID == strength.ClaimID {
+		t.Fatalf("expected previous claim and span: %+v", strength)
+	}
+	if added := changes[1]; added.Kind != ChangeAdded || added.Strength != StrengthMust || added.Text != "You MUST sign commits." {
+		t.Fatalf("unexpected added change: %+v", added)
+	}
+	if removed := changes[2]; removed.Kind != ChangeRemoved || removed.Strength != StrengthMay {
+		t.Fatalf("unexpected removed change: %+v", removed)
+	}
+	if changes[1].Span.End <= changes[1].Span.Start {
+		t.Fatalf("expected proof span, got %+v", changes[1].Span)
+	}
+
+	if changes := DiffClaims(base, base); len(changes) != 0 {
+		t.Fatalf("expected no changes for identical claims, got %+v", changes)
+	}
+}
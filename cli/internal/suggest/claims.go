@@ -50,9 +50,18 @@ type Claim struct {
 	Proof      Proof             `json:"proof"`
 }
 
-var normativeRE = regexp.MustCompile(`(?i)\b(must|should|may)\b`)
+var (
+	normativeRE = regexp.MustCompile(`(?i)\b(must|should|may)\b`)
+	codeSpanRE  = regexp.MustCompile("``.*?``|`[^`]*`")
+	strongRE    = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	emStarRE    = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	emUnderRE   = regexp.MustCompile(`(^|\W)_([^_\s](?:[^_]*?[^_\s])?)_(\W|$)`)
+	escapeRE    = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+)
 
 // ExtractClaims scans a normalized document for explicit normative statements.
+// Fenced code blocks and inline code spans are ignored, so keywords in
+// commands or identifiers do not produce claims.
 func ExtractClaims(doc NormalizedDocument, source Source, snapshotID string) []Claim {
 	var claims []Claim
 	for _, section := range doc.Sections {
@@ -62,13 +71,20 @@ func ExtractClaims(doc NormalizedDocument, source Source, snapshotID string) []C
 		}
 		lines := strings.Split(section.Content, "\n")
 		offset := 0
+		inFence := false
 		for _, line := range lines {
 			trimmed := strings.TrimSpace(line)
-			if trimmed == "" {
+			if strings.HasPrefix(trimmed, "```") {
+				inFence = !inFence
 				offset += len(line) + 1
 				continue
 			}
-			strength, ok := strengthFromLine(trimmed)
+			if trimmed == "" || inFence {
+				offset += len(line) + 1
+				continue
+			}
+			text := plainClaimText(trimmed)
+			strength, ok := strengthFromLine(codeSpanRE.ReplaceAllString(text, " "))
 			if !ok {
 				offset += len(line) + 1
 				continue
@@ -76,7 +92,7 @@ func ExtractClaims(doc NormalizedDocument, source Source, snapshotID string) []C
 			leading := len(line) - len(strings.TrimLeft(line, " \t"))
 			start := offset + leading
 			end := start + len(trimmed)
-			normalized := normalizeClaimText(trimmed)
+			normalized := normalizeClaimText(text)
 			claimID := newClaimID(source.ID, snapshotID, section.ID, normalized, strength)
 			claims = append(claims, Claim{
 				ID:         claimID,
@@ -85,7 +101,7 @@ func ExtractClaims(doc NormalizedDocument, source Source, snapshotID string) []C
 				Client:     source.Client,
 				SnapshotID: snapshotID,
 				SectionID:  section.ID,
-				Text:       trimmed,
+				Text:       text,
 				Strength:   strength,
 				Proof: Proof{
 					Sources:           []string{source.URL},
@@ -117,6 +133,28 @@ func strengthFromLine(line string) (NormativeStrength, bool) {
 	}
 }
 
+// plainClaimText drops list and blockquote markers, emphasis, and backslash
+// escapes from a normalized line. Inline code spans are kept verbatim.
+func plainClaimText(line string) string {
+	line = stripBlockMarkers(line)
+	var b strings.Builder
+	last := 0
+	for _, loc := range codeSpanRE.FindAllStringIndex(line, -1) {
+		b.WriteString(stripEmphasis(line[last:loc[0]]))
+		b.WriteString(line[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(stripEmphasis(line[last:]))
+	return strings.TrimSpace(b.String())
+}
+
+func stripEmphasis(text string) string {
+	text = strongRE.ReplaceAllString(text, "$1$2")
+	text = emStarRE.ReplaceAllString(text, "$1")
+	text = emUnderRE.ReplaceAllString(text, "$1$2$3")
+	return escapeRE.ReplaceAllString(text, "$1")
+}
+
 func normalizeClaimText(text string) string {
 	parts := strings.Fields(text)
 	return strings.Join(parts, " ")
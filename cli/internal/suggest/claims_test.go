@@ -67,7 +67,7 @@ func TestClaimsConflicts(t *testing.T) {
 		{
 			ID:       "c2",
 			Client:   "codex",
-			Text:     "You MUST use yarn.",
+			Text:     "You MUST NOT use pnpm.",
 			Strength: StrengthMust,
 			Proof:    Proof{NormativeStrength: StrengthMust},
 		},
@@ -85,6 +85,13 @@ func TestClaimsConflicts(t *testing.T) {
 			Strength: StrengthMust,
 			Proof:    Proof{NormativeStrength: StrengthMust},
 		},
+		{
+			ID:       "c5",
+			Client:   "codex",
+			Text:     "You MUST use yarn.",
+			Strength: StrengthMust,
+			Proof:    Proof{NormativeStrength: StrengthMust},
+		},
 	}
 
 	updated, conflicts := DetectConflicts(claims)
@@ -157,3 +164,34 @@ func readFixture(t *testing.T, name string) string {
 	}
 	return string(data)
 }
+
+func TestExtractClaimsIgnoresCode(t *testing.T) {
+	input := "# Setup\nRun `go test -run Must` first.\n```sh\n# you must not skip this\n```\nThe ``should`` helper asserts values.\nYou must run `make lint`."
+	doc, err := NormalizeDocument(input, "markdown")
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	claims := ExtractClaims(doc, Source{ID: "fixture", Client: "codex", URL: "https://example.com"}, "sha256:fixture")
+	if len(claims) != 1 {
+		t.Fatalf("expected 1 claim, got %d: %+v", len(claims), claims)
+	}
+	if claims[0].Text != "You must run `make lint`." || claims[0].Strength != StrengthMust {
+		t.Fatalf("unexpected claim: %+v", claims[0])
+	}
+}
+
+func TestExtractClaimsPlainText(t *testing.T) {
+	content := "Intro line.\n- You **must** keep `__init__.py` files.\n> 1. Each file _should_ be UTF-8."
+	doc := NormalizedDocument{Sections: []Section{{ID: "rules", Content: content}}}
+	claims := ExtractClaims(doc, Source{ID: "docs", URL: "https://example.com"}, "sha256:abc")
+	if len(claims) != 2 {
+		t.Fatalf("expected 2 claims, got %d", len(claims))
+	}
+	if claims[0].Text != "You must keep `__init__.py` files." || claims[1].Text != "Each file should be UTF-8." {
+		t.Fatalf("expected plain claim text, got %q and %q", claims[0].Text, claims[1].Text)
+	}
+	span := claims[1].Proof.Spans[0]
+	if content[span.Start:span.End] != "> 1. Each file _should_ be UTF-8." {
+		t.Fatalf("expected proof span on original line, got %q", content[span.Start:span.End])
+	}
+}
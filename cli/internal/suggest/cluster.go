@@ -0,0 +1,300 @@
+package suggest
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// clusterThreshold is the minimum TF-IDF cosine similarity for two claims of
+// one client to state the same guidance.
+const clusterThreshold = 0.5
+
+// clusterClaims groups claims of the same client that state the same
+// guidance. A claim joins the first cluster whose every member it is similar
+// to (complete linkage), so near misses cannot chain unrelated claims
+// together. Two claims are similar when the cosine of their TF-IDF vectors
+// over word unigrams and bigrams reaches clusterThreshold and they do not each
+// carry a rare word the other lacks ("go test" versus "go vet"). Normative and
+// negation words are dropped so "must use pnpm" and "must not use pnpm" land
+// in the same cluster. Claims without a client are never clustered. Clusters
+// and their members follow input order.
+func clusterClaims(claims []Claim) [][]int {
+	return clusterClaimsBy(claims, func(claim Claim) string { return claim.Client })
+}
+
+// clusterClaimsBy is clusterClaims with claims compared only within the
+// scope returned for them; claims with an empty scope stay unclustered.
+func clusterClaimsBy(claims []Claim, scope func(Claim) string) [][]int {
+	byScope := make(map[string][]int)
+	var scopes []string
+	for i, claim := range claims {
+		key := scope(claim)
+		if key == "" {
+			continue
+		}
+		if _, ok := byScope[key]; !ok {
+			scopes = append(scopes, key)
+		}
+		byScope[key] = append(byScope[key], i)
+	}
+
+	// leader maps each claim to the first claim of its cluster.
+	leader := make([]int, len(claims))
+	for i := range leader {
+		leader[i] = i
+	}
+	for _, key := range scopes {
+		members := byScope[key]
+		texts := make([]string, len(members))
+		for i, idx := range members {
+			texts[i] = claims[idx].Text
+		}
+		corpus := newClusterCorpus(texts)
+		var groups [][]int
+		for i := range members {
+			placed := false
+			for g, group := range groups {
+				if corpus.similarToAll(i, group) {
+					groups[g] = append(group, i)
+					placed = true
+					break
+				}
+			}
+			if !placed {
+				groups = append(groups, []int{i})
+			}
+		}
+		for _, group := range groups {
+			for _, i := range group {
+				leader[members[i]] = members[group[0]]
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	var leaders []int
+	for i := range claims {
+		first := leader[i]
+		if _, ok := groups[first]; !ok {
+			leaders = append(leaders, first)
+		}
+		groups[first] = append(groups[first], i)
+	}
+	clusters := make([][]int, 0, len(leaders))
+	for _, first := range leaders {
+		clusters = append(clusters, groups[first])
+	}
+	return clusters
+}
+
+// clusterTerms returns the word unigrams and bigrams of text after dropping
+// stopwords, normative and negation words, and folding plurals.
+func clusterTerms(text string) []string {
+	parts := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	var words []string
+	for _, part := range parts {
+		if len(part) < 2 {
+			continue
+		}
+		if _, drop := stopwords[part]; drop {
+			continue
+		}
+		if _, drop := negationTokens[part]; drop {
+			continue
+		}
+		words = append(words, foldSuffix(part))
+	}
+	terms := append([]string(nil), words...)
+	for i := 1; i < len(words); i++ {
+		terms = append(terms, words[i-1]+" "+words[i])
+	}
+	return terms
+}
+
+// clusterCorpus holds the TF-IDF vectors of one scope's claim texts.
+type clusterCorpus struct {
+	vectors []map[string]float64
+	idf     map[string]float64
+}
+
+// newClusterCorpus weights the terms of each text by term frequency and
+// smoothed inverse document frequency across texts.
+func newClusterCorpus(texts []string) clusterCorpus {
+	counts := make([]map[string]float64, len(texts))
+	docFreq := make(map[string]int)
+	for i, text := range texts {
+		counts[i] = make(map[string]float64)
+		for _, term := range clusterTerms(text) {
+			if counts[i][term] == 0 {
+				docFreq[term]++
+			}
+			counts[i][term]++
+		}
+	}
+	n := float64(len(texts))
+	idf := make(map[string]float64, len(docFreq))
+	for term, df := range docFreq {
+		idf[term] = math.Log((1+n)/(1+float64(df))) + 1
+	}
+	for _, vector := range counts {
+		for term, tf := range vector {
+			vector[term] = tf * idf[term]
+		}
+	}
+	return clusterCorpus{vectors: counts, idf: idf}
+}
+
+func (c clusterCorpus) similarToAll(i int, group []int) bool {
+	for _, j := range group {
+		if !c.similar(i, j) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c clusterCorpus) similar(i, j int) bool {
+	a, b := c.vectors[i], c.vectors[j]
+	if cosineSimilarity(a, b) < clusterThreshold {
+		return false
+	}
+	// Overlapping wording is not enough when each claim names something the
+	// other does not, such as a different command.
+	shared := 0.0
+	for term := range a {
+		if _, ok := b[term]; ok && !strings.Contains(term, " ") {
+			shared = math.Max(shared, c.idf[term])
+		}
+	}
+	return !c.hasRareTerm(a, b, shared) || !c.hasRareTerm(b, a, shared)
+}
+
+// hasRareTerm reports whether vector has a unigram missing from other that
+// is at least as rare as the rarest unigram they share.
+func (c clusterCorpus) hasRareTerm(vector, other map[string]float64, shared float64) bool {
+	for term := range vector {
+		if _, ok := other[term]; ok || strings.Contains(term, " ") {
+			continue
+		}
+		if c.idf[term] >= shared {
+			return true
+		}
+	}
+	return false
+}
+
+func cosineSimilarity(a, b map[string]float64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for term, weight := range a {
+		normA += weight * weight
+		dot += weight * b[term]
+	}
+	for _, weight := range b {
+		normB += weight * weight
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// claimNegated reports whether a claim states a prohibition ("must not",
+// "never", "avoid").
+func claimNegated(claim Claim) bool {
+	return negationRE.MatchString(claim.Text)
+}
+
+// mergeClaimCluster folds equivalent claims into one suggestion. The
+// representative claim comes from the highest tier, then the strongest
+// level, then the lowest ID; the proof carries every member's sources,
+// snapshots and spans.
+func mergeClaimCluster(members []Claim, sources map[string]Source) Suggestion {
+	sorted := append([]Claim(nil), members...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := tierRank(sources[sorted[i].SourceID].Tier), tierRank(sources[sorted[j].SourceID].Tier)
+		if ti != tj {
+			return ti < tj
+		}
+		si, sj := strengthRank(claimStrength(sorted[i])), strengthRank(claimStrength(sorted[j]))
+		if si != sj {
+			return si < sj
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	rep := sorted[0]
+	proof := Proof{NormativeStrength: rep.Proof.NormativeStrength}
+	var merged, localSources []string
+	var web, local bool
+	for _, claim := range sorted {
+		if sources[claim.SourceID].Local() {
+			local = true
+			localSources = append(localSources, claim.SourceID)
+		} else {
+			web = true
+		}
+		proof.Sources = append(proof.Sources, claim.Proof.Sources...)
+		proof.SnapshotIDs = append(proof.SnapshotIDs, claim.Proof.SnapshotIDs...)
+		proof.Spans = append(proof.Spans, claim.Proof.Spans...)
+		if claim.ID != rep.ID {
+			merged = append(merged, claim.ID)
+		}
+	}
+	proof.Sources = uniqueSorted(proof.Sources)
+	proof.SnapshotIDs = uniqueSorted(proof.SnapshotIDs)
+	sort.Strings(merged)
+	provenance := ProvenanceWeb
+	switch {
+	case local && web:
+		provenance = ProvenanceMixed
+	case local:
+		provenance = ProvenanceLocal
+	}
+
+	return Suggestion{
+		ID:             suggestionID(rep),
+		ClaimID:        rep.ID,
+		MergedClaimIDs: merged,
+		Client:         rep.Client,
+		Text:           rep.Text,
+		Sources:        proof.Sources,
+		Provenance:     provenance,
+		LocalSources:   uniqueSorted(localSources),
+		Proof:          proof,
+	}
+}
+
+func claimStrength(claim Claim) NormativeStrength {
+	if claim.Proof.NormativeStrength != "" {
+		return claim.Proof.NormativeStrength
+	}
+	return claim.Strength
+}
+
+func strengthRank(strength NormativeStrength) int {
+	switch strength {
+	case StrengthMust:
+		return 0
+	case StrengthShould:
+		return 1
+	case StrengthMay:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func tierRank(tier string) int {
+	switch strings.ToLower(strings.TrimSpace(tier)) {
+	case "tier-0":
+		return 0
+	case "tier-1":
+		return 1
+	default:
+		return 2
+	}
+}
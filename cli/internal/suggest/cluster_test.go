@@ -0,0 +1,173 @@
+package suggest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClusterClaimsGroupsParaphrases(t *testing.T) {
+	claims := []Claim{
+		{ID: "c1", Client: "codex", Text: "Keep AGENTS.md concise."},
+		{ID: "c2", Client: "codex", Text: "You should keep your AGENTS.md files concise."},
+		{ID: "c3", Client: "codex", Text: "Run tests before committing."},
+		{ID: "c4", Client: "codex", Text: "Run linters before committing."},
+		{ID: "c5", Client: "claude", Text: "Keep AGENTS.md concise."},
+		{ID: "c6", Text: "Keep AGENTS.md concise."},
+	}
+	clusters := clusterClaims(claims)
+	expected := [][]int{{0, 1}, {2}, {3}, {4}, {5}}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Fatalf("unexpected clusters: %v", clusters)
+	}
+}
+
+func TestClusterClaimsKeepsNearMissesApart(t *testing.T) {
+	claims := []Claim{
+		{ID: "c1", Client: "codex", Text: "You must run go test before committing changes."},
+		{ID: "c2", Client: "codex", Text: "You must run go vet before committing."},
+		{ID: "c3", Client: "codex", Text: "Never run go test before committing."},
+		// Unrelated claims raise the weight of the shared words, as in a
+		// real source, so c3 scores above clusterThreshold against c2.
+		{ID: "c4", Client: "codex", Text: "Keep AGENTS.md concise."},
+		{ID: "c5", Client: "codex", Text: "Document the build commands."},
+		{ID: "c6", Client: "codex", Text: "Never commit secrets."},
+	}
+	clusters := clusterClaims(claims)
+	expected := [][]int{{0, 2}, {1}, {3}, {4}, {5}}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Fatalf("unexpected clusters: %v", clusters)
+	}
+
+	_, conflicts := DetectConflicts(claims)
+	if len(conflicts) != 1 || !reflect.DeepEqual(conflicts[0].ClaimIDs, []string{"c1", "c3"}) {
+		t.Fatalf("expected only the go test claims to conflict, got %+v", conflicts)
+	}
+
+	// Directly similar wording still differs in the command it names.
+	pair := []Claim{
+		{ID: "c1", Client: "codex", Text: "You must run go test before committing changes."},
+		{ID: "c2", Client: "codex", Text: "You must run go vet before committing."},
+		{ID: "c3", Client: "codex", Text: "Keep AGENTS.md concise."},
+		{ID: "c4", Client: "codex", Text: "Document the build commands."},
+		{ID: "c5", Client: "codex", Text: "Never commit secrets."},
+		{ID: "c6", Client: "codex", Text: "Use pnpm for installs."},
+		{ID: "c7", Client: "codex", Text: "Prefer small pull requests."},
+	}
+	if clusters := clusterClaims(pair); len(clusters) != len(pair) {
+		t.Fatalf("expected go test and go vet claims to stay apart, got %v", clusters)
+	}
+}
+
+func TestClusterTermsDropModalsAndNegation(t *testing.T) {
+	expected := []string{"commit", "secret", "commit secret"}
+	for _, text := range []string{"You MUST NOT commit secrets.", "Never commit a secret", "should commit secrets"} {
+		if terms := clusterTerms(text); !reflect.DeepEqual(terms, expected) {
+			t.Fatalf("unexpected terms for %q: %v", text, terms)
+		}
+	}
+}
+
+func TestGenerateSuggestionsMergesEquivalentClaims(t *testing.T) {
+	sources := map[string]Source{
+		"s0": {ID: "s0", Tier: "tier-0", Client: "codex", URL: "https://tier0.example"},
+		"s1": {ID: "s1", Tier: "tier-1", Client: "codex", URL: "https://tier1.example"},
+	}
+	claim := func(id, sourceID, text string, strength NormativeStrength) Claim {
+		url := sources[sourceID].URL
+		return Claim{
+			ID:       id,
+			SourceID: sourceID,
+			Client:   "codex",
+			Text:     text,
+			Strength: strength,
+			Proof: Proof{
+				Sources:           []string{url},
+				SnapshotIDs:       []string{"sha256:" + id},
+				Spans:             []ProofSpan{{SectionID: id, Start: 0, End: len(text)}},
+				NormativeStrength: strength,
+			},
+		}
+	}
+	claims := []Claim{
+		claim("c1", "s1", "You MUST keep AGENTS.md concise.", StrengthMust),
+		claim("c2", "s0", "You should keep your AGENTS.md files concise.", StrengthShould),
+		claim("c3", "s1", "You should keep AGENTS.md concise.", StrengthShould),
+		claim("c4", "s0", "Document the build commands.", StrengthShould),
+	}
+
+	report := GenerateSuggestions(claims, sources)
+	if len(report.Conflicts) != 0 || len(report.Omissions) != 0 {
+		t.Fatalf("expected no conflicts or omissions, got %+v %+v", report.Conflicts, report.Omissions)
+	}
+	if len(report.Suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions, got %d", len(report.Suggestions))
+	}
+
+	var merged Suggestion
+	for _, suggestion := range report.Suggestions {
+		if suggestion.ClaimID == "c2" {
+			merged = suggestion
+		}
+	}
+	if merged.ID != suggestionID(claims[1]) {
+		t.Fatalf("expected tier-0 claim c2 to represent the cluster, got %+v", report.Suggestions)
+	}
+	if !reflect.DeepEqual(merged.MergedClaimIDs, []string{"c1", "c3"}) {
+		t.Fatalf("unexpected merged claims: %v", merged.MergedClaimIDs)
+	}
+	if !reflect.DeepEqual(merged.Sources, []string{"https://tier0.example", "https://tier1.example"}) {
+		t.Fatalf("unexpected sources: %v", merged.Sources)
+	}
+	if len(merged.Proof.SnapshotIDs) != 3 || len(merged.Proof.Spans) != 3 {
+		t.Fatalf("expected every proof to be carried, got %+v", merged.Proof)
+	}
+	if merged.Proof.Spans[0].SectionID != "c2" {
+		t.Fatalf("expected representative span first, got %+v", merged.Proof.Spans)
+	}
+}
+
+func TestDetectConflictsRequiresOpposingPolarity(t *testing.T) {
+	claims := []Claim{
+		{ID: "c1", Client: "codex", Text: "You must commit lockfiles."},
+		{ID: "c2", Client: "codex", Text: "You should commit the lockfiles."},
+		{ID: "c3", Client: "codex", Text: "Never commit lockfiles."},
+		{ID: "c4", Client: "codex", Text: "Never commit secrets."},
+	}
+	updated, conflicts := DetectConflicts(claims)
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", conflicts)
+	}
+	for _, conflict := range conflicts {
+		if conflict.ClaimIDs[1] != "c3" || conflict.Reason != "opposing polarity in claim cluster" {
+			t.Fatalf("unexpected conflict: %+v", conflict)
+		}
+		if !reflect.DeepEqual(conflict.Tokens, []string{"commit", "lockfile"}) {
+			t.Fatalf("unexpected conflict tokens: %v", conflict.Tokens)
+		}
+	}
+	if len(updated[2].Proof.ConflictsWith) != 2 || len(updated[3].Proof.ConflictsWith) != 0 {
+		t.Fatalf("unexpected conflict annotations: %+v", updated)
+	}
+}
+
+func TestDetectCrossClientConflicts(t *testing.T) {
+	claims := []Claim{
+		{ID: "c1", Client: "codex", Text: "You must commit lockfiles."},
+		{ID: "c2", Client: "codex", Text: "Never commit lockfiles."},
+		{ID: "c3", Client: "claude", Text: "Never commit the lockfiles."},
+		{ID: "c4", Client: "gemini", Text: "You should commit lockfiles."},
+		{ID: "c5", Client: "claude", Text: "Never commit secrets."},
+	}
+	conflicts := DetectCrossClientConflicts(claims)
+	pairs := make(map[string]bool)
+	for _, conflict := range conflicts {
+		if conflict.Client != "" || len(conflict.Clients) != 2 || conflict.Reason != "opposing polarity across clients" {
+			t.Fatalf("unexpected conflict: %+v", conflict)
+		}
+		pairs[conflict.ClaimIDs[0]+"/"+conflict.ClaimIDs[1]] = true
+	}
+	expected := map[string]bool{"c3/c1": true, "c2/c4": true, "c3/c4": true}
+	if !reflect.DeepEqual(pairs, expected) {
+		t.Fatalf("unexpected conflict pairs: %v", pairs)
+	}
+}
@@ -10,8 +10,10 @@ import (
 
 // Conflict captures overlapping claims that cannot be reconciled.
 type Conflict struct {
-	ID       string   `json:"id"`
-	Client   string   `json:"client"`
+	ID     string `json:"id"`
+	Client string `json:"client,omitempty"`
+	// Clients lists both sides of a cross-client conflict; Client is empty.
+	Clients  []string `json:"clients,omitempty"`
 	ClaimIDs []string `json:"claimIds"`
 	Reason   string   `json:"reason"`
 	Tokens   []string `json:"tokens,omitempty"`
@@ -27,52 +29,36 @@ var stopwords = map[string]struct{}{
 	"you": {}, "your": {},
 }
 
-// DetectConflicts compares claims within the same client scope and annotates conflicts.
+// DetectConflicts clusters claims within the same client scope and
+// annotates conflicts between claims of one cluster with opposing polarity
+// ("must" versus "must not"). Claims that merely share words, or that state
+// the same guidance at different levels, do not conflict.
 func DetectConflicts(input []Claim) ([]Claim, []Conflict) {
 	claims := append([]Claim(nil), input...)
-	tokenSets := make([]map[string]struct{}, len(claims))
-	for i, claim := range claims {
-		tokenSets[i] = tokenSet(claimTokens(claim.Text))
-	}
-
-	seen := make(map[string]struct{})
 	var conflicts []Conflict
 
-	for i := 0; i < len(claims); i++ {
-		for j := i + 1; j < len(claims); j++ {
-			if claims[i].Client == "" || claims[j].Client == "" {
-				continue
-			}
-			if claims[i].Client != claims[j].Client {
-				continue
-			}
-			if claims[i].Text == claims[j].Text && claims[i].Strength == claims[j].Strength {
-				continue
-			}
-			intersection := intersectTokens(tokenSets[i], tokenSets[j])
-			if len(intersection) == 0 {
-				continue
-			}
-
-			claimIDs := []string{claims[i].ID, claims[j].ID}
-			sort.Strings(claimIDs)
-			key := claims[i].Client + "|" + strings.Join(claimIDs, "|")
-			if _, exists := seen[key]; exists {
-				continue
+	for _, cluster := range clusterClaims(claims) {
+		for a := 0; a < len(cluster); a++ {
+			for b := a + 1; b < len(cluster); b++ {
+				i, j := cluster[a], cluster[b]
+				if claimNegated(claims[i]) == claimNegated(claims[j]) {
+					continue
+				}
+
+				claimIDs := []string{claims[i].ID, claims[j].ID}
+				sort.Strings(claimIDs)
+				conflictID := newConflictID(claims[i].Client, claimIDs)
+				conflicts = append(conflicts, Conflict{
+					ID:       conflictID,
+					Client:   claims[i].Client,
+					ClaimIDs: claimIDs,
+					Reason:   "opposing polarity in claim cluster",
+					Tokens:   intersectTokens(tokenSet(gapTokens(claims[i].Text)), tokenSet(gapTokens(claims[j].Text))),
+				})
+
+				claims[i].Proof.ConflictsWith = appendUnique(claims[i].Proof.ConflictsWith, conflictID)
+				claims[j].Proof.ConflictsWith = appendUnique(claims[j].Proof.ConflictsWith, conflictID)
 			}
-			seen[key] = struct{}{}
-
-			conflictID := newConflictID(claims[i].Client, claimIDs)
-			conflicts = append(conflicts, Conflict{
-				ID:       conflictID,
-				Client:   claims[i].Client,
-				ClaimIDs: claimIDs,
-				Reason:   "overlapping tokens in same client scope",
-				Tokens:   intersection,
-			})
-
-			claims[i].Proof.ConflictsWith = appendUnique(claims[i].Proof.ConflictsWith, conflictID)
-			claims[j].Proof.ConflictsWith = appendUnique(claims[j].Proof.ConflictsWith, conflictID)
 		}
 	}
 
@@ -83,6 +69,47 @@ func DetectConflicts(input []Claim) ([]Claim, []Conflict) {
 	return claims, conflicts
 }
 
+// DetectCrossClientConflicts clusters claims across clients and reports
+// pairs from different clients with opposing polarity, such as one client's
+// docs requiring what another's forbid. Conflicts within one client are left
+// to DetectConflicts, and claims are not annotated: each client's guidance
+// stands on its own, so these conflicts cause no omissions.
+func DetectCrossClientConflicts(claims []Claim) []Conflict {
+	var conflicts []Conflict
+	for _, cluster := range clusterClaimsBy(claims, func(claim Claim) string {
+		if claim.Client == "" {
+			return ""
+		}
+		return "all"
+	}) {
+		for a := 0; a < len(cluster); a++ {
+			for b := a + 1; b < len(cluster); b++ {
+				left, right := claims[cluster[a]], claims[cluster[b]]
+				if left.Client == right.Client || claimNegated(left) == claimNegated(right) {
+					continue
+				}
+				if left.Client > right.Client {
+					left, right = right, left
+				}
+				clients := []string{left.Client, right.Client}
+				claimIDs := []string{left.ID, right.ID}
+				conflicts = append(conflicts, Conflict{
+					ID:       newConflictID(strings.Join(clients, "+"), claimIDs),
+					Clients:  clients,
+					ClaimIDs: claimIDs,
+					Reason:   "opposing polarity across clients",
+					Tokens:   intersectTokens(tokenSet(gapTokens(left.Text)), tokenSet(gapTokens(right.Text))),
+				})
+			}
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].ID < conflicts[j].ID
+	})
+	return conflicts
+}
+
 func claimTokens(text string) []string {
 	lower := strings.ToLower(text)
 	parts := strings.FieldsFunc(lower, func(r rune) bool {
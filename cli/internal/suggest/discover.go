@@ -0,0 +1,262 @@
+package suggest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultDiscoverTier         = "tier-1"
+	defaultDiscoverRefreshHours = 24
+	defaultMaxSitemaps          = 20
+)
+
+// DiscoverOptions configures sitemap-driven source discovery.
+type DiscoverOptions struct {
+	// Host is an allowlisted host, optionally with a port.
+	Host string
+	// Client restricts discovery to one client's rules; empty uses all rules
+	// for the host.
+	Client      string
+	HTTPClient  *http.Client
+	UserAgent   string
+	MaxSitemaps int
+}
+
+// DiscoverResult lists proposed sources for review.
+type DiscoverResult struct {
+	Host     string           `json:"host"`
+	Sitemaps []string         `json:"sitemaps"`
+	Proposed []Source         `json:"proposed"`
+	Rejected []RejectedSource `json:"rejected,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// RejectedSource is a candidate page that was not proposed.
+type RejectedSource struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// Discover reads robots.txt and the sitemaps it lists (or /sitemap.xml) on
+// an allowlisted host and proposes a Source for each page under a
+// configured path prefix that the registry does not list yet. Every
+// proposal passes ValidateSources together with the existing registry.
+func Discover(ctx context.Context, reg SourceRegistry, opts DiscoverOptions) (DiscoverResult, error) {
+	base, err := url.Parse("https://" + strings.TrimSpace(opts.Host))
+	if err != nil || base.Hostname() == "" || base.Path != "" {
+		return DiscoverResult{}, fmt.Errorf("invalid host: %s", opts.Host)
+	}
+	hostname := strings.ToLower(base.Hostname())
+	allowlisted := false
+	for _, host := range reg.AllowlistHosts {
+		if strings.EqualFold(strings.TrimSpace(host), hostname) {
+			allowlisted = true
+			break
+		}
+	}
+	if !allowlisted {
+		return DiscoverResult{}, fmt.Errorf("%w: %s", ErrHostNotAllowlisted, hostname)
+	}
+
+	var rules []DiscoveryRule
+	for _, rule := range reg.Discovery {
+		if !strings.EqualFold(rule.Host, hostname) {
+			continue
+		}
+		if opts.Client != "" && !strings.EqualFold(rule.Client, opts.Client) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return DiscoverResult{}, fmt.Errorf("no discovery rules for host %s", hostname)
+	}
+
+	fetcher, err := NewFetcher(FetcherOptions{Client: opts.HTTPClient, UserAgent: opts.UserAgent, Allowlist: reg.AllowlistHosts})
+	if err != nil {
+		return DiscoverResult{}, err
+	}
+	result := DiscoverResult{Host: base.Host, Sitemaps: []string{}, Proposed: []Source{}}
+
+	robots, info := fetcher.robotsRules(ctx, base)
+	result.Warnings = append(result.Warnings, info.Warnings...)
+	queue := info.Sitemaps
+	if len(queue) == 0 {
+		queue = []string{base.String() + "/sitemap.xml"}
+	}
+
+	maxSitemaps := opts.MaxSitemaps
+	if maxSitemaps <= 0 {
+		maxSitemaps = defaultMaxSitemaps
+	}
+	seenSitemaps := make(map[string]struct{})
+	var pages []string
+	for len(queue) > 0 {
+		sitemapURL := queue[0]
+		queue = queue[1:]
+		if _, ok := seenSitemaps[sitemapURL]; ok {
+			continue
+		}
+		if len(seenSitemaps) >= maxSitemaps {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("stopped after %d sitemaps", maxSitemaps))
+			break
+		}
+		seenSitemaps[sitemapURL] = struct{}{}
+
+		body, err := fetchSitemap(ctx, fetcher, sitemapURL)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("sitemap %s: %v", sitemapURL, err))
+			continue
+		}
+		parsed, err := ParseSitemap(body)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("sitemap %s: parse failed: %v", sitemapURL, err))
+			continue
+		}
+		result.Sitemaps = append(result.Sitemaps, sitemapURL)
+		queue = append(queue, parsed.Sitemaps...)
+		pages = append(pages, parsed.URLs...)
+	}
+
+	known := make(map[string]struct{}, len(reg.Sources))
+	ids := make(map[string]struct{}, len(reg.Sources))
+	for _, src := range reg.Sources {
+		known[canonicalSourceURL(src.URL)] = struct{}{}
+		ids[src.ID] = struct{}{}
+	}
+
+	sort.Strings(pages)
+	working := reg
+	working.Sources = append([]Source(nil), reg.Sources...)
+	for _, page := range pages {
+		key := canonicalSourceURL(page)
+		if _, ok := known[key]; ok {
+			continue
+		}
+		known[key] = struct{}{}
+
+		parsed, err := url.Parse(page)
+		if err != nil {
+			result.Rejected = append(result.Rejected, RejectedSource{URL: page, Reason: "invalid url"})
+			continue
+		}
+		if !strings.EqualFold(parsed.Host, base.Host) {
+			continue
+		}
+		rule, ok := matchDiscoveryRule(rules, parsed.Path)
+		if !ok {
+			continue
+		}
+		if !robots.Allows(parsed.Path) {
+			result.Rejected = append(result.Rejected, RejectedSource{URL: page, Reason: "robots disallow"})
+			continue
+		}
+
+		source := Source{
+			ID:           uniqueSourceID(ids, rule.Client, parsed.Path),
+			Tier:         rule.Tier,
+			Client:       rule.Client,
+			URL:          page,
+			RefreshHours: rule.RefreshHours,
+		}
+		if source.Tier == "" {
+			source.Tier = defaultDiscoverTier
+		}
+		if source.RefreshHours == 0 {
+			source.RefreshHours = defaultDiscoverRefreshHours
+		}
+
+		candidate := working
+		candidate.Sources = append(append([]Source(nil), working.Sources...), source)
+		if err := ValidateSources(candidate); err != nil {
+			result.Rejected = append(result.Rejected, RejectedSource{URL: page, Reason: err.Error()})
+			continue
+		}
+		ids[source.ID] = struct{}{}
+		working.Sources = candidate.Sources
+		result.Proposed = append(result.Proposed, source)
+	}
+	return result, nil
+}
+
+func fetchSitemap(ctx context.Context, fetcher *Fetcher, sitemapURL string) ([]byte, error) {
+	res, err := fetcher.Fetch(ctx, FetchSource{URL: sitemapURL})
+	if err != nil {
+		return nil, err
+	}
+	if res.Skipped {
+		return nil, fmt.Errorf("skipped: %s", res.SkipReason)
+	}
+	if res.Status != http.StatusOK {
+		return nil, fmt.Errorf("status %d", res.Status)
+	}
+	body := res.Body
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+		return io.ReadAll(reader)
+	}
+	return body, nil
+}
+
+// matchDiscoveryRule returns the rule with the longest matching prefix.
+func matchDiscoveryRule(rules []DiscoveryRule, path string) (DiscoveryRule, bool) {
+	var best DiscoveryRule
+	bestLen := -1
+	for _, rule := range rules {
+		for _, prefix := range rule.PathPrefixes {
+			if strings.HasPrefix(path, prefix) && len(prefix) > bestLen {
+				best = rule
+				bestLen = len(prefix)
+			}
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// canonicalSourceURL ignores a trailing slash and fragment so listed sources
+// are not proposed again under a slightly different URL.
+func canonicalSourceURL(raw string) string {
+	if idx := strings.Index(raw, "#"); idx >= 0 {
+		raw = raw[:idx]
+	}
+	return strings.TrimRight(strings.ToLower(raw), "/")
+}
+
+var sourceIDInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// uniqueSourceID derives "<client>-<path slug>" and appends a counter on
+// collision.
+func uniqueSourceID(ids map[string]struct{}, client, path string) string {
+	slug := strings.Trim(sourceIDInvalid.ReplaceAllString(strings.ToLower(path), "-"), "-")
+	client = strings.ToLower(client)
+	id := client
+	switch {
+	case slug == "":
+	case slug == client || strings.HasPrefix(slug, client+"-"):
+		id = slug
+	default:
+		id = client + "-" + slug
+	}
+	candidate := id
+	for n := 2; ; n++ {
+		if _, ok := ids[candidate]; !ok {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d", id, n)
+	}
+}
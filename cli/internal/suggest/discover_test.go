@@ -0,0 +1,168 @@
+package suggest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newDiscoveryServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	base := server.URL
+
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, "User-agent: *\nDisallow: /docs/codex/private/\nSitemap: %s/sitemap-index.xml\n", base)
+	})
+	mux.HandleFunc("/sitemap-index.xml", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0"?><sitemapindex><sitemap><loc>%[1]s/sitemap-docs.xml.gz</loc></sitemap><sitemap><loc>%[1]s/sitemap-blog.xml</loc></sitemap><sitemap><loc>https://elsewhere.example/sitemap.xml</loc></sitemap></sitemapindex>`, base)
+	})
+	mux.HandleFunc("/sitemap-docs.xml.gz", func(w http.ResponseWriter, _ *http.Request) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		fmt.Fprintf(zw, `<urlset>
+<url><loc>%[1]s/docs/codex/agents/</loc></url>
+<url><loc>%[1]s/docs/codex/known/</loc></url>
+<url><loc>%[1]s/docs/codex/private/secret</loc></url>
+<url><loc>%[1]s/docs/claude/memory</loc></url>
+<url><loc>%[1]s/docs/other/page</loc></url>
+</urlset>`, base)
+		_ = zw.Close()
+		_, _ = w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/sitemap-blog.xml", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `<urlset><url><loc>%s/blog/post</loc></url><url><loc>https://elsewhere.example/docs/codex/x</loc></url></urlset>`, base)
+	})
+	return server
+}
+
+func discoveryRegistry(t *testing.T, server *httptest.Server) (SourceRegistry, string) {
+	t.Helper()
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse server url: %v", err)
+	}
+	return SourceRegistry{
+		Version:        "1.0",
+		AllowlistHosts: []string{parsed.Hostname()},
+		Sources: []Source{
+			{ID: "codex-known", Tier: "tier-0", Client: "codex", URL: server.URL + "/docs/codex/known", RefreshHours: 24},
+		},
+		Discovery: []DiscoveryRule{
+			{Client: "codex", Host: parsed.Hostname(), PathPrefixes: []string{"/docs/codex/"}, Tier: "tier-0", RefreshHours: 12},
+			{Client: "claude", Host: parsed.Hostname(), PathPrefixes: []string{"/docs/claude/"}},
+		},
+	}, parsed.Host
+}
+
+func TestDiscover(t *testing.T) {
+	server := newDiscoveryServer(t)
+	reg, host := discoveryRegistry(t, server)
+
+	result, err := Discover(context.Background(), reg, DiscoverOptions{Host: host, HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(result.Sitemaps) != 3 {
+		t.Fatalf("expected index and two sitemaps, got %v", result.Sitemaps)
+	}
+	if len(result.Proposed) != 2 {
+		t.Fatalf("expected 2 proposals, got %+v", result.Proposed)
+	}
+	claude, codex := result.Proposed[0], result.Proposed[1]
+	if codex.ID != "codex-docs-codex-agents" || codex.Tier != "tier-0" || codex.RefreshHours != 12 || codex.URL != server.URL+"/docs/codex/agents/" {
+		t.Fatalf("unexpected codex proposal: %+v", codex)
+	}
+	if claude.ID != "claude-docs-claude-memory" || claude.Tier != "tier-1" || claude.RefreshHours != 24 {
+		t.Fatalf("unexpected claude proposal with defaults: %+v", claude)
+	}
+	if len(result.Rejected) != 1 || result.Rejected[0].Reason != "robots disallow" {
+		t.Fatalf("expected robots rejection, got %+v", result.Rejected)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "elsewhere.example") {
+		t.Fatalf("expected non-allowlisted sitemap warning, got %v", result.Warnings)
+	}
+
+	combined := reg
+	combined.Sources = append(append([]Source(nil), reg.Sources...), result.Proposed...)
+	if err := ValidateSources(combined); err != nil {
+		t.Fatalf("proposals should validate with the registry: %v", err)
+	}
+
+	filtered, err := Discover(context.Background(), reg, DiscoverOptions{Host: host, Client: "claude", HTTPClient: server.Client(), MaxSitemaps: 1})
+	if err != nil {
+		t.Fatalf("discover claude: %v", err)
+	}
+	if len(filtered.Proposed) != 0 || len(filtered.Warnings) != 1 || !strings.Contains(filtered.Warnings[0], "stopped after 1 sitemaps") {
+		t.Fatalf("expected the sitemap limit to stop discovery, got %+v", filtered)
+	}
+}
+
+func TestDiscoverErrors(t *testing.T) {
+	server := newDiscoveryServer(t)
+	reg, host := discoveryRegistry(t, server)
+
+	if _, err := Discover(context.Background(), reg, DiscoverOptions{Host: "other.example"}); err == nil {
+		t.Fatalf("expected non-allowlisted host error")
+	}
+	if _, err := Discover(context.Background(), reg, DiscoverOptions{Host: host, Client: "gemini"}); err == nil {
+		t.Fatalf("expected missing rules error")
+	}
+	if _, err := Discover(context.Background(), reg, DiscoverOptions{Host: host + "/docs"}); err == nil {
+		t.Fatalf("expected invalid host error")
+	}
+}
+
+func TestUniqueSourceID(t *testing.T) {
+	ids := map[string]struct{}{"codex-guides-agents-md": {}}
+	if id := uniqueSourceID(ids, "codex", "/codex/guides/agents-md/"); id != "codex-guides-agents-md-2" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+	if id := uniqueSourceID(ids, "claude", "/en/docs/Memory.html"); id != "claude-en-docs-memory-html" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+	if id := uniqueSourceID(ids, "gemini", "/"); id != "gemini" {
+		t.Fatalf("unexpected id: %s", id)
+	}
+}
+
+func TestValidateSourcesDiscovery(t *testing.T) {
+	valid := DiscoveryRule{Client: "codex", Host: "example.com", PathPrefixes: []string{"/docs/"}}
+	cases := map[string]func(rule *DiscoveryRule){
+		"missing client":     func(rule *DiscoveryRule) { rule.Client = "" },
+		"host not allowlist": func(rule *DiscoveryRule) { rule.Host = "other.com" },
+		"missing prefixes":   func(rule *DiscoveryRule) { rule.PathPrefixes = nil },
+		"relative prefix":    func(rule *DiscoveryRule) { rule.PathPrefixes = []string{"docs/"} },
+		"invalid tier":       func(rule *DiscoveryRule) { rule.Tier = "tier-9" },
+		"negative refresh":   func(rule *DiscoveryRule) { rule.RefreshHours = -1 },
+	}
+	newRegistry := func(rule DiscoveryRule) SourceRegistry {
+		return SourceRegistry{
+			Version:        "1.0",
+			AllowlistHosts: []string{"example.com"},
+			Sources:        []Source{{ID: "example", Tier: "tier-0", Client: "codex", URL: "https://example.com/docs", RefreshHours: 24}},
+			Discovery:      []DiscoveryRule{rule},
+		}
+	}
+	if err := ValidateSources(newRegistry(valid)); err != nil {
+		t.Fatalf("expected valid discovery rule: %v", err)
+	}
+	for name, mod := range cases {
+		t.Run(name, func(t *testing.T) {
+			rule := valid
+			rule.PathPrefixes = append([]string(nil), valid.PathPrefixes...)
+			mod(&rule)
+			if err := ValidateSources(newRegistry(rule)); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -70,7 +71,9 @@ type Cache interface {
 	Get(url string) ([]byte, bool)
 }
 
-// Fetcher fetches sources with robots and conditional GET handling.
+// Fetcher fetches sources with robots and conditional GET handling. It is
+// safe for concurrent use: robots.txt is read once per host and metadata
+// store access is serialized.
 type Fetcher struct {
 	client    *http.Client
 	bridge    FetchBridge
@@ -79,7 +82,17 @@ type Fetcher struct {
 	store     MetadataWriter
 	cache     Cache
 	now       func() time.Time
-	robots    map[string]RobotsRules
+	storeMu   sync.Mutex
+	robotsMu  sync.Mutex
+	robots    map[string]*robotsEntry
+}
+
+// robotsEntry caches the robots rules for one host. The first caller loads
+// them and receives the warnings and sitemaps; later callers wait on once.
+type robotsEntry struct {
+	once  sync.Once
+	rules RobotsRules
+	info  robotsInfo
 }
 
 // FetcherOptions configures a Fetcher.
@@ -129,7 +142,7 @@ func NewFetcher(opts FetcherOptions) (*Fetcher, error) {
 		store:     opts.Store,
 		cache:     opts.Cache,
 		now:       now,
-		robots:    map[string]RobotsRules{},
+		robots:    map[string]*robotsEntry{},
 	}, nil
 }
 
@@ -178,7 +191,10 @@ func (f *Fetcher) Fetch(ctx context.Context, source FetchSource) (FetchResult, e
 	req.Header.Set("User-Agent", f.userAgent)
 
 	if f.store != nil {
-		if rec, ok := f.store.Get(source.ID, source.URL); ok {
+		f.storeMu.Lock()
+		rec, ok := f.store.Get(source.ID, source.URL)
+		f.storeMu.Unlock()
+		if ok {
 			if rec.ETag != "" {
 				req.Header.Set("If-None-Match", rec.ETag)
 			}
@@ -233,6 +249,8 @@ func (f *Fetcher) updateMetadata(source FetchSource, result FetchResult) {
 		LastModified:   result.LastModified,
 		LastVerifiedAt: f.now().UnixMilli(),
 	}
+	f.storeMu.Lock()
+	defer f.storeMu.Unlock()
 	f.store.Put(record)
 	_ = f.store.Save()
 }
@@ -244,10 +262,26 @@ type robotsInfo struct {
 
 func (f *Fetcher) robotsRules(ctx context.Context, parsed *url.URL) (RobotsRules, robotsInfo) {
 	host := strings.ToLower(parsed.Hostname())
-	if rules, ok := f.robots[host]; ok {
-		return rules, robotsInfo{}
+	f.robotsMu.Lock()
+	entry, ok := f.robots[host]
+	if !ok {
+		entry = &robotsEntry{}
+		f.robots[host] = entry
 	}
+	f.robotsMu.Unlock()
+
+	loaded := false
+	entry.once.Do(func() {
+		entry.rules, entry.info = f.loadRobots(ctx, parsed)
+		loaded = true
+	})
+	if !loaded {
+		return entry.rules, robotsInfo{}
+	}
+	return entry.rules, entry.info
+}
 
+func (f *Fetcher) loadRobots(ctx context.Context, parsed *url.URL) (RobotsRules, robotsInfo) {
 	info := robotsInfo{}
 	robotsURL := &url.URL{Scheme: parsed.Scheme, Host: parsed.Host, Path: "/robots.txt"}
 
@@ -257,26 +291,20 @@ func (f *Fetcher) robotsRules(ctx context.Context, parsed *url.URL) (RobotsRules
 		data, err = f.bridge.Fetch(ctx, robotsURL.String())
 		if err != nil {
 			info.Warnings = append(info.Warnings, fmt.Sprintf("robots fetch failed via bridge: %v", err))
-			rules := RobotsRules{}
-			f.robots[host] = rules
-			return rules, info
+			return RobotsRules{}, info
 		}
 	} else {
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
 		if err != nil {
 			info.Warnings = append(info.Warnings, fmt.Sprintf("robots request build failed: %v", err))
-			rules := RobotsRules{}
-			f.robots[host] = rules
-			return rules, info
+			return RobotsRules{}, info
 		}
 		req.Header.Set("User-Agent", f.userAgent)
 
 		resp, err := f.client.Do(req)
 		if err != nil {
 			info.Warnings = append(info.Warnings, fmt.Sprintf("robots fetch failed: %v", err))
-			rules := RobotsRules{}
-			f.robots[host] = rules
-			return rules, info
+			return RobotsRules{}, info
 		}
 		defer func() {
 			_ = resp.Body.Close()
@@ -284,24 +312,19 @@ func (f *Fetcher) robotsRules(ctx context.Context, parsed *url.URL) (RobotsRules
 
 		if resp.StatusCode != http.StatusOK {
 			info.Warnings = append(info.Warnings, fmt.Sprintf("robots status %d", resp.StatusCode))
-			rules := RobotsRules{}
-			f.robots[host] = rules
-			return rules, info
+			return RobotsRules{}, info
 		}
 
 		data, err = io.ReadAll(resp.Body)
 		if err != nil {
 			info.Warnings = append(info.Warnings, fmt.Sprintf("robots read failed: %v", err))
-			rules := RobotsRules{}
-			f.robots[host] = rules
-			return rules, info
+			return RobotsRules{}, info
 		}
 	}
 
 	parsedRobots := ParseRobots(data)
 	rules := parsedRobots.RulesFor(f.userAgent)
 	info.Sitemaps = append([]string(nil), parsedRobots.Sitemaps...)
-	f.robots[host] = rules
 	return rules, info
 }
 
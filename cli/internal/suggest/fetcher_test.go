@@ -10,6 +10,8 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -246,3 +248,65 @@ func scaledTestTimeout(t *testing.T, base time.Duration) time.Duration { //nolin
 	}
 	return base
 }
+
+func TestFetcherConcurrentFetchReadsRobotsOnce(t *testing.T) {
+	var robotsHits atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsHits.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"`+r.URL.Path+`"`)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	client := server.Client()
+	client.Timeout = scaledTestTimeout(t, 5*time.Second)
+	parsed, _ := url.Parse(server.URL)
+	// memoryStore is not synchronized; the race detector flags any
+	// unserialized store access by the Fetcher.
+	store := &memoryStore{}
+	fetcher, err := NewFetcher(FetcherOptions{
+		Client:    client,
+		Allowlist: []string{parsed.Hostname()},
+		Store:     store,
+	})
+	if err != nil {
+		t.Fatalf("new fetcher: %v", err)
+	}
+
+	const fetches = 8
+	results := make([]FetchResult, fetches)
+	var wg sync.WaitGroup
+	for i := 0; i < fetches; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := "src-" + strconv.Itoa(i)
+			result, err := fetcher.Fetch(context.Background(), FetchSource{ID: id, URL: server.URL + "/docs/" + id})
+			if err != nil {
+				t.Errorf("fetch %s: %v", id, err)
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	if hits := robotsHits.Load(); hits != 1 {
+		t.Fatalf("expected robots.txt to be fetched once, got %d", hits)
+	}
+	warned := 0
+	for _, result := range results {
+		if len(result.Warnings) > 0 {
+			warned++
+		}
+	}
+	if warned != 1 {
+		t.Fatalf("expected robots warnings on one result, got %d", warned)
+	}
+	if len(store.records) != fetches || store.saves != fetches {
+		t.Fatalf("expected %d metadata records and saves, got %d and %d", fetches, len(store.records), store.saves)
+	}
+}
@@ -0,0 +1,220 @@
+package suggest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+
+	"markdowntown-cli/internal/instructions"
+
+	"github.com/spf13/afero"
+)
+
+// Coverage classifies how a repo's instructions cover a suggestion.
+type Coverage string
+
+const (
+	// CoverageCovered means an instruction line restates the claim.
+	CoverageCovered Coverage = "covered"
+	// CoveragePartial means an instruction line shares part of the claim.
+	CoveragePartial Coverage = "partial"
+	// CoverageMissing means no instruction line resembles the claim.
+	CoverageMissing Coverage = "missing"
+	// CoverageContradicted means the closest instruction line negates the
+	// claim or the claim negates it.
+	CoverageContradicted Coverage = "contradicted"
+)
+
+const (
+	coveredOverlap = 0.75
+	partialOverlap = 0.4
+)
+
+// InstructionText is the content of one instruction file in the chain.
+type InstructionText struct {
+	Path    string
+	Content string
+}
+
+// CoverageEvidence points at the instruction line closest to a claim.
+type CoverageEvidence struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+	// Overlap is the share of claim tokens found on the line.
+	Overlap float64 `json:"overlap"`
+}
+
+// GapSummary counts suggestions per coverage class.
+type GapSummary struct {
+	Files        []string `json:"files"`
+	Covered      int      `json:"covered"`
+	Partial      int      `json:"partial"`
+	Missing      int      `json:"missing"`
+	Contradicted int      `json:"contradicted"`
+}
+
+var negationRE = regexp.MustCompile(`(?i)\b(not|never|no|don't|do not|doesn't|cannot|can't|avoid|without)\b`)
+
+// negationTokens are left out of overlap scoring; polarity is compared
+// separately.
+var negationTokens = map[string]struct{}{
+	"avoid": {}, "cannot": {}, "doesn": {}, "don": {}, "never": {}, "without": {},
+}
+
+// LoadInstructionTexts reads the applied files of a resolution. Unreadable
+// files are reported as warnings.
+func LoadInstructionTexts(fs afero.Fs, resolution instructions.Resolution) ([]InstructionText, []string) {
+	var docs []InstructionText
+	var warnings []string
+	for _, file := range resolution.Applied {
+		var data []byte
+		var err error
+		if fs != nil {
+			data, err = afero.ReadFile(fs, file.Path)
+		} else {
+			// #nosec G304 -- paths come from the resolved instruction chain.
+			data, err = os.ReadFile(file.Path)
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("read %s failed: %v", file.Path, err))
+			continue
+		}
+		if file.Truncated && file.IncludedBytes < int64(len(data)) {
+			data = data[:file.IncludedBytes]
+		}
+		docs = append(docs, InstructionText{Path: file.Path, Content: string(data)})
+	}
+	return docs, warnings
+}
+
+// AnalyzeGaps classifies each suggestion against the instruction texts and
+// records the closest matching line as evidence.
+func AnalyzeGaps(suggestions []Suggestion, docs []InstructionText) ([]Suggestion, GapSummary) {
+	lines := instructionLines(docs)
+	summary := GapSummary{Files: []string{}}
+	for _, doc := range docs {
+		summary.Files = append(summary.Files, doc.Path)
+	}
+
+	out := append([]Suggestion(nil), suggestions...)
+	for i := range out {
+		coverage, evidence := classifyClaim(out[i].Text, lines)
+		out[i].Coverage = coverage
+		out[i].Evidence = evidence
+		switch coverage {
+		case CoverageCovered:
+			summary.Covered++
+		case CoveragePartial:
+			summary.Partial++
+		case CoverageContradicted:
+			summary.Contradicted++
+		default:
+			summary.Missing++
+		}
+	}
+	return out, summary
+}
+
+type instructionLine struct {
+	path   string
+	line   int
+	text   string
+	tokens map[string]struct{}
+}
+
+func instructionLines(docs []InstructionText) []instructionLine {
+	var lines []instructionLine
+	for _, doc := range docs {
+		scanner := bufio.NewScanner(bytes.NewReader([]byte(doc.Content)))
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			text := strings.TrimSpace(scanner.Text())
+			tokens := gapTokens(text)
+			if len(tokens) == 0 {
+				continue
+			}
+			lines = append(lines, instructionLine{path: doc.Path, line: lineNum, text: text, tokens: tokenSet(tokens)})
+		}
+	}
+	return lines
+}
+
+func classifyClaim(text string, lines []instructionLine) (Coverage, *CoverageEvidence) {
+	tokens := gapTokens(text)
+	if len(tokens) == 0 {
+		return CoverageMissing, nil
+	}
+
+	best := -1
+	bestShared := 0
+	for i, line := range lines {
+		shared := 0
+		for _, token := range tokens {
+			if _, ok := line.tokens[token]; ok {
+				shared++
+			}
+		}
+		if shared > bestShared {
+			best = i
+			bestShared = shared
+		}
+	}
+	if best < 0 {
+		return CoverageMissing, nil
+	}
+
+	overlap := float64(bestShared) / float64(len(tokens))
+	// A single shared word is not evidence for longer claims.
+	if overlap < partialOverlap || (bestShared < 2 && len(tokens) > 2) {
+		return CoverageMissing, nil
+	}
+	line := lines[best]
+	evidence := &CoverageEvidence{
+		Path:    line.path,
+		Line:    line.line,
+		Text:    line.text,
+		Overlap: math.Round(overlap*100) / 100,
+	}
+	if overlap < coveredOverlap {
+		return CoveragePartial, evidence
+	}
+	// Near-identical wording with opposite polarity reads as a contradiction.
+	if negationRE.MatchString(text) != negationRE.MatchString(line.text) {
+		return CoverageContradicted, evidence
+	}
+	return CoverageCovered, evidence
+}
+
+// gapTokens extends claimTokens with plural folding so "file" and "files"
+// match.
+func gapTokens(text string) []string {
+	raw := claimTokens(text)
+	seen := make(map[string]struct{}, len(raw))
+	tokens := make([]string, 0, len(raw))
+	for _, token := range raw {
+		if _, skip := negationTokens[token]; skip {
+			continue
+		}
+		token = foldSuffix(token)
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+func foldSuffix(token string) string {
+	if len(token) > 3 && strings.HasSuffix(token, "s") && !strings.HasSuffix(token, "ss") {
+		return token[:len(token)-1]
+	}
+	return token
+}
@@ -0,0 +1,73 @@
+package suggest
+
+import (
+	"testing"
+
+	"markdowntown-cli/internal/instructions"
+
+	"github.com/spf13/afero"
+)
+
+func TestAnalyzeGaps(t *testing.T) {
+	docs := []InstructionText{
+		{Path: "/repo/AGENTS.md", Content: "# Agents\n\nRun go test before committing changes.\nKeep PR descriptions brief.\nNever commit generated files.\n"},
+		{Path: "/repo/pkg/AGENTS.md", Content: "Use table-driven tests.\n"},
+	}
+	suggestions := []Suggestion{
+		{ID: "covered", Text: "You MUST run go test before committing changes."},
+		{ID: "partial", Text: "You SHOULD keep PR titles and descriptions under 72 characters."},
+		{ID: "missing", Text: "You MAY enable verbose logging for debugging."},
+		{ID: "contradicted", Text: "You SHOULD commit generated files."},
+		{ID: "plural", Text: "Tests SHOULD be table-driven."},
+	}
+
+	got, summary := AnalyzeGaps(suggestions, docs)
+	want := map[string]Coverage{
+		"covered":      CoverageCovered,
+		"partial":      CoveragePartial,
+		"missing":      CoverageMissing,
+		"contradicted": CoverageContradicted,
+		"plural":       CoverageCovered,
+	}
+	for _, suggestion := range got {
+		if suggestion.Coverage != want[suggestion.ID] {
+			t.Fatalf("%s: coverage = %s, want %s (evidence %+v)", suggestion.ID, suggestion.Coverage, want[suggestion.ID], suggestion.Evidence)
+		}
+	}
+	if got[0].Evidence == nil || got[0].Evidence.Path != "/repo/AGENTS.md" || got[0].Evidence.Line != 3 || got[0].Evidence.Overlap != 1 {
+		t.Fatalf("unexpected covered evidence: %+v", got[0].Evidence)
+	}
+	if got[3].Evidence == nil || got[3].Evidence.Line != 5 {
+		t.Fatalf("unexpected contradiction evidence: %+v", got[3].Evidence)
+	}
+	if got[2].Evidence != nil {
+		t.Fatalf("expected no evidence for missing claim, got %+v", got[2].Evidence)
+	}
+	if got[4].Evidence == nil || got[4].Evidence.Path != "/repo/pkg/AGENTS.md" {
+		t.Fatalf("unexpected plural evidence: %+v", got[4].Evidence)
+	}
+	if summary.Covered != 2 || summary.Partial != 1 || summary.Missing != 1 || summary.Contradicted != 1 || len(summary.Files) != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if suggestions[0].Coverage != "" {
+		t.Fatalf("expected input suggestions to be left untouched")
+	}
+}
+
+func TestLoadInstructionTexts(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/repo/AGENTS.md", []byte("abcdef"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resolution := instructions.Resolution{Applied: []instructions.InstructionFile{
+		{Path: "/repo/AGENTS.md", Truncated: true, IncludedBytes: 3},
+		{Path: "/repo/missing.md"},
+	}}
+	docs, warnings := LoadInstructionTexts(fs, resolution)
+	if len(docs) != 1 || docs[0].Content != "abc" {
+		t.Fatalf("unexpected docs: %+v", docs)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("expected read warning, got %v", warnings)
+	}
+}
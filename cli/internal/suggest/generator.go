@@ -1,28 +1,46 @@
 package suggest
 
 import (
-	"bytes"
-	"embed"
 	"sort"
 	"strings"
-	"text/template"
 
 	scanhash "markdowntown-cli/internal/hash"
+	"markdowntown-cli/internal/instructions"
 )
 
-//go:embed templates/codex.md
-var codexTemplateFS embed.FS
-
 // Suggestion represents a Codex-focused suggestion with evidence.
 type Suggestion struct {
-	ID      string   `json:"id"`
-	ClaimID string   `json:"claimId"`
-	Client  string   `json:"client"`
-	Text    string   `json:"text"`
-	Sources []string `json:"sources"`
-	Proof   Proof    `json:"proof"`
+	ID      string `json:"id"`
+	ClaimID string `json:"claimId"`
+	// MergedClaimIDs lists equivalent claims folded into this suggestion;
+	// their proofs are part of Proof.
+	MergedClaimIDs []string `json:"mergedClaimIds,omitempty"`
+	Client         string   `json:"client"`
+	Text           string   `json:"text"`
+	Sources        []string `json:"sources"`
+	// Provenance tells vendor documentation apart from local team docs.
+	Provenance ProvenanceType `json:"provenance,omitempty"`
+	// LocalSources lists the registry IDs of local sources behind the
+	// suggestion; patches cite them instead of machine-specific file URLs.
+	LocalSources []string `json:"localSources,omitempty"`
+	Proof        Proof    `json:"proof"`
+	// Coverage and Evidence are set by AnalyzeGaps.
+	Coverage Coverage          `json:"coverage,omitempty"`
+	Evidence *CoverageEvidence `json:"evidence,omitempty"`
 }
 
+// ProvenanceType classifies the sources behind a suggestion.
+type ProvenanceType string
+
+const (
+	// ProvenanceWeb marks suggestions backed only by fetched web sources.
+	ProvenanceWeb ProvenanceType = "web"
+	// ProvenanceLocal marks suggestions backed only by local file sources.
+	ProvenanceLocal ProvenanceType = "local"
+	// ProvenanceMixed marks suggestions backed by both.
+	ProvenanceMixed ProvenanceType = "mixed"
+)
+
 // Omission records why a claim was not suggested.
 type Omission struct {
 	ClaimID string `json:"claimId"`
@@ -37,10 +55,12 @@ type SuggestionReport struct {
 }
 
 // GenerateSuggestions builds Codex suggestions from validated claims.
+// Equivalent claims (see DetectConflicts) are merged into one suggestion
+// that carries every proof.
 func GenerateSuggestions(claims []Claim, sources map[string]Source) SuggestionReport {
 	updated, conflicts := DetectConflicts(claims)
 
-	var suggestions []Suggestion
+	var eligible []Claim
 	var omissions []Omission
 
 	for _, claim := range updated {
@@ -49,14 +69,24 @@ func GenerateSuggestions(claims []Claim, sources map[string]Source) SuggestionRe
 			omissions = append(omissions, Omission{ClaimID: claim.ID, Reason: reason})
 			continue
 		}
-		suggestions = append(suggestions, Suggestion{
-			ID:      suggestionID(claim),
-			ClaimID: claim.ID,
-			Client:  claim.Client,
-			Text:    claim.Text,
-			Sources: uniqueSorted(claim.Proof.Sources),
-			Proof:   claim.Proof,
-		})
+		eligible = append(eligible, claim)
+	}
+
+	var suggestions []Suggestion
+	for _, cluster := range clusterClaims(eligible) {
+		var required, prohibited []Claim
+		for _, idx := range cluster {
+			if claimNegated(eligible[idx]) {
+				prohibited = append(prohibited, eligible[idx])
+			} else {
+				required = append(required, eligible[idx])
+			}
+		}
+		for _, members := range [][]Claim{required, prohibited} {
+			if len(members) > 0 {
+				suggestions = append(suggestions, mergeClaimCluster(members, sources))
+			}
+		}
 	}
 
 	sort.Slice(suggestions, func(i, j int) bool {
@@ -76,21 +106,12 @@ func GenerateSuggestions(claims []Claim, sources map[string]Source) SuggestionRe
 
 // RenderCodexSuggestions applies the Codex markdown template to a report.
 func RenderCodexSuggestions(report SuggestionReport) (string, error) {
-	data, err := codexTemplateFS.ReadFile("templates/codex.md")
-	if err != nil {
-		return "", err
-	}
-	tmpl, err := template.New("codex").Funcs(template.FuncMap{
-		"join": strings.Join,
-	}).Parse(string(data))
-	if err != nil {
-		return "", err
-	}
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, report); err != nil {
-		return "", err
-	}
-	return buf.String(), nil
+	return RenderSuggestions(instructions.ClientCodex, TemplateData{
+		Client:      string(instructions.ClientCodex),
+		Suggestions: report.Suggestions,
+		Conflicts:   report.Conflicts,
+		Omissions:   report.Omissions,
+	})
 }
 
 func omissionReason(claim Claim, sources map[string]Source) string {
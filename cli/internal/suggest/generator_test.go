@@ -88,7 +88,7 @@ func TestGeneratorConflictOmission(t *testing.T) {
 			ID:       "c2",
 			SourceID: "s1",
 			Client:   "codex",
-			Text:     "You MUST use yarn.",
+			Text:     "Never use pnpm.",
 			Proof: Proof{
 				Sources:           []string{"https://tier1.example"},
 				SnapshotIDs:       []string{"sha256:two"},
@@ -0,0 +1,140 @@
+package suggest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	scanhash "markdowntown-cli/internal/hash"
+)
+
+const historyFile = "suggest/history.warc"
+
+// HistorySnapshot is one recorded body of a source URL.
+type HistorySnapshot struct {
+	ID         string
+	URL        string
+	RecordedAt time.Time
+	Body       []byte
+}
+
+// SnapshotHistory is an append-only WARC archive holding every distinct body
+// fetched for each source URL, so claim sets can be compared over time.
+type SnapshotHistory struct {
+	path string
+
+	mu sync.Mutex
+	// latest maps each URL to its newest snapshot. It is read from the
+	// archive on the first Record and kept current as records are appended.
+	latest map[string]HistorySnapshot
+}
+
+// NewSnapshotHistory returns the history archive in XDG data.
+func NewSnapshotHistory() (*SnapshotHistory, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotHistory{path: filepath.Join(dataDir, historyFile)}, nil
+}
+
+// Record appends body for url unless it matches the latest recorded body.
+// It reports whether a record was written. The archive is decoded once per
+// SnapshotHistory, so reuse one value for every Record in a run.
+func (h *SnapshotHistory) Record(url string, recordedAt time.Time, body []byte) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.latest == nil {
+		snapshots, err := h.Snapshots()
+		if err != nil {
+			return false, err
+		}
+		h.latest = make(map[string]HistorySnapshot)
+		for _, snapshot := range snapshots {
+			snapshot.Body = nil
+			h.latest[snapshot.URL] = snapshot
+		}
+	}
+	id := snapshotID(body)
+	last, ok := h.latest[url]
+	if ok && last.ID == id {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o750); err != nil {
+		return false, fmt.Errorf("mkdir history dir: %w", err)
+	}
+	// #nosec G304 -- history path is derived from XDG data.
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return false, err
+	}
+	if _, err := NewWARCWriter(file).WriteSnapshotResponse(url, recordedAt, body); err != nil {
+		_ = file.Close()
+		return false, err
+	}
+	// Match the record's WARC-Date so ordering agrees with Snapshots.
+	recordedAt = recordedAt.UTC().Truncate(time.Second)
+	if !ok || !recordedAt.Before(last.RecordedAt) {
+		h.latest[url] = HistorySnapshot{ID: id, URL: url, RecordedAt: recordedAt}
+	}
+	return true, file.Close()
+}
+
+// Snapshots returns every recorded snapshot ordered by record time. A missing
+// archive yields no snapshots.
+func (h *SnapshotHistory) Snapshots() ([]HistorySnapshot, error) {
+	// #nosec G304 -- history path is derived from XDG data.
+	file, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	reader, err := NewWARCReader(file)
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []HistorySnapshot
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read history: %w", err)
+		}
+		if !strings.EqualFold(record.Type, "response") {
+			continue
+		}
+		body, err := ResponseBody(record)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, HistorySnapshot{
+			ID:         snapshotID(body),
+			URL:        record.TargetURI,
+			RecordedAt: record.Date,
+			Body:       body,
+		})
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].RecordedAt.Before(snapshots[j].RecordedAt)
+	})
+	return snapshots, nil
+}
+
+// snapshotID matches the snapshot IDs assigned to claims.
+func snapshotID(body []byte) string {
+	return "sha256:" + scanhash.SumHex(body)
+}
@@ -0,0 +1,77 @@
+package suggest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotHistoryRecord(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	history, err := NewSnapshotHistory()
+	if err != nil {
+		t.Fatalf("new history: %v", err)
+	}
+	if snapshots, err := history.Snapshots(); err != nil || len(snapshots) != 0 {
+		t.Fatalf("expected empty history, got %v (%v)", snapshots, err)
+	}
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	url := "https://example.com/doc.md"
+	record := func(at time.Time, body string) bool {
+		written, err := history.Record(url, at, []byte(body))
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		return written
+	}
+	if !record(t0, "v1") {
+		t.Fatalf("expected first body to be recorded")
+	}
+	if record(t0.Add(time.Hour), "v1") {
+		t.Fatalf("expected unchanged body to be skipped")
+	}
+	if !record(t0.Add(2*time.Hour), "v2") {
+		t.Fatalf("expected changed body to be recorded")
+	}
+	if _, err := history.Record("https://example.com/other.md", t0.Add(-time.Hour), []byte("other")); err != nil {
+		t.Fatalf("record other: %v", err)
+	}
+
+	snapshots, err := history.Snapshots()
+	if err != nil {
+		t.Fatalf("snapshots: %v", err)
+	}
+	if len(snapshots) != 3 {
+		t.Fatalf("expected 3 snapshots, got %d", len(snapshots))
+	}
+	if snapshots[0].URL != "https://example.com/other.md" || string(snapshots[2].Body) != "v2" {
+		t.Fatalf("expected snapshots ordered by time, got %+v", snapshots)
+	}
+	if snapshots[1].ID != snapshotID([]byte("v1")) {
+		t.Fatalf("unexpected snapshot id: %s", snapshots[1].ID)
+	}
+	// Record reads the archive once; later appends update the in-memory index.
+	if err := os.Remove(history.path); err != nil {
+		t.Fatalf("remove history: %v", err)
+	}
+	if record(t0.Add(3*time.Hour), "v2") {
+		t.Fatalf("expected indexed latest body to be skipped")
+	}
+	if !record(t0.Add(4*time.Hour), "v3") {
+		t.Fatalf("expected changed body to be recorded")
+	}
+	if record(t0.Add(5*time.Hour), "v3") {
+		t.Fatalf("expected appended body to update the index")
+	}
+	if fresh, err := NewSnapshotHistory(); err != nil {
+		t.Fatalf("new history: %v", err)
+	} else if written, err := fresh.Record(url, t0.Add(6*time.Hour), []byte("v3")); err != nil || written {
+		t.Fatalf("expected reloaded history to skip latest body, got %v (%v)", written, err)
+	}
+
+	if filepath.Base(history.path) != "history.warc" {
+		t.Fatalf("unexpected history path: %s", history.path)
+	}
+}
@@ -0,0 +1,515 @@
+package suggest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// boilerplateTokens are class or id words that mark site chrome rather than
+// page content.
+var boilerplateTokens = map[string]struct{}{
+	"banner":      {},
+	"breadcrumb":  {},
+	"breadcrumbs": {},
+	"cookie":      {},
+	"cookies":     {},
+	"feedback":    {},
+	"footer":      {},
+	"linenos":     {},
+	"menu":        {},
+	"nav":         {},
+	"navbar":      {},
+	"navigation":  {},
+	"pagination":  {},
+	"sidebar":     {},
+	"skip":        {},
+	"toc":         {},
+}
+
+// admonitionTokens are class words of note and warning boxes, rendered as
+// blockquotes.
+var admonitionTokens = map[string]struct{}{
+	"admonition": {},
+	"alert":      {},
+	"callout":    {},
+	"caution":    {},
+	"important":  {},
+	"note":       {},
+	"tip":        {},
+	"warning":    {},
+}
+
+// permalinkTokens are class words of heading anchor links.
+var permalinkTokens = map[string]struct{}{
+	"anchor":     {},
+	"headerlink": {},
+	"permalink":  {},
+}
+
+var (
+	classTokenRE = regexp.MustCompile(`[^a-z0-9]+`)
+	spaceRunRE   = regexp.MustCompile(`[ \t\r\f\v]+`)
+)
+
+// htmlToMarkdown converts an HTML page into Markdown for normalizeMarkdown.
+// Only the main content is kept: the first <main> (or role="main"), else the
+// first <article>, else <body>, with navigation, headers, footers and
+// sidebars dropped by tag, role and class heuristics. Headings, lists,
+// tables and preformatted blocks keep their structure, and inline code is
+// wrapped in backticks so ExtractClaims can ignore it.
+func htmlToMarkdown(input string) string {
+	doc, err := html.Parse(strings.NewReader(input))
+	if err != nil {
+		return input
+	}
+	root := findElement(doc, isMainElement)
+	if root == nil {
+		root = findElement(doc, func(n *html.Node) bool { return n.DataAtom == atom.Article })
+	}
+	if root == nil {
+		root = findElement(doc, func(n *html.Node) bool { return n.DataAtom == atom.Body })
+	}
+	if root == nil {
+		root = doc
+	}
+	blocks := markdownBlocks(root)
+	if len(blocks) == 0 {
+		return ""
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// markdownBlocks renders the children of n as Markdown blocks. Inline
+// content between block elements becomes a paragraph.
+func markdownBlocks(n *html.Node) []string {
+	var blocks []string
+	var inline strings.Builder
+	flush := func() {
+		if text := markdownParagraph(inline.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		inline.Reset()
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if skipElement(child) {
+			continue
+		}
+		if child.Type == html.ElementNode && isBlockElement(child) {
+			flush()
+			blocks = append(blocks, markdownBlock(child)...)
+			continue
+		}
+		inline.WriteString(markdownInline(child))
+	}
+	flush()
+	return blocks
+}
+
+func markdownBlock(n *html.Node) []string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.Join(strings.Fields(markdownInline(n)), " ")
+		if text == "" {
+			return nil
+		}
+		level := int(n.Data[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + text}
+	case atom.P:
+		if text := markdownParagraph(markdownInline(n)); text != "" {
+			return []string{text}
+		}
+		return nil
+	case atom.Pre:
+		return []string{markdownFence(n)}
+	case atom.Ul, atom.Ol:
+		if list := markdownList(n); list != "" {
+			return []string{list}
+		}
+		return nil
+	case atom.Dl:
+		if list := markdownDefinitions(n); list != "" {
+			return []string{list}
+		}
+		return nil
+	case atom.Table:
+		if table := markdownTable(n); table != "" {
+			return []string{table}
+		}
+		return nil
+	case atom.Blockquote:
+		return quoteBlocks(markdownBlocks(n))
+	case atom.Hr:
+		return []string{"---"}
+	}
+	if hasClassToken(n, admonitionTokens) {
+		return quoteBlocks(markdownBlocks(n))
+	}
+	return markdownBlocks(n)
+}
+
+// markdownInline renders n as inline Markdown. Block elements nested in
+// inline context are flattened to their text.
+func markdownInline(n *html.Node) string {
+	if skipElement(n) {
+		return ""
+	}
+	switch n.Type {
+	case html.TextNode:
+		return strings.ReplaceAll(spaceRunRE.ReplaceAllString(n.Data, " "), "\n", " ")
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.Img:
+		return attr(n, "alt")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt, atom.Pre:
+		return codeSpan(strings.Join(strings.Fields(nodeText(n)), " "))
+	}
+
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(markdownInline(child))
+	}
+	inner := b.String()
+	switch n.DataAtom {
+	case atom.Strong, atom.B:
+		return wrapInline(inner, "**")
+	case atom.Em, atom.I:
+		return wrapInline(inner, "*")
+	}
+	if isBlockElement(n) {
+		return " " + inner + " "
+	}
+	return inner
+}
+
+// markdownParagraph collapses whitespace per line and escapes leading
+// characters that normalizeMarkdown would treat as structure.
+func markdownParagraph(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(spaceRunRE.ReplaceAllString(line, " "))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "```") {
+			line = `\` + line
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func markdownFence(n *html.Node) string {
+	lang := codeLanguage(n)
+	if code := firstChildElement(n, atom.Code); code != nil && lang == "" {
+		lang = codeLanguage(code)
+	}
+	body := strings.Trim(nodeText(n), "\n")
+	return "```" + lang + "\n" + body + "\n```"
+}
+
+func markdownList(n *html.Node) string {
+	index := 1
+	if n.DataAtom == atom.Ol {
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			index = start
+		}
+	}
+	var items []string
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li || skipElement(child) {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+		items = append(items, indentItem(marker, markdownBlocks(child)))
+	}
+	return strings.Join(items, "\n")
+}
+
+func markdownDefinitions(n *html.Node) string {
+	var items []string
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || skipElement(child) {
+			continue
+		}
+		switch child.DataAtom {
+		case atom.Dt:
+			term := strings.Join(strings.Fields(markdownInline(child)), " ")
+			if term != "" {
+				items = append(items, "- "+wrapInline(term, "**"))
+			}
+		case atom.Dd:
+			blocks := markdownBlocks(child)
+			if len(blocks) > 0 {
+				items = append(items, indentItem("  ", blocks))
+			}
+		}
+	}
+	return strings.Join(items, "\n")
+}
+
+// indentItem prefixes the first line with marker and indents the rest to
+// line up with it.
+func indentItem(marker string, blocks []string) string {
+	pad := strings.Repeat(" ", len(marker))
+	lines := strings.Split(strings.Join(blocks, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			lines[i] = marker + line
+		case line != "":
+			lines[i] = pad + line
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), " ")
+}
+
+func quoteBlocks(blocks []string) []string {
+	if len(blocks) == 0 {
+		return nil
+	}
+	lines := strings.Split(strings.Join(blocks, "\n\n"), "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+			continue
+		}
+		lines[i] = "> " + line
+	}
+	return []string{strings.Join(lines, "\n")}
+}
+
+// markdownTable renders a pipe table. The first row is the header row.
+func markdownTable(n *html.Node) string {
+	var rows [][]string
+	var collect func(*html.Node)
+	collect = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode || skipElement(child) {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				collect(child)
+			case atom.Tr:
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type != html.ElementNode || (cell.DataAtom != atom.Td && cell.DataAtom != atom.Th) {
+						continue
+					}
+					text := strings.Join(strings.Fields(markdownInline(cell)), " ")
+					row = append(row, strings.ReplaceAll(text, "|", `\|`))
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	collect(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", width))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func codeSpan(text string) string {
+	if text == "" {
+		return ""
+	}
+	if strings.Contains(text, "`") {
+		return "`` " + text + " ``"
+	}
+	return "`" + text + "`"
+}
+
+// wrapInline adds emphasis markers inside any surrounding whitespace.
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + marker + trimmed + marker + text[start+len(trimmed):]
+}
+
+// nodeText returns the raw text of n, keeping whitespace and turning <br>
+// into newlines.
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		switch {
+		case node.Type == html.TextNode:
+			b.WriteString(node.Data)
+		case node.Type == html.ElementNode && node.DataAtom == atom.Br:
+			b.WriteString("\n")
+		case skipElement(node):
+		default:
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func codeLanguage(n *html.Node) string {
+	for _, class := range strings.Fields(attr(n, "class")) {
+		for _, prefix := range []string{"language-", "lang-", "highlight-source-", "highlight-"} {
+			if strings.HasPrefix(class, prefix) {
+				return strings.TrimPrefix(class, prefix)
+			}
+		}
+	}
+	return ""
+}
+
+// skipElement reports whether n is chrome, hidden or non-content markup.
+func skipElement(n *html.Node) bool {
+	switch n.Type {
+	case html.CommentNode, html.DoctypeNode:
+		return true
+	case html.ElementNode:
+	default:
+		return false
+	}
+
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg,
+		atom.Nav, atom.Footer, atom.Button, atom.Form, atom.Iframe, atom.Select:
+		return true
+	case atom.Header:
+		// Site banners sit directly under <body> or carry the navigation;
+		// article headers hold the page title and are kept.
+		if findElement(n, func(child *html.Node) bool { return child.DataAtom == atom.Nav }) != nil {
+			return true
+		}
+		if n.Parent != nil && n.Parent.DataAtom == atom.Body &&
+			findElement(n, func(child *html.Node) bool { return child.DataAtom == atom.H1 }) == nil {
+			return true
+		}
+	case atom.Aside:
+		return !hasClassToken(n, admonitionTokens)
+	case atom.A:
+		if hasClassToken(n, permalinkTokens) {
+			return true
+		}
+	}
+
+	if hasAttr(n, "hidden") || strings.EqualFold(attr(n, "aria-hidden"), "true") {
+		return true
+	}
+	switch strings.ToLower(attr(n, "role")) {
+	case "navigation", "banner", "contentinfo", "search", "complementary":
+		return true
+	}
+	// Class and id words are only hints: never drop a subtree that holds
+	// the page title or the main content.
+	if hasClassToken(n, boilerplateTokens) {
+		return findElement(n, func(child *html.Node) bool {
+			return child.DataAtom == atom.H1 || child.DataAtom == atom.Article || isMainElement(child)
+		}) == nil
+	}
+	return false
+}
+
+func isMainElement(n *html.Node) bool {
+	return n.DataAtom == atom.Main || strings.EqualFold(attr(n, "role"), "main")
+}
+
+func isBlockElement(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Address, atom.Article, atom.Aside, atom.Blockquote, atom.Body, atom.Details,
+		atom.Div, atom.Dl, atom.Dd, atom.Dt, atom.Fieldset, atom.Figcaption, atom.Figure,
+		atom.Footer, atom.Form, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Header, atom.Hr, atom.Html, atom.Li, atom.Main, atom.Nav, atom.Ol, atom.P,
+		atom.Pre, atom.Section, atom.Summary, atom.Table, atom.Tbody, atom.Td, atom.Tfoot,
+		atom.Th, atom.Thead, atom.Tr, atom.Ul:
+		return true
+	}
+	return false
+}
+
+// findElement returns the first element below n (depth first) that matches.
+func findElement(n *html.Node, match func(*html.Node) bool) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && match(child) {
+			return child
+		}
+		if found := findElement(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func firstChildElement(n *html.Node, a atom.Atom) *html.Node {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == a {
+			return child
+		}
+	}
+	return nil
+}
+
+// hasClassToken reports whether any word of n's class or id is in tokens.
+func hasClassToken(n *html.Node, tokens map[string]struct{}) bool {
+	for _, value := range []string{attr(n, "class"), attr(n, "id")} {
+		for _, token := range classTokenRE.Split(strings.ToLower(value), -1) {
+			if _, ok := tokens[token]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package suggest
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalDocument is one Markdown or HTML file read from a local source.
+type LocalDocument struct {
+	// URL is the file:// URL recorded in claim proofs.
+	URL    string
+	Path   string
+	Format string
+	Body   []byte
+}
+
+// ReadLocalSource reads the file behind a local source, or every Markdown
+// and HTML file below it when it is a directory. Hidden files and
+// directories are skipped; documents are returned in lexical path order.
+func ReadLocalSource(src Source) ([]LocalDocument, error) {
+	root := src.LocalPath()
+	if root == "" {
+		return nil, fmt.Errorf("source %s is not local", src.ID)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		format, ok := localFormat(root)
+		if !ok {
+			return nil, fmt.Errorf("unsupported local source file: %s", root)
+		}
+		doc, err := readLocalDocument(root, format)
+		if err != nil {
+			return nil, err
+		}
+		return []LocalDocument{doc}, nil
+	}
+
+	var docs []LocalDocument
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || !entry.Type().IsRegular() {
+			return nil
+		}
+		format, ok := localFormat(path)
+		if !ok {
+			return nil
+		}
+		doc, err := readLocalDocument(path, format)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+func readLocalDocument(path, format string) (LocalDocument, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return LocalDocument{}, err
+	}
+	// #nosec G304 -- local source paths come from the user's registry.
+	body, err := os.ReadFile(abs)
+	if err != nil {
+		return LocalDocument{}, err
+	}
+	slashed := filepath.ToSlash(abs)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	fileURL := url.URL{Scheme: "file", Path: slashed}
+	return LocalDocument{URL: fileURL.String(), Path: abs, Format: format, Body: body}, nil
+}
+
+func localFormat(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return "markdown", true
+	case ".html", ".htm":
+		return "html", true
+	default:
+		return "", false
+	}
+}
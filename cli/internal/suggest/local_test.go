@@ -0,0 +1,99 @@
+package suggest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadLocalSourceDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"agents.md":          "# Agents\nYou MUST run make lint.",
+		"guides/review.html": "<h1>Review</h1><p>Reviewers should run the tests.</p>",
+		"notes.txt":          "You must ignore this.",
+		".drafts/wip.md":     "You must ignore drafts.",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	docs, err := ReadLocalSource(Source{ID: "handbook", Path: dir})
+	if err != nil {
+		t.Fatalf("ReadLocalSource: %v", err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 documents, got %#v", docs)
+	}
+	if docs[0].Format != "markdown" || docs[1].Format != "html" {
+		t.Fatalf("unexpected formats: %s, %s", docs[0].Format, docs[1].Format)
+	}
+	if !strings.HasPrefix(docs[1].URL, "file:///") || !strings.HasSuffix(docs[1].URL, "/guides/review.html") {
+		t.Fatalf("unexpected url: %s", docs[1].URL)
+	}
+
+	fileSource := Source{ID: "handbook", URL: docs[0].URL}
+	single, err := ReadLocalSource(fileSource)
+	if err != nil {
+		t.Fatalf("ReadLocalSource file url: %v", err)
+	}
+	if len(single) != 1 || string(single[0].Body) != files["agents.md"] {
+		t.Fatalf("unexpected file source documents: %#v", single)
+	}
+}
+
+func TestReadLocalSourceErrors(t *testing.T) {
+	dir := t.TempDir()
+	notes := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("notes"), 0o600); err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+	if _, err := ReadLocalSource(Source{ID: "notes", Path: notes}); err == nil {
+		t.Fatalf("expected unsupported file error")
+	}
+	if _, err := ReadLocalSource(Source{ID: "missing", Path: filepath.Join(dir, "missing")}); err == nil {
+		t.Fatalf("expected missing path error")
+	}
+	if _, err := ReadLocalSource(Source{ID: "web", URL: "https://example.com/docs"}); err == nil {
+		t.Fatalf("expected error for web source")
+	}
+}
+
+func TestGenerateSuggestionsProvenance(t *testing.T) {
+	sources := map[string]Source{
+		"web":      {ID: "web", Tier: "tier-0", Client: "codex", URL: "https://example.com/docs"},
+		"handbook": {ID: "handbook", Tier: "tier-1", Client: "codex", Path: "/srv/handbook"},
+	}
+	claim := func(id, sourceID, url, text string) Claim {
+		return Claim{
+			ID:       id,
+			SourceID: sourceID,
+			Client:   "codex",
+			Text:     text,
+			Proof:    Proof{Sources: []string{url}, SnapshotIDs: []string{"sha256:" + id}, NormativeStrength: StrengthMust},
+		}
+	}
+	report := GenerateSuggestions([]Claim{
+		claim("c1", "handbook", "file:///srv/handbook/agents.md", "You MUST run make lint."),
+		claim("c2", "web", "https://example.com/docs", "You MUST keep AGENTS.md concise."),
+		claim("c3", "handbook", "file:///srv/handbook/style.md", "Keep AGENTS.md concise."),
+	}, sources)
+
+	provenance := map[string]ProvenanceType{}
+	for _, suggestion := range report.Suggestions {
+		provenance[suggestion.ClaimID] = suggestion.Provenance
+		if len(suggestion.LocalSources) != 1 || suggestion.LocalSources[0] != "handbook" {
+			t.Fatalf("expected handbook local source for %s, got %v", suggestion.ClaimID, suggestion.LocalSources)
+		}
+	}
+	if provenance["c1"] != ProvenanceLocal || provenance["c2"] != ProvenanceMixed || len(provenance) != 2 {
+		t.Fatalf("unexpected provenance: %v", provenance)
+	}
+}
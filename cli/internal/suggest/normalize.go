@@ -3,7 +3,6 @@ package suggest
 import (
 	"bufio"
 	"errors"
-	"html"
 	"regexp"
 	"strings"
 )
@@ -28,10 +27,7 @@ type Span struct {
 	End   int
 }
 
-var (
-	headingRE = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
-	tagRE     = regexp.MustCompile(`(?s)<[^>]+>`)
-)
+var headingRE = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
 
 // NormalizeDocument normalizes HTML or Markdown content into sections.
 func NormalizeDocument(input, format string) (NormalizedDocument, error) {
@@ -93,38 +89,6 @@ func normalizeMarkdown(input string) NormalizedDocument {
 	return NormalizedDocument{Sections: sections}
 }
 
-func htmlToMarkdown(input string) string {
-	replacements := []struct {
-		pattern *regexp.Regexp
-		replace string
-	}{
-		{regexp.MustCompile(`(?i)<h1[^>]*>`), "\n# "},
-		{regexp.MustCompile(`(?i)</h1>`), "\n"},
-		{regexp.MustCompile(`(?i)<h2[^>]*>`), "\n## "},
-		{regexp.MustCompile(`(?i)</h2>`), "\n"},
-		{regexp.MustCompile(`(?i)<h3[^>]*>`), "\n### "},
-		{regexp.MustCompile(`(?i)</h3>`), "\n"},
-		{regexp.MustCompile(`(?i)<h4[^>]*>`), "\n#### "},
-		{regexp.MustCompile(`(?i)</h4>`), "\n"},
-		{regexp.MustCompile(`(?i)<h5[^>]*>`), "\n##### "},
-		{regexp.MustCompile(`(?i)</h5>`), "\n"},
-		{regexp.MustCompile(`(?i)<h6[^>]*>`), "\n###### "},
-		{regexp.MustCompile(`(?i)</h6>`), "\n"},
-		{regexp.MustCompile(`(?i)<p[^>]*>`), "\n"},
-		{regexp.MustCompile(`(?i)</p>`), "\n"},
-		{regexp.MustCompile(`(?i)<br\s*/?>`), "\n"},
-	}
-
-	out := input
-	for _, repl := range replacements {
-		out = repl.pattern.ReplaceAllString(out, repl.replace)
-	}
-
-	out = tagRE.ReplaceAllString(out, "")
-	out = html.UnescapeString(out)
-	return out
-}
-
 func slugify(value string) string {
 	lower := strings.ToLower(value)
 	var out []rune
@@ -1,6 +1,14 @@
 package suggest
 
-import "testing"
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update-golden", false, "update golden fixtures")
 
 func TestNormalizeMarkdownHeadings(t *testing.T) {
 	input := "# Title\nIntro text\n## Sub\nSub text"
@@ -54,3 +62,88 @@ func TestNormalizeMalformedHTML(t *testing.T) {
 		t.Fatalf("expected sections for malformed html")
 	}
 }
+
+func TestHTMLToMarkdownGolden(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("..", "..", "testdata", "suggest", "html", "*.html"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(pages) == 0 {
+		t.Fatalf("expected saved html pages")
+	}
+	for _, page := range pages {
+		t.Run(filepath.Base(page), func(t *testing.T) {
+			// #nosec G304 -- fixture path is test-controlled.
+			input, err := os.ReadFile(page)
+			if err != nil {
+				t.Fatalf("read page: %v", err)
+			}
+			actual := htmlToMarkdown(string(input))
+			goldenPath := strings.TrimSuffix(page, ".html") + ".md"
+			if *updateGolden {
+				if err := os.WriteFile(goldenPath, []byte(actual), 0o600); err != nil {
+					t.Fatalf("write golden: %v", err)
+				}
+				return
+			}
+			// #nosec G304 -- golden path is test-controlled.
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("read golden: %v", err)
+			}
+			if actual != string(expected) {
+				t.Fatalf("golden mismatch (run with -update-golden to refresh)\nexpected:\n%s\nactual:\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestHTMLToMarkdownStructure(t *testing.T) {
+	input := `<body><nav><a href="/">Home</a></nav><main>
+<h2>Options</h2>
+<ul><li>One<ol start="3"><li>Nested</li></ol></li><li>Two</li></ul>
+<table><tr><th>Flag</th><th>Use</th></tr><tr><td><code>--x</code></td><td>a | b</td></tr></table>
+<pre><code class="language-sh">make test
+</code></pre>
+</main><footer>Footer</footer></body>`
+	expected := "## Options\n\n- One\n  3. Nested\n- Two\n\n| Flag | Use |\n| --- | --- |\n| `--x` | a \\| b |\n\n```sh\nmake test\n```\n"
+	if got := htmlToMarkdown(input); got != expected {
+		t.Fatalf("unexpected markdown:\n%s", got)
+	}
+}
+
+func TestHTMLToMarkdownKeepsTitleInChrome(t *testing.T) {
+	input := `<body><div class="page-with-sidebar"><h1>Title</h1><p>Body</p></div></body>`
+	doc, err := NormalizeDocument(input, "html")
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if len(doc.Sections) != 1 || doc.Sections[0].ID != "title" || doc.Sections[0].Content != "Body" {
+		t.Fatalf("unexpected sections: %+v", doc.Sections)
+	}
+}
+
+func TestHTMLClaimsIgnoreChromeAndCode(t *testing.T) {
+	page := filepath.Join("..", "..", "testdata", "suggest", "html", "codex-agents.html")
+	// #nosec G304 -- fixture path is test-controlled.
+	input, err := os.ReadFile(page)
+	if err != nil {
+		t.Fatalf("read page: %v", err)
+	}
+	doc, err := NormalizeDocument(string(input), "html")
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	claims := ExtractClaims(doc, Source{ID: "fixture", Client: "codex", URL: "https://example.com"}, "sha256:fixture")
+	var texts []string
+	for _, claim := range claims {
+		texts = append(texts, string(claim.Strength)+" "+claim.Text)
+	}
+	expected := []string{
+		"MUST Each file must be UTF-8 encoded.",
+		"SHOULD Combined instructions should stay below the limit set by `project_doc_max_bytes`; content past the limit may be truncated.",
+	}
+	if strings.Join(texts, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected claims:\n%s", strings.Join(texts, "\n"))
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+
+	"markdowntown-cli/internal/instructions"
 )
 
 // WriteSuggestReport renders suggest or audit output in JSON or Markdown.
@@ -18,7 +20,39 @@ func WriteSuggestReport(w io.Writer, format string, report Report) error {
 		enc.SetEscapeHTML(false)
 		return enc.Encode(report)
 	case "md":
-		payload, err := renderSuggestMarkdown(report)
+		tmpl, err := ClientTemplate(report.Client)
+		if err != nil {
+			return err
+		}
+		return WriteSuggestMarkdown(w, report, tmpl)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// WriteSuggestMarkdown renders a report with tmpl and appends the audit
+// sections.
+func WriteSuggestMarkdown(w io.Writer, report Report, tmpl *SuggestionTemplate) error {
+	payload, err := renderSuggestMarkdown(report, tmpl)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, payload)
+	return err
+}
+
+// WriteMultiClientReport renders a multi-client report in JSON or Markdown.
+// Markdown output renders each client's section with its default template,
+// in client order, followed by cross-client conflicts and run warnings.
+func WriteMultiClientReport(w io.Writer, format string, report MultiClientReport) error {
+	switch normalizeFormat(format) {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(report)
+	case "md":
+		payload, err := renderMultiClientMarkdown(report)
 		if err != nil {
 			return err
 		}
@@ -46,6 +80,22 @@ func WriteResolveReport(w io.Writer, format string, report ResolveReport) error
 	}
 }
 
+// WriteChangeReport renders suggest changes output in JSON or Markdown.
+func WriteChangeReport(w io.Writer, format string, report ChangeReport) error {
+	switch normalizeFormat(format) {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(report)
+	case "md":
+		_, err := fmt.Fprint(w, renderChangeMarkdown(report))
+		return err
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
 func normalizeFormat(format string) string {
 	trimmed := strings.ToLower(strings.TrimSpace(format))
 	switch trimmed {
@@ -58,36 +108,70 @@ func normalizeFormat(format string) string {
 	}
 }
 
-func renderSuggestMarkdown(report Report) (string, error) {
+func renderSuggestMarkdown(report Report, tmpl *SuggestionTemplate) (string, error) {
+	sorted := append([]Suggestion(nil), report.Suggestions...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	payload, err := tmpl.Render(TemplateData{
+		Client:      string(report.Client),
+		GeneratedAt: report.GeneratedAt,
+		Suggestions: sorted,
+		Conflicts:   report.Conflicts,
+		Omissions:   report.Omissions,
+		Gaps:        report.Gaps,
+	})
+	if err != nil {
+		return "", err
+	}
+
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("# Suggestions (%s)\n\n", report.Client))
-
-	if len(report.Suggestions) > 0 {
-		sorted := append([]Suggestion(nil), report.Suggestions...)
-		sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
-		if strings.EqualFold(string(report.Client), "codex") {
-			tempReport := SuggestionReport{Suggestions: sorted}
-			payload, err := RenderCodexSuggestions(tempReport)
-			if err != nil {
-				return "", err
-			}
-			builder.WriteString(payload)
-			if !strings.HasSuffix(payload, "\n") {
-				builder.WriteString("\n")
-			}
-		} else {
-			for _, suggestion := range sorted {
-				builder.WriteString(fmt.Sprintf("- %s\n", suggestion.Text))
-				if len(suggestion.Sources) > 0 {
-					builder.WriteString(fmt.Sprintf("  - Sources: %s\n", strings.Join(suggestion.Sources, ", ")))
-				}
+	builder.WriteString(payload)
+	if !strings.HasSuffix(payload, "\n") {
+		builder.WriteString("\n")
+	}
+	appendAuditSections(&builder, report)
+	return builder.String(), nil
+}
+
+func renderMultiClientMarkdown(report MultiClientReport) (string, error) {
+	clients := make([]string, 0, len(report.Reports))
+	for client := range report.Reports {
+		clients = append(clients, string(client))
+	}
+	sort.Strings(clients)
+
+	var builder strings.Builder
+	for i, client := range clients {
+		section := report.Reports[instructions.Client(client)]
+		tmpl, err := ClientTemplate(section.Client)
+		if err != nil {
+			return "", err
+		}
+		payload, err := renderSuggestMarkdown(section, tmpl)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(payload)
+	}
+
+	if len(report.CrossClientConflicts) > 0 {
+		builder.WriteString("\n## Cross-client conflicts\n\n")
+		for _, conflict := range report.CrossClientConflicts {
+			fmt.Fprintf(&builder, "- %s: %s\n", strings.Join(conflict.Clients, " vs "), conflict.Reason)
+			for _, claimID := range conflict.ClaimIDs {
+				fmt.Fprintf(&builder, "  - %s\n", claimID)
 			}
 		}
-	} else {
-		builder.WriteString("_No suggestions available._\n")
 	}
 
-	appendAuditSections(&builder, report)
+	if len(report.Warnings) > 0 {
+		builder.WriteString("\n## Run warnings\n\n")
+		for _, warning := range report.Warnings {
+			fmt.Fprintf(&builder, "- %s\n", formatMarkdownURLs(warning))
+		}
+	}
 	return builder.String(), nil
 }
 
@@ -125,7 +209,74 @@ func renderResolveMarkdown(report ResolveReport) string {
 	return builder.String()
 }
 
+func renderChangeMarkdown(report ChangeReport) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# Claim changes (%s) since %s\n", report.Client, report.Since)
+
+	changed := 0
+	for _, source := range report.Sources {
+		if len(source.Changes) == 0 {
+			continue
+		}
+		changed++
+		fmt.Fprintf(&builder, "\n## %s (%s)\n\n", source.SourceID, source.Tier)
+		fmt.Fprintf(&builder, "%s: `%s` → `%s`\n\n", formatMarkdownURLs(source.URL), shortSnapshot(source.BaseSnapshot), shortSnapshot(source.HeadSnapshot))
+		for _, change := range source.Changes {
+			builder.WriteString(formatChangeLine(change))
+		}
+	}
+	if changed == 0 {
+		builder.WriteString("\n_No claim changes._\n")
+	}
+
+	if len(report.Unreflected) > 0 {
+		builder.WriteString("\n## Not reflected in instructions\n\n")
+		for _, change := range report.Unreflected {
+			builder.WriteString(formatChangeLine(change))
+		}
+	}
+
+	if len(report.Warnings) > 0 {
+		builder.WriteString("\n## Warnings\n\n")
+		for _, warning := range report.Warnings {
+			fmt.Fprintf(&builder, "- %s\n", formatMarkdownURLs(warning))
+		}
+	}
+	return builder.String()
+}
+
+func formatChangeLine(change ClaimChange) string {
+	span := fmt.Sprintf("%s:%d-%d", change.Span.SectionID, change.Span.Start, change.Span.End)
+	switch change.Kind {
+	case ChangeStrength:
+		return fmt.Sprintf("- %s → %s: %s (%s)\n", change.PreviousStrength, change.Strength, change.Text, span)
+	case ChangeAdded:
+		return fmt.Sprintf("- added %s: %s (%s)\n", change.Strength, change.Text, span)
+	default:
+		return fmt.Sprintf("- removed %s: %s (%s)\n", change.Strength, change.Text, span)
+	}
+}
+
+func shortSnapshot(id string) string {
+	trimmed := strings.TrimPrefix(id, "sha256:")
+	if len(trimmed) > 12 {
+		trimmed = trimmed[:12]
+	}
+	return trimmed
+}
+
 func appendAuditSections(builder *strings.Builder, report Report) {
+	if report.Gaps != nil {
+		builder.WriteString("\n## Coverage\n\n")
+		fmt.Fprintf(builder, "- Covered: %d\n", report.Gaps.Covered)
+		fmt.Fprintf(builder, "- Partial: %d\n", report.Gaps.Partial)
+		fmt.Fprintf(builder, "- Missing: %d\n", report.Gaps.Missing)
+		fmt.Fprintf(builder, "- Contradicted: %d\n", report.Gaps.Contradicted)
+		for _, path := range report.Gaps.Files {
+			fmt.Fprintf(builder, "  - %s\n", path)
+		}
+	}
+
 	if len(report.Conflicts) > 0 {
 		builder.WriteString("\n## Conflicts\n\n")
 		for _, conflict := range report.Conflicts {
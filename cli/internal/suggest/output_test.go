@@ -55,6 +55,34 @@ func TestWriteSuggestReportMarkdown(t *testing.T) {
 	}
 }
 
+func TestWriteMultiClientReportMarkdown(t *testing.T) {
+	report := MultiClientReport{
+		Reports: map[instructions.Client]Report{
+			instructions.ClientCodex:  {Client: instructions.ClientCodex, Suggestions: []Suggestion{{ID: "S1", Text: "Commit lockfiles"}}},
+			instructions.ClientClaude: {Client: instructions.ClientClaude, Warnings: []string{"no sources available for client"}},
+		},
+		CrossClientConflicts: []Conflict{{Clients: []string{"claude", "codex"}, ClaimIDs: []string{"C1", "C2"}, Reason: "opposing polarity across clients"}},
+		Warnings:             []string{"offline mode enabled; using cached data only"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMultiClientReport(&buf, "md", report); err != nil {
+		t.Fatalf("WriteMultiClientReport markdown: %v", err)
+	}
+	output := buf.String()
+	claude := strings.Index(output, "# Suggestions (claude)")
+	codex := strings.Index(output, "# Suggestions (codex)")
+	if claude < 0 || codex < claude {
+		t.Fatalf("expected client sections in order:\n%s", output)
+	}
+	if !strings.Contains(output, "## Cross-client conflicts\n\n- claude vs codex: opposing polarity across clients\n") {
+		t.Fatalf("expected cross-client conflicts:\n%s", output)
+	}
+	if !strings.Contains(output, "## Run warnings\n\n- offline mode") {
+		t.Fatalf("expected run warnings:\n%s", output)
+	}
+}
+
 func TestWriteResolveReportMarkdown(t *testing.T) {
 	report := ResolveReport{
 		Client: "codex",
@@ -0,0 +1,232 @@
+package suggest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"markdowntown-cli/internal/fix"
+	"markdowntown-cli/internal/instructions"
+)
+
+// ManagedSectionHeading opens the section that --emit-patch and --apply own
+// in the primary instruction file.
+const ManagedSectionHeading = "## markdowntown suggestions"
+
+const provenancePrefix = "<!-- markdowntown:suggestion "
+
+var provenanceIDRE = regexp.MustCompile(`<!-- markdowntown:suggestion id=(\S+)`)
+
+// blockMarkerRE matches leading list item and blockquote markers, possibly nested.
+var blockMarkerRE = regexp.MustCompile(`^(?:\s*(?:>|[-*+](?:\s|$)|\d+[.)](?:\s|$)))+\s*`)
+
+// SuggestionPatch is a unified diff that adds suggestions to a client's
+// primary instruction file.
+type SuggestionPatch struct {
+	// Path is the target file relative to the repo root, slash-separated.
+	Path    string
+	Created bool
+	// Added lists the suggestion IDs written by the patch.
+	Added []string
+	Diff  string
+}
+
+// PrimaryInstructionFile returns the repo-relative instruction file that
+// suggestions are written to for client.
+func PrimaryInstructionFile(client instructions.Client) (string, error) {
+	switch client {
+	case instructions.ClientCodex:
+		return "AGENTS.md", nil
+	case instructions.ClientClaude:
+		return "CLAUDE.md", nil
+	case instructions.ClientGemini:
+		return "GEMINI.md", nil
+	case instructions.ClientCopilot, instructions.ClientVSCode:
+		return ".github/copilot-instructions.md", nil
+	default:
+		return "", fmt.Errorf("unsupported client: %s", client)
+	}
+}
+
+// AcceptedSuggestions drops suggestions the repo already covers or
+// deliberately contradicts. Unclassified suggestions are accepted.
+func AcceptedSuggestions(suggestions []Suggestion) []Suggestion {
+	var accepted []Suggestion
+	for _, suggestion := range suggestions {
+		if suggestion.Coverage == CoverageCovered || suggestion.Coverage == CoverageContradicted {
+			continue
+		}
+		accepted = append(accepted, suggestion)
+	}
+	return accepted
+}
+
+// BuildSuggestionPatch renders suggestions into the managed section of the
+// client's primary instruction file under repoRoot. Suggestions already
+// recorded in the section are skipped; an empty Diff means nothing to add.
+func BuildSuggestionPatch(repoRoot string, client instructions.Client, suggestions []Suggestion) (SuggestionPatch, error) {
+	rel, err := PrimaryInstructionFile(client)
+	if err != nil {
+		return SuggestionPatch{}, err
+	}
+	patch := SuggestionPatch{Path: rel}
+
+	// #nosec G304 -- path is a fixed instruction file under the repo root.
+	data, err := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(rel)))
+	switch {
+	case os.IsNotExist(err):
+		patch.Created = true
+	case err != nil:
+		return SuggestionPatch{}, err
+	}
+	before := string(data)
+
+	after, added := mergeManagedSection(before, suggestions)
+	if len(added) == 0 {
+		return patch, nil
+	}
+	patch.Added = added
+
+	oldName := "a/" + rel
+	if patch.Created {
+		oldName = "/dev/null"
+	}
+	patch.Diff = fix.UnifiedDiff(oldName, "b/"+rel, before, after)
+	return patch, nil
+}
+
+// stripBlockMarkers removes leading list and blockquote markers so suggestion
+// text renders as a single list item in the managed section.
+func stripBlockMarkers(text string) string {
+	return blockMarkerRE.ReplaceAllString(text, "")
+}
+
+// mergeManagedSection appends suggestions missing from the managed section,
+// creating the section at the end of content when absent.
+func mergeManagedSection(content string, suggestions []Suggestion) (string, []string) {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	start, end := managedSectionBounds(lines)
+
+	existing := make(map[string]struct{})
+	if start >= 0 {
+		for _, line := range lines[start:end] {
+			if match := provenanceIDRE.FindStringSubmatch(line); match != nil {
+				existing[match[1]] = struct{}{}
+			}
+		}
+	}
+
+	var entries strings.Builder
+	var added []string
+	for _, suggestion := range suggestions {
+		if _, ok := existing[suggestion.ID]; ok {
+			continue
+		}
+		existing[suggestion.ID] = struct{}{}
+		added = append(added, suggestion.ID)
+		entries.WriteString(provenanceComment(suggestion))
+		fmt.Fprintf(&entries, "- %s\n", stripBlockMarkers(strings.Join(strings.Fields(suggestion.Text), " ")))
+	}
+	if len(added) == 0 {
+		return content, nil
+	}
+
+	if start < 0 {
+		var builder strings.Builder
+		builder.WriteString(content)
+		if content != "" {
+			if !strings.HasSuffix(content, "\n") {
+				builder.WriteString("\n")
+			}
+			if !strings.HasSuffix(content, "\n\n") {
+				builder.WriteString("\n")
+			}
+		}
+		builder.WriteString(ManagedSectionHeading + "\n\n")
+		builder.WriteString(entries.String())
+		return builder.String(), added
+	}
+
+	// Insert after the last non-blank line of the section so trailing blank
+	// lines stay between it and the next heading.
+	insert := end
+	for insert > start+1 && strings.TrimSpace(lines[insert-1]) == "" {
+		insert--
+	}
+	var builder strings.Builder
+	for _, line := range lines[:insert] {
+		builder.WriteString(line)
+	}
+	if insert > 0 && !strings.HasSuffix(lines[insert-1], "\n") {
+		builder.WriteString("\n")
+	}
+	if insert == start+1 {
+		builder.WriteString("\n")
+	}
+	builder.WriteString(entries.String())
+	for _, line := range lines[insert:] {
+		builder.WriteString(line)
+	}
+	return builder.String(), added
+}
+
+// managedSectionBounds returns the heading line index and the index of the
+// next heading of level two or higher, or -1 when the section is absent.
+func managedSectionBounds(lines []string) (int, int) {
+	start := -1
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if start < 0 {
+			if strings.EqualFold(trimmed, ManagedSectionHeading) {
+				start = i
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "# ") || strings.HasPrefix(trimmed, "## ") {
+			return start, i
+		}
+	}
+	return start, len(lines)
+}
+
+func provenanceComment(suggestion Suggestion) string {
+	var builder strings.Builder
+	builder.WriteString(provenancePrefix)
+	builder.WriteString("id=" + suggestion.ID)
+	if suggestion.ClaimID != "" {
+		builder.WriteString(" claim=" + suggestion.ClaimID)
+	}
+	if sources := provenanceSources(suggestion); len(sources) > 0 {
+		builder.WriteString(" sources=" + strings.Join(sources, ","))
+	}
+	builder.WriteString(" -->\n")
+	return builder.String()
+}
+
+// provenanceSources cites web sources by URL and local sources by registry
+// ID, so committed comments do not carry paths from the author's machine.
+func provenanceSources(suggestion Suggestion) []string {
+	var sources []string
+	for _, source := range suggestion.Sources {
+		if !strings.HasPrefix(strings.ToLower(source), "file:") {
+			sources = append(sources, source)
+		}
+	}
+	return append(sources, suggestion.LocalSources...)
+}
@@ -0,0 +1,105 @@
+package suggest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/instructions"
+)
+
+func TestMergeManagedSection(t *testing.T) {
+	first := Suggestion{ID: "suggest:sha256:aa", ClaimID: "claim:sha256:1", Text: "You MUST keep  instructions short.", Sources: []string{"https://example.com/a"}}
+	second := Suggestion{ID: "suggest:sha256:bb", Text: "You SHOULD run tests.", Sources: []string{"https://example.com/a", "https://example.com/b"}}
+
+	got, added := mergeManagedSection("# Agents\n\nBe nice.", []Suggestion{first})
+	want := "# Agents\n\nBe nice.\n\n## markdowntown suggestions\n\n" +
+		"<!-- markdowntown:suggestion id=suggest:sha256:aa claim=claim:sha256:1 sources=https://example.com/a -->\n" +
+		"- You MUST keep instructions short.\n"
+	if got != want || len(added) != 1 {
+		t.Fatalf("unexpected new section:\n%q\nwant\n%q", got, want)
+	}
+
+	withNext := got + "\n## Testing\n\nRun go test.\n"
+	merged, added := mergeManagedSection(withNext, []Suggestion{first, second})
+	if len(added) != 1 || added[0] != second.ID {
+		t.Fatalf("expected only the new suggestion, got %v", added)
+	}
+	wantMerged := want +
+		"<!-- markdowntown:suggestion id=suggest:sha256:bb sources=https://example.com/a,https://example.com/b -->\n" +
+		"- You SHOULD run tests.\n" +
+		"\n## Testing\n\nRun go test.\n"
+	if merged != wantMerged {
+		t.Fatalf("unexpected merge:\n%q\nwant\n%q", merged, wantMerged)
+	}
+
+	same, added := mergeManagedSection(merged, []Suggestion{first, second})
+	if same != merged || len(added) != 0 {
+		t.Fatalf("expected no changes on rerun, got %v", added)
+	}
+
+	local := Suggestion{ID: "suggest:sha256:cc", Text: "Run make lint.", Sources: []string{"file:///home/dev/handbook/lint.md", "https://example.com/a"}, LocalSources: []string{"handbook"}}
+	withLocal, _ := mergeManagedSection(merged, []Suggestion{local})
+	if !strings.Contains(withLocal, "id=suggest:sha256:cc sources=https://example.com/a,handbook -->") || strings.Contains(withLocal, "file://") {
+		t.Fatalf("expected local source cited by id:\n%s", withLocal)
+	}
+
+	listItem := Suggestion{ID: "suggest:sha256:dd", Text: "- You must run tests before pushing."}
+	quoted := Suggestion{ID: "suggest:sha256:ee", Text: "> 1. Each file must be UTF-8."}
+	withMarkers, _ := mergeManagedSection("", []Suggestion{listItem, quoted})
+	if !strings.Contains(withMarkers, "\n- You must run tests before pushing.\n") || !strings.Contains(withMarkers, "\n- Each file must be UTF-8.\n") {
+		t.Fatalf("expected list and quote markers stripped:\n%s", withMarkers)
+	}
+
+	fenced := "```\n## markdowntown suggestions\n```\n"
+	out, _ := mergeManagedSection(fenced, []Suggestion{first})
+	if strings.Count(out, ManagedSectionHeading) != 2 {
+		t.Fatalf("expected heading in code fence to be ignored:\n%s", out)
+	}
+}
+
+func TestBuildSuggestionPatch(t *testing.T) {
+	repo := t.TempDir()
+	suggestions := []Suggestion{{ID: "suggest:sha256:aa", Text: "You MUST keep instructions short."}}
+
+	patch, err := BuildSuggestionPatch(repo, instructions.ClientClaude, suggestions)
+	if err != nil {
+		t.Fatalf("BuildSuggestionPatch: %v", err)
+	}
+	if patch.Path != "CLAUDE.md" || !patch.Created {
+		t.Fatalf("unexpected patch target: %+v", patch)
+	}
+	if !strings.HasPrefix(patch.Diff, "--- /dev/null\n+++ b/CLAUDE.md\n@@ -0,0 +1,4 @@\n+## markdowntown suggestions\n") {
+		t.Fatalf("unexpected diff:\n%s", patch.Diff)
+	}
+
+	if err := os.MkdirAll(filepath.Join(repo, ".github"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	existing := "# Copilot\n\n## markdowntown suggestions\n\n<!-- markdowntown:suggestion id=suggest:sha256:aa -->\n- You MUST keep instructions short.\n"
+	if err := os.WriteFile(filepath.Join(repo, ".github", "copilot-instructions.md"), []byte(existing), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	patch, err = BuildSuggestionPatch(repo, instructions.ClientVSCode, suggestions)
+	if err != nil {
+		t.Fatalf("BuildSuggestionPatch: %v", err)
+	}
+	if patch.Diff != "" || patch.Created || len(patch.Added) != 0 {
+		t.Fatalf("expected empty patch, got %+v", patch)
+	}
+}
+
+func TestAcceptedSuggestions(t *testing.T) {
+	accepted := AcceptedSuggestions([]Suggestion{
+		{ID: "a"}, {ID: "b", Coverage: CoverageCovered}, {ID: "c", Coverage: CoveragePartial},
+		{ID: "d", Coverage: CoverageContradicted}, {ID: "e", Coverage: CoverageMissing},
+	})
+	var ids []string
+	for _, suggestion := range accepted {
+		ids = append(ids, suggestion.ID)
+	}
+	if strings.Join(ids, ",") != "a,c,e" {
+		t.Fatalf("unexpected accepted suggestions: %v", ids)
+	}
+}
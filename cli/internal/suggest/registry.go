@@ -37,19 +37,63 @@ type SourceRegistry struct {
 	Version        string   `json:"version"`
 	AllowlistHosts []string `json:"allowlistHosts"`
 	Sources        []Source `json:"sources"`
+	// Discovery scopes sources discover to path prefixes per client and host.
+	Discovery []DiscoveryRule `json:"discovery,omitempty"`
 }
 
-// Source defines a documentation source entry.
+// Source defines a documentation source entry. Local sources set Path (a
+// Markdown/HTML file or a directory of them) or a file:// URL instead of an
+// HTTPS URL.
 type Source struct {
 	ID           string   `json:"id"`
 	Tier         string   `json:"tier"`
 	Client       string   `json:"client"`
-	URL          string   `json:"url"`
-	RefreshHours int      `json:"refreshHours"`
+	URL          string   `json:"url,omitempty"`
+	Path         string   `json:"path,omitempty"`
+	RefreshHours int      `json:"refreshHours,omitempty"`
 	Tags         []string `json:"tags,omitempty"`
 	Notes        string   `json:"notes,omitempty"`
 }
 
+// Local reports whether the source is read from the filesystem rather than
+// fetched.
+func (s Source) Local() bool {
+	if s.Path != "" {
+		return true
+	}
+	parsed, err := url.Parse(s.URL)
+	return err == nil && strings.EqualFold(parsed.Scheme, "file")
+}
+
+// LocalPath returns the filesystem path of a local source, or "" for web
+// sources.
+func (s Source) LocalPath() string {
+	if s.Path != "" {
+		return s.Path
+	}
+	parsed, err := url.Parse(s.URL)
+	if err != nil || !strings.EqualFold(parsed.Scheme, "file") {
+		return ""
+	}
+	local := parsed.Path
+	// file:///C:/docs names a Windows drive path.
+	if len(local) > 2 && local[0] == '/' && local[2] == ':' {
+		local = local[1:]
+	}
+	return filepath.FromSlash(local)
+}
+
+// DiscoveryRule lists the path prefixes on an allowlisted host whose pages
+// are proposed as sources for a client. Tier and RefreshHours are the
+// defaults for proposed entries.
+type DiscoveryRule struct {
+	Client       string   `json:"client"`
+	Host         string   `json:"host"`
+	PathPrefixes []string `json:"pathPrefixes"`
+	Tier         string   `json:"tier,omitempty"`
+	RefreshHours int      `json:"refreshHours,omitempty"`
+}
+
 // LoadSources reads and validates the registry JSON from the resolved path.
 func LoadSources() (SourceRegistry, string, error) {
 	path, err := ResolveSourcesPath()
@@ -66,6 +110,9 @@ func LoadSources() (SourceRegistry, string, error) {
 	if err := json.Unmarshal(data, &reg); err != nil {
 		return SourceRegistry{}, "", fmt.Errorf("parse sources: %w", err)
 	}
+	if err := resolveSourcePaths(&reg, filepath.Dir(path)); err != nil {
+		return SourceRegistry{}, "", err
+	}
 
 	if err := ValidateSources(reg); err != nil {
 		return SourceRegistry{}, "", err
@@ -74,6 +121,26 @@ func LoadSources() (SourceRegistry, string, error) {
 	return reg, path, nil
 }
 
+// resolveSourcePaths expands "~" in local source paths and makes relative
+// paths relative to the registry directory.
+func resolveSourcePaths(reg *SourceRegistry, dir string) error {
+	for i := range reg.Sources {
+		src := &reg.Sources[i]
+		if strings.TrimSpace(src.Path) == "" {
+			continue
+		}
+		expanded, err := expandHome(src.Path)
+		if err != nil {
+			return fmt.Errorf("source %s path: %w", src.ID, err)
+		}
+		if !filepath.IsAbs(expanded) {
+			expanded = filepath.Join(dir, expanded)
+		}
+		src.Path = filepath.Clean(expanded)
+	}
+	return nil
+}
+
 // ReadSourcesFile reads the raw registry JSON from disk.
 func ReadSourcesFile(path string) ([]byte, error) {
 	// #nosec G304 -- source registry path comes from env override or well-known locations.
@@ -200,6 +267,21 @@ func ValidateSources(reg SourceRegistry) error {
 			return fmt.Errorf("source %s has invalid tier: %s", src.ID, src.Tier)
 		}
 
+		if src.Path != "" && src.URL != "" {
+			return fmt.Errorf("source %s sets both url and path", src.ID)
+		}
+		if src.Local() {
+			if err := validateLocalSource(src); err != nil {
+				return err
+			}
+			location := src.LocalPath()
+			if _, exists := seenURLs[location]; exists {
+				return fmt.Errorf("duplicate source path: %s", location)
+			}
+			seenURLs[location] = struct{}{}
+			continue
+		}
+
 		if strings.TrimSpace(src.URL) == "" {
 			return fmt.Errorf("source %s missing url", src.ID)
 		}
@@ -234,6 +316,51 @@ func ValidateSources(reg SourceRegistry) error {
 		}
 	}
 
+	for i, rule := range reg.Discovery {
+		if strings.TrimSpace(rule.Client) == "" {
+			return fmt.Errorf("discovery rule %d missing client", i)
+		}
+		if _, ok := allowlist[strings.ToLower(strings.TrimSpace(rule.Host))]; !ok {
+			return fmt.Errorf("discovery rule %d host not allowlisted: %s", i, rule.Host)
+		}
+		if len(rule.PathPrefixes) == 0 {
+			return fmt.Errorf("discovery rule %d missing pathPrefixes", i)
+		}
+		for _, prefix := range rule.PathPrefixes {
+			if !strings.HasPrefix(prefix, "/") || strings.Contains(prefix, "..") {
+				return fmt.Errorf("discovery rule %d has invalid path prefix: %s", i, prefix)
+			}
+		}
+		if rule.Tier != "" && !validTier(rule.Tier) {
+			return fmt.Errorf("discovery rule %d has invalid tier: %s", i, rule.Tier)
+		}
+		if rule.RefreshHours < 0 {
+			return fmt.Errorf("discovery rule %d has negative refreshHours", i)
+		}
+	}
+
+	return nil
+}
+
+// validateLocalSource checks a path or file:// source. Local sources are not
+// fetched, so the allowlist and refreshHours do not apply.
+func validateLocalSource(src Source) error {
+	if src.Path != "" {
+		if strings.TrimSpace(src.Path) == "" || strings.ContainsRune(src.Path, 0) {
+			return fmt.Errorf("source %s has invalid path", src.ID)
+		}
+		return nil
+	}
+	parsed, err := url.Parse(src.URL)
+	if err != nil {
+		return fmt.Errorf("source %s has invalid url: %w", src.ID, err)
+	}
+	if host := strings.ToLower(parsed.Host); host != "" && host != "localhost" {
+		return fmt.Errorf("source %s file url must not name a remote host: %s", src.ID, parsed.Host)
+	}
+	if !strings.HasPrefix(parsed.Path, "/") {
+		return fmt.Errorf("source %s file url must be absolute: %s", src.ID, src.URL)
+	}
 	return nil
 }
 
@@ -327,3 +327,68 @@ func TestResolveSourcesPathSkipsDirectoryCandidate(t *testing.T) {
 		t.Fatalf("expected ErrSourcesNotFound, got %v", err)
 	}
 }
+
+func TestValidateSourcesLocalSources(t *testing.T) {
+	web := Source{ID: "web", Tier: "tier-0", Client: "codex", URL: "https://example.com/docs", RefreshHours: 24}
+	cases := []struct {
+		name    string
+		source  Source
+		wantErr string
+	}{
+		{name: "path", source: Source{ID: "handbook", Tier: "tier-1", Client: "codex", Path: "/srv/handbook"}},
+		{name: "file url", source: Source{ID: "handbook", Tier: "tier-1", Client: "codex", URL: "file:///srv/handbook/agents.md"}},
+		{name: "localhost file url", source: Source{ID: "handbook", Tier: "tier-1", Client: "codex", URL: "file://localhost/srv/handbook"}},
+		{name: "remote file host", source: Source{ID: "handbook", Tier: "tier-1", Client: "codex", URL: "file://fileserver/handbook"}, wantErr: "remote host"},
+		{name: "url and path", source: Source{ID: "handbook", Tier: "tier-1", Client: "codex", URL: "https://example.com/x", Path: "/srv/handbook"}, wantErr: "both url and path"},
+		{name: "blank path", source: Source{ID: "handbook", Tier: "tier-1", Client: "codex", Path: "  "}, wantErr: "invalid path"},
+		{name: "invalid tier", source: Source{ID: "handbook", Tier: "tier-9", Client: "codex", Path: "/srv/handbook"}, wantErr: "invalid tier"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := SourceRegistry{Version: "1.0", AllowlistHosts: []string{"example.com"}, Sources: []Source{web, tc.source}}
+			err := ValidateSources(reg)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected valid registry, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	duplicate := SourceRegistry{Version: "1.0", AllowlistHosts: []string{"example.com"}, Sources: []Source{
+		{ID: "a", Tier: "tier-1", Client: "codex", Path: "/srv/handbook"},
+		{ID: "b", Tier: "tier-1", Client: "codex", URL: "file:///srv/handbook"},
+	}}
+	if err := ValidateSources(duplicate); err == nil || !strings.Contains(err.Error(), "duplicate source path") {
+		t.Fatalf("expected duplicate path error, got %v", err)
+	}
+}
+
+func TestLoadSourcesResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doc-sources.json")
+	payload := `{
+  "version": "1.0",
+  "allowlistHosts": ["example.com"],
+  "sources": [
+    {"id":"handbook","tier":"tier-1","client":"codex","path":"handbook/eng"}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(payload), 0o600); err != nil {
+		t.Fatalf("write sources: %v", err)
+	}
+	t.Setenv(SourcesEnvVar, path)
+
+	reg, _, err := LoadSources()
+	if err != nil {
+		t.Fatalf("LoadSources: %v", err)
+	}
+	expected := filepath.Join(dir, "handbook", "eng")
+	if reg.Sources[0].Path != expected || !reg.Sources[0].Local() || reg.Sources[0].LocalPath() != expected {
+		t.Fatalf("expected path %s, got %#v", expected, reg.Sources[0])
+	}
+}
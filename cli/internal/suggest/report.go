@@ -9,12 +9,47 @@ type Report struct {
 	Suggestions []Suggestion        `json:"suggestions,omitempty"`
 	Conflicts   []Conflict          `json:"conflicts,omitempty"`
 	Omissions   []Omission          `json:"omissions,omitempty"`
+	Gaps        *GapSummary         `json:"gaps,omitempty"`
 	Warnings    []string            `json:"warnings,omitempty"`
 }
 
+// MultiClientReport captures suggest output for several clients built from
+// one fetch pass. Warnings holds run-wide warnings; per-source warnings stay
+// on the report of the client that owns the source.
+type MultiClientReport struct {
+	GeneratedAt          int64                          `json:"generatedAt"`
+	Reports              map[instructions.Client]Report `json:"reports"`
+	CrossClientConflicts []Conflict                     `json:"crossClientConflicts,omitempty"`
+	Warnings             []string                       `json:"warnings,omitempty"`
+}
+
 // ResolveReport captures resolve output.
 type ResolveReport struct {
 	Client      instructions.Client     `json:"client"`
 	GeneratedAt int64                   `json:"generatedAt"`
 	Resolution  instructions.Resolution `json:"resolution"`
 }
+
+// ChangeReport captures claim changes between stored snapshots.
+type ChangeReport struct {
+	Client      instructions.Client `json:"client"`
+	GeneratedAt int64               `json:"generatedAt"`
+	Since       string              `json:"since"`
+	Sources     []SourceChanges     `json:"sources"`
+	// Unreflected lists new or strengthened tier-0 MUST claims the repo's
+	// instructions do not cover yet.
+	Unreflected []ClaimChange `json:"unreflected,omitempty"`
+	Warnings    []string      `json:"warnings,omitempty"`
+}
+
+// SourceChanges lists claim changes for one source between two snapshots.
+type SourceChanges struct {
+	SourceID       string        `json:"sourceId"`
+	URL            string        `json:"url"`
+	Tier           string        `json:"tier"`
+	BaseSnapshot   string        `json:"baseSnapshot"`
+	BaseRecordedAt int64         `json:"baseRecordedAt"`
+	HeadSnapshot   string        `json:"headSnapshot"`
+	HeadRecordedAt int64         `json:"headRecordedAt"`
+	Changes        []ClaimChange `json:"changes"`
+}
@@ -0,0 +1,145 @@
+package suggest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	scanhash "markdowntown-cli/internal/hash"
+)
+
+// WARCSnapshots replays the response records of a WARC archive as a Cache.
+// When a URL was recorded more than once, the latest record wins.
+type WARCSnapshots struct {
+	entries  map[string]warcSnapshot
+	warnings []string
+}
+
+type warcSnapshot struct {
+	date time.Time
+	body []byte
+}
+
+// LoadWARCSnapshots reads every response record from r.
+func LoadWARCSnapshots(r io.Reader) (*WARCSnapshots, error) {
+	reader, err := NewWARCReader(r)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := &WARCSnapshots{entries: make(map[string]warcSnapshot)}
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(record.Type, "response") || record.TargetURI == "" {
+			continue
+		}
+		body, err := ResponseBody(record)
+		if err != nil {
+			snapshots.warnings = append(snapshots.warnings, fmt.Sprintf("warc %s skipped: %v", record.TargetURI, err))
+			continue
+		}
+		if existing, ok := snapshots.entries[record.TargetURI]; ok && existing.date.After(record.Date) {
+			continue
+		}
+		snapshots.entries[record.TargetURI] = warcSnapshot{date: record.Date, body: body}
+	}
+	return snapshots, nil
+}
+
+// Get returns the recorded body for url.
+func (s *WARCSnapshots) Get(url string) ([]byte, bool) {
+	entry, ok := s.entries[url]
+	if !ok {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// URLs returns the recorded target URLs in sorted order.
+func (s *WARCSnapshots) URLs() []string {
+	urls := make([]string, 0, len(s.entries))
+	for url := range s.entries {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// RecordedAt returns the WARC-Date of the snapshot for url.
+func (s *WARCSnapshots) RecordedAt(url string) (time.Time, bool) {
+	entry, ok := s.entries[url]
+	return entry.date, ok
+}
+
+// Warnings lists response records that could not be replayed.
+func (s *WARCSnapshots) Warnings() []string {
+	return s.warnings
+}
+
+// ResponseBody extracts the document body from a response record. Records
+// typed application/http are parsed as HTTP responses and must be 2xx; any
+// other payload is returned as-is.
+func ResponseBody(record WARCRecord) ([]byte, error) {
+	if !strings.HasPrefix(strings.ToLower(record.ContentType), "application/http") {
+		return record.Payload, nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(record.Payload)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse http response: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read http body: %w", err)
+	}
+	return body, nil
+}
+
+// WriteSnapshotResponse records body as a 200 response for url. The record
+// ID is derived from the URL and body so exporting the same snapshot twice
+// produces identical archives.
+func (w *WARCWriter) WriteSnapshotResponse(url string, fetchedAt time.Time, body []byte) (RecordInfo, error) {
+	var payload bytes.Buffer
+	payload.WriteString("HTTP/1.1 200 OK\r\n")
+	fmt.Fprintf(&payload, "Content-Type: %s\r\n", snapshotContentType(url))
+	fmt.Fprintf(&payload, "Content-Length: %d\r\n", len(body))
+	payload.WriteString("\r\n")
+	payload.Write(body)
+
+	return w.WriteRecord(WARCRecord{
+		Type:        "response",
+		TargetURI:   url,
+		Date:        fetchedAt.UTC().Truncate(time.Second),
+		RecordID:    snapshotRecordID(url, body),
+		ContentType: "application/http; msgtype=response",
+		Payload:     payload.Bytes(),
+	})
+}
+
+func snapshotContentType(url string) string {
+	lower := strings.ToLower(url)
+	if strings.HasSuffix(lower, ".html") || strings.HasSuffix(lower, ".htm") {
+		return "text/html; charset=utf-8"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+func snapshotRecordID(url string, body []byte) string {
+	hex := scanhash.SumHex([]byte(url + "\n" + scanhash.SumHex(body)))
+	return fmt.Sprintf("<urn:uuid:%s-%s-%s-%s-%s>", hex[0:8], hex[8:12], hex[12:16], hex[16:20], hex[20:32])
+}
@@ -0,0 +1,76 @@
+package suggest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadWARCSnapshots(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := NewWARCWriter(buf)
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+
+	if _, err := writer.WriteSnapshotResponse("https://example.com/docs.md", newer, []byte("new body")); err != nil {
+		t.Fatalf("write newer: %v", err)
+	}
+	if _, err := writer.WriteSnapshotResponse("https://example.com/docs.md", older, []byte("old body")); err != nil {
+		t.Fatalf("write older: %v", err)
+	}
+	if _, err := writer.WriteRecord(WARCRecord{
+		Type:        "response",
+		TargetURI:   "https://example.com/missing",
+		Date:        older,
+		ContentType: "application/http; msgtype=response",
+		Payload:     []byte("HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n"),
+	}); err != nil {
+		t.Fatalf("write 404: %v", err)
+	}
+	if _, err := writer.WriteRecord(WARCRecord{Type: "resource", TargetURI: "https://example.com/raw", Date: older, ContentType: "text/plain", Payload: []byte("raw")}); err != nil {
+		t.Fatalf("write resource: %v", err)
+	}
+
+	snapshots, err := LoadWARCSnapshots(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	body, ok := snapshots.Get("https://example.com/docs.md")
+	if !ok || string(body) != "new body" {
+		t.Fatalf("expected newest body, got %q (%v)", body, ok)
+	}
+	if recordedAt, _ := snapshots.RecordedAt("https://example.com/docs.md"); !recordedAt.Equal(newer) {
+		t.Fatalf("unexpected recorded time: %v", recordedAt)
+	}
+	if _, ok := snapshots.Get("https://example.com/missing"); ok {
+		t.Fatalf("expected non-2xx response to be skipped")
+	}
+	if _, ok := snapshots.Get("https://example.com/raw"); ok {
+		t.Fatalf("expected non-response record to be ignored")
+	}
+	if urls := snapshots.URLs(); len(urls) != 1 {
+		t.Fatalf("unexpected urls: %v", urls)
+	}
+	if warnings := snapshots.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "status 404") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestWriteSnapshotResponseDeterministic(t *testing.T) {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC)
+	render := func() []byte {
+		buf := &bytes.Buffer{}
+		if _, err := NewWARCWriter(buf).WriteSnapshotResponse("https://example.com/page.html", at, []byte("<p>hi</p>")); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		return buf.Bytes()
+	}
+	first := render()
+	if !bytes.Equal(first, render()) {
+		t.Fatalf("expected identical archives")
+	}
+	if !bytes.Contains(first, []byte("Content-Type: text/html; charset=utf-8")) {
+		t.Fatalf("expected html content type in payload:\n%s", first)
+	}
+}
@@ -0,0 +1,124 @@
+package suggest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"markdowntown-cli/internal/instructions"
+)
+
+//go:embed templates/*.md
+var clientTemplateFS embed.FS
+
+// TemplateData is the value suggestion templates execute against.
+type TemplateData struct {
+	Client      string
+	GeneratedAt int64
+	Suggestions []Suggestion
+	Conflicts   []Conflict
+	Omissions   []Omission
+	Gaps        *GapSummary
+}
+
+// SuggestionTemplate renders suggestions as Markdown for one client.
+type SuggestionTemplate struct {
+	tmpl *template.Template
+}
+
+// ClientTemplate returns the embedded template for client.
+func ClientTemplate(client instructions.Client) (*SuggestionTemplate, error) {
+	data, err := clientTemplateFS.ReadFile("templates/" + string(client) + ".md")
+	if err != nil {
+		return nil, fmt.Errorf("no suggestion template for client %q", client)
+	}
+	return ParseSuggestionTemplate(string(client), string(data))
+}
+
+// ParseSuggestionTemplate parses a text/template with the suggestion helpers.
+func ParseSuggestionTemplate(name, text string) (*SuggestionTemplate, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs()).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &SuggestionTemplate{tmpl: tmpl}, nil
+}
+
+// Render executes the template.
+func (t *SuggestionTemplate) Render(data TemplateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderSuggestions applies the embedded template for client.
+func RenderSuggestions(client instructions.Client, data TemplateData) (string, error) {
+	tmpl, err := ClientTemplate(client)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.Render(data)
+}
+
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"join":       strings.Join,
+		"oneLine":    oneLine,
+		"sourceURLs": sourceURLs,
+		"link":       markdownLink,
+		"spans":      proofSpans,
+		"evidence":   evidenceRef,
+		"provenance": localProvenance,
+	}
+}
+
+// oneLine collapses whitespace so multi-line claims fit in a list item.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// sourceURLs returns the suggestion's source URLs, falling back to the proof
+// sources when the suggestion carries none.
+func sourceURLs(suggestion Suggestion) []string {
+	if len(suggestion.Sources) > 0 {
+		return suggestion.Sources
+	}
+	return uniqueSorted(suggestion.Proof.Sources)
+}
+
+func markdownLink(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	return "<" + rawURL + ">"
+}
+
+// proofSpans formats each proof span as "section:start-end".
+func proofSpans(suggestion Suggestion) []string {
+	spans := make([]string, 0, len(suggestion.Proof.Spans))
+	for _, span := range suggestion.Proof.Spans {
+		spans = append(spans, fmt.Sprintf("%s:%d-%d", span.SectionID, span.Start, span.End))
+	}
+	return spans
+}
+
+// localProvenance returns the provenance of suggestions backed by local
+// sources, or "" for web-only suggestions.
+func localProvenance(suggestion Suggestion) string {
+	if suggestion.Provenance == ProvenanceLocal || suggestion.Provenance == ProvenanceMixed {
+		return string(suggestion.Provenance)
+	}
+	return ""
+}
+
+// evidenceRef formats coverage evidence as "path:line", or "" without it.
+func evidenceRef(evidence *CoverageEvidence) string {
+	if evidence == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", evidence.Path, evidence.Line)
+}
@@ -0,0 +1,83 @@
+package suggest
+
+import (
+	"strings"
+	"testing"
+
+	"markdowntown-cli/internal/instructions"
+)
+
+func TestClientTemplates(t *testing.T) {
+	suggestion := Suggestion{
+		ID:      "s1",
+		Text:    "Keep instructions\n  short.",
+		Sources: []string{"https://example.com/docs"},
+		Proof:   Proof{Spans: []ProofSpan{{SectionID: "intro", Start: 4, End: 30}}},
+	}
+	for _, client := range instructions.AllClients() {
+		output, err := RenderSuggestions(client, TemplateData{Client: string(client), Suggestions: []Suggestion{suggestion}})
+		if err != nil {
+			t.Fatalf("%s: render: %v", client, err)
+		}
+		if !strings.Contains(output, "- Keep instructions short.") {
+			t.Fatalf("%s: expected collapsed suggestion text, got:\n%s", client, output)
+		}
+		if !strings.Contains(output, "https://example.com/docs") || !strings.Contains(output, "intro:4-30") {
+			t.Fatalf("%s: expected source URL and proof span, got:\n%s", client, output)
+		}
+
+		if strings.Contains(output, "rovenance") {
+			t.Fatalf("%s: expected no provenance for web suggestions, got:\n%s", client, output)
+		}
+		local := suggestion
+		local.Provenance = ProvenanceLocal
+		output, err = RenderSuggestions(client, TemplateData{Client: string(client), Suggestions: []Suggestion{local}})
+		if err != nil {
+			t.Fatalf("%s: render local: %v", client, err)
+		}
+		if !strings.Contains(output, "rovenance: local") {
+			t.Fatalf("%s: expected local provenance, got:\n%s", client, output)
+		}
+
+		empty, err := RenderSuggestions(client, TemplateData{Client: string(client)})
+		if err != nil {
+			t.Fatalf("%s: render empty: %v", client, err)
+		}
+		if !strings.Contains(empty, "_No suggestions available._") {
+			t.Fatalf("%s: expected empty placeholder, got:\n%s", client, empty)
+		}
+	}
+
+	copilot, err := RenderSuggestions(instructions.ClientCopilot, TemplateData{Client: "copilot"})
+	if err != nil {
+		t.Fatalf("render copilot: %v", err)
+	}
+	if !strings.HasPrefix(copilot, "---\napplyTo: \"**\"\n---\n") {
+		t.Fatalf("expected copilot applyTo frontmatter, got:\n%s", copilot)
+	}
+}
+
+func TestParseSuggestionTemplate(t *testing.T) {
+	tmpl, err := ParseSuggestionTemplate("custom", `{{ range .Suggestions }}{{ .ID }} {{ evidence .Evidence }} {{ link (index (sourceURLs .) 0) }}{{ end }}`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	output, err := tmpl.Render(TemplateData{Suggestions: []Suggestion{{
+		ID:       "s1",
+		Proof:    Proof{Sources: []string{"https://b.example", "https://a.example"}},
+		Evidence: &CoverageEvidence{Path: "AGENTS.md", Line: 3},
+	}}})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if output != "s1 AGENTS.md:3 <https://a.example>" {
+		t.Fatalf("unexpected output: %q", output)
+	}
+
+	if _, err := ParseSuggestionTemplate("bad", "{{ .Suggestions"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	if _, err := ClientTemplate(instructions.Client("unknown")); err == nil {
+		t.Fatalf("expected error for unknown client")
+	}
+}
@@ -1,10 +1,13 @@
 package suggest
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"crypto/rand"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -107,6 +110,138 @@ func (w *WARCWriter) WriteMetadata(targetURL string, payload []byte) (RecordInfo
 	})
 }
 
+// DefaultMaxWARCRecordBytes caps a single record payload when
+// WARCReader.MaxRecordBytes is unset.
+const DefaultMaxWARCRecordBytes int64 = 64 << 20
+
+// WARCReader reads WARC 1.0/1.1 records, including per-record gzip archives.
+type WARCReader struct {
+	// MaxRecordBytes caps the Content-Length of a record. Zero means
+	// DefaultMaxWARCRecordBytes.
+	MaxRecordBytes int64
+
+	r      *bufio.Reader
+	record int
+}
+
+// NewWARCReader returns a reader over r. Gzip input (.warc.gz) is detected
+// from its magic bytes.
+func NewWARCReader(r io.Reader) (*WARCReader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("open gzip warc: %w", err)
+		}
+		buffered = bufio.NewReader(gz)
+	}
+	return &WARCReader{r: buffered}, nil
+}
+
+// Next returns the next record, or io.EOF when the archive is exhausted.
+func (r *WARCReader) Next() (WARCRecord, error) {
+	version, err := r.readVersion()
+	if err != nil {
+		return WARCRecord{}, err
+	}
+	r.record++
+	if !strings.HasPrefix(version, "WARC/") {
+		return WARCRecord{}, fmt.Errorf("warc record %d: unexpected version line %q", r.record, version)
+	}
+
+	var record WARCRecord
+	length := int64(-1)
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return WARCRecord{}, fmt.Errorf("warc record %d: read headers: %w", r.record, unexpectedEOF(err))
+		}
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return WARCRecord{}, fmt.Errorf("warc record %d: malformed header %q", r.record, line)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "warc-type":
+			record.Type = value
+		case "warc-target-uri":
+			record.TargetURI = strings.Trim(value, "<>")
+		case "warc-date":
+			date, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return WARCRecord{}, fmt.Errorf("warc record %d: invalid WARC-Date %q", r.record, value)
+			}
+			record.Date = date
+		case "warc-record-id":
+			record.RecordID = value
+		case "content-type":
+			record.ContentType = value
+		case "content-length":
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed < 0 {
+				return WARCRecord{}, fmt.Errorf("warc record %d: invalid Content-Length %q", r.record, value)
+			}
+			length = parsed
+		}
+	}
+	if length < 0 {
+		return WARCRecord{}, fmt.Errorf("warc record %d: missing Content-Length", r.record)
+	}
+
+	limit := r.MaxRecordBytes
+	if limit <= 0 {
+		limit = DefaultMaxWARCRecordBytes
+	}
+	if length > limit {
+		return WARCRecord{}, fmt.Errorf("warc record %d: Content-Length %d exceeds limit of %d bytes", r.record, length, limit)
+	}
+
+	// Grow the payload as bytes arrive instead of trusting Content-Length for
+	// the allocation.
+	payload, err := io.ReadAll(io.LimitReader(r.r, length))
+	if err != nil {
+		return WARCRecord{}, fmt.Errorf("warc record %d: read payload: %w", r.record, err)
+	}
+	if int64(len(payload)) < length {
+		return WARCRecord{}, fmt.Errorf("warc record %d: read payload: %w", r.record, io.ErrUnexpectedEOF)
+	}
+	record.Payload = payload
+	return record, nil
+}
+
+// readVersion skips the blank lines separating records and returns the
+// version line.
+func (r *WARCReader) readVersion() (string, error) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return "", err
+		}
+		if line != "" {
+			return line, nil
+		}
+	}
+}
+
+func (r *WARCReader) readLine() (string, error) {
+	line, err := r.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
 // SnapshotIndexEntry records snapshot metadata for audit.
 type SnapshotIndexEntry struct {
 	ID        string `json:"id"`
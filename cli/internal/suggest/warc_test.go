@@ -2,7 +2,9 @@ package suggest
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -91,3 +93,89 @@ func TestWARCWriterMetadata(t *testing.T) {
 		t.Fatalf("expected json content type")
 	}
 }
+
+func TestWARCReaderRoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := NewWARCWriter(buf)
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := writer.WriteRecord(WARCRecord{Type: "response", TargetURI: "https://example.com/a", Date: date, ContentType: "text/plain", Payload: []byte("alpha\r\n\r\nbody")}); err != nil {
+		t.Fatalf("write response: %v", err)
+	}
+	if _, err := writer.WriteMetadata("https://example.com/a", []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("write metadata: %v", err)
+	}
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write(buf.Bytes()); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+
+	for name, input := range map[string][]byte{"plain": buf.Bytes(), "gzip": gz.Bytes()} {
+		reader, err := NewWARCReader(bytes.NewReader(input))
+		if err != nil {
+			t.Fatalf("%s: new reader: %v", name, err)
+		}
+		first, err := reader.Next()
+		if err != nil {
+			t.Fatalf("%s: read first: %v", name, err)
+		}
+		if first.Type != "response" || first.TargetURI != "https://example.com/a" || !first.Date.Equal(date) || string(first.Payload) != "alpha\r\n\r\nbody" {
+			t.Fatalf("%s: unexpected first record: %+v", name, first)
+		}
+		second, err := reader.Next()
+		if err != nil {
+			t.Fatalf("%s: read second: %v", name, err)
+		}
+		if second.Type != "metadata" || string(second.Payload) != `{"ok":true}` {
+			t.Fatalf("%s: unexpected second record: %+v", name, second)
+		}
+		if _, err := reader.Next(); err != io.EOF {
+			t.Fatalf("%s: expected EOF, got %v", name, err)
+		}
+	}
+}
+
+func TestWARCReaderErrors(t *testing.T) {
+	cases := map[string]string{
+		"version":   "HTTP/1.1 200 OK\r\n\r\n",
+		"length":    "WARC/1.1\r\nWARC-Type: response\r\n\r\n",
+		"truncated": "WARC/1.1\r\nWARC-Type: response\r\nContent-Length: 10\r\n\r\nabc",
+		"date":      "WARC/1.1\r\nWARC-Date: yesterday\r\nContent-Length: 0\r\n\r\n",
+		"oversized": "WARC/1.1\r\nWARC-Type: response\r\nContent-Length: 9223372036854775807\r\n\r\nabc",
+	}
+	for name, input := range cases {
+		reader, err := NewWARCReader(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("%s: new reader: %v", name, err)
+		}
+		if _, err := reader.Next(); err == nil || err == io.EOF {
+			t.Fatalf("%s: expected error, got %v", name, err)
+		}
+	}
+}
+
+func TestWARCReaderMaxRecordBytes(t *testing.T) {
+	input := "WARC/1.1\r\nWARC-Type: response\r\nContent-Length: 5\r\n\r\nhello\r\n\r\n"
+	reader, err := NewWARCReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	reader.MaxRecordBytes = 4
+	if _, err := reader.Next(); err == nil || !strings.Contains(err.Error(), "exceeds limit") {
+		t.Fatalf("expected limit error, got %v", err)
+	}
+
+	reader, err = NewWARCReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	reader.MaxRecordBytes = 5
+	record, err := reader.Next()
+	if err != nil || string(record.Payload) != "hello" {
+		t.Fatalf("expected record at the limit, got %q %v", record.Payload, err)
+	}
+}
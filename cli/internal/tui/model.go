@@ -217,7 +217,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.searchCancel = cancel
 
 		cmd = func() tea.Msg {
-			results, _ := context_pkg.SearchInstructions(ctx, m.repoRoot, m.registry, msg.Query)
+			results, _ := context_pkg.SearchInstructions(ctx, nil, m.repoRoot, m.registry, msg.Query)
 			return SearchResultsMsg{Results: results, Generation: msg.Generation}
 		}
 	case SearchResultsMsg:
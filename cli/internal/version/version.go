@@ -8,4 +8,6 @@ const (
 	SchemaVersion = "1.0.0"
 	// AuditSchemaVersion is the audit output schema version.
 	AuditSchemaVersion = "audit-spec-v1"
+	// FleetSchemaVersion is the scan-fleet report schema version.
+	FleetSchemaVersion = "fleet-spec-v1"
 )
@@ -10,10 +10,30 @@ import (
 
 	"markdowntown-cli/internal/audit"
 	"markdowntown-cli/internal/engine"
+	"markdowntown-cli/internal/scan"
 	"markdowntown-cli/internal/version"
 )
 
 func (s *Server) runAudit(ctx context.Context, req AuditRequest) (AuditResult, *workerError) {
+	if req.RepoRoot != "" && req.Archive != nil {
+		return AuditResult{}, newWorkerError(ErrCodeInvalidRequest, "repoRoot and archive are mutually exclusive", http.StatusBadRequest, nil)
+	}
+	if req.Scan.SchemaVersion == "" && (req.RepoRoot != "" || req.Archive != nil) {
+		if s.registry.Version == "" {
+			return AuditResult{}, newWorkerError(ErrCodeConfig, "registry version missing", http.StatusInternalServerError, nil)
+		}
+		var output scan.Output
+		var werr *workerError
+		if req.Archive != nil {
+			output, werr = s.scanArchive(ctx, *req.Archive)
+		} else {
+			output, werr = s.scanRepo(ctx, req.RepoRoot)
+		}
+		if werr != nil {
+			return AuditResult{}, werr
+		}
+		req.Scan = output
+	}
 	if req.Scan.SchemaVersion == "" {
 		return AuditResult{}, newWorkerError(ErrCodeInvalidRequest, "scan schemaVersion is required", http.StatusBadRequest, nil)
 	}
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"markdowntown-cli/internal/scan"
+	"markdowntown-cli/internal/version"
+)
+
+// scanRepo scans a repository on the worker host so audit requests can name a
+// path instead of shipping scan output. Only paths that resolve under
+// Config.ScanRoots are accepted, and the scan stops when the request deadline passes.
+func (s *Server) scanRepo(ctx context.Context, repoRoot string) (scan.Output, *workerError) {
+	if len(s.scanRoots) == 0 {
+		return scan.Output{}, newWorkerError(ErrCodeInvalidRequest, "repoRoot scans are disabled on this worker", http.StatusBadRequest, nil)
+	}
+	if !filepath.IsAbs(repoRoot) {
+		return scan.Output{}, newWorkerError(ErrCodeInvalidRequest, "repoRoot must be an absolute path", http.StatusBadRequest, nil)
+	}
+	// Resolve symlinks before the root check so a link under an allowed root
+	// cannot point the scan elsewhere; the resolved path is what gets scanned.
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(repoRoot))
+	if err != nil {
+		return scan.Output{}, newWorkerError(ErrCodeInvalidRequest, fmt.Sprintf("repoRoot: %v", err), http.StatusBadRequest, nil)
+	}
+	repoRoot = resolved
+	if !s.scanRootAllowed(repoRoot) {
+		return scan.Output{}, newWorkerError(ErrCodeInvalidRequest, "repoRoot is outside the allowed scan roots", http.StatusBadRequest, nil)
+	}
+
+	startedAt := time.Now()
+	result, werr := s.scanContext(ctx, scan.Options{
+		RepoRoot:       repoRoot,
+		RepoOnly:       true,
+		IncludeContent: true,
+		Registry:       s.registry,
+	})
+	if werr != nil {
+		return scan.Output{}, werr
+	}
+	if updated, err := scan.ApplyGitignore(result, repoRoot); err == nil {
+		result = updated
+	}
+	return s.buildScanOutput(result, repoRoot, startedAt), nil
+}
+
+// archiveRoot is where uploaded archives are mounted; scan output paths are
+// reported under it.
+const archiveRoot = "/archive"
+
+// scanArchive scans an uploaded archive held in memory. Nothing is written to
+// the worker's disk, so it does not require Config.ScanRoots.
+func (s *Server) scanArchive(ctx context.Context, req ArchiveRequest) (scan.Output, *workerError) {
+	if len(req.Data) == 0 {
+		return scan.Output{}, newWorkerError(ErrCodeInvalidRequest, "archive data is required", http.StatusBadRequest, nil)
+	}
+	startedAt := time.Now()
+	archive, err := scan.LoadArchive(bytes.NewReader(req.Data), int64(len(req.Data)), scan.ArchiveOptions{
+		Format:          req.Format,
+		Root:            archiveRoot,
+		StripComponents: req.StripComponents,
+	})
+	if err != nil {
+		return scan.Output{}, newWorkerError(ErrCodeInvalidRequest, fmt.Sprintf("archive: %v", err), http.StatusBadRequest, nil)
+	}
+	result, werr := s.scanContext(ctx, scan.Options{
+		RepoRoot:       archive.Root,
+		RepoOnly:       true,
+		IncludeContent: true,
+		Registry:       s.registry,
+		Fs:             archive.Fs,
+	})
+	if werr != nil {
+		return scan.Output{}, werr
+	}
+	result = archive.ApplyGitignore(result)
+	return s.buildScanOutput(result, archive.Root, startedAt), nil
+}
+
+func (s *Server) scanContext(ctx context.Context, opts scan.Options) (scan.Result, *workerError) {
+	result, err := scan.ScanContext(ctx, opts, scan.Sink{})
+	if err != nil {
+		if ctx.Err() != nil {
+			return scan.Result{}, mapContextError(ctx.Err())
+		}
+		return scan.Result{}, newWorkerError(ErrCodeInternal, fmt.Sprintf("scan failed: %v", err), http.StatusInternalServerError, nil)
+	}
+	return result, nil
+}
+
+func (s *Server) buildScanOutput(result scan.Result, repoRoot string, startedAt time.Time) scan.Output {
+	finishedAt := time.Now()
+
+	totalMs := finishedAt.Sub(startedAt).Milliseconds()
+	return scan.BuildOutput(result, scan.OutputOptions{
+		SchemaVersion:   version.SchemaVersion,
+		RegistryVersion: s.registry.Version,
+		ToolVersion:     version.ToolVersion,
+		RepoRoot:        repoRoot,
+		ScanStartedAt:   startedAt.UnixMilli(),
+		GeneratedAt:     finishedAt.UnixMilli(),
+		Timing:          scan.Timing{DiscoveryMs: totalMs, TotalMs: totalMs},
+	})
+}
+
+func (s *Server) scanRootAllowed(repoRoot string) bool {
+	for _, root := range s.scanRoots {
+		rel, err := filepath.Rel(root, repoRoot)
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
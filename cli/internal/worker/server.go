@@ -7,6 +7,8 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"markdowntown-cli/internal/scan"
@@ -38,6 +40,9 @@ type Config struct {
 	Timeout      time.Duration
 	MaxBodyBytes int64
 	Logger       *log.Logger
+	// ScanRoots lists directories audit requests may scan via repoRoot.
+	// Empty disables server-side scans.
+	ScanRoots []string
 }
 
 // Server handles worker requests.
@@ -46,6 +51,7 @@ type Server struct {
 	timeout      time.Duration
 	maxBodyBytes int64
 	logger       *log.Logger
+	scanRoots    []string
 }
 
 // NewServer creates a worker server with defaults.
@@ -62,8 +68,20 @@ func NewServer(cfg Config) *Server {
 	if logger == nil {
 		logger = log.New(log.Writer(), "engine-worker: ", log.LstdFlags)
 	}
+	scanRoots := make([]string, 0, len(cfg.ScanRoots))
+	for _, root := range cfg.ScanRoots {
+		if root = strings.TrimSpace(root); root == "" {
+			continue
+		}
+		root = filepath.Clean(root)
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		scanRoots = append(scanRoots, root)
+	}
 	return &Server{
 		registry:     cfg.Registry,
+		scanRoots:    scanRoots,
 		timeout:      timeout,
 		maxBodyBytes: maxBody,
 		logger:       logger,
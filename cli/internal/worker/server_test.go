@@ -1,11 +1,14 @@
 package worker
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/json"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -263,3 +266,136 @@ func TestHandleRunSuggestMissingPayload(t *testing.T) {
 		t.Errorf("expected message about suggest payload, got: %s", resp.Error.Message)
 	}
 }
+
+func runAuditRepoRoot(t *testing.T, s *Server, repoRoot string) (int, RunResponse) {
+	t.Helper()
+	return runAuditRequest(t, s, &AuditRequest{RepoRoot: repoRoot})
+}
+
+func runAuditRequest(t *testing.T, s *Server, audit *AuditRequest) (int, RunResponse) {
+	t.Helper()
+	payload, err := json.Marshal(RunRequest{Type: RunTypeAudit, Audit: audit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/run", bytes.NewReader(payload))
+	w := httptest.NewRecorder()
+
+	s.HandleRun(w, req)
+
+	var resp RunResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	return w.Code, resp
+}
+
+func TestHandleRunAuditRepoRoot(t *testing.T) {
+	registry, _, err := scan.LoadRegistry()
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+	scanRoot := t.TempDir()
+	repoRoot := filepath.Join(scanRoot, "repo")
+	if err := os.MkdirAll(repoRoot, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, "AGENTS.md"), []byte("# Agents\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(Config{Registry: registry, ScanRoots: []string{scanRoot}})
+	code, resp := runAuditRepoRoot(t, s, repoRoot)
+	if code != http.StatusOK || !resp.Ok || resp.Audit == nil {
+		t.Fatalf("expected ok audit response, got %d %#v", code, resp.Error)
+	}
+	if resolved, err := filepath.EvalSymlinks(repoRoot); err == nil {
+		repoRoot = resolved
+	}
+	if resp.Audit.Output.SourceScan.RepoRoot != repoRoot {
+		t.Errorf("expected source scan repoRoot %q, got %q", repoRoot, resp.Audit.Output.SourceScan.RepoRoot)
+	}
+
+	// A configured root reached through a symlink still admits its repos.
+	linkRoot := filepath.Join(t.TempDir(), "roots")
+	if err := os.Symlink(scanRoot, linkRoot); err != nil {
+		t.Fatal(err)
+	}
+	s = NewServer(Config{Registry: registry, ScanRoots: []string{linkRoot}})
+	if code, resp := runAuditRepoRoot(t, s, filepath.Join(linkRoot, "repo")); code != http.StatusOK || !resp.Ok {
+		t.Fatalf("expected ok audit through symlinked root, got %d %#v", code, resp.Error)
+	}
+}
+
+func TestHandleRunAuditRepoRootRejected(t *testing.T) {
+	scanRoot := t.TempDir()
+	if err := os.Mkdir(filepath.Join(scanRoot, "allowed-not"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(scanRoot, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		scanRoots []string
+		repoRoot  string
+		message   string
+	}{
+		{name: "disabled", repoRoot: scanRoot, message: "disabled"},
+		{name: "relative", scanRoots: []string{scanRoot}, repoRoot: "repo", message: "absolute"},
+		{name: "outside", scanRoots: []string{filepath.Join(scanRoot, "allowed")}, repoRoot: filepath.Join(scanRoot, "allowed-not"), message: "outside"},
+		{name: "symlink escape", scanRoots: []string{scanRoot}, repoRoot: filepath.Join(scanRoot, "escape"), message: "outside"},
+		{name: "missing", scanRoots: []string{scanRoot}, repoRoot: filepath.Join(scanRoot, "missing"), message: "repoRoot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(Config{Registry: scan.Registry{Version: "test"}, ScanRoots: tt.scanRoots})
+			code, resp := runAuditRepoRoot(t, s, tt.repoRoot)
+			if code != http.StatusBadRequest || resp.Error == nil || resp.Error.Code != ErrCodeInvalidRequest {
+				t.Fatalf("expected invalid_request, got %d %#v", code, resp.Error)
+			}
+			if !strings.Contains(resp.Error.Message, tt.message) {
+				t.Errorf("expected message containing %q, got %q", tt.message, resp.Error.Message)
+			}
+		})
+	}
+}
+
+func TestHandleRunAuditArchive(t *testing.T) {
+	registry, _, err := scan.LoadRegistry()
+	if err != nil {
+		t.Fatalf("load registry: %v", err)
+	}
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	file, err := writer.Create("repo-main/AGENTS.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte("# Agents\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Archive audits do not need ScanRoots.
+	s := NewServer(Config{Registry: registry})
+	code, resp := runAuditRequest(t, s, &AuditRequest{Archive: &ArchiveRequest{Data: buf.Bytes(), StripComponents: 1}})
+	if code != http.StatusOK || !resp.Ok || resp.Audit == nil {
+		t.Fatalf("expected ok audit response, got %d %#v", code, resp.Error)
+	}
+	if resp.Audit.Output.SourceScan.RepoRoot != archiveRoot {
+		t.Errorf("expected source scan repoRoot %q, got %q", archiveRoot, resp.Audit.Output.SourceScan.RepoRoot)
+	}
+
+	code, resp = runAuditRequest(t, s, &AuditRequest{Archive: &ArchiveRequest{Data: []byte("not an archive")}})
+	if code != http.StatusBadRequest || resp.Error == nil || resp.Error.Code != ErrCodeInvalidRequest {
+		t.Fatalf("expected invalid_request for bad archive, got %d %#v", code, resp.Error)
+	}
+	code, resp = runAuditRequest(t, s, &AuditRequest{RepoRoot: "/srv/repo", Archive: &ArchiveRequest{Data: buf.Bytes()}})
+	if code != http.StatusBadRequest || resp.Error == nil || !strings.Contains(resp.Error.Message, "mutually exclusive") {
+		t.Fatalf("expected repoRoot and archive to conflict, got %d %#v", code, resp.Error)
+	}
+}
@@ -26,9 +26,13 @@ type RunRequest struct {
 	Suggest   *SuggestRequest `json:"suggest,omitempty"`
 }
 
-// AuditRequest describes an audit run input.
+// AuditRequest describes an audit run input. One of Scan, RepoRoot, or
+// Archive is required; RepoRoot scans a directory on the worker host and
+// Archive scans an uploaded tarball or zip.
 type AuditRequest struct {
 	Scan                scan.Output               `json:"scan"`
+	RepoRoot            string                    `json:"repoRoot,omitempty"`
+	Archive             *ArchiveRequest           `json:"archive,omitempty"`
 	RedactMode          audit.RedactMode          `json:"redactMode,omitempty"`
 	IncludeScanWarnings bool                      `json:"includeScanWarnings,omitempty"`
 	OnlyRules           []string                  `json:"onlyRules,omitempty"`
@@ -39,6 +43,15 @@ type AuditRequest struct {
 	XDGConfigHome       string                    `json:"xdgConfigHome,omitempty"`
 }
 
+// ArchiveRequest carries a repository snapshot inline. Data is base64 in
+// JSON.
+type ArchiveRequest struct {
+	Data []byte `json:"data"`
+	// Format is tar, tar.gz, or zip; empty detects it from the data.
+	Format          string `json:"format,omitempty"`
+	StripComponents int    `json:"stripComponents,omitempty"`
+}
+
 // SuggestRequest describes a suggest run input.
 type SuggestRequest struct {
 	Client  string `json:"client"`
@@ -21,6 +21,8 @@ type DiagnosticsSettings = {
   includeRelatedInfo: boolean;
   includeEvidence: boolean;
   redactPaths: string;
+  rulesDir: string;
+  allowPlugins: boolean;
 };
 
 function readDiagnosticsSettings(): DiagnosticsSettings {
@@ -40,6 +42,8 @@ function readDiagnosticsSettings(): DiagnosticsSettings {
     ),
     includeEvidence: config.get<boolean>("diagnostics.includeEvidence", true),
     redactPaths: config.get<string>("diagnostics.redactPaths", "never"),
+    rulesDir: config.get<string>("diagnostics.rulesDir", ""),
+    allowPlugins: config.get<boolean>("diagnostics.allowPlugins", false),
   };
 }
 